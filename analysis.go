@@ -0,0 +1,85 @@
+//go:build !gocatch_lite
+
+package catch
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+)
+
+// detectContextFromSource analyzes source code around error line
+func detectContextFromSource(filename string, errorLine int) map[string]interface{} {
+	ctx := make(map[string]interface{})
+
+	// Wrap in defer to handle any panics from AST parsing
+	defer func() {
+		if r := recover(); r != nil {
+			// Silently fall back to basic context if AST parsing fails
+		}
+	}()
+
+	// Parse the source file
+	fset := token.NewFileSet()
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return ctx
+	}
+
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return ctx
+	}
+
+	// Find variables and function calls near the error line
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+
+		pos := fset.Position(n.Pos())
+		if abs(pos.Line-errorLine) <= 2 { // Within 2 lines of error
+			switch node := n.(type) {
+			case *ast.CallExpr:
+				// Detect function calls like os.Open(filename)
+				if ident, ok := node.Fun.(*ast.SelectorExpr); ok {
+					if x, ok := ident.X.(*ast.Ident); ok {
+						funcName := fmt.Sprintf("%s.%s", x.Name, ident.Sel.Name)
+						ctx["function_call"] = funcName
+
+						// Extract arguments
+						for i, arg := range node.Args {
+							if ident, ok := arg.(*ast.Ident); ok {
+								ctx[fmt.Sprintf("arg_%d_%s", i, ident.Name)] = ident.Name
+
+								// Common patterns
+								if strings.Contains(ident.Name, "file") || strings.Contains(ident.Name, "path") {
+									ctx["target_file"] = ident.Name
+								}
+							}
+						}
+					}
+				}
+			case *ast.AssignStmt:
+				// Detect assignments like file, err := os.Open(...)
+				for _, expr := range node.Lhs {
+					if ident, ok := expr.(*ast.Ident); ok {
+						if ident.Name != "err" {
+							ctx["assigned_var"] = ident.Name
+						}
+					}
+				}
+			}
+		}
+		return true
+	})
+
+	if hint := localsHint(fset, file, errorLine); hint != "" {
+		ctx["locals_hint"] = hint
+	}
+
+	return ctx
+}
@@ -0,0 +1,32 @@
+//go:build !gocatch_lite
+
+package catch
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// trimmedStore creates its error on a line calling strings.TrimSpace,
+// for source analysis to find
+func trimmedStore() error {
+	return newCallersError(strings.TrimSpace(" store locked "))
+}
+
+func TestOriginSourceAnalysis(t *testing.T) {
+	for _, focus := range []SourceFocus{SourceHandled, SourceOrigin} {
+		t.Run(string(focus), func(t *testing.T) {
+			config := testConfig()
+			config.OriginSource = focus
+			rec := recordCatch(t, config)
+
+			Err(fmt.Errorf("starting: %w", trimmedStore()))
+
+			atOrigin := rec.reports()[0].Context["function_call"] == "strings.TrimSpace"
+			if atOrigin != (focus == SourceOrigin) {
+				t.Errorf("function_call = %v under %s", rec.reports()[0].Context["function_call"], focus)
+			}
+		})
+	}
+}
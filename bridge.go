@@ -0,0 +1,79 @@
+package catch
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrCatcherCycle is returned by SetParent when the parent is the child
+// or forwards to it already
+var ErrCatcherCycle = errors.New("catch: catcher hierarchy would contain a cycle")
+
+// bridgeMu guards the parent links of every catcher, so cycle checks see
+// a consistent hierarchy
+var bridgeMu sync.RWMutex
+
+// SetParent makes child forward the reports it handles to parent, after
+// its own policy: what the child drops, deduplicates or exits on is
+// decided by the child alone. Forwarded reports carry the child's label
+// in ErrorInfo.Catcher; the parent's hooks and counters see them, its
+// outputs write them when its ChildSinks is set, and they never make it
+// exit. A nil parent removes the link.
+// Usage: catch.SetParent(library.Catcher, catch.Catch)
+func SetParent(child, parent *ErrorCatcher) error {
+	bridgeMu.Lock()
+	defer bridgeMu.Unlock()
+	for p := parent; p != nil; p = p.parent {
+		if p == child {
+			return ErrCatcherCycle
+		}
+	}
+	child.parent = parent
+	return nil
+}
+
+// label names e in reports it forwards: Config.Source, or the package
+// that called New
+func (e *ErrorCatcher) label() string {
+	if source := e.getConfig().Source; source != "" {
+		return source
+	}
+	return e.creator
+}
+
+// forwardToParent hands a report e handled to its parent, if any. A
+// report forwarded on up keeps the label of the catcher it started at.
+func (e *ErrorCatcher) forwardToParent(info ErrorInfo) {
+	bridgeMu.RLock()
+	parent := e.parent
+	bridgeMu.RUnlock()
+	if parent == nil {
+		return
+	}
+
+	if info.Catcher == "" {
+		info.Catcher = e.label()
+	}
+	context := make(map[string]interface{}, len(info.Context)+1)
+	for k, v := range info.Context {
+		context[k] = v
+	}
+	info.Context = context
+	setContext(&info, "catcher", info.Catcher)
+	info.timing = nil
+	parent.receive(info)
+}
+
+// receive takes a report forwarded by a child catcher
+func (e *ErrorCatcher) receive(info ErrorInfo) {
+	config := e.getConfig()
+	if info.Severity < config.minLevel() {
+		return
+	}
+	action := e.runHooks(&info, config)
+	if config.ChildSinks && action&SkipOutput == 0 {
+		e.writeOutputs(&info, config)
+	}
+	e.stats.record(info)
+	e.forwardToParent(info)
+}
@@ -0,0 +1,132 @@
+package catch
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// bridged returns a child catcher forwarding to a parent, each writing
+// its console reports to the returned builders
+func bridged(t *testing.T, child, parent ErrorConfig) (c, p *ErrorCatcher, childOut, parentOut *strings.Builder) {
+	t.Helper()
+	childOut, parentOut = &strings.Builder{}, &strings.Builder{}
+	child.Output, parent.Output = childOut, parentOut
+	c, p = New(child), New(parent)
+	if err := SetParent(c, p); err != nil {
+		t.Fatal(err)
+	}
+	return c, p, childOut, parentOut
+}
+
+func TestSetParentRefusesCycles(t *testing.T) {
+	a, b, c := New(testConfig()), New(testConfig()), New(testConfig())
+
+	if err := SetParent(a, a); !errors.Is(err, ErrCatcherCycle) {
+		t.Errorf("SetParent(a, a) = %v, want ErrCatcherCycle", err)
+	}
+	if err := SetParent(b, a); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetParent(c, b); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetParent(a, c); !errors.Is(err, ErrCatcherCycle) {
+		t.Errorf("closing a three-level loop = %v, want ErrCatcherCycle", err)
+	}
+	if a.parent != nil {
+		t.Error("refused link was kept")
+	}
+	if err := SetParent(c, nil); err != nil || c.parent != nil {
+		t.Errorf("removing the link = %v, parent %p", err, c.parent)
+	}
+	if err := SetParent(a, c); err != nil {
+		t.Errorf("SetParent after the loop was broken = %v", err)
+	}
+}
+
+func TestForwardedReportsCarryChildLabel(t *testing.T) {
+	childConfig := testConfig()
+	childConfig.Source = "billing"
+	c, p, _, _ := bridged(t, childConfig, testConfig())
+	quiet := testConfig()
+	quiet.Output = io.Discard
+	grand := New(quiet)
+	if err := SetParent(p, grand); err != nil {
+		t.Fatal(err)
+	}
+	var seen []ErrorInfo
+	for _, catcher := range []*ErrorCatcher{p, grand} {
+		catcher.RegisterHook(func(info *ErrorInfo) HookAction {
+			seen = append(seen, *info)
+			return Continue
+		})
+	}
+
+	c.Err(errors.New("card declined"))
+	if len(seen) != 2 {
+		t.Fatalf("ancestors saw %d reports, want 2", len(seen))
+	}
+	for i, info := range seen {
+		if info.Catcher != "billing" || info.Context["catcher"] != "billing" {
+			t.Errorf("ancestor %d saw catcher %q, context %v; want billing", i, info.Catcher, info.Context["catcher"])
+		}
+	}
+	if n := p.Summary().ErrorCount; n != 1 {
+		t.Errorf("parent counted %d errors, want 1", n)
+	}
+
+	unnamed := New(quiet)
+	if err := SetParent(unnamed, p); err != nil {
+		t.Fatal(err)
+	}
+	seen = nil
+	unnamed.Err(errors.New("card declined"))
+	if len(seen) == 0 || seen[0].Catcher != "catch" {
+		t.Errorf("unnamed child labelled %v, want the package calling New", seen)
+	}
+}
+
+func TestParentWithoutChildSinksWritesNothing(t *testing.T) {
+	parentConfig := testConfig()
+	parentConfig.LogToFile = filepath.Join(t.TempDir(), "parent.log")
+	c, _, childOut, parentOut := bridged(t, testConfig(), parentConfig)
+
+	c.Err(errors.New("card declined"))
+	if countHeadlines(childOut.String(), "card declined") != 1 {
+		t.Errorf("child report missing:\n%s", childOut)
+	}
+	if parentOut.Len() != 0 {
+		t.Errorf("parent wrote a forwarded report:\n%s", parentOut)
+	}
+	if _, err := os.Stat(parentConfig.LogToFile); !os.IsNotExist(err) {
+		t.Errorf("parent log file written (stat: %v)", err)
+	}
+
+	parentConfig.ChildSinks = true
+	c, _, _, parentOut = bridged(t, testConfig(), parentConfig)
+	c.Err(errors.New("card declined"))
+	if countHeadlines(parentOut.String(), "card declined") != 1 {
+		t.Errorf("parent with ChildSinks lacks the report:\n%s", parentOut)
+	}
+}
+
+func TestParentNeverExitsForChild(t *testing.T) {
+	exits := stubExit(t)
+	parentConfig := testConfig()
+	parentConfig.ExitOnError = true
+	parentConfig.ChildSinks = true
+	c, _, _, _ := bridged(t, testConfig(), parentConfig)
+
+	c.Err(errors.New("card declined"))
+	if len(*exits) != 0 {
+		t.Errorf("exits %v for a child that does not exit", *exits)
+	}
+	c.Fatal(errors.New("ledger corrupt"))
+	if len(*exits) != 1 {
+		t.Errorf("exits %v, want the child's one exit", *exits)
+	}
+}
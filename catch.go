@@ -2,19 +2,23 @@ package catch
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
-	"go/ast"
-	"go/parser"
-	"go/token"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
 )
 
-// Catch is a global variable that can be used to catch and handle errors
-var Catch = ErrorCatcher{}
+// Catch is a global variable that can be used to catch and handle errors.
+// It is a pointer so every handle to it, including ContextualCatchers
+// created before Configure, sees the current configuration.
+var Catch = &ErrorCatcher{}
 
 // ErrorConfig holds configuration for error handling
 type ErrorConfig struct {
@@ -22,12 +26,200 @@ type ErrorConfig struct {
 	ShowSourceCode      bool
 	ShowSuggestions     bool
 	ExitOnError         bool
-	LogToFile           string
+	LogToFile           string    // Relative paths resolve against the startup directory
+	MaxLogSizeBytes     int64     // Rotate LogToFile to name.1 before it grows past this; 0 never rotates
+	MaxLogBackups       int       // Rotated files kept, name.1 newest (default 1)
+	LogFormat           LogFormat // LogToFile entry format (default LogText)
 	MaxStackDepth       int
 	ContextLines        int
-	UseColors           bool
-	EnableSmartAnalysis bool // New: Toggle for source code analysis
-	EnableStackAnalysis bool // New: Toggle for stack trace analysis
+	UseColors           bool      // Colors on terminals; see Colors for finer control
+	Colors              ColorMode // Overrides UseColors when set; unset, UseColors means Always and its absence Never
+	Accessible          bool      // Text markers instead of color; also set by GOCATCH_ACCESSIBLE
+	EnableSmartAnalysis bool      // New: Toggle for source code analysis
+	EnableStackAnalysis bool      // New: Toggle for stack trace analysis
+	ShowUptime          bool      // Show how long the process had been running under errors and fatal errors
+
+	// Stack trace filters. Hidden frames are summarized in place as
+	// "... N frames hidden ..." and MaxStackDepth counts only the frames
+	// shown. StackFilter keeps only functions starting with one of the
+	// prefixes, e.g. "example.com/app/".
+	HideRuntimeFrames bool // Hide runtime and testing harness frames
+	HidePackageFrames bool // Hide frames inside gocatch itself
+	StackFilter       []string
+
+	// Console throttling for error bursts: more than ThrottleCompactAfter
+	// reports in one second switch to one-liners, more than
+	// ThrottleRollupAfter to a per-second roll-up. The log file always
+	// receives full reports. Zero, the default, disables throttling; 20
+	// and 200 suit a terminal.
+	ThrottleCompactAfter int
+	ThrottleRollupAfter  int
+	ThrottleQuietPeriod  time.Duration // Calm time before full reports resume (default DefaultThrottleQuietPeriod)
+
+	RunSummaryPath string // Write a JSON run summary here on Close or fatal exit
+	ErrorBudget    int    // Errors tolerated before the run summary reports failure
+
+	// ExitCode is the process exit status for fatal errors (default 1).
+	// With DryRunExit the exit is skipped; reports are marked with the
+	// code that would have been used and Summary counts them.
+	ExitCode   int
+	DryRunExit bool
+
+	// ParseMessageFields moves a leading "key=value key=value: " prefix of
+	// the error message into context, shortening the headline
+	ParseMessageFields bool
+
+	// MaxReportBytes caps the size of each rendered report; stack frames,
+	// the source block and context entries are dropped in that order to
+	// fit. JSON reports drop the same items and count them in the
+	// *_omitted keys, staying valid JSON. Zero means no limit.
+	MaxReportBytes int
+
+	PathStyle     PathStyle         // How file paths are displayed (default PathBase)
+	ShortenHome   bool              // Show the home directory as ~ (%USERPROFILE% on Windows)
+	SourcePathMap map[string]string // Build path prefix -> local prefix for loading sources
+
+	Handler       Handler      // Replaces the console output; nil means ConsoleHandler
+	Output        io.Writer    // Console destination (default os.Stderr)
+	RouteToStdout bool         // Send console output to os.Stdout when Output is nil
+	Format        OutputFormat // Console output format (default FormatPretty)
+	HTTPDebug     bool         // HTTPMiddleware answers a panic with the full JSON report
+
+	ShowVerboseError bool // Show what %+v prints beyond Error() as a details block
+
+	PartialMaxListed int // Failures listed in a Partial report (default DefaultPartialListed)
+
+	// CommonShare is the fraction of a group's reports that must carry the
+	// same context value for CommonContext to count it as shared (default
+	// DefaultCommonShare)
+	CommonShare float64
+
+	// ShowRawError ends console reports with the exact Error() text (and
+	// %+v with ShowVerboseError) between "----- raw error -----" markers,
+	// uncolored and unsanitized, for copying into support tickets
+	ShowRawError bool
+
+	OnHandlerIssue func(HandlerIssue) // Observes failures of the log file, handlers and probes
+
+	Fields FieldSelector // Fields emitted by the JSON, LogJSONL and compact formats
+
+	// FlushBefore starts console reports on a fresh line when output
+	// written through WrapStdout was left mid-line on the same stream, and
+	// ends each full report with a separator line
+	FlushBefore bool
+
+	// ExpandStructs expands every exported field of a struct passed as
+	// context, not only those tagged `catch:"name"`
+	ExpandStructs bool
+
+	// HeaderContextKeys are context keys promoted to the log file's
+	// one-line "fields:" summary, e.g. request_id
+	HeaderContextKeys []string
+
+	// DiagnosticsDir receives a bundle for each fatal error: the JSON
+	// report plus the main module's source files on the stack
+	DiagnosticsDir string
+
+	OnDegrade func(Degradation) // Observes reports rendered below full detail
+
+	// ShowHints ends the first console report of the process with a note
+	// on how to quiet or adjust the output, for users who meet gocatch
+	// through a dependency. Off by default; only the global Catch shows
+	// it. HintText replaces the default wording.
+	ShowHints bool
+	HintText  string
+
+	// Timing measures each phase of handling an error, appending a
+	// breakdown to reports and feeding TimingStats; GOCATCH_TIMING=1 turns
+	// it on for every catcher
+	Timing bool
+
+	// StrictMode reports misuse of the API once per pattern, as warnings:
+	// a deferred Recover() whose result is never called, Try's result
+	// called with nil, key-value context missing its last value, and
+	// contradictory settings
+	StrictMode bool
+
+	// ValidateRendering makes Configure render a sample report through the
+	// built-in sinks and report each one whose output would not parse as a
+	// CFG001 warning; see CheckRendering
+	ValidateRendering bool
+
+	// LeaksAsErrors reports resources registered with Track and left open
+	// as errors rather than warnings
+	LeaksAsErrors bool
+
+	// DevMarkersWarn reports Todo and Unreachable as warnings rather than
+	// fatal errors, as builds tagged gocatch_prod do
+	DevMarkersWarn bool
+
+	// OriginSource chooses whether errors carrying their creation stack
+	// show source, and get source analysis, where they were handled
+	// (default), where they were created, or both
+	OriginSource SourceFocus
+
+	// LowMemory keeps gocatch small for memory-limited processes: source
+	// analysis is skipped, context is cut to a few short entries,
+	// diagnostics bundles leave out sources, and console reports are
+	// written section by section. Set on the global Catch it also shrinks
+	// the silenced-event and timing buffers every catcher shares.
+	LowMemory bool
+
+	// Logger receives every report as a structured record: Warn level for
+	// warnings, Error otherwise. With LoggerOnly the console output, or
+	// Handler, is skipped.
+	Logger     *slog.Logger
+	LoggerOnly bool
+
+	// PrimaryCode picks the leaf of a cause tree, from errors joined inside
+	// wrapped errors, that sets the report's code (default PrimaryFirst)
+	PrimaryCode PrimaryCodeRule
+
+	// Source labels reports this catcher forwards to a parent set with
+	// SetParent (default the package that called New). ChildSinks makes
+	// reports forwarded to this catcher go to its log file, logger and
+	// handler as well as its hooks and counters.
+	Source     string
+	ChildSinks bool
+
+	// DedupWindow, when set, gives only the first of identical errors
+	// (same code, message and reporting line) a full report; repeats
+	// within the window are counted and summarized in one line when it
+	// closes or the process exits. Fatal errors are always reported.
+	DedupWindow time.Duration
+
+	// MinLevel drops reports of lower severity; catch.Silenced lists
+	// them. Nil shows everything. A Severity is a Leveler, so it can be
+	// set directly: MinLevel: catch.LevelError.
+	MinLevel Leveler
+
+	// PromoteNotices lists Notice IDs to report as warnings when first
+	// hit, for rolling out behavior changes in stages
+	PromoteNotices []string
+
+	// MaxTrackedKeys bounds the per-call-site state kept for sampling
+	// and the open DedupWindow windows together; the least recently seen sites are
+	// forgotten first, and a window forgotten early writes its summary
+	// then (default DefaultMaxTrackedKeys)
+	MaxTrackedKeys int
+
+	// Interactive offers to run the FixCommand given to a call after its
+	// report, reading the answer from Input (default os.Stdin). Without it
+	// fixes are only shown as help.
+	Interactive bool
+	Input       io.Reader
+
+	// EnrichmentBudget bounds the time spent on enrichment steps (built-in
+	// analysis and Enrichers) per report; zero, the default, means no
+	// limit. DefaultEnrichmentBudget suits programs with slow probes.
+	EnrichmentBudget time.Duration
+	Enrichers        []Enricher
+
+	// Prober is what enrichment steps probe the system through (default
+	// RealProber); EnrichFuncs are further steps using it, run after the
+	// built-in ones and before Enrichers
+	Prober      Prober
+	EnrichFuncs []EnrichFunc
 }
 
 // DefaultConfig provides sensible defaults with Rust-like formatting
@@ -39,13 +231,36 @@ var DefaultConfig = ErrorConfig{
 	MaxStackDepth:       10,
 	ContextLines:        2,
 	UseColors:           true,
+	Colors:              ColorAuto,
 	EnableSmartAnalysis: true,
 	EnableStackAnalysis: true,
+	ShowUptime:          true,
 }
 
 // ErrorCatcher is a type that can be used to catch and handle errors
 type ErrorCatcher struct {
-	Config ErrorConfig
+	Config ErrorConfig // Set with Configure; direct writes are not synchronized
+
+	mu        sync.RWMutex // Guards Config, hasConfig and hooks
+	hasConfig bool         // Set by New and Configure
+	hooks     []Hook
+	stats     runStats
+	dedup     dedupState
+	keys      trackedKeys   // AssertSampled sites and DedupWindow windows
+	parent    *ErrorCatcher // Set with SetParent, guarded by bridgeMu
+	creator   string        // Package that called New
+	startup   startupState
+	resources sync.Map // Open resources registered with Track
+}
+
+// New creates an independent catcher with its own configuration
+// Usage: c := catch.New(catch.DefaultConfig)
+func New(config ErrorConfig) *ErrorCatcher {
+	e := &ErrorCatcher{Config: config, hasConfig: true}
+	if frames := reportFrames(1); len(frames) > 0 {
+		e.creator, _ = splitFuncName(frames[0].Function)
+	}
+	return e
 }
 
 // Enhanced error information
@@ -53,19 +268,81 @@ type ErrorInfo struct {
 	Error       error
 	File        string
 	Line        int
-	Column      int
+	Column      int // 1-based byte column of the failing expression, 0 when unknown
+	Span        int // Width of that expression in characters; 0 marks Column alone
+	EndLine     int // Last line of an expression spanning lines, 0 otherwise
+	EndColumn   int // Byte column of its last character on EndLine
 	Function    string
 	Context     map[string]interface{}
 	Stack       []StackFrame
 	SourceLines []SourceLine
 	ErrorCode   string
 	Suggestion  string
+	Uptime      time.Duration // Time since process start when the error occurred
+	Severity    Severity
+	GroupKey    string            // Stable key grouping the same failure across hosts
+	ID          string            // Unique identifier of this occurrence
+	Time        time.Time         // When the error was handled
+	Headline    string            // Message shown in the header when it differs from Error()
+	Causes      []string          // Messages of the wrapped errors beneath Headline, innermost last
+	Joined      []JoinedError     // The errors of an errors.Join group, each with its own code
+	CauseTree   *CauseNode        // Causes of a chain with joins below the top, replacing Causes
+	Provenance  map[string]string // Where non-explicit context keys came from
+	OriginStack []StackFrame      // Stack captured where the error was created, when known
+	Details     string            // Extra %+v output when ShowVerboseError is on
+
+	// OriginSourceLines is the snippet where the error was created, at
+	// OriginFile:OriginLine, when OriginSource selects it
+	OriginSourceLines []SourceLine
+	OriginFile        string
+	OriginLine        int
+
+	HandlerIssues []HandlerIssue  // Failures while handling this error, shown as a trailing note
+	WouldExit     int             // Exit code skipped under DryRunExit, 0 if none
+	Catcher       string          // Label of the child catcher a forwarded report came from
+	DegradedTo    Degradation     // Simplest rendering level used for this report
+	opts          callOptions     // Per-call Options
+	timing        *pipelineTiming // Phase durations when timing is enabled
+	degraded      *degradeState   // Level renders of this report fell to
+	ctxCause      error           // Cancellation cause of the ErrCtx context, the innermost cause
+
+	// StackFingerprint hashes the shape of the call path, ignoring line
+	// numbers; a grouping hint that survives code moving between versions
+	StackFingerprint string
+
+	stackOmitted   int // Frames dropped to fit MaxReportBytes
+	originOmitted  int // Origin frames dropped to fit MaxReportBytes
+	sourceOmitted  int // Source lines dropped to fit MaxReportBytes
+	contextOmitted int // Context entries dropped to fit MaxReportBytes
+	joinedOmitted  int // Joined errors left out of a Partial report
+}
+
+// HasLocation reports whether the file and line of the error are known
+func (info ErrorInfo) HasLocation() bool {
+	return info.File != ""
+}
+
+// headline returns the message shown in the report header
+func (info ErrorInfo) headline() string {
+	if info.Headline != "" {
+		return info.Headline
+	}
+	return describeError(info.Error)
+}
+
+// location renders file:line for display, or a placeholder when unknown
+func (info ErrorInfo) location(config ErrorConfig) string {
+	if !info.HasLocation() {
+		return "(location unavailable)"
+	}
+	return fmt.Sprintf("%s:%d", info.displayPath(info.File, config), info.Line)
 }
 
 type StackFrame struct {
 	File     string
 	Line     int
 	Function string
+	Hidden   int // Set only on placeholders: the number of frames filters hid here
 }
 
 type SourceLine struct {
@@ -91,12 +368,22 @@ const (
 
 // Configure sets the error handling configuration
 func (e *ErrorCatcher) Configure(config ErrorConfig) *ErrorCatcher {
-	e.Config = config
+	e.mu.Lock()
+	e.Config, e.hasConfig = config, true
+	e.mu.Unlock()
+	if w := consoleWriter(config); w != nil {
+		terminalFor(w) // Assess the console once, at configuration time
+	}
+	e.ready()
+	e.checkConfigMisuse(config)
+	if config.ValidateRendering {
+		e.checkRendering(config)
+	}
 	return e
 }
 
 // WithContext adds contextual information to error handling
-func (e ErrorCatcher) WithContext(key string, value interface{}) *ContextualCatcher {
+func (e *ErrorCatcher) WithContext(key string, value interface{}) *ContextualCatcher {
 	return &ContextualCatcher{
 		catcher: e,
 		context: map[string]interface{}{key: value},
@@ -105,7 +392,7 @@ func (e ErrorCatcher) WithContext(key string, value interface{}) *ContextualCatc
 
 // ContextualCatcher allows chaining context information
 type ContextualCatcher struct {
-	catcher ErrorCatcher
+	catcher *ErrorCatcher
 	context map[string]interface{}
 }
 
@@ -115,87 +402,116 @@ func (c *ContextualCatcher) WithContext(key string, value interface{}) *Contextu
 	return c
 }
 
-// Set handles error with accumulated context
-func (c *ContextualCatcher) Set(err error) error {
+// Set handles error with accumulated context and any Options
+func (c *ContextualCatcher) Set(err error, opts ...Option) error {
 	if err != nil {
-		info := c.catcher.buildErrorInfo(err, 1)
-		info.Context = c.context
+		info := c.catcher.buildErrorInfo(err)
+		for k, v := range c.context {
+			info.Context[k] = v
+		}
+		info.opts = collectOptions(opts)
 		c.catcher.handleError(info)
 	}
 	return err
 }
 
-// X is the main auto-detecting error handler
+// X is the main auto-detecting error handler. It returns err itself, so
+// errors.Is and errors.As on the result behave as on err; once a Fix was
+// run in Interactive mode the result matches ErrFixApplied as well.
 // Usage: except.X(err)
 // Usage: except.X(err, filename)
 // Usage: except.X(err, "processing", filename)
 // Usage: except.X(err, map[string]interface{}{"file": filename, "op": "read"})
 func Err(err error, context ...interface{}) error {
+	return Catch.Err(err, context...)
+}
+
+// Err is the package-level Err reporting through this catcher and its
+// configuration. The location is the first frame outside this package
+// either way, so both report the caller's line.
+// Usage: c := catch.New(config); c.Err(err, filename)
+func (e *ErrorCatcher) Err(err error, context ...interface{}) error {
 	if err == nil {
 		return nil
 	}
 
-	// Build smart context
-	info := buildSmartErrorInfo(err, context...)
-	Catch.handleError(info)
-	return err
+	opts, context := splitOptions(context)
+	e.checkContextMisuse(context)
+	e.handleSmart(err, opts, context)
+	return fixOutcome(err, opts)
 }
 
-// buildSmartErrorInfo creates comprehensive error info with auto-detection
-func buildSmartErrorInfo(err error, context ...interface{}) ErrorInfo {
-	config := Catch.getConfig()
+// Warn reports err as a warning: yellow, without a stack backtrace and
+// never exiting. Pass AsWarn to Err instead to keep the backtrace.
+// Usage: catch.Warn(err, "cache", key)
+func Warn(err error, context ...interface{}) error {
+	return Catch.Warn(err, context...)
+}
 
-	// Get caller information
-	pc, file, line, ok := runtime.Caller(2) // Skip X() and buildSmartErrorInfo()
-	if !ok {
-		file = "unknown"
-		line = 0
-	}
+// Warn is the package-level Warn reporting through this catcher
+func (e *ErrorCatcher) Warn(err error, context ...interface{}) error {
+	return e.Err(err, append(context[:len(context):len(context)], AsWarn, NoStack)...)
+}
 
-	var funcName string
-	if fn := runtime.FuncForPC(pc); fn != nil {
-		funcName = fn.Name()
-		if lastSlash := strings.LastIndex(funcName, "/"); lastSlash >= 0 {
-			funcName = funcName[lastSlash+1:]
-		}
-	}
+// Fatal reports err as fatal and exits, whatever ExitOnError says
+// Usage: catch.Fatal(err, "config", path)
+func Fatal(err error, context ...interface{}) error {
+	return Catch.Fatal(err, context...)
+}
 
-	info := ErrorInfo{
-		Error:      err,
-		File:       file,
-		Line:       line,
-		Function:   funcName,
-		Context:    make(map[string]interface{}),
-		ErrorCode:  generateSmartErrorCode(err),
-		Suggestion: generateSmartSuggestion(err),
-	}
+// Fatal is the package-level Fatal reporting through this catcher
+func (e *ErrorCatcher) Fatal(err error, context ...interface{}) error {
+	return e.Err(err, append(context[:len(context):len(context)], AsFatal)...)
+}
 
-	// Auto-detect and build context
-	info.Context = buildSmartContext(file, line, context...)
+// handleSmart reports err through e with auto-detected context
+func (e *ErrorCatcher) handleSmart(err error, opts callOptions, context []interface{}) {
+	info := e.buildSmartErrorInfo(err, context...)
+	info.opts = opts
+	e.handleError(info)
+}
 
-	// Load source code context if enabled
-	if config.ShowSourceCode {
-		info.SourceLines = Catch.loadSourceContext(file, line, config.ContextLines)
-	}
+// buildSmartErrorInfo creates comprehensive error info with auto-detection
+func (e *ErrorCatcher) buildSmartErrorInfo(err error, context ...interface{}) ErrorInfo {
+	config := e.getConfig()
 
-	// Build stack trace if enabled
-	if config.ShowStackTrace {
-		info.Stack = Catch.buildStackTrace(1)
+	info := ErrorInfo{
+		Error:   err,
+		Context: make(map[string]interface{}),
+		Uptime:  now().Sub(processStart),
+		timing:  newTiming(config),
+	}
+	frames := info.locate(config)
+	start := info.timing.now()
+	info.ErrorCode, info.Suggestion = classify(err)
+	info.timing.add(phaseClassify, start)
+	start = info.timing.now()
+
+	// Auto-detect and build context, at the origin when OriginSource asks
+	file, line := info.File, info.Line
+	if originFile, originLine, ok := originSite(err, config); ok {
+		file, line = originFile, originLine
+	}
+	info.Context = buildSmartContext(file, line, frames, config, context...)
+	if _, exists := info.Context["error_type"]; !exists {
+		info.Context["error_type"] = errorTypeName(err)
 	}
 
+	e.loadSources(&info, config)
+	info.timing.add(phaseAnalysis, start)
 	return info
 }
 
 // buildSmartContext auto-detects context from various sources
-func buildSmartContext(file string, line int, context ...interface{}) map[string]interface{} {
+func buildSmartContext(file string, line int, frames []runtime.Frame, config ErrorConfig, context ...interface{}) map[string]interface{} {
 	ctx := make(map[string]interface{})
 
 	// 1. Parse provided context
-	ctx = parseProvidedContext(ctx, context...)
+	ctx = parseProvidedContext(ctx, config, context...)
 
 	// 2. Auto-detect from source code
-	if Catch.getConfig().EnableSmartAnalysis {
-		sourceCtx := detectContextFromSource(file, line)
+	if config.EnableSmartAnalysis && !config.LowMemory && file != "" {
+		sourceCtx := detectContextFromSource(mapSourcePath(file, config.SourcePathMap), line)
 		for k, v := range sourceCtx {
 			if _, exists := ctx[k]; !exists { // Don't override explicit context
 				ctx[k] = v
@@ -204,8 +520,8 @@ func buildSmartContext(file string, line int, context ...interface{}) map[string
 	}
 
 	// 3. Auto-detect from stack trace
-	if Catch.getConfig().EnableStackAnalysis {
-		stackCtx := detectContextFromStack()
+	if config.EnableStackAnalysis {
+		stackCtx := detectContextFromStack(frames)
 		for k, v := range stackCtx {
 			if _, exists := ctx[k]; !exists {
 				ctx[k] = v
@@ -217,7 +533,7 @@ func buildSmartContext(file string, line int, context ...interface{}) map[string
 }
 
 // parseProvidedContext handles various context input formats
-func parseProvidedContext(ctx map[string]interface{}, context ...interface{}) map[string]interface{} {
+func parseProvidedContext(ctx map[string]interface{}, config ErrorConfig, context ...interface{}) map[string]interface{} {
 	if len(context) == 0 {
 		return ctx
 	}
@@ -250,6 +566,13 @@ func parseProvidedContext(ctx map[string]interface{}, context ...interface{}) ma
 		case int, int64, float64:
 			ctx[fmt.Sprintf("value_%d", i)] = v
 		default:
+			// Structs expand into one entry per tagged field
+			if fields, ok := expandStruct(v, config.ExpandStructs); ok {
+				for k, fv := range fields {
+					ctx[k] = fv
+				}
+				continue
+			}
 			// Try to infer meaning from type
 			ctx[inferContextKey(v)] = v
 		}
@@ -271,135 +594,94 @@ func inferContextKey(value interface{}) string {
 		return "description"
 	case *os.File:
 		return "file"
+	case *BodyPreview:
+		return "body_preview"
 	default:
 		t := reflect.TypeOf(value)
-		if t != nil {
+		if t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t != nil && t.Name() != "" {
 			return strings.ToLower(t.Name())
 		}
 		return "context"
 	}
 }
 
-// detectContextFromSource analyzes source code around error line
-func detectContextFromSource(filename string, errorLine int) map[string]interface{} {
+// detectContextFromStack analyzes the reporting frame for patterns
+func detectContextFromStack(frames []runtime.Frame) map[string]interface{} {
 	ctx := make(map[string]interface{})
-
-	// Wrap in defer to handle any panics from AST parsing
-	defer func() {
-		if r := recover(); r != nil {
-			// Silently fall back to basic context if AST parsing fails
-		}
-	}()
-
-	// Parse the source file
-	fset := token.NewFileSet()
-	src, err := os.ReadFile(filename)
-	if err != nil {
+	if len(frames) == 0 || frames[0].Function == "" {
 		return ctx
 	}
+	frame := frames[0]
+	funcName := shortFuncName(frame.Function)
 
-	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
-	if err != nil {
-		return ctx
+	// Detect patterns in function names
+	lower := strings.ToLower(funcName)
+	switch {
+	case strings.Contains(lower, "read"):
+		ctx["operation_type"] = "read"
+	case strings.Contains(lower, "write"):
+		ctx["operation_type"] = "write"
+	case strings.Contains(lower, "open"):
+		ctx["operation_type"] = "open"
+	case strings.Contains(lower, "process"):
+		ctx["operation_type"] = "process"
+	case strings.Contains(lower, "handle"):
+		ctx["operation_type"] = "handle"
 	}
 
-	// Find variables and function calls near the error line
-	ast.Inspect(file, func(n ast.Node) bool {
-		if n == nil {
-			return false
-		}
-
-		pos := fset.Position(n.Pos())
-		if abs(pos.Line-errorLine) <= 2 { // Within 2 lines of error
-			switch node := n.(type) {
-			case *ast.CallExpr:
-				// Detect function calls like os.Open(filename)
-				if ident, ok := node.Fun.(*ast.SelectorExpr); ok {
-					if x, ok := ident.X.(*ast.Ident); ok {
-						funcName := fmt.Sprintf("%s.%s", x.Name, ident.Sel.Name)
-						ctx["function_call"] = funcName
-
-						// Extract arguments
-						for i, arg := range node.Args {
-							if ident, ok := arg.(*ast.Ident); ok {
-								ctx[fmt.Sprintf("arg_%d_%s", i, ident.Name)] = ident.Name
-
-								// Common patterns
-								if strings.Contains(ident.Name, "file") || strings.Contains(ident.Name, "path") {
-									ctx["target_file"] = ident.Name
-								}
-							}
-						}
-					}
-				}
-			case *ast.AssignStmt:
-				// Detect assignments like file, err := os.Open(...)
-				for _, expr := range node.Lhs {
-					if ident, ok := expr.(*ast.Ident); ok {
-						if ident.Name != "err" {
-							ctx["assigned_var"] = ident.Name
-						}
-					}
-				}
-			}
-		}
-		return true
-	})
+	// Add calling context
+	ctx["caller_function"] = funcName
+	ctx["caller_location"] = fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
 
 	return ctx
 }
 
-// detectContextFromStack analyzes stack frames for patterns
-func detectContextFromStack() map[string]interface{} {
-	ctx := make(map[string]interface{})
-
-	// Look at calling functions
-	for i := 3; i < 8; i++ { // Skip our internal calls
-		pc, file, line, ok := runtime.Caller(i)
-		if !ok {
-			break
-		}
-
-		if fn := runtime.FuncForPC(pc); fn != nil {
-			funcName := fn.Name()
-
-			// Clean function name
-			if lastSlash := strings.LastIndex(funcName, "/"); lastSlash >= 0 {
-				funcName = funcName[lastSlash+1:]
-			}
+// errorTypeName returns the concrete type of an error, e.g. *fs.PathError
+func errorTypeName(err error) string {
+	t := reflect.TypeOf(err)
+	if t == nil {
+		return "<nil>"
+	}
+	return t.String()
+}
 
-			// Detect patterns in function names
-			lower := strings.ToLower(funcName)
-			switch {
-			case strings.Contains(lower, "read"):
-				ctx["operation_type"] = "read"
-			case strings.Contains(lower, "write"):
-				ctx["operation_type"] = "write"
-			case strings.Contains(lower, "open"):
-				ctx["operation_type"] = "open"
-			case strings.Contains(lower, "process"):
-				ctx["operation_type"] = "process"
-			case strings.Contains(lower, "handle"):
-				ctx["operation_type"] = "handle"
-			}
+// describeError returns the error message, substituting a description of
+// the error type when the message (or the innermost wrapped message) is empty
+func describeError(err error) string {
+	msg := safeFormat("%v", err)
+	if strings.TrimSpace(msg) == "" {
+		return fmt.Sprintf("(error of type %s with empty message)", errorTypeName(err))
+	}
 
-			// Add calling context
-			ctx["caller_function"] = funcName
-			ctx["caller_location"] = fmt.Sprintf("%s:%d", filepath.Base(file), line)
-			break
+	// A wrapper around an empty error ends in a dangling "prefix: "
+	for inner := errors.Unwrap(err); inner != nil; inner = errors.Unwrap(inner) {
+		if strings.TrimSpace(safeFormat("%v", inner)) == "" && strings.HasSuffix(msg, ": ") {
+			return fmt.Sprintf("%s(error of type %s with empty message)", msg, errorTypeName(inner))
 		}
 	}
+	return msg
+}
 
-	return ctx
+// classificationText returns the text used to classify an error, falling
+// back to the type name when the message is empty
+func classificationText(err error) string {
+	msg := safeFormat("%v", err)
+	if strings.TrimSpace(msg) == "" {
+		return errorTypeName(err)
+	}
+	return msg
 }
 
 // generateSmartErrorCode creates context-aware error codes
 func generateSmartErrorCode(err error) string {
-	errStr := strings.ToLower(err.Error())
+	errStr := strings.ToLower(classificationText(err))
 
 	// File system errors
 	switch {
-	case strings.Contains(errStr, "no such file"):
+	case strings.Contains(errStr, "no such file"), strings.Contains(errStr, "file does not exist"):
 		return "FS001"
 	case strings.Contains(errStr, "permission denied"):
 		return "FS002"
@@ -413,7 +695,7 @@ func generateSmartErrorCode(err error) string {
 	// Network errors
 	case strings.Contains(errStr, "connection refused"):
 		return "NET001"
-	case strings.Contains(errStr, "timeout"):
+	case strings.Contains(errStr, "timeout"), strings.Contains(errStr, "deadline exceeded"):
 		return "NET002"
 	case strings.Contains(errStr, "host not found"):
 		return "NET003"
@@ -446,16 +728,16 @@ func generateSmartErrorCode(err error) string {
 
 // generateSmartSuggestion creates context-aware suggestions
 func generateSmartSuggestion(err error) string {
-	errStr := strings.ToLower(err.Error())
+	errStr := strings.ToLower(classificationText(err))
 
 	switch {
-	case strings.Contains(errStr, "no such file"):
+	case strings.Contains(errStr, "no such file"), strings.Contains(errStr, "file does not exist"):
 		return "verify the file path exists, check for typos, or create the file first"
 	case strings.Contains(errStr, "permission denied"):
 		return "run with appropriate permissions, check file ownership, or modify file permissions"
 	case strings.Contains(errStr, "connection refused"):
 		return "ensure the target service is running, check firewall settings, or verify the address and port"
-	case strings.Contains(errStr, "timeout"):
+	case strings.Contains(errStr, "timeout"), strings.Contains(errStr, "deadline exceeded"):
 		return "increase timeout duration, check network connectivity, or optimize the operation"
 	case strings.Contains(errStr, "parse"):
 		return "validate input format, check for encoding issues, or review the data structure"
@@ -468,52 +750,66 @@ func generateSmartSuggestion(err error) string {
 	}
 }
 
-// buildErrorInfo creates detailed error information with source code context
-func (e ErrorCatcher) buildErrorInfo(err error, skip int) ErrorInfo {
+// buildErrorInfo creates detailed error information with source code context,
+// located at the first frame outside this package
+func (e *ErrorCatcher) buildErrorInfo(err error) ErrorInfo {
 	config := e.getConfig()
 
-	// Get caller information
-	pc, file, line, ok := runtime.Caller(skip + 1)
-	if !ok {
-		file = "unknown"
-		line = 0
+	info := ErrorInfo{
+		Error:   err,
+		Context: make(map[string]interface{}),
+		Uptime:  now().Sub(processStart),
+		timing:  newTiming(config),
 	}
+	info.locate(config)
+	start := info.timing.now()
+	info.ErrorCode, info.Suggestion = classify(err)
+	info.timing.add(phaseClassify, start)
+	info.Context["error_type"] = errorTypeName(err)
+
+	start = info.timing.now()
+	e.loadSources(&info, config)
+	info.timing.add(phaseAnalysis, start)
+	return info
+}
 
-	var funcName string
-	if fn := runtime.FuncForPC(pc); fn != nil {
-		funcName = fn.Name()
-		// Clean up function name (remove package path)
-		if lastSlash := strings.LastIndex(funcName, "/"); lastSlash >= 0 {
-			funcName = funcName[lastSlash+1:]
-		}
+// loadSources loads the source snippets if enabled: at the handling site,
+// the error's origin, or both as OriginSource selects
+func (e *ErrorCatcher) loadSources(info *ErrorInfo, config ErrorConfig) {
+	if !config.ShowSourceCode {
+		return
 	}
-
-	info := ErrorInfo{
-		Error:      err,
-		File:       file,
-		Line:       line,
-		Function:   funcName,
-		Context:    make(map[string]interface{}),
-		ErrorCode:  generateSmartErrorCode(err),
-		Suggestion: generateSmartSuggestion(err),
+	if file, line, ok := originSite(info.Error, config); ok {
+		info.OriginFile, info.OriginLine = file, line
+		info.OriginSourceLines = e.loadSourceContext(file, line, config.ContextLines)
+		if len(info.OriginSourceLines) > 0 && config.OriginSource == SourceOrigin {
+			return
+		}
 	}
-
-	// Load source code context if enabled
-	if config.ShowSourceCode {
-		info.SourceLines = e.loadSourceContext(file, line, config.ContextLines)
+	info.SourceLines = e.loadSourceContext(info.File, info.Line, config.ContextLines)
+	if len(info.SourceLines) == 0 || info.Column != 0 || config.LowMemory {
+		return
 	}
-
-	// Build stack trace if enabled
-	if config.ShowStackTrace {
-		info.Stack = e.buildStackTrace(skip + 1)
+	span := callSpan(mapSourcePath(info.File, config.SourcePathMap), info.Line)
+	info.Column, info.Span, info.EndLine, info.EndColumn = span.Column, span.Width, span.EndLine, span.EndColumn
+	if after := info.Line + config.ContextLines; span.EndLine > after {
+		// Widen the window to show the whole span
+		lines := e.loadSourceContext(info.File, info.Line, span.EndLine-info.Line)
+		from := info.Line - config.ContextLines
+		for len(lines) > 0 && lines[0].Number < from {
+			lines = lines[1:]
+		}
+		info.SourceLines = lines
 	}
-
-	return info
 }
 
 // loadSourceContext reads source code around the error line
-func (e ErrorCatcher) loadSourceContext(filename string, errorLine, contextLines int) []SourceLine {
-	file, err := os.Open(filename)
+func (e *ErrorCatcher) loadSourceContext(filename string, errorLine, contextLines int) []SourceLine {
+	if filename == "" {
+		return nil
+	}
+
+	file, err := os.Open(mapSourcePath(filename, e.getConfig().SourcePathMap))
 	if err != nil {
 		return nil
 	}
@@ -547,203 +843,197 @@ func (e ErrorCatcher) loadSourceContext(filename string, errorLine, contextLines
 	return lines
 }
 
-// buildStackTrace creates a stack trace
-func (e ErrorCatcher) buildStackTrace(skip int) []StackFrame {
-	config := e.getConfig()
-	var stack []StackFrame
-
-	for i := skip + 1; i < skip+config.MaxStackDepth; i++ {
-		pc, file, line, ok := runtime.Caller(i)
-		if !ok {
-			break
-		}
-
-		var funcName string
-		if fn := runtime.FuncForPC(pc); fn != nil {
-			funcName = fn.Name()
-			if lastSlash := strings.LastIndex(funcName, "/"); lastSlash >= 0 {
-				funcName = funcName[lastSlash+1:]
-			}
-		}
-
-		stack = append(stack, StackFrame{
-			File:     file,
-			Line:     line,
-			Function: funcName,
-		})
-	}
-
-	return stack
-}
-
 // handleError processes and outputs the error in Rust style
-func (e ErrorCatcher) handleError(info ErrorInfo) {
+func (e *ErrorCatcher) handleError(info ErrorInfo) {
 	config := e.getConfig()
-
-	var output strings.Builder
-
-	// Rust-style error header
-	if config.UseColors {
-		output.WriteString(fmt.Sprintf("%serror[%s%s%s]: %s%s%s\n",
-			BrightRed, Bold, info.ErrorCode, Reset+BrightRed, Bold, info.Error.Error(), Reset))
-	} else {
-		output.WriteString(fmt.Sprintf("error[%s]: %s\n", info.ErrorCode, info.Error.Error()))
+	e.maybeDebugConfig()
+	if config.StrictMode {
+		e.checkRecovers()
 	}
-
-	// File location with arrow
-	filename := filepath.Base(info.File)
-	if config.UseColors {
-		output.WriteString(fmt.Sprintf(" %s-->%s %s:%d\n", Blue+Bold, Reset, filename, info.Line))
-	} else {
-		output.WriteString(fmt.Sprintf(" --> %s:%d\n", filename, info.Line))
+	if e != Catch {
+		config.ShowHints = false // Catchers from New belong to libraries
 	}
 
-	// Source code context
-	if config.ShowSourceCode && len(info.SourceLines) > 0 {
-		output.WriteString("  |\n")
-
-		// Calculate padding for line numbers
-		maxLineNum := info.SourceLines[len(info.SourceLines)-1].Number
-		padding := len(fmt.Sprintf("%d", maxLineNum))
-
-		for _, sourceLine := range info.SourceLines {
-			lineNumStr := fmt.Sprintf("%*d", padding, sourceLine.Number)
-
-			if sourceLine.IsError {
-				if config.UseColors {
-					output.WriteString(fmt.Sprintf("%s%s%s |%s %s\n",
-						Red+Bold, lineNumStr, Reset, Reset, sourceLine.Content))
-				} else {
-					output.WriteString(fmt.Sprintf("%s | %s\n", lineNumStr, sourceLine.Content))
-				}
-
-				// Add error pointer
-				spaces := strings.Repeat(" ", padding)
-				if config.UseColors {
-					output.WriteString(fmt.Sprintf("%s |%s %s%s^\n",
-						spaces, Reset, Red+Bold, Reset))
-				} else {
-					output.WriteString(fmt.Sprintf("%s | ^\n", spaces))
-				}
-			} else {
-				if config.UseColors {
-					output.WriteString(fmt.Sprintf("%s%s%s |%s %s%s%s\n",
-						Blue, lineNumStr, Reset, Reset, Gray, sourceLine.Content, Reset))
-				} else {
-					output.WriteString(fmt.Sprintf("%s | %s\n", lineNumStr, sourceLine.Content))
-				}
-			}
-		}
-		output.WriteString("  |\n")
+	if info.timing == nil {
+		info.timing = newTiming(config)
+	}
+	info.opts.applyTo(&info, &config)
+	if info.Severity < config.minLevel() {
+		silenced.record(silencedEntry{time: now(), reason: SilencedLevel, code: info.ErrorCode, file: info.File, line: info.Line, err: info.Error}, config)
+		return
+	}
+	start := info.timing.now()
+	e.prepare(&info, config)
+	info.timing.add(phaseAnalysis, start)
+	action := e.runHooks(&info, config)
+	if info.opts.report != nil {
+		*info.opts.report = info
+	}
+	exiting := (info.Severity.shouldExit(config) || info.opts.exitNow) && !info.opts.noExit && action&SkipExit == 0
+	if exiting && config.DryRunExit {
+		info.WouldExit = config.exitCode()
+		exiting = false
 	}
 
-	// Add context if available
-	if len(info.Context) > 0 {
-		if config.UseColors {
-			output.WriteString(fmt.Sprintf("  %s=%s %scontext:%s\n", Blue+Bold, Reset, Yellow+Bold, Reset))
-		} else {
-			output.WriteString("  = context:\n")
+	// Before configuration, hold the error for replay; only fatal exits
+	if e.buffering() {
+		if action&SkipOutput == 0 {
+			e.bufferStartup(info)
 		}
-
-		for k, v := range info.Context {
-			if config.UseColors {
-				output.WriteString(fmt.Sprintf("    %s%s%s: %v\n", Cyan, k, Reset, v))
-			} else {
-				output.WriteString(fmt.Sprintf("    %s: %v\n", k, v))
-			}
+		if info.Severity >= LevelFatal && !config.DryRunExit && !info.opts.noExit && action&SkipExit == 0 {
+			exit(config.exitCode())
 		}
-		output.WriteString("\n")
+		return
 	}
 
-	// Add suggestion
-	if config.ShowSuggestions && info.Suggestion != "" {
-		if config.UseColors {
-			output.WriteString(fmt.Sprintf("  %s=%s %shelp:%s %s\n",
-				Blue+Bold, Reset, Green+Bold, Reset, info.Suggestion))
-		} else {
-			output.WriteString(fmt.Sprintf("  = help: %s\n", info.Suggestion))
-		}
-		output.WriteString("\n")
+	if !exiting && !e.dedupAdmit(info, config) {
+		silenced.record(silencedEntry{time: info.Time, reason: SilencedRepeated, code: info.ErrorCode, file: info.File, line: info.Line, err: info.Error}, config)
+		e.stats.record(info)
+		return
 	}
 
-	// Add stack trace if enabled
-	if config.ShowStackTrace && len(info.Stack) > 0 {
-		if config.UseColors {
-			output.WriteString(fmt.Sprintf("  %s=%s %sstack backtrace:%s\n",
-				Blue+Bold, Reset, Yellow+Bold, Reset))
+	if exiting && config.DiagnosticsDir != "" {
+		if dir, err := writeDiagnostics(info, config); err != nil {
+			noteIssue(&info, config, "diagnostics", err)
 		} else {
-			output.WriteString("  = stack backtrace:\n")
+			setContext(&info, "diagnostics", dir)
 		}
+	}
 
-		for i, frame := range info.Stack {
-			frameFile := filepath.Base(frame.File)
-			if config.UseColors {
-				output.WriteString(fmt.Sprintf("   %s%2d:%s %s%s%s\n          at %s%s:%d%s\n",
-					Gray, i, Reset, Bold, frame.Function, Reset,
-					Gray, frameFile, frame.Line, Reset))
-			} else {
-				output.WriteString(fmt.Sprintf("   %2d: %s\n          at %s:%d\n",
-					i, frame.Function, frameFile, frame.Line))
-			}
-		}
-		output.WriteString("\n")
+	output := action&SkipOutput == 0
+	if output {
+		info.degraded = new(degradeState)
+		e.writeOutputs(&info, config)
+		info.settleDegradation(config)
 	}
 
-	message := output.String()
+	e.stats.record(info)
+	info.timing.finish()
 
-	// Output to stderr
-	fmt.Fprint(os.Stderr, message)
+	if output && info.opts.fix != nil && config.Interactive {
+		if result := offerFix(info.opts.fix, config); result.Ran && result.Err == nil {
+			*info.opts.fixed = true
+		}
+	}
+	e.forwardToParent(info)
 
-	// Log to file if configured
-	if config.LogToFile != "" {
-		e.logToFile(config.LogToFile, message)
+	if len(config.PromoteNotices) > 0 {
+		e.reportNotices(config)
 	}
 
 	// Exit if configured
-	if config.ExitOnError {
-		os.Exit(1)
+	if exiting {
+		e.flushDedup()
+		e.reportLeaks()
+		e.setExitReason(ExitFatal, "")
+		e.writeConfiguredSummary()
+		exit(config.exitCode())
 	}
 }
 
-// logToFile writes error to a log file (without colors)
-func (e ErrorCatcher) logToFile(filename, message string) {
-	// Strip ANSI colors for file logging
-	cleanMessage := e.stripANSI(message)
+// writeOutputs writes a report to the log file, the logger and the
+// handler, noting their failures on info
+func (e *ErrorCatcher) writeOutputs(info *ErrorInfo, config ErrorConfig) {
+	// Log to file first so a failure can be noted in the console report
+	if config.LogToFile != "" {
+		start := info.timing.now()
+		entry, err := renderLogEntry(*info, config)
+		info.timing.add(phaseRender, start)
+		start = info.timing.now()
+		if err == nil {
+			err = e.logToFile(config.LogToFile, entry)
+		}
+		if err != nil {
+			noteIssue(info, config, "log file", err)
+		}
+		info.timing.add(phaseLog, start)
+	}
 
-	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return // Silently fail to avoid infinite recursion
+	// Output to the console, or the configured handler
+	handler := config.Handler
+	if handler == nil {
+		handler = ConsoleHandler{}
 	}
-	defer file.Close()
+	start, rendered := info.timing.now(), info.timing.get(phaseRender)
+	if config.Logger != nil {
+		if err := logToSlog(*info, config); err != nil {
+			noteIssue(info, config, "logger", err)
+		}
+	}
+	if config.Logger == nil || !config.LoggerOnly {
+		if err := callHandler(handler, *info, config); err != nil {
+			noteIssue(info, config, "handler", err)
+		}
+	}
+	info.timing.add(phaseHandler, start.Add(info.timing.get(phaseRender)-rendered))
+}
 
-	fmt.Fprint(file, cleanMessage)
+// logToFile writes error to a log file (without colors), rotating it as
+// MaxLogSizeBytes says. The error is returned for the report's handler
+// issues, never handled itself, to avoid infinite recursion
+func (e *ErrorCatcher) logToFile(filename, message string) error {
+	// Strip ANSI colors for file logging
+	cleanMessage := e.stripANSI(message)
+	return sharedLogFile(resolvePath(filename)).write(cleanMessage, e.getConfig())
 }
 
-// stripANSI removes ANSI color codes from text
-func (e ErrorCatcher) stripANSI(text string) string {
-	// Simple ANSI escape sequence removal
-	result := text
+// stripANSI removes ANSI color codes from text, repeating until none is
+// left, since removing one can join the halves of another around it
+func (e *ErrorCatcher) stripANSI(text string) string {
 	escapes := []string{Reset, Bold, Red, Green, Yellow, Blue, Magenta, Cyan, White, BrightRed, Gray}
-	for _, escape := range escapes {
-		result = strings.ReplaceAll(result, escape, "")
+	for {
+		result := text
+		for _, escape := range escapes {
+			result = strings.ReplaceAll(result, escape, "")
+		}
+		if result == text {
+			return result
+		}
+		text = result
 	}
-	return result
 }
 
-// getConfig returns the current configuration or default
-func (e ErrorCatcher) getConfig() ErrorConfig {
-	if e.Config.MaxStackDepth == 0 {
+// getConfig returns the current configuration with defaults filled in,
+// or DefaultConfig before any is supplied
+func (e *ErrorCatcher) getConfig() ErrorConfig {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if !e.configuredLocked() {
 		return DefaultConfig
 	}
-	return e.Config
+	return e.Config.withDefaults()
+}
+
+// withDefaults fills in the fields whose zero value means nothing useful
+// from DefaultConfig, each on its own; every other field is kept as given
+func (config ErrorConfig) withDefaults() ErrorConfig {
+	if config.MaxStackDepth == 0 {
+		config.MaxStackDepth = DefaultConfig.MaxStackDepth
+	}
+	return config
+}
+
+// configured reports whether a configuration has been supplied, by New,
+// Configure or a direct write to Config
+func (e *ErrorCatcher) configured() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.configuredLocked()
+}
+
+// configuredLocked is configured with e.mu held
+func (e *ErrorCatcher) configuredLocked() bool {
+	return e.hasConfig || !reflect.ValueOf(e.Config).IsZero()
 }
 
 // Set assigns an error value and handles it if not nil
 // Usage: file, err := os.Open(filePath); except.Catch.Set(err)
-func (e ErrorCatcher) Set(err error) error {
+func (e *ErrorCatcher) Set(err error, opts ...Option) error {
 	if err != nil {
-		info := e.buildErrorInfo(err, 1)
+		info := e.buildErrorInfo(err)
+		info.opts = collectOptions(opts)
 		e.handleError(info)
+		return fixOutcome(err, info.opts)
 	}
 	return err
 }
@@ -751,24 +1041,35 @@ func (e ErrorCatcher) Set(err error) error {
 // E is the shortest possible function name for error handling
 // Usage: E(err) will check if err is not nil and handle it
 func E(err error) {
+	Catch.E(err)
+}
+
+// E is the package-level E reporting through this catcher
+func (e *ErrorCatcher) E(err error) {
 	if err != nil {
-		info := Catch.buildErrorInfo(err, 1)
-		Catch.handleError(info)
+		info := e.buildErrorInfo(err)
+		e.handleError(info)
 	}
 }
 
 // F is like E but with a custom format string and context
 // Usage: F(err, "failed to open %s", filename)
 func F(err error, format string, args ...interface{}) {
+	Catch.F(err, format, args...)
+}
+
+// F is the package-level F reporting through this catcher
+func (e *ErrorCatcher) F(err error, format string, args ...interface{}) {
 	if err != nil {
 		// Create a wrapped error with the formatted message
 		wrappedErr := fmt.Errorf(format+": %w", append(args, err)...)
-		info := Catch.buildErrorInfo(wrappedErr, 1)
-		Catch.handleError(info)
+		info := e.buildErrorInfo(wrappedErr)
+		e.handleError(info)
 	}
 }
 
-// Wrap creates a new error with additional context without handling it
+// Wrap creates a new error with additional context without handling it.
+// The result wraps err with %w, so errors.Is and errors.As see through it.
 // Usage: return except.Wrap(err, "failed to process file %s", filename)
 func Wrap(err error, format string, args ...interface{}) error {
 	if err == nil {
@@ -781,7 +1082,7 @@ func Wrap(err error, format string, args ...interface{}) error {
 // Usage: file := Must(os.Open(filename))
 func Must[T any](val T, err error) T {
 	if err != nil {
-		info := Catch.buildErrorInfo(err, 1)
+		info := Catch.buildErrorInfo(err)
 		var msg strings.Builder
 		msg.WriteString(fmt.Sprintf("Must failed in %s:%d", filepath.Base(info.File), info.Line))
 		if info.Function != "" {
@@ -793,57 +1094,127 @@ func Must[T any](val T, err error) T {
 	return val
 }
 
-// Try returns a function that will check the error and handle it
+// Try returns a function that will check the error and handle it. A panic
+// in the function deferring it is recovered, converted as by Recover,
+// stored into *err and handled too.
 // Usage: defer Try()(&err)
 func Try() func(*error) {
+	return Catch.Try()
+}
+
+// Try is the package-level Try reporting through this catcher
+// Usage: defer c.Try()(&err)
+func (e *ErrorCatcher) Try() func(*error) {
 	return func(errp *error) {
-		if errp != nil && *errp != nil {
-			info := Catch.buildErrorInfo(*errp, 2) // Skip 2 levels for defer
-			Catch.handleError(info)
+		if errp == nil && e.getConfig().StrictMode {
+			e.reportMisuseHere(misuseTryNil)
+		}
+		err := panicError(recover())
+		if err != nil {
+			if frames := reportFrames(1); len(frames) > 0 {
+				recordNotice(NoticeTryRecovers, frames[0].File, frames[0].Line)
+			}
+		}
+		if errp != nil {
+			err = afterError(*errp, err)
+			*errp = err
+		}
+		if err != nil {
+			info := e.buildErrorInfo(err)
+			e.handleError(info)
 		}
 	}
 }
 
+// Try1 calls fn and returns its value, handling its error or panic with
+// the global catcher; after one, the value is whatever fn returned, or
+// the zero value after a panic
+// Usage: cfg := catch.Try1(func() (Config, error) { return load(path) })
+func Try1[T any](fn func() (T, error)) (val T) {
+	var err error
+	defer Try()(&err)
+	val, err = fn()
+	return val
+}
+
+// Try2 is Try1 for functions returning two values
+// Usage: n, rest := catch.Try2(func() (int, string, error) { return parse(s) })
+func Try2[T, U any](fn func() (T, U, error)) (val1 T, val2 U) {
+	var err error
+	defer Try()(&err)
+	val1, val2, err = fn()
+	return val1, val2
+}
+
+// panicError converts a recovered panic value to an error, or nil when
+// there was none. An error value is kept unchanged, so errors.Is and
+// errors.As still match it.
+func panicError(r interface{}) error {
+	switch v := r.(type) {
+	case nil:
+		return nil
+	case error:
+		return v
+	case string:
+		return fmt.Errorf("panic: %s", v)
+	default:
+		return fmt.Errorf("panic: %v", v)
+	}
+}
+
+// afterError combines an error already returned with a panic that came
+// after it, so neither hides the other
+func afterError(err, panicErr error) error {
+	if err == nil || panicErr == nil {
+		if panicErr != nil {
+			return panicErr
+		}
+		return err
+	}
+	return fmt.Errorf("%w; then %w", err, panicErr)
+}
+
 // Assert checks a condition and creates an error if false
 // Usage: except.Assert(len(items) > 0, "items slice cannot be empty")
 func Assert(condition bool, message string, args ...interface{}) {
 	if !condition {
 		err := fmt.Errorf("assertion failed: "+message, args...)
-		info := Catch.buildErrorInfo(err, 1)
+		info := Catch.buildErrorInfo(err)
 		Catch.handleError(info)
 	}
 }
 
 // Check is a convenient function that returns true if error is nil
 // Usage: if !except.Check(err) { return }
-func Check(err error) bool {
+func Check(err error, opts ...Option) bool {
+	return Catch.Check(err, opts...)
+}
+
+// Check is the package-level Check reporting through this catcher
+// Usage: if !c.Check(err) { return }
+func (e *ErrorCatcher) Check(err error, opts ...Option) bool {
 	if err != nil {
-		info := Catch.buildErrorInfo(err, 1)
-		Catch.handleError(info)
+		info := e.buildErrorInfo(err)
+		info.opts = collectOptions(opts)
+		e.handleError(info)
 		return false
 	}
 	return true
 }
 
-// Recover handles panics and converts them to errors
+// Recover handles panics and converts them to errors. A panic with an
+// error value yields that error unchanged, so errors.Is and errors.As
+// still match it.
 // Usage: defer except.Recover()(&err)
 func Recover() func(*error) {
+	pending := Catch.trackRecover()
 	return func(errp *error) {
-		if r := recover(); r != nil {
-			var err error
-			switch v := r.(type) {
-			case error:
-				err = v
-			case string:
-				err = fmt.Errorf("panic: %s", v)
-			default:
-				err = fmt.Errorf("panic: %v", v)
-			}
-
+		untrackRecover(pending)
+		if err := panicError(recover()); err != nil {
 			if errp != nil {
-				*errp = err
+				*errp = afterError(*errp, err)
 			} else {
-				info := Catch.buildErrorInfo(err, 2)
+				info := Catch.buildErrorInfo(err)
 				Catch.handleError(info)
 			}
 		}
@@ -855,12 +1226,19 @@ func Recover() func(*error) {
 // Xf formats and handles error with context
 // Usage: except.Xf(err, "failed to process %s", filename)
 func Errf(err error, format string, args ...interface{}) error {
+	return Catch.Errf(err, format, args...)
+}
+
+// Errf is the package-level Errf reporting through this catcher
+func (e *ErrorCatcher) Errf(err error, format string, args ...interface{}) error {
 	if err == nil {
 		return nil
 	}
 
+	opts, args := splitOptions(args)
 	wrappedErr := fmt.Errorf(format+": %w", append(args, err)...)
-	return Err(wrappedErr, args...)
+	e.handleSmart(wrappedErr, opts, args)
+	return wrappedErr
 }
 
 // XMust panics with smart error info if err is not nil
@@ -882,6 +1260,24 @@ func ErrCheck(err error) bool {
 	return true
 }
 
+// processStart records when the package was initialized, used for uptime
+var processStart = time.Now()
+
+// exit terminates the process; replaceable so exit paths can be observed
+var exit = os.Exit
+
+// formatUptime renders a duration compactly: 42s, 3m12s, 2h14m
+func formatUptime(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%ds", int(d/time.Minute), int(d%time.Minute/time.Second))
+	default:
+		return fmt.Sprintf("%dh%dm", int(d/time.Hour), int(d%time.Hour/time.Minute))
+	}
+}
+
 // abs returns absolute value of an integer
 func abs(x int) int {
 	if x < 0 {
@@ -889,29 +1285,3 @@ func abs(x int) int {
 	}
 	return x
 }
-
-// Examples of usage:
-/*
-// Ultra simple - auto-detects everything
-except.X(err)
-
-// With explicit context
-except.X(err, filename)
-except.X(err, "file_processing", filename)
-except.X(err, map[string]interface{}{"file": filename, "operation": "read"})
-
-// Auto-formatting
-except.Xf(err, "failed to process %s", filename)
-
-// Must pattern
-file := except.XMust(os.Open(filename))
-
-// Check pattern
-if !except.XCheck(err) { return }
-
-// All original functions still work:
-except.E(err)
-except.F(err, "message")
-except.Catch.Set(err)
-except.Catch.WithContext("key", value).Set(err)
-*/
@@ -0,0 +1,306 @@
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUptimeFooterScales(t *testing.T) {
+	for _, tc := range []struct {
+		uptime time.Duration
+		want   string
+	}{
+		{42 * time.Second, "uptime: 42s"},
+		{3*time.Minute + 12*time.Second, "uptime: 3m12s"},
+		{2*time.Hour + 14*time.Minute + 59*time.Second, "uptime: 2h14m"},
+	} {
+		t.Run(tc.want, func(t *testing.T) {
+			defer SetClockForTesting(NewManualClock(processStart.Add(tc.uptime)))()
+			out := testCatch(t, testConfig())
+
+			Err(errors.New("disk full"))
+			if !strings.Contains(out.String(), tc.want+"\n") {
+				t.Errorf("report lacks %q:\n%s", tc.want, out)
+			}
+		})
+	}
+}
+
+func TestUptimeFooterHiddenWhenDisabled(t *testing.T) {
+	defer SetClockForTesting(NewManualClock(processStart.Add(time.Hour)))()
+	config := testConfig()
+	config.ShowUptime = false
+	config.ShowSourceCode = false
+	out := testCatch(t, config)
+
+	Err(errors.New("disk full"))
+	if strings.Contains(out.String(), "uptime:") {
+		t.Errorf("uptime shown with ShowUptime off:\n%s", out)
+	}
+}
+
+func TestUptimeFooterOnlyForErrors(t *testing.T) {
+	defer SetClockForTesting(NewManualClock(processStart.Add(time.Hour)))()
+	config := testConfig()
+	config.ShowUptime = true
+	config.ShowSourceCode = false
+	out := testCatch(t, config)
+
+	Warn(errors.New("disk 80% full"))
+	if strings.Contains(out.String(), "uptime:") {
+		t.Errorf("warning has the uptime footer:\n%s", out)
+	}
+	stubExit(t)
+	Fatal(errors.New("disk full"))
+	if !strings.Contains(out.String(), "uptime: 1h0m\n") {
+		t.Errorf("fatal report lacks the uptime footer:\n%s", out)
+	}
+}
+
+func TestUptimeInMachineFormats(t *testing.T) {
+	defer SetClockForTesting(NewManualClock(processStart.Add(90 * time.Second)))()
+	config := testConfig()
+	config.Format = FormatJSON
+	out := testCatch(t, config)
+
+	Err(errors.New("disk full"))
+	if !strings.Contains(out.String(), `"uptime_ms":90000`) {
+		t.Errorf("JSON report lacks uptime_ms:\n%s", out)
+	}
+}
+
+// emptyError is an error whose message is empty, like a zero-value custom type
+type emptyError struct{}
+
+func (*emptyError) Error() string { return "" }
+
+// timeoutError has an empty message but a telling type name
+type timeoutError struct{}
+
+func (timeoutError) Error() string { return "" }
+
+func TestEmptyMessageHeadline(t *testing.T) {
+	config := testConfig()
+	config.ShowSourceCode = false
+	out := testCatch(t, config)
+
+	Err(&emptyError{})
+	want := "]: (error of type *catch.emptyError with empty message)\n"
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("report lacks %q:\n%s", want, out)
+	}
+	if !strings.Contains(out.String(), "error_type: *catch.emptyError") {
+		t.Errorf("report lacks error_type:\n%s", out)
+	}
+}
+
+func TestEmptyMessageWrapped(t *testing.T) {
+	err := fmt.Errorf("loading profile: %w", &emptyError{})
+	want := "loading profile: (error of type *catch.emptyError with empty message)"
+	if got := describeError(err); got != want {
+		t.Errorf("describeError = %q, want %q", got, want)
+	}
+
+	config := testConfig()
+	config.ShowSourceCode = false
+	out := testCatch(t, config)
+	Err(err)
+	cause := "0: (error of type *catch.emptyError with empty message)\n"
+	if !strings.Contains(out.String(), "]: loading profile\n") || !strings.Contains(out.String(), cause) {
+		t.Errorf("report lacks the wrapper headline and the empty cause:\n%s", out)
+	}
+	if !strings.Contains(out.String(), "error_type: *fmt.wrapError") {
+		t.Errorf("report lacks the wrapper's error_type:\n%s", out)
+	}
+}
+
+func TestEmptyMessageClassifiedByTypeName(t *testing.T) {
+	if code := generateSmartErrorCode(timeoutError{}); code != "NET002" {
+		t.Errorf("code = %s, want NET002 from the type name", code)
+	}
+}
+
+func TestErrorTypeOnEveryReport(t *testing.T) {
+	info := Catch.buildErrorInfo(errors.New("plain"))
+	if got := info.Context["error_type"]; got != "*errors.errorString" {
+		t.Errorf("error_type = %v, want *errors.errorString", got)
+	}
+}
+
+func TestConfigureReachesEarlierHandles(t *testing.T) {
+	handle := Catch
+	scoped := Catch.WithContext("request_id", "r-17")
+	codes := stubExit(t)
+
+	config := testConfig()
+	config.ShowSourceCode = false
+	out := testCatch(t, config)
+
+	scoped.Set(errors.New("upstream closed"))
+	handle.Err(errors.New("cache miss"))
+
+	if len(*codes) != 0 {
+		t.Errorf("exited with %v; the handles kept a stale ExitOnError", *codes)
+	}
+	if countHeadlines(out.String(), "upstream closed") != 1 || countHeadlines(out.String(), "cache miss") != 1 {
+		t.Errorf("reports missing from the configured output:\n%s", out)
+	}
+	if !strings.Contains(out.String(), "request_id: r-17") {
+		t.Errorf("contextual report lacks its context:\n%s", out)
+	}
+}
+
+func TestNewIsIndependentOfConfigure(t *testing.T) {
+	var own strings.Builder
+	config := testConfig()
+	config.Output = &own
+	config.ShowSourceCode = false
+	c := New(config)
+
+	global := testCatch(t, testConfig())
+	c.Err(errors.New("private failure"))
+
+	if countHeadlines(own.String(), "private failure") != 1 {
+		t.Errorf("New catcher did not use its own output:\n%s", own.String())
+	}
+	if global.Len() != 0 {
+		t.Errorf("global output received the private report:\n%s", global)
+	}
+}
+
+func TestCatchersKeepSeparateLogFiles(t *testing.T) {
+	dir := t.TempDir()
+	var catchers [2]*ErrorCatcher
+	for i := range catchers {
+		config := testConfig()
+		config.Output = io.Discard
+		config.LogToFile = filepath.Join(dir, fmt.Sprintf("catcher%d.log", i))
+		catchers[i] = New(config)
+	}
+
+	var wg sync.WaitGroup
+	for i, c := range catchers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				c.Err(fmt.Errorf("failure from catcher %d", i))
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, c := range catchers {
+		logged, err := os.ReadFile(c.getConfig().LogToFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		other := fmt.Sprintf("failure from catcher %d", 1-i)
+		if !strings.Contains(string(logged), fmt.Sprintf("failure from catcher %d", i)) || strings.Contains(string(logged), other) {
+			t.Errorf("log of catcher %d mixes reports:\n%s", i, logged)
+		}
+	}
+}
+
+func TestPartialConfigKeepsGivenFields(t *testing.T) {
+	var out strings.Builder
+	c := New(ErrorConfig{Output: &out})
+	config := c.getConfig()
+
+	if config.ExitOnError {
+		t.Error("ExitOnError taken from DefaultConfig for a partial config")
+	}
+	if config.Output != &out {
+		t.Error("Output replaced for a partial config")
+	}
+	if config.MaxStackDepth != DefaultConfig.MaxStackDepth {
+		t.Errorf("MaxStackDepth = %d, want the default %d", config.MaxStackDepth, DefaultConfig.MaxStackDepth)
+	}
+}
+
+// recovered returns what Recover stores for a panic with err
+func recovered(err error) (got error) {
+	defer Recover()(&got)
+	panic(err)
+}
+
+// tried returns what Try stores for a panic after prior was returned
+func tried(prior, err error) (got error) {
+	defer Try()(&got)
+	got = prior
+	panic(err)
+}
+
+// reaches reports whether walking Unwrap, both the single and the joined
+// form, from err arrives at target
+func reaches(err, target error) bool {
+	if err == target {
+		return true
+	}
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		return u.Unwrap() != nil && reaches(u.Unwrap(), target)
+	case interface{ Unwrap() []error }:
+		for _, e := range u.Unwrap() {
+			if reaches(e, target) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestReturnedErrorsKeepIdentity(t *testing.T) {
+	config := testConfig()
+	config.ShowSourceCode = false
+	testCatch(t, config)
+
+	sentinel := errors.New("quota exceeded")
+	for _, wrapper := range []struct {
+		name string
+		wrap func(error) error
+	}{
+		{"Err", func(err error) error { return Err(err, "op", "read") }},
+		{"Errf", func(err error) error { return Errf(err, "loading %s", "config.yaml") }},
+		{"Wrap", func(err error) error { return Wrap(err, "loading %s", "config.yaml") }},
+		{"Wrap twice", func(err error) error { return Wrap(Wrap(err, "inner"), "outer") }},
+		{"fmt %w of Wrap", func(err error) error { return fmt.Errorf("caller: %w", Wrap(err, "lib")) }},
+		{"Wrap of fmt %w", func(err error) error { return Wrap(fmt.Errorf("lib: %w", err), "caller") }},
+		{"Err of joined", func(err error) error { return Err(errors.Join(sentinel, err)) }},
+		{"Partial", func(err error) error { return Partial(3, 2, []error{sentinel, err}) }},
+		{"Errf of Partial", func(err error) error { return Errf(Partial(2, 1, []error{err}), "import") }},
+		{"Recover", recovered},
+		{"Try after error", func(err error) error { return tried(sentinel, err) }},
+		{"fix applied", func(err error) error { return &fixedError{err: err} }},
+		{"FSError", func(err error) error { return &FSError{FS: "assets", Err: err} }},
+	} {
+		t.Run(wrapper.name, func(t *testing.T) {
+			orig := &fs.PathError{Op: "open", Path: "config.yaml", Err: fs.ErrNotExist}
+			got := wrapper.wrap(orig)
+
+			for _, probe := range []struct {
+				name string
+				ok   func() bool
+			}{
+				{"Is sentinel", func() bool { return errors.Is(got, fs.ErrNotExist) }},
+				{"As concrete", func() bool {
+					var pathErr *fs.PathError
+					return errors.As(got, &pathErr) && pathErr == orig
+				}},
+				{"Unwrap chain", func() bool { return reaches(got, orig) }},
+			} {
+				if !probe.ok() {
+					t.Errorf("%s fails on %T %q", probe.name, got, got)
+				}
+			}
+		})
+	}
+}
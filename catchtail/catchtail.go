@@ -0,0 +1,213 @@
+// Package catchtail follows a JSON lines file of catch reports, as written
+// by a program configured with catch.FormatJSON, and routes the reports to
+// handlers. It lets a sidecar process notify about errors of a program
+// that cannot be changed to do so itself.
+package catchtail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"catch"
+)
+
+// DefaultPollInterval is how often Tail checks the file for new reports
+const DefaultPollInterval = 250 * time.Millisecond
+
+// maxLineBytes bounds a report line; longer lines are skipped
+const maxLineBytes = 1 << 20
+
+// Option configures Tail
+type Option func(*tailer)
+
+// PollInterval sets how often the file is checked for new reports and for
+// rotation (default DefaultPollInterval)
+func PollInterval(d time.Duration) Option {
+	return func(t *tailer) {
+		if d > 0 {
+			t.poll = d
+		}
+	}
+}
+
+// FromStart reads the reports already in the file too, not only those
+// written after Tail starts
+func FromStart() Option {
+	return func(t *tailer) { t.fromStart = true }
+}
+
+// OnError receives lines that are not valid reports and read errors;
+// Tail skips them and keeps following the file
+func OnError(fn func(error)) Option {
+	return func(t *tailer) { t.onError = fn }
+}
+
+type tailer struct {
+	path      string
+	poll      time.Duration
+	fromStart bool
+	onError   func(error)
+
+	file    *os.File
+	stat    os.FileInfo
+	offset  int64
+	pending []byte // A partial last line, waiting for its newline
+	skip    bool   // Discarding the rest of an over-long line
+	out     chan<- catch.ErrorInfo
+}
+
+// Tail follows the reports appended to path and sends each on the returned
+// channel, which is closed once ctx is done. Rotation is detected by the
+// file at path changing identity: the old file is read to its end before
+// the new one is followed from its start. A file that shrinks was
+// truncated and is read again from its start. A last line without its
+// newline is held until the rest is written. A file rotated out and
+// replaced again within one poll interval is never seen.
+// Usage: reports, err := catchtail.Tail(ctx, "/var/log/app/errors.jsonl")
+func Tail(ctx context.Context, path string, opts ...Option) (<-chan catch.ErrorInfo, error) {
+	t := &tailer{path: path, poll: DefaultPollInterval}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if err := t.open(); err != nil {
+		return nil, err
+	}
+	if !t.fromStart {
+		t.offset = t.stat.Size()
+	}
+
+	out := make(chan catch.ErrorInfo)
+	t.out = out
+	go t.run(ctx, out)
+	return out, nil
+}
+
+// open opens the file now at path, to be read from its start
+func (t *tailer) open() error {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if t.file != nil {
+		t.file.Close()
+	}
+	t.file, t.stat, t.offset, t.pending, t.skip = f, stat, 0, nil, false
+	return nil
+}
+
+func (t *tailer) run(ctx context.Context, out chan<- catch.ErrorInfo) {
+	defer close(out)
+	defer func() { t.file.Close() }()
+
+	ticker := time.NewTicker(t.poll)
+	defer ticker.Stop()
+	for {
+		if !t.step(ctx) {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// step reads what was appended since the last one and follows a rotation
+// or truncation. It returns false once ctx is done.
+func (t *tailer) step(ctx context.Context) bool {
+	if stat, err := t.file.Stat(); err == nil && stat.Size() < t.offset {
+		t.offset, t.pending, t.skip = 0, nil, false // Truncated in place
+	}
+	if !t.read(ctx) {
+		return false
+	}
+
+	current, err := os.Stat(t.path)
+	if err != nil || os.SameFile(current, t.stat) {
+		return true // Unchanged, or the new file is not created yet
+	}
+	if !t.read(ctx) { // Drain what was written before the rotation
+		return false
+	}
+	if len(t.pending) > 0 {
+		t.emit(ctx, t.pending) // The old file ended without a newline
+	}
+	if err := t.open(); err != nil {
+		t.fail(err)
+		return true
+	}
+	return t.read(ctx)
+}
+
+// read sends every complete line from offset to the end of the file
+func (t *tailer) read(ctx context.Context) bool {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := t.file.ReadAt(buf, t.offset)
+		t.offset += int64(n)
+		data := append(t.pending, buf[:n]...)
+		if t.skip {
+			_, rest, found := bytes.Cut(data, []byte("\n"))
+			data, t.skip = rest, !found // The over-long line ends at its newline
+		}
+		for {
+			line, rest, found := bytes.Cut(data, []byte("\n"))
+			if !found {
+				break
+			}
+			if !t.emit(ctx, line) {
+				return false
+			}
+			data = rest
+		}
+		t.pending = append([]byte(nil), data...)
+		if len(t.pending) > maxLineBytes {
+			t.fail(fmt.Errorf("%s: line longer than %d bytes skipped", t.path, maxLineBytes))
+			t.pending, t.skip = nil, true
+		}
+
+		if errors.Is(err, io.EOF) || n == 0 {
+			return ctx.Err() == nil
+		}
+		if err != nil {
+			t.fail(err)
+			return ctx.Err() == nil
+		}
+	}
+}
+
+// emit decodes and sends one line, reporting lines that are not reports
+func (t *tailer) emit(ctx context.Context, line []byte) bool {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return true
+	}
+	info, err := catch.Import(line)
+	if err != nil {
+		t.fail(fmt.Errorf("%s: %w", t.path, err))
+		return true
+	}
+	select {
+	case t.out <- info:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (t *tailer) fail(err error) {
+	if t.onError != nil {
+		t.onError(err)
+	}
+}
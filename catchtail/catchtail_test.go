@@ -0,0 +1,92 @@
+package catchtail
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"catch"
+)
+
+// reportLine is a JSON line report of msg
+func reportLine(t *testing.T, msg string) string {
+	t.Helper()
+	data, err := catch.MarshalReport(catch.ErrorInfo{Error: errors.New(msg), ErrorCode: "GEN000", Time: time.Unix(0, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data) + "\n"
+}
+
+// tailFile follows path from its start, collecting the errors reported
+func tailFile(t *testing.T, path string) (<-chan catch.ErrorInfo, func() []error) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	var mu sync.Mutex
+	var errs []error
+	reports, err := Tail(ctx, path, FromStart(), PollInterval(10*time.Millisecond), OnError(func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reports, func() []error {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]error(nil), errs...)
+	}
+}
+
+// next waits for the next report
+func next(t *testing.T, reports <-chan catch.ErrorInfo) catch.ErrorInfo {
+	t.Helper()
+	select {
+	case info := <-reports:
+		return info
+	case <-time.After(5 * time.Second):
+		t.Fatal("no report")
+		return catch.ErrorInfo{}
+	}
+}
+
+func TestTailReadsReports(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.jsonl")
+	os.WriteFile(path, []byte(reportLine(t, "first")), 0o644)
+	reports, _ := tailFile(t, path)
+	if info := next(t, reports); info.Error.Error() != "first" {
+		t.Errorf("got %v", info.Error)
+	}
+
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	defer f.Close()
+	line := reportLine(t, "second")
+	f.WriteString(line[:10]) // Held until its newline arrives
+	time.Sleep(30 * time.Millisecond)
+	f.WriteString(line[10:])
+	if info := next(t, reports); info.Error.Error() != "second" {
+		t.Errorf("got %v", info.Error)
+	}
+}
+
+func TestTailSkipsAllOfAnOverlongLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.jsonl")
+	long := `{"schema":"` + strings.Repeat("x", maxLineBytes+100<<10) + "\"}\n"
+	os.WriteFile(path, []byte(long+reportLine(t, "after")), 0o644)
+	reports, errs := tailFile(t, path)
+
+	if info := next(t, reports); info.Error.Error() != "after" {
+		t.Errorf("got %v", info.Error)
+	}
+	got := errs()
+	if len(got) != 1 || !strings.Contains(got[0].Error(), "longer than") {
+		t.Errorf("errors = %v, want only the skipped line", got)
+	}
+}
@@ -0,0 +1,100 @@
+package catchtail
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"catch"
+	"catch/internal/keycache"
+)
+
+// Filter selects reports. Its zero value matches every report.
+type Filter struct {
+	Codes       []string       // Match only these codes; empty matches all
+	MinSeverity catch.Severity // Match this severity and above
+	Limit       int            // Match at most Limit reports per code in each Per; 0 is unlimited
+	Per         time.Duration  // The window of Limit (default one minute)
+	MaxKeys     int            // Codes counted for Limit at most, least recent forgotten first (default catch.DefaultMaxTrackedKeys)
+
+	windows *keycache.Cache[string, *rateWindow]
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// Match reports whether info passes the filter, counting it against the
+// rate limit if it does
+func (f *Filter) Match(info catch.ErrorInfo) bool {
+	if len(f.Codes) > 0 && !slices.Contains(f.Codes, info.ErrorCode) {
+		return false
+	}
+	if info.Severity < f.MinSeverity {
+		return false
+	}
+	if f.Limit <= 0 {
+		return true
+	}
+
+	per := f.Per
+	if per <= 0 {
+		per = time.Minute
+	}
+	if f.windows == nil {
+		f.windows = &keycache.Cache[string, *rateWindow]{}
+	}
+	now := catch.Now()
+	w := f.windows.Get(info.ErrorCode, f.MaxKeys, func() *rateWindow { return &rateWindow{start: now} })
+	if now.Sub(w.start) >= per {
+		w.start, w.count = now, 0
+	}
+	if w.count >= f.Limit {
+		return false
+	}
+	w.count++
+	return true
+}
+
+// Route sends the reports its filter matches to a handler
+type Route struct {
+	Filter
+	Handler catch.Handler
+}
+
+// Dispatch hands each report from reports to every route whose filter
+// matches it, in order, until reports is closed or ctx is done. Handlers
+// get config; their failures and panics go to config.OnHandlerIssue.
+// Usage: catchtail.Dispatch(ctx, reports, config, catchtail.Route{Filter: catchtail.Filter{MinSeverity: catch.LevelFatal}, Handler: pager})
+func Dispatch(ctx context.Context, reports <-chan catch.ErrorInfo, config catch.ErrorConfig, routes ...Route) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case info, ok := <-reports:
+			if !ok {
+				return
+			}
+			for i := range routes {
+				if routes[i].Handler == nil || !routes[i].Match(info) {
+					continue
+				}
+				if err := handle(routes[i].Handler, info, config); err != nil && config.OnHandlerIssue != nil {
+					config.OnHandlerIssue(catch.HandlerIssue{Source: fmt.Sprintf("route %d", i), Err: err})
+				}
+			}
+		}
+	}
+}
+
+// handle runs a handler, turning a panic into an error
+func handle(h catch.Handler, info catch.ErrorInfo, config catch.ErrorConfig) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return h.Handle(info, config)
+}
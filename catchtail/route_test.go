@@ -0,0 +1,45 @@
+package catchtail
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"catch"
+)
+
+func TestFilterBoundsRateLimitKeys(t *testing.T) {
+	f := Filter{Limit: 2, MaxKeys: 16}
+	hot := 0
+	for i := 0; i < 1000; i++ {
+		if f.Match(catch.ErrorInfo{ErrorCode: "HOT001"}) {
+			hot++
+		}
+		f.Match(catch.ErrorInfo{ErrorCode: fmt.Sprintf("GEN%04d", i)})
+	}
+	if n := f.windows.Len(); n != 16 {
+		t.Errorf("%d codes tracked, want the cap of 16", n)
+	}
+	if hot != 2 {
+		t.Errorf("hot code matched %d times, want its Limit of 2", hot)
+	}
+}
+
+func TestFilterRateWindowFollowsPackageClock(t *testing.T) {
+	clock := catch.NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer catch.SetClockForTesting(clock)()
+
+	f := Filter{Limit: 1, Per: time.Minute}
+	info := catch.ErrorInfo{ErrorCode: "GEN000"}
+	if !f.Match(info) {
+		t.Fatal("first report filtered")
+	}
+	clock.Advance(59 * time.Second)
+	if f.Match(info) {
+		t.Error("second report within the window matched")
+	}
+	clock.Advance(time.Second)
+	if !f.Match(info) {
+		t.Error("report in the next window filtered")
+	}
+}
@@ -0,0 +1,156 @@
+// Package catchtest builds realistic catch.ErrorInfo values for testing
+// custom handlers, notifiers and templates without triggering real errors.
+package catchtest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"catch"
+)
+
+// fixedTime is the default time of built reports, so output is stable
+var fixedTime = time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+
+// InfoBuilder assembles an ErrorInfo with fluent setters
+// Usage: info := catchtest.NewInfo(err).WithCode("NET002").WithStackDepth(3).Build()
+type InfoBuilder struct {
+	info catch.ErrorInfo
+}
+
+// NewInfo starts a builder for err with a plausible location, ID, time and
+// group key already filled in
+func NewInfo(err error) *InfoBuilder {
+	if err == nil {
+		err = errors.New("something went wrong")
+	}
+	return &InfoBuilder{info: catch.ErrorInfo{
+		Error:     err,
+		File:      "service.go",
+		Line:      42,
+		Function:  "main.run",
+		Context:   map[string]interface{}{"error_type": fmt.Sprintf("%T", err)},
+		ErrorCode: "GEN000",
+		Severity:  catch.LevelError,
+		GroupKey:  "0123456789abcdef",
+		ID:        "01HTEST000000000000000000",
+		Time:      fixedTime,
+		Uptime:    1500 * time.Millisecond,
+	}}
+}
+
+// WithCode sets the error code and, if given, the suggestion
+func (b *InfoBuilder) WithCode(code string, suggestion ...string) *InfoBuilder {
+	b.info.ErrorCode = code
+	if len(suggestion) > 0 {
+		b.info.Suggestion = suggestion[0]
+	}
+	return b
+}
+
+// WithSeverity sets the severity
+func (b *InfoBuilder) WithSeverity(s catch.Severity) *InfoBuilder {
+	b.info.Severity = s
+	return b
+}
+
+// WithLocation sets the reporting file, line and function
+func (b *InfoBuilder) WithLocation(file string, line int, function string) *InfoBuilder {
+	b.info.File, b.info.Line, b.info.Function = file, line, function
+	return b
+}
+
+// WithStackDepth replaces the stack with n synthetic frames, innermost first
+func (b *InfoBuilder) WithStackDepth(n int) *InfoBuilder {
+	b.info.Stack = make([]catch.StackFrame, 0, n)
+	for i := 0; i < n; i++ {
+		b.info.Stack = append(b.info.Stack, catch.StackFrame{
+			File:     fmt.Sprintf("layer%d.go", i),
+			Line:     10 + i,
+			Function: fmt.Sprintf("main.layer%d", i),
+		})
+	}
+	return b
+}
+
+// WithContextKV adds alternating key/value pairs to the context
+func (b *InfoBuilder) WithContextKV(kv ...interface{}) *InfoBuilder {
+	for i := 0; i+1 < len(kv); i += 2 {
+		b.info.Context[fmt.Sprint(kv[i])] = kv[i+1]
+	}
+	return b
+}
+
+// WithSourceSnippet sets the source lines, numbered so that the line at
+// index errorLine is the error line and matches the report's Line
+func (b *InfoBuilder) WithSourceSnippet(lines []string, errorLine int) *InfoBuilder {
+	b.info.SourceLines = make([]catch.SourceLine, len(lines))
+	first := b.info.Line - errorLine
+	if first < 1 {
+		first = 1
+		b.info.Line = first + errorLine
+	}
+	for i, content := range lines {
+		b.info.SourceLines[i] = catch.SourceLine{
+			Number:  first + i,
+			Content: content,
+			IsError: i == errorLine,
+		}
+	}
+	return b
+}
+
+// WithTime sets the occurrence time
+func (b *InfoBuilder) WithTime(t time.Time) *InfoBuilder {
+	b.info.Time = t
+	return b
+}
+
+// Build returns the ErrorInfo; the builder may be reused
+func (b *InfoBuilder) Build() catch.ErrorInfo {
+	info := b.info
+	info.Context = make(map[string]interface{}, len(b.info.Context))
+	for k, v := range b.info.Context {
+		info.Context[k] = v
+	}
+	info.Stack = append([]catch.StackFrame(nil), b.info.Stack...)
+	info.SourceLines = append([]catch.SourceLine(nil), b.info.SourceLines...)
+	return info
+}
+
+// FileNotFound returns a report for a missing configuration file
+func FileNotFound() catch.ErrorInfo {
+	err := &os.PathError{Op: "open", Path: "config.yaml", Err: os.ErrNotExist}
+	return NewInfo(err).
+		WithCode("FS001", "Check if the file path exists and is accessible").
+		WithContextKV("path", "config.yaml").
+		WithSourceSnippet([]string{
+			"func load() error {",
+			"\tf, err := os.Open(\"config.yaml\")",
+			"\tif err != nil {",
+		}, 1).
+		WithStackDepth(3).
+		Build()
+}
+
+// Timeout returns a report for an expired request deadline
+func Timeout() catch.ErrorInfo {
+	return NewInfo(fmt.Errorf("GET /users: %w", os.ErrDeadlineExceeded)).
+		WithCode("NET002", "Increase timeout duration or check network connectivity").
+		WithLocation("client.go", 88, "api.(*Client).Get").
+		WithContextKV("url", "https://example.com/users", "timeout", 5*time.Second).
+		WithStackDepth(4).
+		Build()
+}
+
+// PanicRecovered returns a fatal report for a recovered panic
+func PanicRecovered() catch.ErrorInfo {
+	return NewInfo(errors.New("panic: runtime error: index out of range [3] with length 3")).
+		WithCode("PANIC").
+		WithSeverity(catch.LevelFatal).
+		WithLocation("worker.go", 17, "main.worker").
+		WithStackDepth(6).
+		Build()
+}
@@ -0,0 +1,160 @@
+package catchtest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"catch"
+)
+
+// renderConfig is the default configuration without colors, hints or exits
+func renderConfig() catch.ErrorConfig {
+	config := catch.DefaultConfig
+	config.UseColors = false
+	config.ShowHints = false
+	config.ExitOnError = false
+	return config
+}
+
+func TestFixturesRender(t *testing.T) {
+	tests := []struct {
+		name string
+		info catch.ErrorInfo
+		want []string
+	}{
+		{"FileNotFound", FileNotFound(), []string{
+			"error[FS001]: open config.yaml: file does not exist",
+			" --> service.go:42",
+			`42 | 	f, err := os.Open("config.yaml")`,
+			"path: config.yaml",
+			"= help: Check if the file path exists and is accessible",
+			"2: main.layer2",
+		}},
+		{"Timeout", Timeout(), []string{
+			"error[NET002]: GET /users: i/o timeout",
+			" --> client.go:88",
+			"timeout: 5s",
+			"url: https://example.com/users",
+			"3: main.layer3",
+		}},
+		{"PanicRecovered", PanicRecovered(), []string{
+			"fatal[PANIC]: panic: runtime error: index out of range [3] with length 3",
+			" --> worker.go:17",
+			"5: main.layer5",
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			report := catch.RenderReport(tc.info, renderConfig())
+			for _, want := range tc.want {
+				if !strings.Contains(report, want) {
+					t.Errorf("report lacks %q:\n%s", want, report)
+				}
+			}
+		})
+	}
+}
+
+func TestFixturesRoundTripJSON(t *testing.T) {
+	for _, info := range []catch.ErrorInfo{FileNotFound(), Timeout(), PanicRecovered()} {
+		data, err := catch.MarshalReport(info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := catch.ValidateReport(data); err != nil {
+			t.Errorf("%s: %v\n%s", info.ErrorCode, err, data)
+		}
+		got, err := catch.Import(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Error.Error() != info.Error.Error() || got.ErrorCode != info.ErrorCode || got.Severity != info.Severity ||
+			got.Line != info.Line || len(got.Stack) != len(info.Stack) || len(got.SourceLines) != len(info.SourceLines) {
+			t.Errorf("%s did not survive the round trip:\n got %+v\nwant %+v", info.ErrorCode, got, info)
+		}
+	}
+}
+
+func TestWithSourceSnippetNumbersLines(t *testing.T) {
+	info := NewInfo(nil).WithSourceSnippet([]string{"a", "b", "c"}, 2).Build()
+	want := []catch.SourceLine{{Number: 40, Content: "a"}, {Number: 41, Content: "b"}, {Number: 42, Content: "c", IsError: true}}
+	if !reflect.DeepEqual(info.SourceLines, want) {
+		t.Errorf("lines = %+v, want %+v", info.SourceLines, want)
+	}
+
+	// Too near the top of the file: the snippet starts at line 1 and the
+	// report's line follows the error line
+	info = NewInfo(nil).WithLocation("main.go", 2, "main.main").WithSourceSnippet([]string{"a", "b", "c", "d"}, 3).Build()
+	if info.SourceLines[0].Number != 1 || info.Line != 4 || !info.SourceLines[3].IsError {
+		t.Errorf("line %d, lines %+v; want line 4 from 1", info.Line, info.SourceLines)
+	}
+}
+
+func TestWithStackDepth(t *testing.T) {
+	for _, n := range []int{0, 1, 5} {
+		info := NewInfo(nil).WithStackDepth(n).Build()
+		if len(info.Stack) != n {
+			t.Fatalf("WithStackDepth(%d) gave %d frames", n, len(info.Stack))
+		}
+		for i, frame := range info.Stack {
+			if frame.Function != fmt.Sprintf("main.layer%d", i) || frame.File == "" || frame.Line == 0 {
+				t.Errorf("frame %d = %+v", i, frame)
+			}
+		}
+	}
+}
+
+func TestBuildReturnsIndependentCopies(t *testing.T) {
+	b := NewInfo(errors.New("boom")).WithContextKV("user", 7).WithStackDepth(2).WithSourceSnippet([]string{"x"}, 0)
+	first := b.Build()
+	first.Context["user"] = 8
+	first.Stack[0].Function = "changed"
+	first.SourceLines[0].Content = "changed"
+
+	second := b.WithCode("GEN001").Build()
+	if second.Context["user"] != 7 || second.Stack[0].Function != "main.layer0" || second.SourceLines[0].Content != "x" {
+		t.Errorf("changes to a built report reached the builder: %+v", second)
+	}
+	if first.ErrorCode != "GEN000" || second.ErrorCode != "GEN001" {
+		t.Errorf("codes = %s, %s; want GEN000, GEN001", first.ErrorCode, second.ErrorCode)
+	}
+}
+
+func ExampleNewInfo() {
+	info := NewInfo(errors.New("connection refused")).
+		WithCode("NET001", "Check that the server is running").
+		WithContextKV("host", "db-1").
+		Build()
+
+	sel := catch.FieldSelector{Include: []catch.Field{catch.FieldMessage, catch.FieldContext, catch.FieldSuggestion}}
+	data, _ := catch.MarshalReportFields(info, sel)
+	fmt.Println(string(data))
+	// Output:
+	// {"schema":"gocatch/1","time":"2024-01-02T15:04:05Z","severity":"error","message":"connection refused","suggestion":"Check that the server is running","context":{"error_type":"*errors.errorString","host":"db-1"},"group_key":"0123456789abcdef","uptime_ms":1500}
+}
+
+func ExampleFileNotFound() {
+	config := catch.DefaultConfig
+	config.UseColors = false
+	config.ShowHints = false
+	config.ShowStackTrace = false
+	config.ShowUptime = false
+	fmt.Print(catch.RenderReport(FileNotFound(), config))
+	// Output:
+	// error[FS001]: open config.yaml: file does not exist
+	//  --> service.go:42
+	//   |
+	// 41 | func load() error {
+	// 42 | 	f, err := os.Open("config.yaml")
+	//    | 	^
+	// 43 | 	if err != nil {
+	//   |
+	//   = context:
+	//     error_type: *fs.PathError
+	//     path: config.yaml
+	//
+	//   = help: Check if the file path exists and is accessible
+}
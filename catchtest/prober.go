@@ -0,0 +1,72 @@
+package catchtest
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"strings"
+	"testing/fstest"
+
+	"catch"
+)
+
+// FakeProber is a catch.Prober answering from fixed data, for testing
+// enrichments without touching the system. Paths are looked up in FS with
+// any leading slash removed, so "/etc/app.yaml" is FS["etc/app.yaml"].
+// Usage: config.Prober = &catchtest.FakeProber{FS: fstest.MapFS{"etc/app.yaml": {}}}
+type FakeProber struct {
+	FS     fstest.MapFS
+	Limits map[string][2]uint64 // Soft and hard limit by resource name
+	Hosts  map[string][]string  // Addresses by host; others are not found
+}
+
+var _ catch.Prober = (*FakeProber)(nil)
+
+// fsName converts a probed path to its FS key
+func fsName(name string) string {
+	name = strings.Trim(name, "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// Stat reports the entry in FS, or a *fs.PathError for the probed path
+func (p *FakeProber) Stat(name string) (fs.FileInfo, error) {
+	info, err := fs.Stat(p.FS, fsName(name))
+	return info, renamePathError(err, name)
+}
+
+// ReadDir lists the entries of a directory in FS
+func (p *FakeProber) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(p.FS, fsName(name))
+	return entries, renamePathError(err, name)
+}
+
+// Rlimit returns the limits in Limits
+func (p *FakeProber) Rlimit(resource string) (uint64, uint64, error) {
+	limit, ok := p.Limits[resource]
+	if !ok {
+		return 0, 0, errors.New("no limit for " + resource)
+	}
+	return limit[0], limit[1], nil
+}
+
+// LookupHost returns the addresses in Hosts, or a not found DNS error
+func (p *FakeProber) LookupHost(_ context.Context, host string) ([]string, error) {
+	if addrs, ok := p.Hosts[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+// renamePathError reports a path error under the path that was probed
+// rather than its FS key
+func renamePathError(err error, name string) error {
+	var pe *fs.PathError
+	if errors.As(err, &pe) {
+		return &fs.PathError{Op: pe.Op, Path: name, Err: pe.Err}
+	}
+	return err
+}
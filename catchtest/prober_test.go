@@ -0,0 +1,102 @@
+package catchtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"catch"
+)
+
+// probedReport reports err through a catcher using prober and returns
+// the report
+func probedReport(t *testing.T, prober catch.Prober, err error, funcs ...catch.EnrichFunc) catch.ErrorInfo {
+	t.Helper()
+	var reports []catch.ErrorInfo
+	config := renderConfig()
+	config.Prober = prober
+	config.EnrichFuncs = funcs
+	config.Handler = catch.HandlerFunc(func(info catch.ErrorInfo, _ catch.ErrorConfig) error {
+		reports = append(reports, info)
+		return nil
+	})
+	catch.New(config).Err(err)
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reports))
+	}
+	return reports[0]
+}
+
+func TestMissingPathAgainstFake(t *testing.T) {
+	prober := &FakeProber{FS: fstest.MapFS{
+		"srv/app/config.yaml": {},
+		"srv/app/main.go":     {},
+	}}
+
+	typo := &fs.PathError{Op: "open", Path: "/srv/app/confg.yaml", Err: fs.ErrNotExist}
+	info := probedReport(t, prober, typo)
+	if got := info.Context["did_you_mean"]; got != "/srv/app/config.yaml" {
+		t.Errorf("did_you_mean = %v, want /srv/app/config.yaml", got)
+	}
+	if !strings.Contains(info.Suggestion, "/srv/app/config.yaml does") {
+		t.Errorf("suggestion %q lacks the near match", info.Suggestion)
+	}
+
+	deep := &fs.PathError{Op: "open", Path: "/srv/app/certs/live/tls.pem", Err: fs.ErrNotExist}
+	info = probedReport(t, prober, deep)
+	if info.Context["missing_dir"] != "/srv/app/certs" || info.Context["existing_parent"] != "/srv/app" {
+		t.Errorf("context %v, want certs missing below /srv/app", info.Context)
+	}
+}
+
+func TestFileLimitAgainstFake(t *testing.T) {
+	prober := &FakeProber{Limits: map[string][2]uint64{"nofile": {256, 4096}}}
+	err := &fs.PathError{Op: "open", Path: "/srv/app/data.db", Err: syscall.EMFILE}
+
+	info := probedReport(t, prober, err)
+	if got := info.Context["open_files_limit"]; got != "256 (hard 4096)" {
+		t.Errorf("open_files_limit = %v, want 256 (hard 4096)", got)
+	}
+	if !strings.Contains(info.Suggestion, "limit of 256 open files") {
+		t.Errorf("suggestion %q lacks the limit", info.Suggestion)
+	}
+
+	info = probedReport(t, &FakeProber{}, err)
+	if _, ok := info.Context["open_files_limit"]; ok {
+		t.Errorf("limit added although the prober has none: %v", info.Context)
+	}
+}
+
+func TestEnrichFuncReceivesProber(t *testing.T) {
+	prober := &FakeProber{Hosts: map[string][]string{"db.internal": {"10.0.0.7"}}}
+	resolve := func(info *catch.ErrorInfo, p catch.Prober) {
+		addrs, err := p.LookupHost(context.Background(), "db.internal")
+		if err == nil {
+			info.Context["db_addrs"] = strings.Join(addrs, ",")
+		}
+		_, err = p.LookupHost(context.Background(), "cache.internal")
+		info.Context["cache_lookup"] = fmt.Sprint(err)
+	}
+
+	info := probedReport(t, prober, os.ErrDeadlineExceeded, resolve)
+	if info.Context["db_addrs"] != "10.0.0.7" {
+		t.Errorf("db_addrs = %v, want the fake address", info.Context["db_addrs"])
+	}
+	if got := fmt.Sprint(info.Context["cache_lookup"]); !strings.Contains(got, "no such host") {
+		t.Errorf("cache_lookup = %q, want a not found error", got)
+	}
+}
+
+func TestFakeProberReportsProbedPath(t *testing.T) {
+	_, err := (&FakeProber{}).Stat("/etc/app.yaml")
+	var pe *fs.PathError
+	if !errors.As(err, &pe) || pe.Path != "/etc/app.yaml" {
+		t.Errorf("Stat error %v, want one for /etc/app.yaml", err)
+	}
+}
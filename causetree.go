@@ -0,0 +1,274 @@
+package catch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Caps on the cause tree; what lies beyond is counted, not shown
+const (
+	maxTreeDepth   = 6  // Nested joins followed
+	maxTreeBreadth = 10 // Errors shown per join
+)
+
+// PrimaryCodeRule chooses which leaf of a cause tree gives the report its
+// code and suggestion
+type PrimaryCodeRule string
+
+const (
+	PrimaryFirst      PrimaryCodeRule = "first"       // the first leaf with a specific code (default)
+	PrimaryMostSevere PrimaryCodeRule = "most-severe" // the leaf whose code ranks highest, see codeRank
+)
+
+// CauseNode is a branch of a cause tree: the text a run of single
+// wrappers added, then either the errors joined beneath it or, at a leaf,
+// the code and suggestion that error classifies as
+type CauseNode struct {
+	Message    string
+	Code       string // Leaves only
+	Suggestion string // Leaves only
+	Children   []CauseNode
+	Elided     int // Joined errors not shown, past the breadth or depth cap
+}
+
+// unwrapAll returns the non-nil errors err wraps directly
+func unwrapAll(err error) []error {
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if next := u.Unwrap(); next != nil {
+			return []error{next}
+		}
+	case interface{ Unwrap() []error }:
+		var errs []error
+		for _, e := range u.Unwrap() {
+			if e != nil {
+				errs = append(errs, e)
+			}
+		}
+		return errs
+	}
+	return nil
+}
+
+// ownText is the text a wrapper with message msg adds to the message of
+// the error it wraps: msg minus the ": inner" suffix, or nothing when it
+// only repeats or joins its inner messages
+func ownText(msg, inner string) string {
+	switch {
+	case msg == inner || strings.HasPrefix(msg, inner+"\n"):
+		return ""
+	case strings.HasSuffix(msg, ": "+inner):
+		return strings.TrimSuffix(msg, ": "+inner)
+	}
+	return msg
+}
+
+// hasNestedJoin reports whether a multi-error sits somewhere below err
+// along its single wraps. A join at the top is rendered as sections
+// instead, see enrichJoined.
+func hasNestedJoin(err error) bool {
+	for depth := 0; depth < maxCauseDepth; depth++ {
+		wrapped := unwrapAll(err)
+		switch {
+		case len(wrapped) > 1:
+			return depth > 0
+		case len(wrapped) == 0:
+			return false
+		}
+		err = wrapped[0]
+	}
+	return false
+}
+
+// causeTree builds the tree for err, classifying every leaf on its own
+func causeTree(err error, depth int) CauseNode {
+	var parts []string
+	for steps := 0; steps < maxCauseDepth; steps++ {
+		wrapped := unwrapAll(err)
+		msg := safeFormat("%v", err)
+		switch len(wrapped) {
+		case 0:
+			parts = append(parts, describeError(err))
+			code, suggestion := classify(err)
+			return CauseNode{Message: strings.Join(parts, ": "), Code: code, Suggestion: suggestion}
+		case 1:
+			if own := ownText(msg, safeFormat("%v", wrapped[0])); own != "" {
+				parts = append(parts, own)
+			}
+			err = wrapped[0]
+		default:
+			node := CauseNode{Message: strings.Join(parts, ": ")}
+			if depth >= maxTreeDepth {
+				node.Elided = len(wrapped)
+				return node
+			}
+			for i, child := range wrapped {
+				if i == maxTreeBreadth {
+					node.Elided = len(wrapped) - i
+					break
+				}
+				node.Children = append(node.Children, causeTree(child, depth+1))
+			}
+			return node
+		}
+	}
+	return CauseNode{Message: strings.Join(parts, ": ")}
+}
+
+// leaves returns the leaves of node in display order
+func (node CauseNode) leaves() []CauseNode {
+	if len(node.Children) == 0 {
+		return []CauseNode{node}
+	}
+	var out []CauseNode
+	for _, child := range node.Children {
+		out = append(out, child.leaves()...)
+	}
+	return out
+}
+
+// codeRanks orders code families for PrimaryMostSevere: failures of the
+// system and its resources outrank I/O, which outranks data and logic
+// errors. Unlisted codes, such as registered ones, rank 1 and GEN000 0.
+var codeRanks = map[string]int{"SYS": 6, "FS": 5, "NET": 4, "DATA": 3, "TIME": 2, "TMPL": 2, "LOGIC": 1}
+
+// codeRank ranks code by its family prefix
+func codeRank(code string) int {
+	if code == "GEN000" {
+		return 0
+	}
+	family := strings.TrimRight(code, "0123456789")
+	if rank, ok := codeRanks[family]; ok {
+		return rank
+	}
+	return 1
+}
+
+// primaryLeaf picks the leaf that gives the report its code
+func primaryLeaf(root CauseNode, rule PrimaryCodeRule) (CauseNode, bool) {
+	leaves := root.leaves()
+	if len(leaves) == 0 || leaves[0].Code == "" {
+		return CauseNode{}, false
+	}
+	best := leaves[0]
+	for _, leaf := range leaves {
+		if rule == PrimaryMostSevere {
+			if codeRank(leaf.Code) > codeRank(best.Code) {
+				best = leaf
+			}
+		} else if codeRank(leaf.Code) > 0 {
+			return leaf, true
+		}
+	}
+	return best, true
+}
+
+// enrichCauseTree renders a chain holding a join below the top as a tree:
+// the outer wrappers become the headline, each leaf is classified, and
+// the report takes its code from the primary leaf unless Code forced one
+func enrichCauseTree(info *ErrorInfo, config ErrorConfig) bool {
+	if !hasNestedJoin(info.Error) {
+		return false
+	}
+	root := causeTree(info.Error, 0)
+	if root.Message == "" {
+		root.Message = fmt.Sprintf("%d errors", len(root.Children)+root.Elided)
+	}
+	info.Headline, info.CauseTree = root.Message, &root
+	if leaf, ok := primaryLeaf(root, config.PrimaryCode); ok && info.opts.code == "" {
+		info.ErrorCode, info.Suggestion = leaf.Code, leaf.Suggestion
+		if leaf.Code != "GEN000" {
+			recordNotice(NoticeCauseTreeCode, info.File, info.Line)
+		}
+	}
+	return true
+}
+
+// RenderCauseTree renders the "= caused by:" block of a cause tree, with
+// branch characters and each leaf's code inline
+func RenderCauseTree(info ErrorInfo, config ErrorConfig) string {
+	if info.CauseTree == nil {
+		return ""
+	}
+	var output strings.Builder
+	if config.UseColors {
+		output.WriteString(fmt.Sprintf("  %s=%s %scaused by:%s\n", Blue+Bold, Reset, Yellow+Bold, Reset))
+	} else {
+		output.WriteString("  = caused by:\n")
+	}
+	renderCauseNodes(&output, info.CauseTree.Children, info.CauseTree.Elided, "    ", config)
+	output.WriteString("\n")
+	return output.String()
+}
+
+// renderCauseNodes renders one level of the tree under prefix
+func renderCauseNodes(output *strings.Builder, nodes []CauseNode, elided int, prefix string, config ErrorConfig) {
+	for i, node := range nodes {
+		branch, indent := "├─ ", "│  "
+		if i == len(nodes)-1 && elided == 0 {
+			branch, indent = "└─ ", "   "
+		}
+		line := node.Message
+		if line == "" {
+			line = fmt.Sprintf("%d errors", len(node.Children)+node.Elided)
+		}
+		if node.Code != "" {
+			code := "[" + node.Code + "]"
+			if config.UseColors {
+				code = Bold + code + Reset
+			}
+			line = code + " " + line
+		}
+		if len(node.Children) == 0 && node.Elided > 0 {
+			line += fmt.Sprintf(" (%d joined errors not shown)", node.Elided)
+		}
+		output.WriteString(prefix + branch + line + "\n")
+		if len(node.Children) > 0 {
+			renderCauseNodes(output, node.Children, node.Elided, prefix+indent, config)
+		}
+	}
+	if elided > 0 && len(nodes) > 0 {
+		output.WriteString(fmt.Sprintf("%s└─ … %d more\n", prefix, elided))
+	}
+}
+
+// flatten joins the leaves of a tree into one line for compact output
+func (node CauseNode) flatten() string {
+	var parts []string
+	for _, leaf := range node.leaves() {
+		parts = append(parts, fmt.Sprintf("[%s] %s", leaf.Code, strings.ReplaceAll(leaf.Message, "\n", "; ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// CauseV1 is a node of the cause tree in a ReportV1
+type CauseV1 struct {
+	Message string    `json:"message"`
+	Code    string    `json:"code,omitempty"`
+	Causes  []CauseV1 `json:"causes,omitempty"`
+	Elided  int       `json:"elided,omitempty"`
+}
+
+// causeV1 converts a cause tree to its JSON form
+func causeV1(node *CauseNode) *CauseV1 {
+	if node == nil {
+		return nil
+	}
+	out := &CauseV1{Message: node.Message, Code: node.Code, Elided: node.Elided}
+	for i := range node.Children {
+		out.Causes = append(out.Causes, *causeV1(&node.Children[i]))
+	}
+	return out
+}
+
+// causeNode converts a cause tree back from its JSON form
+func causeNode(c *CauseV1) *CauseNode {
+	if c == nil {
+		return nil
+	}
+	node := &CauseNode{Message: c.Message, Code: c.Code, Elided: c.Elided}
+	for i := range c.Causes {
+		node.Children = append(node.Children, *causeNode(&c.Causes[i]))
+	}
+	return node
+}
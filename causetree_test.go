@@ -0,0 +1,152 @@
+package catch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+// mixedTree returns a three-level chain: a wrapper over a join whose
+// middle error wraps a second join
+func mixedTree() error {
+	return fmt.Errorf("sync: %w", errors.Join(
+		errors.New("connection refused"),
+		fmt.Errorf("store: %w", errors.Join(
+			errors.New("invalid character 'x' looking for beginning of value"),
+			&fs.PathError{Op: "open", Path: "/data/cache", Err: fs.ErrPermission},
+		)),
+		errors.New("deadline exceeded"),
+	))
+}
+
+func TestCauseTreeRendersBranches(t *testing.T) {
+	config := testConfig()
+	config.ShowSourceCode = false
+	buf := testCatch(t, config)
+
+	Err(mixedTree())
+
+	want := "  = caused by:\n" +
+		"    ├─ [NET001] connection refused\n" +
+		"    ├─ store\n" +
+		"    │  ├─ [GEN000] invalid character 'x' looking for beginning of value\n" +
+		"    │  └─ [FS002] open /data/cache: permission denied\n" +
+		"    └─ [NET002] deadline exceeded\n"
+	if out := buf.String(); !strings.Contains(out, want) {
+		t.Errorf("report lacks the tree\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestCauseTreePrimaryCode(t *testing.T) {
+	for _, tc := range []struct {
+		rule PrimaryCodeRule
+		want string
+	}{
+		{"", "NET001"},
+		{PrimaryFirst, "NET001"},
+		{PrimaryMostSevere, "FS002"},
+	} {
+		config := testConfig()
+		config.PrimaryCode = tc.rule
+		rec := recordCatch(t, config)
+		Err(mixedTree())
+
+		info := rec.reports()[0]
+		if info.ErrorCode != tc.want {
+			t.Errorf("rule %q: code %s, want %s", tc.rule, info.ErrorCode, tc.want)
+		}
+		if info.Headline != "sync" {
+			t.Errorf("rule %q: headline %q, want the outer wrapper", tc.rule, info.Headline)
+		}
+	}
+}
+
+func TestCauseTreeSkipsGenericLeaves(t *testing.T) {
+	err := fmt.Errorf("load: %w", errors.Join(errors.New("something odd"), fs.ErrNotExist))
+	if leaf, _ := primaryLeaf(causeTree(err, 0), PrimaryFirst); leaf.Code == "GEN000" {
+		t.Errorf("first rule picked the generic leaf %q", leaf.Message)
+	}
+}
+
+func TestCauseTreeForcedCodeWins(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	Err(mixedTree(), Code("SYNC42"))
+
+	if code := rec.reports()[0].ErrorCode; code != "SYNC42" {
+		t.Errorf("code %s, want the forced SYNC42", code)
+	}
+}
+
+func TestCauseTreeBreadthCap(t *testing.T) {
+	errs := make([]error, maxTreeBreadth+3)
+	for i := range errs {
+		errs[i] = fmt.Errorf("shard %d failed", i)
+	}
+	root := causeTree(fmt.Errorf("replicate: %w", errors.Join(errs...)), 0)
+
+	if len(root.Children) != maxTreeBreadth || root.Elided != 3 {
+		t.Fatalf("%d children, %d elided; want %d and 3", len(root.Children), root.Elided, maxTreeBreadth)
+	}
+	out := RenderCauseTree(ErrorInfo{CauseTree: &root}, testConfig())
+	if !strings.Contains(out, "    └─ … 3 more\n") {
+		t.Errorf("rendering lacks the elision marker:\n%s", out)
+	}
+	if strings.Contains(out, "└─ shard") {
+		t.Errorf("last shown shard drawn as the last branch:\n%s", out)
+	}
+}
+
+func TestCauseTreeDepthCap(t *testing.T) {
+	err := errors.Join(errors.New("a"), errors.New("b"))
+	for i := 0; i <= maxTreeDepth; i++ {
+		err = fmt.Errorf("level %d: %w", i, errors.Join(err, errors.New("sibling")))
+	}
+	root := causeTree(fmt.Errorf("top: %w", err), 0)
+
+	node, depth := root, 0
+	for len(node.Children) > 0 {
+		node, depth = node.Children[0], depth+1
+	}
+	if depth != maxTreeDepth || node.Elided != 2 {
+		t.Fatalf("deepest node at depth %d with %d elided, want %d and 2", depth, node.Elided, maxTreeDepth)
+	}
+	out := RenderCauseTree(ErrorInfo{CauseTree: &root}, testConfig())
+	if !strings.Contains(out, "(2 joined errors not shown)") {
+		t.Errorf("rendering lacks the elision note:\n%s", out)
+	}
+}
+
+func TestCauseTreeJSONNestsCauses(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	Err(mixedTree())
+
+	data, err := json.Marshal(NewReportV1(rec.reports()[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		CauseTree CauseV1 `json:"cause_tree"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	tree := report.CauseTree
+	if tree.Message != "sync" || len(tree.Causes) != 3 {
+		t.Fatalf("root %q with %d causes, want sync with 3", tree.Message, len(tree.Causes))
+	}
+	store := tree.Causes[1]
+	if store.Message != "store" || store.Code != "" || len(store.Causes) != 2 {
+		t.Fatalf("middle cause %+v, want store with two nested causes", store)
+	}
+	if leaf := store.Causes[1]; leaf.Code != "FS002" || leaf.Message != "open /data/cache: permission denied" {
+		t.Errorf("nested leaf %+v", leaf)
+	}
+
+	back := NewReportV1(rec.reports()[0]).ErrorInfo().CauseTree
+	if back == nil || len(back.Children) != 3 || back.Children[1].Children[1].Code != "FS002" {
+		t.Errorf("tree lost in the round trip: %+v", back)
+	}
+}
@@ -0,0 +1,122 @@
+package catch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxCauseDepth bounds the walk down an error chain
+const maxCauseDepth = 32
+
+// unwrapFirst returns the error err wraps: the only one for Unwrap() error,
+// the first for Unwrap() []error along with how many others were skipped
+func unwrapFirst(err error) (next error, others int) {
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		return u.Unwrap(), 0
+	case interface{ Unwrap() []error }:
+		var errs []error
+		for _, e := range u.Unwrap() {
+			if e != nil {
+				errs = append(errs, e)
+			}
+		}
+		if len(errs) == 0 {
+			return nil, 0
+		}
+		return errs[0], len(errs) - 1
+	}
+	return nil, 0
+}
+
+// errorChain splits err into the message each layer added, outermost
+// first. A wrapper's own text is its message minus the ": inner" suffix;
+// errors.Join and other multi-error layers add no text of their own and
+// are noted on the first layer of the branch that is followed.
+func errorChain(err error) []string {
+	var levels []string
+	pendingNote := ""
+	for depth := 0; err != nil && depth < maxCauseDepth; depth++ {
+		next, others := unwrapFirst(err)
+		msg := safeFormat("%v", err)
+		own := msg
+		if others > 0 {
+			own = "" // A joined error, shown through its first branch
+		} else if next != nil {
+			own = ownText(msg, safeFormat("%v", next))
+		}
+		if others == 1 {
+			pendingNote = " (and 1 other joined error, not shown)"
+		} else if others > 1 {
+			pendingNote = fmt.Sprintf(" (and %d other joined errors, not shown)", others)
+		}
+		if own != "" || next == nil {
+			if strings.TrimSpace(own) == "" {
+				own = fmt.Sprintf("(error of type %s with empty message)", errorTypeName(err))
+			}
+			levels = append(levels, own+pendingNote)
+			pendingNote = ""
+		}
+		err = next
+	}
+	return levels
+}
+
+// enrichCauses splits a wrapped error into the headline and the causes
+// beneath it, so a long "a: b: c" message reads as a chain, ending with
+// the cancellation cause of an ErrCtx context. A headline chosen by the
+// caller is kept whole.
+func enrichCauses(info *ErrorInfo, config ErrorConfig) {
+	if info.Error == nil || info.Headline != "" || enrichCauseTree(info, config) {
+		return
+	}
+	levels := errorChain(info.Error)
+	if info.ctxCause != nil {
+		levels = append(levels, describeError(info.ctxCause))
+	}
+	if len(levels) < 2 {
+		return
+	}
+	info.Headline, info.Causes = levels[0], levels[1:]
+}
+
+// chainMessage joins the headline and causes, or the joined errors, back
+// into one line, for formats without room for the sections
+func (info ErrorInfo) chainMessage() string {
+	if info.CauseTree != nil {
+		return info.headline() + ": " + info.CauseTree.flatten()
+	}
+	if len(info.Joined) > 0 {
+		messages := make([]string, len(info.Joined))
+		for i, joined := range info.Joined {
+			messages[i] = fmt.Sprintf("[%s] %s", joined.ErrorCode, strings.ReplaceAll(describeError(joined.Error), "\n", "; "))
+		}
+		if info.joinedOmitted > 0 {
+			messages = append(messages, fmt.Sprintf("… %d more", info.joinedOmitted))
+		}
+		return info.headline() + ": " + strings.Join(messages, "; ")
+	}
+	if len(info.Causes) == 0 {
+		return info.headline()
+	}
+	return info.headline() + ": " + strings.Join(info.Causes, ": ")
+}
+
+// RenderCauses renders the "= caused by:" block, innermost error last
+func RenderCauses(info ErrorInfo, config ErrorConfig) string {
+	if len(info.Causes) == 0 {
+		return ""
+	}
+
+	var output strings.Builder
+	if config.UseColors {
+		output.WriteString(fmt.Sprintf("  %s=%s %scaused by:%s\n", Blue+Bold, Reset, Yellow+Bold, Reset))
+	} else {
+		output.WriteString("  = caused by:\n")
+	}
+	for i, cause := range info.Causes {
+		output.WriteString(fmt.Sprintf("    %d: %s\n", i, cause))
+	}
+	output.WriteString("\n")
+	return output.String()
+}
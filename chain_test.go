@@ -0,0 +1,74 @@
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestErrorChainSplitsLayers(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{"single", errors.New("disk full"), []string{"disk full"}},
+		{
+			"three layers",
+			fmt.Errorf("saving report: %w", fmt.Errorf("writing index: %w", errors.New("disk full"))),
+			[]string{"saving report", "writing index", "disk full"},
+		},
+		{
+			"joined branch",
+			fmt.Errorf("batch: %w", errors.Join(errors.New("first"), errors.New("second"), errors.New("third"))),
+			[]string{"batch", "first (and 2 other joined errors, not shown)"},
+		},
+		{
+			"empty root",
+			fmt.Errorf("closing: %w", &emptyError{}),
+			[]string{"closing", "(error of type *catch.emptyError with empty message)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorChain(tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("errorChain = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCausedByRendersInnermostLast(t *testing.T) {
+	config := testConfig()
+	config.ShowSourceCode = false
+	buf := testCatch(t, config)
+
+	Err(fmt.Errorf("saving report: %w", fmt.Errorf("writing index: %w", errors.New("disk full"))))
+
+	out := buf.String()
+	if countHeadlines(out, "saving report") != 1 {
+		t.Errorf("headline isn't the outermost layer alone:\n%s", out)
+	}
+	if !strings.Contains(out, "  = caused by:\n    0: writing index\n    1: disk full\n") {
+		t.Errorf("no caused-by chain:\n%s", out)
+	}
+}
+
+func TestCodeComesFromRootCause(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	root := errors.New("connection refused")
+	Err(fmt.Errorf("loading profile: %w", root))
+
+	info := rec.reports()[0]
+	if want := generateSmartErrorCode(root); info.ErrorCode != want {
+		t.Errorf("code %s, want %s of the root cause", info.ErrorCode, want)
+	}
+	if want := generateSmartSuggestion(root); info.Suggestion != want {
+		t.Errorf("suggestion %q, want the root cause's %q", info.Suggestion, want)
+	}
+	if got := info.chainMessage(); got != "loading profile: connection refused" {
+		t.Errorf("chainMessage = %q", got)
+	}
+}
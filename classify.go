@@ -0,0 +1,56 @@
+package catch
+
+import "fmt"
+
+// classify picks the error code and suggestion for err. Codes registered
+// with RegisterCode take precedence over everything else. The innermost
+// wrapped error decides, since wrapper messages like "failed to parse
+// flags" describe the caller's intent rather than the failure; the full
+// message is only consulted when the root cause is unrecognized. Errno
+// values, clock-skew symptoms and template errors are matched first, since
+// their messages vary by platform and library or wrap the real cause.
+func classify(err error) (code, suggestion string) {
+	if code, suggestion, ok := classifyRegistered(err); ok {
+		return code, suggestion
+	}
+	if code, suggestion, ok := classifyErrno(err); ok {
+		return code, suggestion
+	}
+	if code, suggestion, ok := classifyClockSkew(err); ok {
+		return code, suggestion
+	}
+	if code, suggestion, ok := classifyTemplate(err); ok {
+		return code, suggestion
+	}
+	if root := rootCause(err); root != err {
+		if code := generateSmartErrorCode(root); code != "GEN000" {
+			return code, generateSmartSuggestion(root)
+		}
+	}
+	return generateSmartErrorCode(err), generateSmartSuggestion(err)
+}
+
+// rootCause follows the Unwrap chain to the innermost error, taking the
+// first branch of joined errors
+func rootCause(err error) error {
+	for depth := 0; depth < maxCauseDepth; depth++ {
+		next, _ := unwrapFirst(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+	return err
+}
+
+// enrichRootCause records the root cause in context when the headline
+// and the causes don't already show it
+func enrichRootCause(info *ErrorInfo) {
+	root := rootCause(info.Error)
+	if root == info.Error || len(info.Causes) > 0 || len(info.Joined) > 0 {
+		return
+	}
+	if msg := describeError(root); msg != info.headline() {
+		setContext(info, "root_error", fmt.Sprintf("%s: %s", errorTypeName(root), msg))
+	}
+}
@@ -0,0 +1,46 @@
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// pathologicalSeeds are inputs that broke similar libraries: invalid
+// UTF-8, embedded NULs, enormous strings and stray escape sequences
+var pathologicalSeeds = []string{
+	"",
+	"open /etc/app.yaml: no such file or directory",
+	"invalid \xff\xfe utf-8 \xc3",
+	"nul\x00in the\x00middle",
+	strings.Repeat("x", 1<<16),
+	strings.Repeat("0x1f ", 1<<12),
+	"\033[31mred\033[0m and \033[\033[0m0m",
+	"quote \" unbalanced ' and ` marks",
+	"panic: runtime error: index out of range [5] with length 3",
+}
+
+func FuzzClassify(f *testing.F) {
+	for _, seed := range pathologicalSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, msg string) {
+		err := errors.New(msg)
+		code, _ := classify(err)
+		if code == "" {
+			t.Errorf("no code for %q", msg)
+		}
+		if wrapped, _ := classify(fmt.Errorf("outer: %w", err)); wrapped == "" {
+			t.Errorf("no code for wrapped %q", msg)
+		}
+		generateSmartSuggestion(err)
+		if Normalize(msg) != Normalize(msg) {
+			t.Errorf("Normalize(%q) is not deterministic", msg)
+		}
+		info := ErrorInfo{Error: err, ErrorCode: code, Function: "main.run"}
+		if len(groupKey(info)) != 16 {
+			t.Errorf("group key %q", groupKey(info))
+		}
+	})
+}
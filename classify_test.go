@@ -0,0 +1,72 @@
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestClassifyPrefersRootCause(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			"parse wrapper over a missing file",
+			fmt.Errorf("failed to parse flags: %w", &fs.PathError{Op: "open", Path: "flags.conf", Err: fs.ErrNotExist}),
+			"FS001",
+		},
+		{
+			"two wrappers",
+			fmt.Errorf("parse config: %w", fmt.Errorf("timeout loading include: %w",
+				&fs.PathError{Op: "open", Path: "inc.conf", Err: fs.ErrPermission})),
+			"FS002",
+		},
+		{
+			"unrecognized root falls back to the wrapper",
+			fmt.Errorf("failed to parse flags: %w", errors.New("unexpected token '='")),
+			"DATA001",
+		},
+		{"unwrapped", errors.New("failed to parse flags"), "DATA001"},
+	} {
+		if code, _ := classify(tc.err); code != tc.want {
+			t.Errorf("%s: code = %s, want %s", tc.name, code, tc.want)
+		}
+	}
+}
+
+func TestClassifyWrappedPathErrorReport(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	err := fmt.Errorf("failed to parse flags: %w", &fs.PathError{Op: "open", Path: "flags.conf", Err: fs.ErrNotExist})
+
+	Err(err)
+	info := rec.reports()[0]
+	if info.ErrorCode != "FS001" {
+		t.Errorf("code = %s, want FS001", info.ErrorCode)
+	}
+	if strings.Contains(strings.ToLower(info.Suggestion), "format") {
+		t.Errorf("suggestion = %q follows the wrapper text", info.Suggestion)
+	}
+}
+
+func TestRootErrorContext(t *testing.T) {
+	root := &fs.PathError{Op: "open", Path: "flags.conf", Err: fs.ErrNotExist}
+	info := ErrorInfo{
+		Error:    fmt.Errorf("failed to parse flags: %w", root),
+		Headline: "could not start",
+		Context:  map[string]interface{}{},
+	}
+	enrichRootCause(&info)
+	if got, want := info.Context["root_error"], "*errors.errorString: file does not exist"; got != want {
+		t.Errorf("root_error = %v, want %q", got, want)
+	}
+
+	info = ErrorInfo{Error: errors.New("flags missing"), Context: map[string]interface{}{}}
+	enrichRootCause(&info)
+	if _, ok := info.Context["root_error"]; ok {
+		t.Errorf("root_error set for an unwrapped error: %v", info.Context)
+	}
+}
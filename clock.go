@@ -0,0 +1,128 @@
+package catch
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock is the time source for uptime, throttling, summaries and every
+// other time-dependent feature
+type Clock interface {
+	Now() time.Time
+}
+
+// Entropy is the randomness source for error IDs
+type Entropy interface {
+	Uint64() uint64
+}
+
+// systemClock is the production Clock
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// systemEntropy is the production Entropy
+type systemEntropy struct{}
+
+func (systemEntropy) Uint64() uint64 { return rand.Uint64() }
+
+type clockHolder struct{ Clock }
+type entropyHolder struct{ Entropy }
+
+// The active clock, entropy and terminal detector are set while package
+// variables initialize, ahead of every init function, which may already
+// report: the crash monitor runs from one.
+var (
+	activeClock   = storedPointer(&clockHolder{systemClock{}})
+	activeEntropy = storedPointer(&entropyHolder{systemEntropy{}})
+)
+
+// storedPointer returns an atomic pointer holding v
+func storedPointer[T any](v *T) *atomic.Pointer[T] {
+	var p atomic.Pointer[T]
+	p.Store(v)
+	return &p
+}
+
+// SetClockForTesting replaces the package clock and returns a function
+// restoring the previous one
+// Usage: defer catch.SetClockForTesting(clock)()
+func SetClockForTesting(c Clock) (restore func()) {
+	prev := activeClock.Swap(&clockHolder{c})
+	return func() { activeClock.Store(prev) }
+}
+
+// SetEntropyForTesting replaces the package randomness source and returns
+// a function restoring the previous one
+func SetEntropyForTesting(e Entropy) (restore func()) {
+	prev := activeEntropy.Swap(&entropyHolder{e})
+	return func() { activeEntropy.Store(prev) }
+}
+
+// now returns the current time from the active clock
+func now() time.Time {
+	return activeClock.Load().Now()
+}
+
+// Now returns the current time from the package clock, so that companion
+// packages such as catchtail follow SetClockForTesting too
+func Now() time.Time {
+	return now()
+}
+
+// newErrorID returns a random identifier for a handled error
+func newErrorID() string {
+	return fmt.Sprintf("%016x", activeEntropy.Load().Uint64())
+}
+
+// ManualClock is a Clock that only moves when told to
+type ManualClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+// NewManualClock returns a clock frozen at t
+func NewManualClock(t time.Time) *ManualClock {
+	return &ManualClock{t: t}
+}
+
+// Now returns the clock's current time
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+// Advance moves the clock forward by d
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+// Set moves the clock to t
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = t
+}
+
+// NewSeededEntropy returns a deterministic Entropy for reproducible IDs
+func NewSeededEntropy(seed uint64) Entropy {
+	return &seededEntropy{rng: rand.New(rand.NewPCG(seed, seed))}
+}
+
+// seededEntropy serializes access to a seeded generator
+type seededEntropy struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func (s *seededEntropy) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.Uint64()
+}
@@ -0,0 +1,74 @@
+package catch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManualClockDrivesDedupWindow(t *testing.T) {
+	clock := NewManualClock(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	defer SetClockForTesting(clock)()
+	c, buf := dedupCatcher(time.Minute, 0)
+
+	report := func() { c.Err(errors.New("disk full")) }
+	report()
+	clock.Advance(30 * time.Second)
+	report()
+	report()
+	if got := countHeadlines(buf.String(), "disk full"); got != 1 {
+		t.Fatalf("%d full reports inside the window, want 1:\n%s", got, buf)
+	}
+
+	clock.Advance(31 * time.Second)
+	report()
+	if got := countHeadlines(buf.String(), "disk full"); got != 2 {
+		t.Errorf("%d full reports once the window ended, want 2:\n%s", got, buf)
+	}
+	if want := "error[GEN000] repeated 2 more times (last at 09:00:30)\n"; !containsLine(buf.String(), want) {
+		t.Errorf("report lacks %q:\n%s", want, buf)
+	}
+}
+
+func TestSeededEntropyRepeatsIDs(t *testing.T) {
+	ids := func() []string {
+		defer SetEntropyForTesting(NewSeededEntropy(42))()
+		rec := recordCatch(t, testConfig())
+		for i := 0; i < 3; i++ {
+			Err(errors.New("boom"))
+		}
+		var ids []string
+		for _, info := range rec.reports() {
+			ids = append(ids, info.ID)
+		}
+		return ids
+	}
+
+	first, second := ids(), ids()
+	if len(first) != 3 || first[0] == first[1] || first[1] == first[2] {
+		t.Fatalf("ids = %v, want 3 distinct", first)
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("id %d = %s then %s under the same seed", i, first[i], second[i])
+		}
+	}
+}
+
+func TestManualClockStampsReports(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	defer SetClockForTesting(NewManualClock(at))()
+	rec := recordCatch(t, testConfig())
+
+	Err(errors.New("boom"))
+	if got := rec.reports()[0].Time; !got.Equal(at) {
+		t.Errorf("Time = %v, want %v", got, at)
+	}
+}
+
+// containsLine reports whether line, newline included, is one of the
+// lines of out
+func containsLine(out, line string) bool {
+	return strings.Contains("\n"+out, "\n"+line)
+}
@@ -0,0 +1,85 @@
+package catch
+
+import (
+	"errors"
+	"sync"
+)
+
+// codeRule is an error code registered with RegisterCode
+type codeRule struct {
+	match      func(error) bool
+	code       string
+	suggestion string
+}
+
+// codeRules holds the registered rules in registration order
+var codeRules struct {
+	mu    sync.RWMutex
+	rules []codeRule
+}
+
+// RegisterCode gives errors matching match the code and suggestion,
+// ahead of the built-in classification. Rules are tried in registration
+// order and the first match wins. It is safe to call from init functions
+// in several packages.
+// Usage: catch.RegisterCode(isQuotaError, "BIZ042", "see the quota runbook")
+func RegisterCode(match func(error) bool, code, suggestion string) {
+	codeRules.mu.Lock()
+	defer codeRules.mu.Unlock()
+	codeRules.rules = append(codeRules.rules, codeRule{match: match, code: code, suggestion: suggestion})
+}
+
+// RegisterCodeFor is RegisterCode for errors wrapping target, as reported
+// by errors.Is
+// Usage: catch.RegisterCodeFor(ErrQuotaExceeded, "BIZ042", "see the quota runbook")
+func RegisterCodeFor(target error, code, suggestion string) {
+	RegisterCode(func(err error) bool { return errors.Is(err, target) }, code, suggestion)
+}
+
+// CodeRegistration describes a registered code
+type CodeRegistration struct {
+	Code       string
+	Suggestion string
+}
+
+// RegisteredCodes lists the registered codes in registration order
+func RegisteredCodes() []CodeRegistration {
+	codeRules.mu.RLock()
+	defer codeRules.mu.RUnlock()
+	list := make([]CodeRegistration, len(codeRules.rules))
+	for i, rule := range codeRules.rules {
+		list[i] = CodeRegistration{Code: rule.code, Suggestion: rule.suggestion}
+	}
+	return list
+}
+
+// ClearRegisteredCodes removes every registered code, for tests
+func ClearRegisteredCodes() {
+	codeRules.mu.Lock()
+	defer codeRules.mu.Unlock()
+	codeRules.rules = nil
+}
+
+// classifyRegistered returns the code of the first registered rule
+// matching err. A rule whose match function panics is skipped.
+func classifyRegistered(err error) (code, suggestion string, ok bool) {
+	codeRules.mu.RLock()
+	rules := codeRules.rules
+	codeRules.mu.RUnlock()
+	for _, rule := range rules {
+		if safeMatch(rule.match, err) {
+			return rule.code, rule.suggestion, true
+		}
+	}
+	return "", "", false
+}
+
+// safeMatch calls match, treating a panic as no match
+func safeMatch(match func(error) bool, err error) (matched bool) {
+	defer func() {
+		if recover() != nil {
+			matched = false
+		}
+	}()
+	return match(err)
+}
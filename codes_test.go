@@ -0,0 +1,77 @@
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+	"testing"
+)
+
+// clearCodes removes the registered codes before and after one test
+func clearCodes(t *testing.T) {
+	ClearRegisteredCodes()
+	t.Cleanup(ClearRegisteredCodes)
+}
+
+var errQuotaExceeded = errors.New("quota exceeded")
+
+func TestRegisteredCodeWinsOverBuiltIn(t *testing.T) {
+	clearCodes(t)
+	err := fmt.Errorf("saving: %w", fs.ErrPermission)
+	builtIn, _ := classify(err)
+
+	RegisterCodeFor(fs.ErrPermission, "BIZ001", "ask ops for write access")
+	if code, suggestion := classify(err); code != "BIZ001" || suggestion != "ask ops for write access" {
+		t.Errorf("classified %s (%q), want BIZ001 over the built-in %s", code, suggestion, builtIn)
+	}
+}
+
+func TestRegisteredCodesMatchInOrder(t *testing.T) {
+	clearCodes(t)
+	RegisterCode(func(err error) bool { panic("broken rule") }, "BAD001", "")
+	RegisterCodeFor(errQuotaExceeded, "BIZ042", "see the quota runbook")
+	RegisterCode(func(error) bool { return true }, "BIZ999", "catch-all")
+
+	if code, _ := classify(fmt.Errorf("upload: %w", errQuotaExceeded)); code != "BIZ042" {
+		t.Errorf("quota error classified %s, want BIZ042", code)
+	}
+	if code, _ := classify(errors.New("other")); code != "BIZ999" {
+		t.Errorf("other error classified %s, want BIZ999", code)
+	}
+	list := RegisteredCodes()
+	if len(list) != 3 || list[1] != (CodeRegistration{Code: "BIZ042", Suggestion: "see the quota runbook"}) {
+		t.Errorf("RegisteredCodes = %+v", list)
+	}
+}
+
+func TestClearRegisteredCodes(t *testing.T) {
+	clearCodes(t)
+	builtIn, _ := classify(errQuotaExceeded)
+	RegisterCodeFor(errQuotaExceeded, "BIZ042", "")
+	ClearRegisteredCodes()
+
+	if n := len(RegisteredCodes()); n != 0 {
+		t.Errorf("%d codes left after clearing", n)
+	}
+	if code, _ := classify(errQuotaExceeded); code != builtIn {
+		t.Errorf("classified %s after clearing, want the built-in %s", code, builtIn)
+	}
+}
+
+func TestRegisterCodeConcurrently(t *testing.T) {
+	clearCodes(t)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			RegisterCodeFor(errQuotaExceeded, fmt.Sprintf("BIZ%03d", i), "")
+			classify(errQuotaExceeded)
+		}()
+	}
+	wg.Wait()
+	if n := len(RegisteredCodes()); n != 50 {
+		t.Errorf("%d codes registered, want 50", n)
+	}
+}
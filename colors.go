@@ -0,0 +1,79 @@
+package catch
+
+import (
+	"io"
+	"os"
+)
+
+// ColorMode chooses when console reports are colored
+type ColorMode string
+
+const (
+	ColorAuto   ColorMode = "auto"   // on terminals with UseColors, unless NO_COLOR is set; FORCE_COLOR overrides both
+	ColorAlways ColorMode = "always" // even when piped
+	ColorNever  ColorMode = "never"
+)
+
+// Color settings from the environment, read once at startup. Per the
+// informal conventions, a non-empty NO_COLOR turns colors off and a
+// FORCE_COLOR other than "0" or "false" turns them on.
+var (
+	noColorEnv    = os.Getenv("NO_COLOR") != ""
+	forceColorEnv = os.Getenv("FORCE_COLOR")
+)
+
+// accessibleEnv turns on Accessible for every catcher when
+// GOCATCH_ACCESSIBLE is set to anything other than "0" or "false"
+var accessibleEnv = func() bool {
+	switch os.Getenv("GOCATCH_ACCESSIBLE") {
+	case "", "0", "false":
+		return false
+	}
+	return true
+}()
+
+// accessible reports whether reports use text markers instead of color
+func (config ErrorConfig) accessible() bool {
+	return config.Accessible || accessibleEnv
+}
+
+// colorMode returns the effective mode. Without Colors, the legacy
+// UseColors maps to Always or Never, so configs predating Colors keep
+// forcing colors on pipes. ColorAuto, as in DefaultConfig, still yields
+// to UseColors false, so a copy of DefaultConfig with UseColors cleared
+// stays uncolored.
+func (config ErrorConfig) colorMode() ColorMode {
+	switch {
+	case config.Colors == ColorAuto && !config.UseColors:
+		return ColorNever
+	case config.Colors != "":
+		return config.Colors
+	case config.UseColors:
+		return ColorAlways
+	}
+	return ColorNever
+}
+
+// colorsFor decides whether reports written to w are colored; never under
+// Accessible, whatever the mode and environment say. The
+// terminal check is cached per writer, so the decision is made once per
+// destination and again when the output changes.
+func colorsFor(config ErrorConfig, w io.Writer) bool {
+	if config.accessible() {
+		return false
+	}
+	switch config.colorMode() {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	switch forceColorEnv {
+	case "":
+	case "0", "false":
+		return false
+	default:
+		return true
+	}
+	return !noColorEnv && terminalFor(w).TTY
+}
@@ -0,0 +1,70 @@
+package catch
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// colorEnv sets the color environment and terminal detection for one test
+func colorEnv(t *testing.T, tty bool, noColor bool, forceColor string) {
+	t.Helper()
+	prevNo, prevForce := noColorEnv, forceColorEnv
+	noColorEnv, forceColorEnv = noColor, forceColor
+	restore := SetTerminalDetectorForTesting(func(io.Writer) Terminal { return Terminal{TTY: tty} })
+	t.Cleanup(func() {
+		restore()
+		noColorEnv, forceColorEnv = prevNo, prevForce
+	})
+}
+
+func TestLegacyUseColorsMapsToAlwaysAndNever(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config ErrorConfig
+		tty    bool
+		want   bool
+	}{
+		{"UseColors true on a pipe", ErrorConfig{UseColors: true}, false, true},
+		{"UseColors true on a terminal", ErrorConfig{UseColors: true}, true, true},
+		{"UseColors false on a terminal", ErrorConfig{UseColors: false}, true, false},
+		{"UseColors false on a pipe", ErrorConfig{UseColors: false}, false, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			colorEnv(t, tc.tty, false, "")
+			if got := colorsFor(tc.config, new(bytes.Buffer)); got != tc.want {
+				t.Errorf("colored = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestColorModes(t *testing.T) {
+	noColors := DefaultConfig
+	noColors.UseColors = false
+	for _, tc := range []struct {
+		name       string
+		config     ErrorConfig
+		tty        bool
+		noColor    bool
+		forceColor string
+		want       bool
+	}{
+		{"default on a terminal", DefaultConfig, true, false, "", true},
+		{"default on a pipe", DefaultConfig, false, false, "", false},
+		{"default with NO_COLOR", DefaultConfig, true, true, "", false},
+		{"default with FORCE_COLOR on a pipe", DefaultConfig, false, false, "1", true},
+		{"default with FORCE_COLOR=0", DefaultConfig, true, false, "0", false},
+		{"default with UseColors cleared", noColors, true, false, "", false},
+		{"Always on a pipe", ErrorConfig{Colors: ColorAlways}, false, true, "", true},
+		{"Never on a terminal", ErrorConfig{UseColors: true, Colors: ColorNever}, true, false, "1", false},
+		{"Accessible", ErrorConfig{Colors: ColorAlways, Accessible: true}, true, false, "", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			colorEnv(t, tc.tty, tc.noColor, tc.forceColor)
+			if got := colorsFor(tc.config, new(bytes.Buffer)); got != tc.want {
+				t.Errorf("colored = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
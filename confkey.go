@@ -0,0 +1,156 @@
+package catch
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ConfigCatcher reports errors caused by one configuration value, so the
+// report names the key that held it rather than only the parse error
+type ConfigCatcher struct {
+	key        string
+	raw        string
+	expected   string
+	candidates []string
+	path       string
+	line       int
+	context    map[string]interface{}
+}
+
+// ConfigKey starts a report about the configuration key holding rawValue.
+// Values of keys that look like secrets are redacted.
+// Usage: catch.ConfigKey("server.timeout", raw).Expect("duration").Set(err)
+func ConfigKey(key, rawValue string) *ConfigCatcher {
+	return &ConfigCatcher{key: key, raw: rawValue, context: make(map[string]interface{})}
+}
+
+// ConfigErr reports err as caused by the value of a configuration key
+// Usage: catch.ConfigErr("server.timeout", raw, err)
+func ConfigErr(key, rawValue string, err error) error {
+	return ConfigKey(key, rawValue).Set(err)
+}
+
+// Expect records the type or form the value should have, e.g. "duration"
+func (c *ConfigCatcher) Expect(typeName string) *ConfigCatcher {
+	c.expected = typeName
+	return c
+}
+
+// Candidates lists the accepted values of an enumerated setting
+func (c *ConfigCatcher) Candidates(values ...string) *ConfigCatcher {
+	c.candidates = values
+	return c
+}
+
+// AtLine points the report at the configuration file line holding the
+// value, whose source is shown instead of the Go code
+func (c *ConfigCatcher) AtLine(path string, line int) *ConfigCatcher {
+	c.path, c.line = path, line
+	return c
+}
+
+// WithContext adds contextual information to the report
+func (c *ConfigCatcher) WithContext(key string, value interface{}) *ConfigCatcher {
+	c.context[key] = value
+	return c
+}
+
+// Set reports err with code CONF001 and returns it
+func (c *ConfigCatcher) Set(err error) error {
+	if err == nil {
+		return nil
+	}
+	config := Catch.getConfig()
+	info := Catch.buildErrorInfo(err)
+	for k, v := range c.context {
+		info.Context[k] = v
+	}
+
+	raw := c.raw
+	if secretKeyPattern.MatchString(c.key) {
+		raw = "[REDACTED]"
+		if c.raw != "" {
+			info.Headline = strings.ReplaceAll(info.headline(), c.raw, raw)
+		}
+	}
+	info.ErrorCode = "CONF001"
+	info.Context["config_key"] = c.key
+	info.Context["raw_value"] = raw
+	if c.expected != "" {
+		info.Context["expected_type"] = c.expected
+	}
+	if len(c.candidates) > 0 {
+		info.Context["candidates"] = strings.Join(c.candidates, ", ")
+	}
+	info.Suggestion = c.suggestion(raw)
+
+	if c.path != "" {
+		info.Context["reported_at"] = fmt.Sprintf("%s:%d", filepath.Base(info.File), info.Line)
+		info.File, info.Line, info.Function = c.path, c.line, ""
+		info.SourceLines = nil
+		if config.ShowSourceCode && c.line > 0 {
+			info.SourceLines = Catch.loadSourceContext(c.path, c.line, config.ContextLines)
+		}
+		if raw != c.raw && c.raw != "" {
+			for i := range info.SourceLines {
+				info.SourceLines[i].Content = strings.ReplaceAll(info.SourceLines[i].Content, c.raw, raw)
+			}
+		}
+		info.setSpan(spanOf(info.SourceLines, raw)) // The Go call's span means nothing here
+	}
+
+	Catch.handleError(info)
+	return err
+}
+
+// suggestion builds the help text naming the key
+func (c *ConfigCatcher) suggestion(raw string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "check the value of %q", c.key)
+	if c.path != "" {
+		fmt.Fprintf(&b, " in %s", filepath.Base(c.path))
+	}
+	switch {
+	case len(c.candidates) > 0:
+		fmt.Fprintf(&b, ": %q is not one of %s", raw, strings.Join(c.candidates, ", "))
+		if best := closestCandidate(c.raw, c.candidates); best != "" && raw == c.raw {
+			fmt.Fprintf(&b, "; did you mean %q?", best)
+		}
+	case c.expected != "":
+		fmt.Fprintf(&b, ": expected a %s, got %q", c.expected, raw)
+	}
+	return b.String()
+}
+
+// closestCandidate returns the candidate within two edits of value, if any
+func closestCandidate(value string, candidates []string) string {
+	best, bestDist := "", 3
+	for _, candidate := range candidates {
+		if d := editDistance(strings.ToLower(value), strings.ToLower(candidate)); d < bestDist {
+			best, bestDist = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,137 @@
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// configReport returns the one report made by fn
+func configReport(t *testing.T, fn func()) ErrorInfo {
+	t.Helper()
+	rec := recordCatch(t, testConfig())
+	fn()
+	reports := rec.reports()
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reports))
+	}
+	return reports[0]
+}
+
+func TestConfigKeyBadDuration(t *testing.T) {
+	_, err := time.ParseDuration("5 sec")
+	info := configReport(t, func() { ConfigKey("server.timeout", "5 sec").Expect("duration").Set(err) })
+
+	if info.ErrorCode != "CONF001" {
+		t.Errorf("code = %s, want CONF001", info.ErrorCode)
+	}
+	for key, want := range map[string]string{"config_key": "server.timeout", "raw_value": "5 sec", "expected_type": "duration"} {
+		if got := info.Context[key]; got != want {
+			t.Errorf("%s = %v, want %s", key, got, want)
+		}
+	}
+	if want := `check the value of "server.timeout": expected a duration, got "5 sec"`; info.Suggestion != want {
+		t.Errorf("suggestion = %q, want %q", info.Suggestion, want)
+	}
+	if filepath.Base(info.File) != "confkey_test.go" {
+		t.Errorf("reported at %s, want this test", info.File)
+	}
+}
+
+func TestConfigKeyUnknownEnumValue(t *testing.T) {
+	err := errors.New(`unknown log level "debgu"`)
+	info := configReport(t, func() {
+		ConfigKey("log.level", "debgu").Candidates("debug", "info", "warn", "error").Set(err)
+	})
+	if info.Context["candidates"] != "debug, info, warn, error" {
+		t.Errorf("candidates = %v", info.Context["candidates"])
+	}
+	if want := `check the value of "log.level": "debgu" is not one of debug, info, warn, error; did you mean "debug"?`; info.Suggestion != want {
+		t.Errorf("suggestion = %q, want %q", info.Suggestion, want)
+	}
+
+	// Nothing close enough to suggest
+	info = configReport(t, func() { ConfigKey("log.level", "verbose").Candidates("debug", "info").Set(err) })
+	if strings.Contains(info.Suggestion, "did you mean") {
+		t.Errorf("suggestion = %q, want no guess", info.Suggestion)
+	}
+}
+
+func TestConfigKeySecretRedacted(t *testing.T) {
+	config := testConfig()
+	config.ShowSourceCode = false
+	out := testCatch(t, config)
+
+	ConfigErr("db.password", "hunter2", fmt.Errorf("password %q is too short", "hunter2"))
+	report := out.String()
+	if strings.Contains(report, "hunter2") {
+		t.Errorf("secret value shown:\n%s", report)
+	}
+	for _, want := range []string{`password "[REDACTED]" is too short`, "raw_value: [REDACTED]", `config_key: db.password`} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestConfigKeyAtLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	yaml := "server:\n  port: 8080\n  timeout: 5 sec\n  host: example.com\n"
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config := testConfig()
+	config.ShowStackTrace = false
+	out := testCatch(t, config)
+
+	_, err := time.ParseDuration("5 sec")
+	ConfigKey("server.timeout", "5 sec").Expect("duration").AtLine(path, 3).Set(err)
+	report := out.String()
+	for _, want := range []string{
+		"app.yaml:3",
+		"3 |   timeout: 5 sec\n  |            ^^^^^\n", // Under the value, not the Go call
+		"2 |   port: 8080",
+		"reported_at: confkey_test.go:",
+		`in app.yaml: expected a duration`,
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "ConfigKey(") {
+		t.Errorf("Go source shown instead of the config file:\n%s", report)
+	}
+}
+
+func TestConfigKeyNilError(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	if err := ConfigErr("server.timeout", "5s", nil); err != nil {
+		t.Errorf("ConfigErr with nil = %v", err)
+	}
+	if n := len(rec.reports()); n != 0 {
+		t.Errorf("nil error reported %d times", n)
+	}
+}
+
+func TestConfigKeyAtLineRedactsSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(path, []byte("db:\n  password: hunter2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config := testConfig()
+	config.ShowStackTrace = false
+	out := testCatch(t, config)
+
+	ConfigKey("db.password", "hunter2").AtLine(path, 2).Set(errors.New("password too short"))
+	report := out.String()
+	if strings.Contains(report, "hunter2") {
+		t.Errorf("secret value shown in the config source:\n%s", report)
+	}
+	if !strings.Contains(report, "2 |   password: [REDACTED]\n  |             ^^^^^^^^^^\n") {
+		t.Errorf("redacted line not marked:\n%s", report)
+	}
+}
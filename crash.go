@@ -0,0 +1,253 @@
+package catch
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// crashMonitorEnv carries the configuration of the child process that
+// tees stderr and watches for crashes
+const crashMonitorEnv = "GOCATCH_CRASH_MONITOR"
+
+// errCrashCaptureUnsupported is returned where stderr can't be redirected
+var errCrashCaptureUnsupported = errors.New("crash capture not supported on this platform")
+
+// maxCrashText bounds the crash output the monitor keeps for parsing
+const maxCrashText = 1 << 20
+
+// PanicCapture is stderr redirected by CapturePanicOutput
+type PanicCapture struct {
+	cmd     *exec.Cmd
+	w       *os.File     // Write end of the pipe, duplicated onto stderr
+	restore func() error // Puts the original stderr back
+}
+
+// crashConfig is the part of the configuration the monitor reports with;
+// handlers and hooks are functions and stay in the program
+type crashConfig struct {
+	LogToFile  string       `json:"log_to_file,omitempty"`
+	LogFormat  LogFormat    `json:"log_format,omitempty"`
+	Format     OutputFormat `json:"format,omitempty"`
+	Source     string       `json:"source,omitempty"`
+	UseColors  bool         `json:"use_colors,omitempty"`
+	Colors     ColorMode    `json:"colors,omitempty"`
+	ShowSource bool         `json:"show_source,omitempty"`
+}
+
+// CapturePanicOutput reports crashes the program cannot recover from,
+// such as a panic on a goroutine started by a third-party library.
+// Stderr is redirected through a pipe to a small monitor process that
+// tees everything to the original stderr and watches for the runtime's
+// "panic:" and "fatal error:" banners. When the program dies after one,
+// the monitor parses the message and the stack of the crashed goroutine
+// and writes them as a fatal report to the console and the configured log
+// file, before the raw crash text it has already passed through.
+//
+// The monitor runs this executable again but branches off in package
+// initialization, so main does not run in it; it takes the log file,
+// formats and Source from the configuration at the time of the call, so
+// call this after Configure. Handlers and hooks don't see crash reports.
+// A crash of the running process freezes it before any of its own code
+// can react, which is why the reader lives in another process.
+//
+// Capture is best effort: it is only supported on Unix, an error is
+// returned if the monitor can't be started, and output written while the
+// monitor isn't reading is not reported. Stop restores the original stderr.
+// Usage: pc, err := catch.CapturePanicOutput(); if err == nil { defer pc.Stop() }
+func CapturePanicOutput() (*PanicCapture, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	config := Catch.getConfig()
+	spec, err := json.Marshal(crashConfig{
+		LogToFile:  config.LogToFile,
+		LogFormat:  config.LogFormat,
+		Format:     config.Format,
+		Source:     config.Source,
+		UseColors:  config.UseColors,
+		Colors:     config.Colors,
+		ShowSource: config.ShowSourceCode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(exe)
+	cmd.Env = append(os.Environ(), crashMonitorEnv+"="+string(spec))
+	cmd.Stdin = r
+	cmd.Stderr = os.Stderr // Still the original stderr
+	if err := cmd.Start(); err != nil {
+		r.Close()
+		w.Close()
+		return nil, err
+	}
+	r.Close()
+	restore, err := redirectStderr(w)
+	if err != nil {
+		w.Close()
+		cmd.Wait()
+		return nil, err
+	}
+	return &PanicCapture{cmd: cmd, w: w, restore: restore}, nil
+}
+
+// Stop restores the original stderr and waits for the monitor to pass on
+// what was written before. Child processes started meanwhile share the
+// pipe, so it also waits for those that are still running.
+func (p *PanicCapture) Stop() error {
+	err := p.restore()
+	p.w.Close()
+	if waitErr := p.cmd.Wait(); err == nil {
+		err = waitErr
+	}
+	return err
+}
+
+// init turns the process into a crash monitor when CapturePanicOutput
+// started it, before any of the program's own code runs
+func init() {
+	if spec, ok := os.LookupEnv(crashMonitorEnv); ok {
+		runCrashMonitor(spec, os.Stdin, os.Stderr)
+		os.Exit(0)
+	}
+}
+
+// runCrashMonitor configures Catch from spec and monitors r
+func runCrashMonitor(spec string, r io.Reader, stderr io.Writer) {
+	var cc crashConfig
+	json.Unmarshal([]byte(spec), &cc) // A bad spec leaves the defaults
+	config := DefaultConfig
+	config.ExitOnError = false
+	config.Output = stderr
+	config.LogToFile = cc.LogToFile
+	config.LogFormat = cc.LogFormat
+	config.Format = cc.Format
+	config.Source = cc.Source
+	config.UseColors = cc.UseColors
+	config.Colors = cc.Colors
+	config.ShowSourceCode = cc.ShowSource
+	Catch.Configure(config)
+	monitorCrashes(r, stderr)
+}
+
+// monitorCrashes copies r to stderr line by line, keeping the text from
+// the last crash banner on, and reports it when r ends
+func monitorCrashes(r io.Reader, stderr io.Writer) {
+	var crash strings.Builder
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		stderr.Write([]byte(line))
+		if crashBanner(line) {
+			crash.Reset()
+		}
+		if crash.Len() > 0 || crashBanner(line) {
+			if crash.Len()+len(line) <= maxCrashText {
+				crash.WriteString(line)
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	if crash.Len() == 0 {
+		return // The program exited without crashing
+	}
+	info, ok := parseCrash(crash.String())
+	if !ok {
+		return
+	}
+	info.opts.noExit = true // The crashed process sets the exit status
+	Catch.handleError(info)
+	Catch.writeConfiguredSummary()
+}
+
+// crashBanner reports whether line starts the runtime's crash output
+func crashBanner(line string) bool {
+	return strings.HasPrefix(line, "panic: ") || strings.HasPrefix(line, "fatal error: ")
+}
+
+// crashFrameLine matches the location line of a goroutine stack frame:
+// "\t/src/app/main.go:12 +0x25"
+var crashFrameLine = regexp.MustCompile(`^\t(.+):(\d+)(?: \+0x[0-9a-f]+)?$`)
+
+// parseCrash builds a report from the runtime's crash output: the
+// "panic: ..." or "fatal error: ..." message and the stack of the
+// goroutine that crashed, listed first. The two kinds keep their banner
+// in the message and are told apart by the "crash" context key.
+func parseCrash(text string) (ErrorInfo, bool) {
+	var msg []string
+	var kind string
+	var stack []StackFrame
+	var goroutine string
+	function := ""
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case goroutine == "" && len(msg) == 0:
+			if strings.HasPrefix(line, "panic: ") {
+				kind = "panic"
+			} else if strings.HasPrefix(line, "fatal error: ") {
+				kind = "fatal error"
+			}
+			if kind != "" {
+				msg = append(msg, line)
+			}
+		case goroutine == "" && strings.HasPrefix(line, "goroutine "):
+			goroutine, _, _ = strings.Cut(strings.TrimPrefix(line, "goroutine "), " ")
+		case goroutine == "" && line != "":
+			msg = append(msg, line) // Multi-line panic values
+		case goroutine != "" && line == "":
+			goto done // Only the first goroutine crashed
+		case goroutine != "":
+			if m := crashFrameLine.FindStringSubmatch(line); m != nil && function != "" {
+				n, _ := strconv.Atoi(m[2])
+				stack = append(stack, StackFrame{File: m[1], Line: n, Function: shortFuncName(function)})
+				function = ""
+			} else if name, _, ok := strings.Cut(line, "("); ok && !strings.HasPrefix(line, "\t") {
+				function = name
+			} else if created, ok := strings.CutPrefix(line, "created by "); ok {
+				function, _, _ = strings.Cut(created, " in goroutine")
+			}
+		}
+	}
+done:
+	if len(msg) == 0 {
+		return ErrorInfo{}, false
+	}
+
+	err := errors.New(strings.Join(msg, "\n"))
+	info := ErrorInfo{
+		Error:    err,
+		Context:  map[string]interface{}{"goroutine": goroutine, "captured": "crash output", "crash": kind},
+		Stack:    stack,
+		Severity: LevelFatal,
+		Time:     now(),
+	}
+	info.ErrorCode, info.Suggestion = classify(err)
+	for _, frame := range stack {
+		if !strings.HasPrefix(frame.Function, "runtime.") && !strings.HasPrefix(frame.Function, "panic(") {
+			info.File, info.Line, info.Function = frame.File, frame.Line, frame.Function
+			break
+		}
+	}
+	config := Catch.getConfig()
+	if config.ShowSourceCode {
+		info.SourceLines = Catch.loadSourceContext(info.File, info.Line, config.ContextLines)
+	}
+	return info, true
+}
@@ -0,0 +1,8 @@
+package catch
+
+import "syscall"
+
+// dup2 duplicates oldfd onto newfd; some Linux ports only have dup3
+func dup2(oldfd, newfd int) error {
+	return syscall.Dup3(oldfd, newfd, 0)
+}
@@ -0,0 +1,11 @@
+//go:build !unix || solaris
+
+package catch
+
+import "os"
+
+// redirectStderr is unsupported without Unix file descriptors, or on
+// Solaris, whose syscall package lacks dup2
+func redirectStderr(w *os.File) (func() error, error) {
+	return nil, errCrashCaptureUnsupported
+}
@@ -0,0 +1,103 @@
+package catch
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// crashTestEnv makes the test binary run the crashing side of
+// TestCapturePanicOutputReportsCrash, logging to the file it names
+const crashTestEnv = "GOCATCH_TEST_CRASH_LOG"
+
+func TestCapturePanicOutputReportsCrash(t *testing.T) {
+	if log := os.Getenv(crashTestEnv); log != "" {
+		config := testConfig()
+		config.Output = os.Stderr
+		config.LogToFile = log
+		config.LogFormat = LogJSONL
+		Catch.Configure(config)
+		if _, err := CapturePanicOutput(); err != nil {
+			t.Fatal(err)
+		}
+		done := make(chan struct{})
+		go func() { panic("boom on an untracked goroutine") }()
+		<-done
+	}
+	if !crashCaptureSupported() {
+		t.Skip("crash capture needs Unix file descriptors")
+	}
+
+	log := filepath.Join(t.TempDir(), "crash.jsonl")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCapturePanicOutputReportsCrash$")
+	cmd.Env = append(os.Environ(), crashTestEnv+"="+log)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err == nil {
+		t.Fatal("crashing process exited successfully")
+	}
+
+	raw := stderr.String()
+	if !strings.Contains(raw, "panic: boom on an untracked goroutine") || !strings.Contains(raw, "goroutine ") {
+		t.Errorf("raw panic output missing from stderr:\n%s", raw)
+	}
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := string(data)
+	for _, want := range []string{"panic: boom on an untracked goroutine", `"crash":"panic"`, "crash_test.go"} {
+		if !strings.Contains(entry, want) {
+			t.Errorf("log entry lacks %q:\n%s", want, entry)
+		}
+	}
+}
+
+func TestParseCrashKeepsFatalErrorsApart(t *testing.T) {
+	text := "fatal error: concurrent map writes\n\ngoroutine 7 [running]:\nmain.worker()\n\t/src/app/main.go:12 +0x25\n"
+	info, ok := parseCrash(text)
+	if !ok {
+		t.Fatal("crash not recognized")
+	}
+	if got := info.Error.Error(); got != "fatal error: concurrent map writes" {
+		t.Errorf("message = %q", got)
+	}
+	if info.Context["crash"] != "fatal error" || info.Context["goroutine"] != "7" {
+		t.Errorf("context = %v", info.Context)
+	}
+	if info.File != "/src/app/main.go" || info.Line != 12 || info.Function != "main.worker" {
+		t.Errorf("location = %s:%d %s", info.File, info.Line, info.Function)
+	}
+}
+
+func TestParseCrashPanic(t *testing.T) {
+	text := "panic: boom\n\ngoroutine 1 [running]:\nmain.main()\n\t/src/app/main.go:5 +0x1d\nexit status 2\n"
+	info, ok := parseCrash(text)
+	if !ok || info.Error.Error() != "panic: boom" || info.Context["crash"] != "panic" {
+		t.Errorf("parseCrash = %v, %v, %v", info.Error, info.Context, ok)
+	}
+	if info.Severity != LevelFatal {
+		t.Errorf("severity = %v, want fatal", info.Severity)
+	}
+}
+
+func TestMonitorCrashesTeesOutput(t *testing.T) {
+	var out strings.Builder
+	testCatch(t, testConfig())
+	monitorCrashes(strings.NewReader("starting\nready\n"), &out)
+	if out.String() != "starting\nready\n" {
+		t.Errorf("tee wrote %q", out.String())
+	}
+}
+
+// crashCaptureSupported reports whether redirectStderr works here
+func crashCaptureSupported() bool {
+	switch runtime.GOOS {
+	case "windows", "plan9", "js", "wasip1", "solaris", "illumos":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,26 @@
+//go:build unix && !solaris
+
+package catch
+
+import (
+	"os"
+	"syscall"
+)
+
+// redirectStderr makes w the process's stderr, file descriptor 2, and
+// returns a function putting the original back
+func redirectStderr(w *os.File) (restore func() error, err error) {
+	saved, err := syscall.Dup(2)
+	if err != nil {
+		return nil, err
+	}
+	syscall.CloseOnExec(saved)
+	if err := dup2(int(w.Fd()), 2); err != nil {
+		syscall.Close(saved)
+		return nil, err
+	}
+	return func() error {
+		defer syscall.Close(saved)
+		return dup2(saved, 2)
+	}, nil
+}
@@ -0,0 +1,63 @@
+package catch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrCtx is Err with the context the failing operation ran under, so
+// deadline and cancellation errors report the deadline, how late the
+// operation was, and the cancellation cause
+// Usage: catch.ErrCtx(ctx, err, "fetching", url)
+func ErrCtx(ctx context.Context, err error, context ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	opts, context := splitOptions(context)
+	info := Catch.buildSmartErrorInfo(err, context...)
+	info.opts = opts
+	enrichContextErr(&info, ctx)
+	Catch.handleError(info)
+	return fixOutcome(err, opts)
+}
+
+// enrichContextErr adds deadline and cancellation details from ctx
+func enrichContextErr(info *ErrorInfo, ctx context.Context) {
+	if ctx == nil {
+		return
+	}
+
+	deadlineErr := errors.Is(info.Error, context.DeadlineExceeded)
+	if !deadlineErr && !errors.Is(info.Error, context.Canceled) {
+		return
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		setContext(info, "context_err", ctxErr.Error())
+	}
+	if cause := context.Cause(ctx); cause != nil && cause != ctx.Err() {
+		info.ctxCause = cause
+		setContext(info, "cause", cause.Error())
+		info.Suggestion = fmt.Sprintf("the context was cancelled by its parent or caller: %v", cause)
+	}
+
+	if !deadlineErr {
+		return
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return
+	}
+	late := now().Sub(deadline).Round(time.Millisecond)
+	setContext(info, "deadline", deadline.Format("15:04:05.000"))
+	if late >= 0 {
+		setContext(info, "exceeded_by", fmt.Sprintf("~%s", late))
+		if _, hasCause := info.Context["cause"]; !hasCause {
+			info.Suggestion = fmt.Sprintf("the deadline was %s and was exceeded by ~%s; raise the timeout or find what made the operation slow",
+				deadline.Format("15:04:05.000"), late)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package catch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestErrCtxDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	deadline, _ := ctx.Deadline()
+	defer SetClockForTesting(NewManualClock(deadline.Add(240 * time.Millisecond)))()
+	rec := recordCatch(t, testConfig())
+
+	ErrCtx(ctx, fmt.Errorf("fetching profile: %w", ctx.Err()))
+	info := rec.reports()[0]
+	if info.ErrorCode != "NET002" {
+		t.Errorf("code = %s, want NET002", info.ErrorCode)
+	}
+	want := map[string]interface{}{
+		"deadline":    deadline.Format("15:04:05.000"),
+		"exceeded_by": "~240ms",
+		"context_err": "context deadline exceeded",
+	}
+	for key, value := range want {
+		if info.Context[key] != value {
+			t.Errorf("context[%s] = %v, want %v", key, info.Context[key], value)
+		}
+	}
+	if !strings.Contains(info.Suggestion, "exceeded by ~240ms") {
+		t.Errorf("suggestion = %q, want how late it was", info.Suggestion)
+	}
+}
+
+func TestErrCtxCancelCause(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	shutdown := errors.New("server shutting down")
+	cancel(shutdown)
+	rec := recordCatch(t, testConfig())
+
+	ErrCtx(ctx, fmt.Errorf("fetching profile: %w", ctx.Err()))
+	info := rec.reports()[0]
+	if info.Context["cause"] != "server shutting down" || info.Context["context_err"] != "context canceled" {
+		t.Errorf("context = %v, want the cause and context_err", info.Context)
+	}
+	if _, ok := info.Context["deadline"]; ok {
+		t.Errorf("deadline set for a context without one: %v", info.Context)
+	}
+	if want := []string{"context canceled", "server shutting down"}; strings.Join(info.Causes, "|") != strings.Join(want, "|") {
+		t.Errorf("causes = %q, want %q", info.Causes, want)
+	}
+	if !strings.Contains(info.Suggestion, "server shutting down") {
+		t.Errorf("suggestion = %q, want the cause", info.Suggestion)
+	}
+}
+
+func TestErrCtxDeadlineWithCause(t *testing.T) {
+	slow := errors.New("upstream too slow")
+	ctx, cancel := context.WithTimeoutCause(context.Background(), time.Millisecond, slow)
+	defer cancel()
+	<-ctx.Done()
+	rec := recordCatch(t, testConfig())
+
+	ErrCtx(ctx, ctx.Err())
+	info := rec.reports()[0]
+	if info.Context["cause"] != "upstream too slow" || info.Context["deadline"] == nil {
+		t.Errorf("context = %v, want both the cause and the deadline", info.Context)
+	}
+	if len(info.Causes) != 1 || info.Causes[0] != "upstream too slow" {
+		t.Errorf("causes = %q, want the cause under the deadline error", info.Causes)
+	}
+}
+
+func TestErrCtxCauseRendered(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errors.New("server shutting down"))
+	config := testConfig()
+	config.ShowSourceCode = false
+	out := testCatch(t, config)
+
+	ErrCtx(ctx, ctx.Err())
+	if !strings.Contains(out.String(), "]: context canceled\n") || !strings.Contains(out.String(), "0: server shutting down\n") {
+		t.Errorf("report lacks the cause in the chain:\n%s", out)
+	}
+}
+
+func TestDeadlineWithoutCtx(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+
+	Err(fmt.Errorf("fetching profile: %w", context.DeadlineExceeded))
+	ErrCtx(nil, context.DeadlineExceeded)
+	for _, info := range rec.reports() {
+		if info.ErrorCode != "NET002" {
+			t.Errorf("code = %s, want NET002", info.ErrorCode)
+		}
+		for _, key := range []string{"deadline", "exceeded_by", "cause"} {
+			if _, ok := info.Context[key]; ok {
+				t.Errorf("context[%s] set without a ctx: %v", key, info.Context)
+			}
+		}
+	}
+}
+
+func TestErrCtxTakesOptions(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	ErrCtx(context.Background(), errors.New("queue full"), AsWarn, Code("Q001"), "queue", "ingest")
+
+	info := rec.reports()[0]
+	if info.Severity != LevelWarn || info.ErrorCode != "Q001" {
+		t.Errorf("severity %v, code %s; want a Q001 warning", info.Severity, info.ErrorCode)
+	}
+	for key, value := range info.Context {
+		if _, ok := value.(Option); ok {
+			t.Errorf("context[%s] holds the option %v", key, value)
+		}
+	}
+	if info.Context["queue"] != "ingest" {
+		t.Errorf("context = %v, want the queue", info.Context)
+	}
+}
@@ -0,0 +1,104 @@
+package catch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// withStartDir sets the startup directory for one test
+func withStartDir(t *testing.T, dir string) {
+	prev := startDir
+	startDir = dir
+	t.Cleanup(func() { startDir = prev })
+}
+
+// chdirRemoved moves the process into a directory and deletes it, so
+// os.Getwd fails for the rest of the test
+func chdirRemoved(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the working directory can't be removed on Windows")
+	}
+	gone := filepath.Join(t.TempDir(), "gone")
+	if err := os.Mkdir(gone, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(gone)
+	if err := os.Remove(gone); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Getwd(); err == nil {
+		t.Skip("os.Getwd still works in a removed directory here")
+	}
+}
+
+func TestReportAfterWorkingDirRemoved(t *testing.T) {
+	start := t.TempDir()
+	withStartDir(t, start)
+	chdirRemoved(t)
+
+	config := testConfig()
+	config.PathStyle = PathRelative
+	config.LogToFile = "app.log"
+	config.DiagnosticsDir = "diag"
+	config.RunSummaryPath = "run.json"
+	stubExit(t)
+	out := testCatch(t, config)
+
+	Fatal(errors.New("connection refused"))
+	report := out.String()
+	for _, want := range []string{"fatal[", "connection refused", "--> ", "cwd_test.go:", "Fatal(errors.New"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+	if err := Catch.writeConfiguredSummary(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Relative paths resolved against the start directory, not the cwd
+	for _, path := range []string{"app.log", "run.json"} {
+		if _, err := os.Stat(filepath.Join(start, path)); err != nil {
+			t.Errorf("%s not written under the start directory: %v", path, err)
+		}
+	}
+	if bundles, _ := filepath.Glob(filepath.Join(start, "diag", "gocatch-*", "report.json")); len(bundles) != 1 {
+		t.Errorf("diagnostics bundles under the start directory: %v", bundles)
+	}
+}
+
+func TestDisplayPathWithoutStartDir(t *testing.T) {
+	file := filepath.Join(string(filepath.Separator)+"src", "app", "main.go")
+	withStartDir(t, "")
+	config := testConfig()
+	config.PathStyle = PathRelative
+	if got := displayPath(file, config); got != file {
+		t.Errorf("PathRelative without a start directory = %s, want %s", got, file)
+	}
+	if got := resolvePath("app.log"); got != "app.log" {
+		t.Errorf("resolvePath without a start directory = %s, want it unchanged", got)
+	}
+
+	withStartDir(t, filepath.Dir(filepath.Dir(file)))
+	if got, want := displayPath(file, config), filepath.Join("app", "main.go"); got != want {
+		t.Errorf("PathRelative = %s, want %s", got, want)
+	}
+}
+
+func TestTrimpathSourcesAfterChdir(t *testing.T) {
+	start := t.TempDir()
+	if err := os.WriteFile(filepath.Join(start, "main.go"), []byte("package main\n\nfunc main() {\n\tpanic(\"boom\")\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	withStartDir(t, start)
+	t.Chdir(t.TempDir())
+
+	// A -trimpath build records module-relative paths
+	lines := Catch.loadSourceContext(mapSourcePath("main.go", nil), 4, 1)
+	if len(lines) != 3 || !lines[1].IsError || lines[1].Content != "\tpanic(\"boom\")" {
+		t.Errorf("source lines = %+v, want main.go around line 4", lines)
+	}
+}
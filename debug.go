@@ -0,0 +1,142 @@
+package catch
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// secretKeyPattern matches names of settings, context keys and URL query
+// parameters whose values must not be printed
+var secretKeyPattern = regexp.MustCompile(`(?i)(token|secret|password|passwd|api[_-]?key|auth|credential|sig(nature)?)`)
+
+// DebugConfig writes the global catcher's effective configuration to w
+// Usage: catch.DebugConfig(os.Stderr)
+func DebugConfig(w io.Writer) {
+	Catch.DebugConfig(w)
+}
+
+// DebugConfig writes the effective configuration, after defaults are
+// applied, with secrets redacted
+func (e *ErrorCatcher) DebugConfig(w io.Writer) {
+	config := e.getConfig()
+
+	var b strings.Builder
+	b.WriteString("gocatch configuration:\n")
+	if !e.configured() {
+		b.WriteString("  (not configured, using DefaultConfig)\n")
+	}
+
+	b.WriteString("  sinks:\n")
+	if w := consoleWriter(config); w != nil {
+		format := config.Format
+		if format == "" {
+			format = FormatPretty
+		}
+		b.WriteString(fmt.Sprintf("    console: %s (%s, %s)\n", writerName(w), format, terminalFor(w)))
+	} else {
+		b.WriteString(fmt.Sprintf("    handler: %T\n", config.Handler))
+	}
+	if config.LogToFile != "" {
+		format := config.LogFormat
+		if format == "" {
+			format = LogText
+		}
+		b.WriteString(fmt.Sprintf("    file: %s (%s)\n", redactValue(config.LogToFile), format))
+	}
+	colors := "none (handler decides)"
+	if w := consoleWriter(config); w != nil {
+		colors = fmt.Sprintf("%t", colorsFor(config, w))
+	}
+	b.WriteString(fmt.Sprintf("  colors: %s (mode %s; NO_COLOR and FORCE_COLOR apply in auto)\n", colors, config.colorMode()))
+	if problems := CheckRendering(config); len(problems) > 0 {
+		b.WriteString("  rendering:\n")
+		for _, problem := range problems {
+			b.WriteString(fmt.Sprintf("    %v\n", problem))
+		}
+	}
+	if counts := silenced.summary(); counts != "" {
+		b.WriteString(fmt.Sprintf("  silenced: %s (see catch.Silenced)\n", counts))
+	}
+
+	if notices := Notices(); len(notices) > 0 {
+		b.WriteString("  notices:\n")
+		for _, notice := range notices {
+			b.WriteString(fmt.Sprintf("    %s (%s): %s\n", notice.ID, notice.Kind, notice.Message))
+		}
+	}
+
+	b.WriteString("  settings:\n")
+	v := reflect.ValueOf(config)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		value := fmt.Sprintf("%v", v.Field(i).Interface())
+		if secretKeyPattern.MatchString(field.Name) && value != "" {
+			value = "[REDACTED]"
+		} else {
+			value = redactValue(value)
+		}
+		b.WriteString(fmt.Sprintf("    %s: %s\n", field.Name, value))
+	}
+
+	fmt.Fprint(w, b.String())
+}
+
+// writerName names a console writer for DebugConfig
+func writerName(w io.Writer) string {
+	switch w {
+	case os.Stderr:
+		return "stderr"
+	case os.Stdout:
+		return "stdout"
+	}
+	if f, ok := w.(*os.File); ok {
+		return redactValue(f.Name())
+	}
+	return fmt.Sprintf("%T", w)
+}
+
+// redactValue masks credentials embedded in URLs (user:password@ and
+// secret-looking query parameters)
+func redactValue(s string) string {
+	if !strings.Contains(s, "://") {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return s
+	}
+
+	if _, hasPassword := u.User.Password(); hasPassword {
+		u.User = url.UserPassword(u.User.Username(), "REDACTED")
+	}
+	query := u.Query()
+	for key := range query {
+		if secretKeyPattern.MatchString(key) {
+			query.Set(key, "REDACTED")
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// debugOnce guards the automatic GOCATCH_DEBUG=1 dump
+var debugOnce sync.Once
+
+// maybeDebugConfig prints the configuration once, at the first handled
+// error, when GOCATCH_DEBUG=1
+func (e *ErrorCatcher) maybeDebugConfig() {
+	if os.Getenv("GOCATCH_DEBUG") != "1" {
+		return
+	}
+	debugOnce.Do(func() { e.DebugConfig(e.getConfig().output()) })
+}
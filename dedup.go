@@ -0,0 +1,222 @@
+package catch
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+)
+
+// dedupEntry counts the repeats of one error within its window
+type dedupEntry struct {
+	severity Severity
+	code     string
+	repeats  int
+	last     time.Time
+	closes   time.Time // End of the window
+}
+
+// dedupState holds the one timer that closes the open DedupWindow windows
+// of a catcher as they end. The windows themselves are in the catcher's
+// key cache, under trackedDedup keys.
+type dedupState struct {
+	mu    sync.Mutex // Guards the windows, timer and next
+	timer *time.Timer
+	next  time.Time // When timer fires, zero when it is idle
+}
+
+// dedupKey identifies an error for DedupWindow: its code, message and
+// reporting site
+func dedupKey(info ErrorInfo) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%s\x00%s:%d", info.ErrorCode, safeFormat("%v", info.Error), info.File, info.Line)
+	return h.Sum64()
+}
+
+// dedupAdmit reports whether info gets a full report. The first of a run
+// of identical errors opens a window of DedupWindow; repeats within it are
+// only counted, and summarized in one line when the window closes.
+func (e *ErrorCatcher) dedupAdmit(info ErrorInfo, config ErrorConfig) bool {
+	if config.DedupWindow <= 0 {
+		return true
+	}
+	key := dedupKey(info)
+	t := now()
+
+	opened := false
+	var ended *dedupEntry
+	e.dedup.mu.Lock()
+	value, evicted := e.keys.GetEvicting(trackedKey{trackedDedup, key}, config.MaxTrackedKeys, func() any {
+		opened = true
+		return &dedupEntry{severity: info.Severity, code: info.ErrorCode, closes: t.Add(config.DedupWindow)}
+	})
+	entry := value.(*dedupEntry)
+	forgotten := evictedWindows(evicted)
+	switch {
+	case opened:
+	case !entry.closes.After(t):
+		// The window ended before the timer closed it, as it does under
+		// a clock that only moves when told to: close it and open anew
+		closed := *entry
+		ended = &closed
+		entry.repeats, entry.closes = 0, t.Add(config.DedupWindow)
+		opened = true
+	default:
+		entry.repeats++
+		entry.last = info.Time
+	}
+	if opened {
+		e.scheduleDedup(entry.closes)
+	}
+	e.dedup.mu.Unlock()
+
+	if ended != nil {
+		e.writeDedupSummary(ended)
+	}
+	for i := range forgotten {
+		e.writeDedupSummary(&forgotten[i]) // Closed early to stay within MaxTrackedKeys
+	}
+	return opened
+}
+
+// evictedWindows copies the DedupWindow windows among the states evicted
+// from e.keys; e.dedup.mu is held
+func evictedWindows(evicted []any) []dedupEntry {
+	var windows []dedupEntry
+	for _, value := range evicted {
+		if entry, ok := value.(*dedupEntry); ok {
+			windows = append(windows, *entry)
+		}
+	}
+	return windows
+}
+
+// closeEvicted writes the summaries of the DedupWindow windows among the
+// states evicted from e.keys by another feature
+func (e *ErrorCatcher) closeEvicted(evicted []any) {
+	if len(evicted) == 0 {
+		return
+	}
+	e.dedup.mu.Lock()
+	forgotten := evictedWindows(evicted)
+	e.dedup.mu.Unlock()
+	for i := range forgotten {
+		e.writeDedupSummary(&forgotten[i])
+	}
+}
+
+// scheduleDedup makes the timer fire by at; e.dedup.mu is held
+func (e *ErrorCatcher) scheduleDedup(at time.Time) {
+	if !e.dedup.next.IsZero() && !at.Before(e.dedup.next) {
+		return
+	}
+	e.dedup.next = at
+	if e.dedup.timer == nil {
+		e.dedup.timer = time.AfterFunc(at.Sub(now()), e.closeDueDedup)
+		return
+	}
+	e.dedup.timer.Reset(at.Sub(now()))
+}
+
+// closeDueDedup closes the windows that have ended, writing their
+// summaries in the order they ended, and schedules the next one
+func (e *ErrorCatcher) closeDueDedup() {
+	t := now()
+	e.dedup.mu.Lock()
+	due := e.sweepDedup(func(entry *dedupEntry) bool { return !entry.closes.After(t) })
+	var next time.Time
+	e.keys.Each(func(_ trackedKey, value any) {
+		if entry, ok := value.(*dedupEntry); ok && (next.IsZero() || entry.closes.Before(next)) {
+			next = entry.closes
+		}
+	})
+	e.dedup.next = time.Time{}
+	if !next.IsZero() {
+		e.scheduleDedup(next)
+	}
+	e.dedup.mu.Unlock()
+
+	sort.Slice(due, func(i, j int) bool { return due[i].closes.Before(due[j].closes) })
+	for _, entry := range due {
+		e.writeDedupSummary(entry)
+	}
+}
+
+// flushDedup closes every open window now, before the process exits
+func (e *ErrorCatcher) flushDedup() {
+	e.dedup.mu.Lock()
+	entries := e.sweepDedup(func(*dedupEntry) bool { return true })
+	if e.dedup.timer != nil {
+		e.dedup.timer.Stop()
+	}
+	e.dedup.next = time.Time{}
+	e.dedup.mu.Unlock()
+	for _, entry := range entries {
+		e.writeDedupSummary(entry)
+	}
+}
+
+// sweepDedup removes the open windows for which fn returns true and
+// returns them; e.dedup.mu is held
+func (e *ErrorCatcher) sweepDedup(fn func(*dedupEntry) bool) []*dedupEntry {
+	var swept []*dedupEntry
+	e.keys.Sweep(func(_ trackedKey, value any) bool {
+		entry, ok := value.(*dedupEntry)
+		if !ok || !fn(entry) {
+			return false
+		}
+		swept = append(swept, entry)
+		return true
+	})
+	return swept
+}
+
+// writeDedupSummary writes the "repeated N more times" line to the
+// console, unless it takes JSON, and to the log file, as a repeatV1
+// record under LogJSONL
+func (e *ErrorCatcher) writeDedupSummary(entry *dedupEntry) {
+	if entry.repeats == 0 {
+		return
+	}
+	config := e.getConfig()
+	line := fmt.Sprintf("%s[%s] repeated %d more %s (last at %s)\n",
+		entry.severity, entry.code, entry.repeats, timesWord(entry.repeats), entry.last.Format("15:04:05"))
+
+	if w := consoleWriter(config); w != nil && config.Format != FormatJSON {
+		consoleMu.Lock()
+		fmt.Fprint(w, line)
+		consoleMu.Unlock()
+	}
+	if config.LogToFile != "" {
+		if config.LogFormat == LogJSONL {
+			line = repeatRecord(entry)
+		}
+		e.logToFile(config.LogToFile, line) // A failure has no report to be noted on
+	}
+}
+
+// repeatV1 is the DedupWindow summary in a LogJSONL file. It has no
+// schema key, so Import rejects it and catchtail hands it to OnError
+// instead of treating it as a report.
+type repeatV1 struct {
+	Time     time.Time `json:"time"`
+	Severity string    `json:"severity"`
+	Code     string    `json:"code"`
+	Repeated int       `json:"repeated"`
+}
+
+// repeatRecord renders entry as a repeatV1 line
+func repeatRecord(entry *dedupEntry) string {
+	data, _ := json.Marshal(repeatV1{Time: entry.last, Severity: entry.severity.String(), Code: entry.code, Repeated: entry.repeats})
+	return string(data) + "\n"
+}
+
+// timesWord is "time" or "times" for n
+func timesWord(n int) string {
+	if n == 1 {
+		return "time"
+	}
+	return "times"
+}
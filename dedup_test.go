@@ -0,0 +1,112 @@
+package catch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dedupCatcher is a catcher deduplicating within window, writing to the
+// returned buffer
+func dedupCatcher(window time.Duration, maxKeys int) (*ErrorCatcher, *bytes.Buffer) {
+	var buf bytes.Buffer
+	config := testConfig()
+	config.Output = &buf
+	config.DedupWindow = window
+	config.MaxTrackedKeys = maxKeys
+	return New(config), &buf
+}
+
+// openWindows returns the number of DedupWindow windows c holds open
+func openWindows(c *ErrorCatcher) int {
+	n := 0
+	c.keys.Each(func(key trackedKey, _ any) {
+		if key.kind == trackedDedup {
+			n++
+		}
+	})
+	return n
+}
+
+func TestDedupSummarizesRepeatsOnFlush(t *testing.T) {
+	c, buf := dedupCatcher(time.Hour, 0)
+	for i := 0; i < 4; i++ {
+		c.Err(errors.New("disk full"))
+	}
+	if got := countHeadlines(buf.String(), "disk full"); got != 1 {
+		t.Fatalf("%d full reports, want 1:\n%s", got, buf)
+	}
+	c.flushDedup()
+	if !strings.Contains(buf.String(), "repeated 3 more times") {
+		t.Errorf("no repeat summary:\n%s", buf)
+	}
+	if openWindows(c) != 0 {
+		t.Errorf("%d windows open after flush", openWindows(c))
+	}
+}
+
+func TestDedupClosesWindowsWithOneTimer(t *testing.T) {
+	var out syncBuffer
+	config := testConfig()
+	config.Output = &out
+	config.DedupWindow = 20 * time.Millisecond
+	c := New(config)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 2; j++ {
+			c.Err(fmt.Errorf("error %d", i))
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for strings.Count(out.String(), "repeated 1 more time ") < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := strings.Count(out.String(), "repeated 1 more time "); got != 3 {
+		t.Errorf("%d summaries, want 3:\n%s", got, out.String())
+	}
+	if n := openWindows(c); n != 0 {
+		t.Errorf("%d windows still open", n)
+	}
+}
+
+func TestDedupBoundsOpenWindows(t *testing.T) {
+	c, buf := dedupCatcher(time.Hour, 2)
+	for _, msg := range []string{"first", "first", "second", "third", "first"} {
+		c.Err(errors.New(msg)) // "third" evicts the window of "first"
+	}
+	if n := openWindows(c); n != 2 {
+		t.Errorf("%d windows open, want 2", n)
+	}
+	if !strings.Contains(buf.String(), "repeated 1 more time ") {
+		t.Errorf("evicted window wrote no summary:\n%s", buf)
+	}
+	if got := countHeadlines(buf.String(), "first"); got != 2 {
+		t.Errorf("%d reports of the evicted error, want 2:\n%s", got, buf)
+	}
+}
+
+func TestTrackedKeysShareOneCap(t *testing.T) {
+	c, buf := dedupCatcher(time.Hour, 64)
+	c.Config.ShowSourceCode, c.Config.EnableSmartAnalysis = false, false // Keep thousands of reports quick
+	for i := 0; i < 2000; i++ {
+		c.Err(errors.New("hot"))
+		c.Err(fmt.Errorf("cold %d", i)) // A new dedup key every time
+		c.assertSampled(0, "sampled", nil)
+	}
+	summary := c.Summary()
+	if summary.TrackedKeys != 64 {
+		t.Errorf("%d keys tracked, want the cap of 64", summary.TrackedKeys)
+	}
+	if got := countHeadlines(buf.String(), "hot"); got != 1 {
+		t.Errorf("%d reports of the hot error, want 1", got)
+	}
+	var counts []uint64
+	for _, n := range summary.SampledAssertions {
+		counts = append(counts, n)
+	}
+	if len(counts) != 1 || counts[0] != 2000 {
+		t.Errorf("sampled counts %v, want one site at 2000", counts)
+	}
+}
@@ -0,0 +1,134 @@
+package catch
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// Degradation is a step on the ladder reports fall down when part of the
+// rendering fails, so a failure costs detail rather than garbling output:
+//
+//	DegradeNone     full pretty report, as configured
+//	DegradeSections pretty report without source snippet and details
+//	DegradeCompact  single line, as used when throttling
+//	DegradeMinimal  bare "error: <msg> at file:line"
+//
+// A report is rendered once; only when a level panics is the next one
+// tried. The level used is kept on ErrorInfo.DegradedTo, after the
+// outputs ran, and reported as degraded_to in JSON.
+type Degradation int
+
+const (
+	DegradeNone Degradation = iota
+	DegradeSections
+	DegradeCompact
+	DegradeMinimal
+)
+
+func (d Degradation) String() string {
+	switch d {
+	case DegradeSections:
+		return "sections"
+	case DegradeCompact:
+		return "compact"
+	case DegradeMinimal:
+		return "minimal"
+	default:
+		return "full"
+	}
+}
+
+// parseDegradation is the inverse of Degradation.String
+func parseDegradation(s string) Degradation {
+	for d := DegradeSections; d <= DegradeMinimal; d++ {
+		if d.String() == s {
+			return d
+		}
+	}
+	return DegradeNone
+}
+
+// degradedCount counts degraded reports across all catchers
+var degradedCount atomic.Uint64
+
+// DegradedCount returns how many reports were rendered below full detail
+// since the process started
+func DegradedCount() uint64 {
+	return degradedCount.Load()
+}
+
+// degradeState records the lowest level the renders of one report fell
+// to. Copies of the report share it, so a level that failed is not tried
+// again by the next output. Its methods do nothing on a nil receiver, as
+// for reports rendered outside the pipeline.
+type degradeState struct {
+	level atomic.Int32
+}
+
+// get returns the level reached so far
+func (d *degradeState) get() Degradation {
+	if d == nil {
+		return DegradeNone
+	}
+	return Degradation(d.level.Load())
+}
+
+// raise records that a render used level and reports whether the state
+// is tracked
+func (d *degradeState) raise(level Degradation) bool {
+	if d == nil {
+		return false
+	}
+	for {
+		old := d.level.Load()
+		if Degradation(old) >= level || d.level.CompareAndSwap(old, int32(level)) {
+			return true
+		}
+	}
+}
+
+// settleDegradation keeps the level the outputs fell to on info and
+// counts the report as degraded if they fell at all. A report lacking
+// only its source is not degraded: the snippet is simply left out.
+func (info *ErrorInfo) settleDegradation(config ErrorConfig) {
+	if level := info.degraded.get(); level > info.DegradedTo {
+		info.DegradedTo = level
+		noteDegraded(level, config)
+	}
+}
+
+// noteDegraded counts a degraded report and calls the OnDegrade hook
+func noteDegraded(level Degradation, config ErrorConfig) {
+	degradedCount.Add(1)
+	if config.OnDegrade != nil {
+		func() {
+			defer func() { recover() }()
+			config.OnDegrade(level)
+		}()
+	}
+}
+
+// renderDegraded renders info at a level below DegradeNone
+func renderDegraded(info ErrorInfo, config ErrorConfig) string {
+	switch info.DegradedTo {
+	case DegradeSections:
+		config.ShowSourceCode = false
+		info.Details = ""
+		return joinSections(info, config)
+	case DegradeCompact:
+		return renderCompact(info, config)
+	default:
+		return renderMinimal(info)
+	}
+}
+
+// renderMinimal is the last step of the ladder and uses nothing that can
+// fail: "error: <msg> at file:line"
+func renderMinimal(info ErrorInfo) string {
+	msg := safeFormat("%v", info.Error)
+	if !info.HasLocation() {
+		return cleanOutput(fmt.Sprintf("%s: %s\n", info.Severity, msg))
+	}
+	return cleanOutput(fmt.Sprintf("%s: %s at %s:%d\n", info.Severity, msg, filepath.Base(info.File), info.Line))
+}
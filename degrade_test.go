@@ -0,0 +1,81 @@
+package catch
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// countingValue counts how often it is rendered as context, panicking
+// when told to
+type countingValue struct {
+	calls *atomic.Int32
+	panic bool
+}
+
+func (v countingValue) ContextString() string {
+	v.calls.Add(1)
+	if v.panic {
+		panic("broken context value")
+	}
+	return "value"
+}
+
+// degradeCatcher is a catcher recording the levels OnDegrade observes
+func degradeCatcher(config ErrorConfig) (*ErrorCatcher, *strings.Builder, *[]Degradation) {
+	var out strings.Builder
+	var levels []Degradation
+	config.Output = &out
+	config.OnDegrade = func(d Degradation) { levels = append(levels, d) }
+	return New(config), &out, &levels
+}
+
+func TestReportRendersOnce(t *testing.T) {
+	c, out, levels := degradeCatcher(testConfig())
+	var calls atomic.Int32
+	c.WithContext("value", countingValue{calls: &calls}).Set(errors.New("disk full"))
+	var once atomic.Int32
+	RenderReport(ErrorInfo{Error: errors.New("disk full"), Context: map[string]interface{}{"value": countingValue{calls: &once}}}, testConfig())
+	if calls.Load() != once.Load() {
+		t.Errorf("context formatted %d times, a single render does it %d times", calls.Load(), once.Load())
+	}
+	if len(*levels) != 0 || !strings.Contains(out.String(), "value: value") {
+		t.Errorf("degraded to %v:\n%s", *levels, out)
+	}
+}
+
+func TestRenderPanicStepsDownTheLadder(t *testing.T) {
+	c, out, levels := degradeCatcher(testConfig())
+	var calls atomic.Int32
+	before := DegradedCount()
+	c.WithContext("value", countingValue{calls: &calls, panic: true}).Set(errors.New("disk full"))
+
+	if len(*levels) != 1 || (*levels)[0] != DegradeCompact {
+		t.Fatalf("OnDegrade saw %v, want [compact]", *levels)
+	}
+	if DegradedCount() != before+1 {
+		t.Errorf("DegradedCount rose by %d, want 1", DegradedCount()-before)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("context formatted %d times, want 2: once each by the full and sections levels", n)
+	}
+	if !strings.Contains(out.String(), "disk full") || strings.Contains(out.String(), "broken context value") {
+		t.Errorf("compact report missing:\n%s", out)
+	}
+}
+
+func TestMissingSourceIsNotDegradation(t *testing.T) {
+	config := testConfig()
+	config.ShowSourceCode = true
+	c, out, levels := degradeCatcher(config)
+	info := c.buildErrorInfo(errors.New("disk full"))
+	info.File, info.Line, info.SourceLines = "/nonexistent/main.go", 3, nil
+	c.handleError(info)
+	if len(*levels) != 0 {
+		t.Errorf("degraded to %v for a missing source", *levels)
+	}
+	if !strings.Contains(out.String(), "main.go:3") {
+		t.Errorf("full report missing:\n%s", out)
+	}
+}
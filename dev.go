@@ -0,0 +1,63 @@
+package catch
+
+import "fmt"
+
+// devMarkerSeverity is the level of Todo and Unreachable reports; builds
+// tagged gocatch_prod lower it to a warning
+var devMarkerSeverity = LevelFatal
+
+// Todo reports code that is not implemented yet (DEV001). The report
+// always carries the stack and names the enclosing function, and is fatal
+// unless DevMarkersWarn is set or the build is tagged gocatch_prod.
+// Usage: catch.Todo("handle v2 manifests")
+func Todo(format string, args ...interface{}) {
+	Catch.devMarker("DEV001", "not implemented", "implement the missing code path, or guard it before release", format, args)
+}
+
+// Unreachable reports that code believed unreachable was reached
+// (DEV002). Like Todo it is fatal by default; the formatting arguments are
+// recorded as the value that led there.
+// Usage: catch.Unreachable("unknown state %v", s)
+func Unreachable(format string, args ...interface{}) {
+	Catch.devMarker("DEV002", "entered unreachable code", "an assumption about the possible values no longer holds; handle the value shown in context", format, args)
+}
+
+// devMarker builds and handles a Todo or Unreachable report
+func (e *ErrorCatcher) devMarker(code, what, suggestion, format string, args []interface{}) {
+	config := e.getConfig()
+	config.ShowStackTrace = true
+
+	msg := fmt.Sprintf(format, args...)
+	info := ErrorInfo{
+		Error:   fmt.Errorf("%s: %s", what, msg),
+		Context: make(map[string]interface{}),
+		Uptime:  now().Sub(processStart),
+	}
+	info.locate(config)
+	if info.Function != "" {
+		info.Headline = fmt.Sprintf("%s in %s: %s", what, info.Function, msg)
+	}
+	info.ErrorCode, info.Suggestion = code, suggestion
+	info.Context["function"] = info.Function
+	if code == "DEV002" {
+		switch len(args) {
+		case 0:
+		case 1:
+			info.Context["value"] = fmt.Sprintf("%#v", args[0])
+		default:
+			values := make([]string, len(args))
+			for i, arg := range args {
+				values[i] = fmt.Sprintf("%#v", arg)
+			}
+			info.Context["values"] = values
+		}
+	}
+	e.loadSources(&info, config)
+
+	info.Severity = devMarkerSeverity
+	if config.DevMarkersWarn {
+		info.Severity = LevelWarn
+	}
+	info.opts.showStack = true
+	e.handleError(info)
+}
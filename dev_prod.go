@@ -0,0 +1,8 @@
+//go:build gocatch_prod
+
+package catch
+
+// Production builds report Todo and Unreachable as warnings
+func init() {
+	devMarkerSeverity = LevelWarn
+}
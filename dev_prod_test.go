@@ -0,0 +1,22 @@
+//go:build gocatch_prod
+
+package catch
+
+import "testing"
+
+func TestProductionDevMarkersWarn(t *testing.T) {
+	exits := stubExit(t)
+	rec := recordCatch(t, testConfig())
+
+	Todo("later")
+	Unreachable("state %v", 3)
+
+	for _, info := range rec.reports() {
+		if info.Severity != LevelWarn || len(info.Stack) == 0 {
+			t.Errorf("%s: severity %v with %d frames, want a warning with its stack", info.ErrorCode, info.Severity, len(info.Stack))
+		}
+	}
+	if len(*exits) != 0 {
+		t.Errorf("exited %v", *exits)
+	}
+}
@@ -0,0 +1,64 @@
+//go:build !gocatch_prod
+
+package catch
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTodoIsFatalWithStack(t *testing.T) {
+	exits := stubExit(t)
+	config := testConfig()
+	config.ShowStackTrace = false
+	rec := recordCatch(t, config)
+
+	Todo("handle v%d manifests", 2)
+
+	info := rec.reports()[0]
+	if info.ErrorCode != "DEV001" || info.Severity != LevelFatal {
+		t.Errorf("code %s, severity %v; want DEV001 and fatal", info.ErrorCode, info.Severity)
+	}
+	if len(info.Stack) == 0 {
+		t.Error("no stack although ShowStackTrace is off")
+	}
+	if !strings.Contains(info.Headline, "TestTodoIsFatalWithStack") || !strings.HasSuffix(info.Headline, "handle v2 manifests") {
+		t.Errorf("headline %q doesn't name the enclosing function", info.Headline)
+	}
+	if len(*exits) != 1 {
+		t.Errorf("exits %v, want one", *exits)
+	}
+}
+
+func TestUnreachableRecordsValues(t *testing.T) {
+	stubExit(t)
+	rec := recordCatch(t, testConfig())
+
+	Unreachable("unknown state %v", "paused")
+	Unreachable("bad pair %v/%v", 1, 2)
+
+	reports := rec.reports()
+	if reports[0].ErrorCode != "DEV002" || reports[0].Context["value"] != `"paused"` {
+		t.Errorf("code %s, context %v", reports[0].ErrorCode, reports[0].Context)
+	}
+	if values, _ := reports[1].Context["values"].([]string); len(values) != 2 || values[1] != "2" {
+		t.Errorf("values %v", reports[1].Context["values"])
+	}
+}
+
+func TestDevMarkersWarn(t *testing.T) {
+	exits := stubExit(t)
+	config := testConfig()
+	config.DevMarkersWarn = true
+	rec := recordCatch(t, config)
+
+	Todo("later")
+
+	info := rec.reports()[0]
+	if info.Severity != LevelWarn || len(info.Stack) == 0 {
+		t.Errorf("severity %v with %d frames, want a warning with its stack", info.Severity, len(info.Stack))
+	}
+	if len(*exits) != 0 {
+		t.Errorf("exited %v", *exits)
+	}
+}
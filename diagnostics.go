@@ -0,0 +1,107 @@
+package catch
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Limits on the source files copied into a diagnostics bundle
+const (
+	maxBundleFileBytes   = 256 << 10 // Larger files are skipped
+	maxBundleSourceBytes = 2 << 20   // Total across all files
+)
+
+// stringLiteral matches Go interpreted and raw string literals
+var stringLiteral = regexp.MustCompile("\"(?:[^\"\\\\]|\\\\.)*\"|`[^`]*`")
+
+// writeDiagnostics writes a bundle for a fatal error under
+// config.DiagnosticsDir and returns its directory. The bundle holds
+// report.json and, under sources/, the full source of the reporting file
+// and of every stack frame inside the main module, mirroring their paths.
+// String literals on lines that look like they hold secrets are masked.
+// Under LowMemory the bundle holds report.json alone.
+func writeDiagnostics(info ErrorInfo, config ErrorConfig) (string, error) {
+	dir := filepath.Join(resolvePath(config.DiagnosticsDir), "gocatch-"+info.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	report, err := MarshalReport(info)
+	if err != nil {
+		return dir, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "report.json"), report, 0644); err != nil {
+		return dir, err
+	}
+
+	root := moduleRoot(mapSourcePath(info.File, config.SourcePathMap))
+	if root == "" || config.LowMemory {
+		return dir, nil
+	}
+
+	files := []string{info.File}
+	for _, frame := range info.Stack {
+		files = append(files, frame.File)
+	}
+	seen := make(map[string]bool)
+	budget := maxBundleSourceBytes
+	for _, file := range files {
+		path := mapSourcePath(file, config.SourcePathMap)
+		rel, err := filepath.Rel(root, path)
+		if err != nil || seen[rel] || !filepath.IsLocal(rel) {
+			continue
+		}
+		seen[rel] = true
+
+		data, err := os.ReadFile(path)
+		if err != nil || len(data) > maxBundleFileBytes || len(data) > budget {
+			continue
+		}
+		budget -= len(data)
+
+		dest := filepath.Join(dir, "sources", rel)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return dir, err
+		}
+		if err := os.WriteFile(dest, redactSource(data), 0644); err != nil {
+			return dir, err
+		}
+	}
+	return dir, nil
+}
+
+// moduleRoot returns the directory of the go.mod enclosing file, or ""
+func moduleRoot(file string) string {
+	if file == "" || !filepath.IsAbs(file) {
+		return ""
+	}
+	for dir := filepath.Dir(file); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return ""
+		}
+	}
+}
+
+// redactSource masks string literals on lines mentioning secret-looking
+// names, e.g. `apiKey := "..."`
+func redactSource(src []byte) []byte {
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	scanner.Buffer(make([]byte, 0, 64<<10), maxBundleFileBytes)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if secretKeyPattern.MatchString(line) && !strings.HasPrefix(strings.TrimSpace(line), "//") {
+			line = stringLiteral.ReplaceAllString(line, `"[REDACTED]"`)
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	return out.Bytes()
+}
@@ -0,0 +1,163 @@
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTree creates the files under root, with their parent directories
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// bundleFiles lists the files of a bundle relative to its directory
+func bundleFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	return files
+}
+
+// mustRead returns the content of the file at path
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestDiagnosticsBundleSources(t *testing.T) {
+	module, outside := t.TempDir(), t.TempDir()
+	writeTree(t, module, map[string]string{
+		"go.mod":          "module example.com/app\n",
+		"main.go":         "package main\n\nconst apiKey = \"s3cr3t\"\n\n// token \"kept in comments\"\nvar greeting = \"hello\"\n",
+		"store/db.go":     "package store\n",
+		"store/schema.go": "package store\n" + strings.Repeat("// padding\n", maxBundleFileBytes/10),
+	})
+	writeTree(t, outside, map[string]string{"lib.go": "package lib\n"})
+
+	info := ErrorInfo{
+		Error: errors.New("config missing"),
+		ID:    "01TEST",
+		File:  filepath.Join(module, "main.go"),
+		Line:  3,
+		Stack: []StackFrame{
+			{File: filepath.Join(module, "store", "db.go"), Line: 1},
+			{File: filepath.Join(module, "store", "schema.go"), Line: 1}, // Over the per-file cap
+			{File: filepath.Join(outside, "lib.go"), Line: 1},            // Outside the main module
+			{File: filepath.Join(module, "main.go"), Line: 5},            // Already copied
+		},
+	}
+	config := testConfig()
+	config.DiagnosticsDir = t.TempDir()
+	dir, err := writeDiagnostics(info, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.Join(bundleFiles(t, dir), " ")
+	if want := "report.json sources/main.go sources/store/db.go"; got != want {
+		t.Errorf("bundle files = %s, want %s", got, want)
+	}
+	if err := ValidateReport(mustRead(t, filepath.Join(dir, "report.json"))); err != nil {
+		t.Errorf("report.json: %v", err)
+	}
+	main := string(mustRead(t, filepath.Join(dir, "sources", "main.go")))
+	for _, want := range []string{`const apiKey = "[REDACTED]"`, `// token "kept in comments"`, `var greeting = "hello"`} {
+		if !strings.Contains(main, want) {
+			t.Errorf("bundled main.go lacks %q:\n%s", want, main)
+		}
+	}
+	if strings.Contains(main, "s3cr3t") {
+		t.Errorf("secret copied into the bundle:\n%s", main)
+	}
+}
+
+func TestDiagnosticsBundleTotalCap(t *testing.T) {
+	module := t.TempDir()
+	files := map[string]string{"go.mod": "module example.com/app\n"}
+	chunk := "package big\n" + strings.Repeat("// padding\n", (maxBundleFileBytes-20)/11)
+	info := ErrorInfo{Error: errors.New("config missing"), ID: "01TEST", File: filepath.Join(module, "main.go"), Line: 1}
+	files["main.go"] = "package main\n"
+	for i := 0; i < 2*maxBundleSourceBytes/len(chunk); i++ {
+		name := fmt.Sprintf("big/f%02d.go", i)
+		files[name] = chunk
+		info.Stack = append(info.Stack, StackFrame{File: filepath.Join(module, name), Line: 1})
+	}
+	writeTree(t, module, files)
+
+	config := testConfig()
+	config.DiagnosticsDir = t.TempDir()
+	dir, err := writeDiagnostics(info, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total, copied := 0, 0
+	for _, file := range bundleFiles(t, dir) {
+		if strings.HasPrefix(file, "sources/") {
+			total += len(mustRead(t, filepath.Join(dir, file)))
+			copied++
+		}
+	}
+	if total > maxBundleSourceBytes || copied < 2 || copied == len(info.Stack)+1 {
+		t.Errorf("copied %d of %d files, %d bytes; cap %d", copied, len(info.Stack)+1, total, maxBundleSourceBytes)
+	}
+}
+
+func TestDiagnosticsBundleLowMemory(t *testing.T) {
+	module := t.TempDir()
+	writeTree(t, module, map[string]string{"go.mod": "module example.com/app\n", "main.go": "package main\n"})
+	config := testConfig()
+	config.DiagnosticsDir = t.TempDir()
+	config.LowMemory = true
+	dir, err := writeDiagnostics(ErrorInfo{Error: errors.New("config missing"), ID: "01TEST", File: filepath.Join(module, "main.go"), Line: 1}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := bundleFiles(t, dir); len(got) != 1 || got[0] != "report.json" {
+		t.Errorf("LowMemory bundle = %v, want report.json alone", got)
+	}
+}
+
+func TestFatalWritesDiagnostics(t *testing.T) {
+	stubExit(t)
+	config := testConfig()
+	config.DiagnosticsDir = t.TempDir()
+	rec := recordCatch(t, config)
+
+	Fatal(errors.New("config missing"))
+	info := rec.reports()[0]
+	dir, ok := info.Context["diagnostics"].(string)
+	if !ok {
+		t.Fatalf("context lacks the bundle directory: %v", info.Context)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sources", "diagnostics_test.go")); err != nil {
+		t.Errorf("reporting test file not bundled: %v", err)
+	}
+
+	Err(errors.New("cache miss"))
+	if _, ok := rec.reports()[1].Context["diagnostics"]; ok {
+		t.Error("bundle written for a non-fatal error")
+	}
+}
@@ -0,0 +1,88 @@
+package catch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// dryRunConfig is testConfig exiting with code 3 on errors, under DryRunExit
+func dryRunConfig() ErrorConfig {
+	config := testConfig()
+	config.ShowSourceCode = false
+	config.ExitOnError = true
+	config.ExitCode = 3
+	config.DryRunExit = true
+	return config
+}
+
+func TestDryRunExitMarksInsteadOfExiting(t *testing.T) {
+	codes := stubExit(t)
+	out := testCatch(t, dryRunConfig())
+	before := Summary().WouldExit
+
+	Err(errors.New("connection refused"))
+	Fatal(errors.New("config missing"))
+	Err(errors.New("cache miss"), ExitNow)
+	if len(*codes) != 0 {
+		t.Fatalf("exited with %v under DryRunExit", *codes)
+	}
+	if n := strings.Count(out.String(), "(would exit with code 3)"); n != 3 {
+		t.Errorf("marker shown %d times, want 3:\n%s", n, out)
+	}
+	if got := Summary().WouldExit - before; got != 3 {
+		t.Errorf("Summary().WouldExit rose by %d, want 3", got)
+	}
+}
+
+func TestDryRunExitLeavesWarningsAlone(t *testing.T) {
+	codes := stubExit(t)
+	out := testCatch(t, dryRunConfig())
+	before := Summary().WouldExit
+
+	Warn(errors.New("disk 80% full"))
+	if len(*codes) != 0 || strings.Contains(out.String(), "would exit") {
+		t.Errorf("warning marked or exited (%v):\n%s", *codes, out)
+	}
+	if got := Summary().WouldExit - before; got != 0 {
+		t.Errorf("Summary().WouldExit rose by %d, want 0", got)
+	}
+}
+
+func TestDryRunExitInJSON(t *testing.T) {
+	stubExit(t)
+	config := dryRunConfig()
+	config.Format = FormatJSON
+	out := testCatch(t, config)
+
+	Err(errors.New("connection refused"))
+	if !strings.Contains(out.String(), `"would_exit":3`) {
+		t.Errorf("JSON report lacks would_exit:\n%s", out)
+	}
+}
+
+func TestDryRunExitWithErrorBudget(t *testing.T) {
+	codes := stubExit(t)
+	config := dryRunConfig()
+	config.ErrorBudget = 1
+	config.ExitOnError = false
+	c, path := summaryCatcher(t, config)
+
+	c.Err(errors.New("cache miss"))
+	c.Err(errors.New("cache miss"))
+	c.Fatal(errors.New("config missing"))
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(*codes) != 0 {
+		t.Fatalf("exited with %v under DryRunExit", *codes)
+	}
+
+	summary := readSummary(t, path)
+	if summary.ErrorCount != 3 || summary.WouldExit != 1 {
+		t.Errorf("errors, would exit = %d, %d; want 3, 1", summary.ErrorCount, summary.WouldExit)
+	}
+	if summary.Status != "budget_exceeded" {
+		t.Errorf("status = %s, want budget_exceeded", summary.Status)
+	}
+}
@@ -0,0 +1,165 @@
+package catch
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultEnrichmentBudget is a suitable EnrichmentBudget for programs whose
+// probes may stall, such as on network file systems
+const DefaultEnrichmentBudget = 50 * time.Millisecond
+
+// Enricher is an extra enrichment step run after the built-in ones. It may
+// change any field of info, such as its context, code, suggestion,
+// details or headline, and should return early once ctx is done. Slices
+// in info are shared with the report: replace them rather than changing
+// their elements.
+type Enricher func(ctx context.Context, info *ErrorInfo)
+
+// enrichStep is one enrichment, in the order they run
+type enrichStep func(ctx context.Context, info *ErrorInfo, config ErrorConfig)
+
+// builtinEnrichments are the enrichment steps run for every report
+var builtinEnrichments = []enrichStep{
+	func(_ context.Context, info *ErrorInfo, _ ErrorConfig) { enrichRootCause(info) },
+	func(_ context.Context, info *ErrorInfo, _ ErrorConfig) { enrichFS(info) },
+	func(_ context.Context, info *ErrorInfo, _ ErrorConfig) { enrichSyscall(info) },
+	func(_ context.Context, info *ErrorInfo, _ ErrorConfig) { enrichClockSkew(info) },
+	func(_ context.Context, info *ErrorInfo, _ ErrorConfig) { enrichTemplate(info) },
+	func(_ context.Context, info *ErrorInfo, _ ErrorConfig) { enrichRetry(info) },
+	enrichNilDeref,
+	func(_ context.Context, info *ErrorInfo, config ErrorConfig) { enrichMissingPath(info, config.prober()) },
+	func(_ context.Context, info *ErrorInfo, config ErrorConfig) { enrichFileLimit(info, config.prober()) },
+}
+
+// prepare completes an ErrorInfo before it is rendered: it fills derived
+// fields and runs the enrichment steps that add context and refine the
+// suggestion
+func (e *ErrorCatcher) prepare(info *ErrorInfo, config ErrorConfig) {
+	if info.Context == nil {
+		info.Context = make(map[string]interface{})
+	}
+	applyScopes(info)
+	if info.GroupKey == "" {
+		info.GroupKey = groupKey(*info)
+	}
+	if info.ID == "" {
+		info.ID = newErrorID()
+	}
+	if info.Time.IsZero() {
+		info.Time = now()
+	}
+	if config.ShowStackTrace && info.OriginStack == nil {
+		info.OriginStack = originStack(info.Error, config)
+	}
+	if config.ParseMessageFields && info.Headline == "" {
+		if fields, rest, ok := parseMessageFields(safeFormat("%v", info.Error)); ok {
+			for k, v := range fields {
+				setContext(info, k, v)
+			}
+			info.Headline = rest
+		}
+	}
+
+	if config.ShowVerboseError && info.Details == "" {
+		info.Details = verboseDetails(info.Error)
+	}
+	enrichJoined(info)
+	enrichCauses(info, config)
+
+	runEnrichments(info, config)
+	if config.LowMemory {
+		lowMemoryContext(info)
+	}
+}
+
+// setContext adds a context entry unless explicit context already has it
+func setContext(info *ErrorInfo, key string, value interface{}) {
+	if _, exists := info.Context[key]; !exists {
+		info.Context[key] = value
+	}
+}
+
+// budgetNote is recorded when enrichment steps were abandoned
+const budgetNote = "some enrichments skipped (budget exceeded)"
+
+// runEnrichments runs the built-in steps and config.Enrichers. With an
+// EnrichmentBudget they run on a copy of info in another goroutine; steps
+// unfinished when the budget runs out are abandoned and their results
+// discarded, so a slow probe cannot stall error handling. Rendering is
+// not part of the budget.
+func runEnrichments(info *ErrorInfo, config ErrorConfig) {
+	steps := append([]enrichStep(nil), builtinEnrichments...)
+	for _, fn := range config.EnrichFuncs {
+		steps = append(steps, func(_ context.Context, info *ErrorInfo, config ErrorConfig) {
+			defer func() { recover() }()
+			fn(info, config.prober())
+		})
+	}
+	for _, enricher := range config.Enrichers {
+		steps = append(steps, func(ctx context.Context, info *ErrorInfo, _ ErrorConfig) {
+			defer func() { recover() }() // A failing enricher costs only its own result
+			enricher(ctx, info)
+		})
+	}
+
+	if config.EnrichmentBudget <= 0 {
+		for _, step := range steps {
+			step(context.Background(), info, config)
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.EnrichmentBudget)
+	defer cancel()
+
+	// Each finished step publishes a snapshot; the newest one wins
+	snapshots := make(chan ErrorInfo, len(steps))
+	go func(work ErrorInfo) {
+		defer close(snapshots)
+		for _, step := range steps {
+			if ctx.Err() != nil {
+				return
+			}
+			step(ctx, &work, config)
+			snapshots <- cloneEnrichable(work)
+		}
+	}(cloneEnrichable(*info))
+
+	finished := 0
+	var latest *ErrorInfo
+wait:
+	for finished < len(steps) {
+		select {
+		case snapshot, ok := <-snapshots:
+			if !ok {
+				break wait // Stopped by the deadline between steps
+			}
+			latest = &snapshot
+			finished++
+		case <-ctx.Done():
+			break wait
+		}
+	}
+
+	if latest != nil {
+		*info = *latest
+	}
+	if finished < len(steps) {
+		setContext(info, "enrichment", budgetNote)
+	}
+}
+
+// cloneEnrichable copies info deeply enough for enrichment steps: its
+// maps, which steps add to, are copied; its slices are shared
+func cloneEnrichable(info ErrorInfo) ErrorInfo {
+	info.Context = copyFields(info.Context)
+	if info.Provenance != nil {
+		provenance := make(map[string]string, len(info.Provenance))
+		for k, v := range info.Provenance {
+			provenance[k] = v
+		}
+		info.Provenance = provenance
+	}
+	return info
+}
@@ -0,0 +1,77 @@
+package catch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEnrichersRunWithoutBudgetByDefault(t *testing.T) {
+	config := DefaultConfig
+	var hasDeadline bool
+	config.Enrichers = []Enricher{func(ctx context.Context, info *ErrorInfo) {
+		_, hasDeadline = ctx.Deadline()
+		info.Context["tenant"] = "acme"
+	}}
+	info := ErrorInfo{Error: errors.New("boom"), Context: map[string]interface{}{}}
+	runEnrichments(&info, config)
+	if hasDeadline || info.Context["tenant"] != "acme" {
+		t.Errorf("deadline %t, context %v", hasDeadline, info.Context)
+	}
+}
+
+func TestEnrichmentBudgetAbandonsSlowSteps(t *testing.T) {
+	config := testConfig()
+	config.EnrichmentBudget = 20 * time.Millisecond
+	release := make(chan struct{})
+	defer close(release)
+	config.Enrichers = []Enricher{
+		func(_ context.Context, info *ErrorInfo) { info.Context["fast"] = true },
+		func(_ context.Context, info *ErrorInfo) {
+			<-release
+			info.Context["slow"] = true
+		},
+	}
+	info := ErrorInfo{Error: errors.New("boom"), Context: map[string]interface{}{}}
+	start := time.Now()
+	runEnrichments(&info, config)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("enrichment took %s", elapsed)
+	}
+	if info.Context["fast"] != true || info.Context["slow"] != nil || info.Context["enrichment"] != budgetNote {
+		t.Errorf("context %v", info.Context)
+	}
+}
+
+func TestPanickingEnricherCostsOnlyItsResult(t *testing.T) {
+	config := testConfig()
+	config.Enrichers = []Enricher{
+		func(context.Context, *ErrorInfo) { panic("broken") },
+		func(_ context.Context, info *ErrorInfo) { info.Context["after"] = true },
+	}
+	info := ErrorInfo{Error: errors.New("boom"), Context: map[string]interface{}{}}
+	runEnrichments(&info, config)
+	if info.Context["after"] != true {
+		t.Errorf("context %v", info.Context)
+	}
+}
+
+func TestBudgetedEnrichersChangeAnyField(t *testing.T) {
+	config := testConfig()
+	config.EnrichmentBudget = time.Second
+	config.Enrichers = []Enricher{func(_ context.Context, info *ErrorInfo) {
+		info.ErrorCode = "DB042"
+		info.Details = "replica lag 12s"
+		info.Headline = "query failed"
+		info.Suggestion = "retry on the primary"
+		info.Causes = append([]string(nil), "timeout")
+		info.Context["replica"] = "db-2"
+	}}
+	info := ErrorInfo{Error: errors.New("boom"), ErrorCode: "GEN000", Context: map[string]interface{}{}}
+	runEnrichments(&info, config)
+	if info.ErrorCode != "DB042" || info.Details != "replica lag 12s" || info.Headline != "query failed" ||
+		info.Suggestion != "retry on the primary" || len(info.Causes) != 1 || info.Context["replica"] != "db-2" {
+		t.Errorf("enricher changes lost: %+v", info)
+	}
+}
@@ -0,0 +1,129 @@
+package catch
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// lineOf returns the line it is called from
+func lineOf() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+// The test is compiled with the default optimizations, so the entry points
+// and the helpers beneath them may be inlined; the reporting frame must
+// still be the caller's
+func TestEntryPointsReportCaller(t *testing.T) {
+	stubExit(t)
+	boom := errors.New("boom")
+	scoped := Catch.WithContext("request_id", "r-1")
+	tests := []struct {
+		name string
+		line int // 0 when the call spans lines
+		call func()
+	}{
+		{"Err", lineOf(), func() { Err(boom) }},
+		{"Warn", lineOf(), func() { Warn(boom) }},
+		{"Fatal", lineOf(), func() { Fatal(boom) }},
+		{"Errf", lineOf(), func() { Errf(boom, "loading %s", "config") }},
+		{"E", lineOf(), func() { E(boom) }},
+		{"F", lineOf(), func() { F(boom, "loading %s", "config") }},
+		{"Set", lineOf(), func() { scoped.Set(boom) }},
+		{"Assert", lineOf(), func() { Assert(false, "items must not be empty") }},
+		{"Check", lineOf(), func() { Check(boom) }},
+		{"ErrCheck", lineOf(), func() { ErrCheck(boom) }},
+		{"Try", 0, func() {
+			var err error
+			defer Try()(&err)
+			err = boom
+		}},
+		{"Try panic", 0, func() {
+			var err error
+			defer Try()(&err)
+			panic(boom)
+		}},
+		{"Try1", 0, func() {
+			Try1(func() (int, error) { return 0, boom })
+		}},
+		{"Recover", 0, func() {
+			defer Recover()(nil)
+			panic(boom)
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := recordCatch(t, testConfig())
+			tc.call()
+			reports := rec.reports()
+			if len(reports) != 1 {
+				t.Fatalf("got %d reports, want 1", len(reports))
+			}
+			info := reports[0]
+			if filepath.Base(info.File) != "entrypoints_test.go" || !strings.Contains(info.Function, "TestEntryPointsReportCaller") {
+				t.Errorf("reported at %s:%d in %s, want this test", info.File, info.Line, info.Function)
+			}
+			if tc.line != 0 && info.Line != tc.line {
+				t.Errorf("reported at line %d, want %d", info.Line, tc.line)
+			}
+		})
+	}
+}
+
+// The methods of a catcher made by New report the same frame as the
+// package functions delegating to Catch
+func TestCatcherMethodsReportCaller(t *testing.T) {
+	stubExit(t)
+	boom := errors.New("boom")
+	tests := []struct {
+		name string
+		line int // 0 when the call spans lines
+		call func(c *ErrorCatcher)
+	}{
+		{"Err", lineOf(), func(c *ErrorCatcher) { c.Err(boom) }},
+		{"Errf", lineOf(), func(c *ErrorCatcher) { c.Errf(boom, "loading %s", "config") }},
+		{"E", lineOf(), func(c *ErrorCatcher) { c.E(boom) }},
+		{"F", lineOf(), func(c *ErrorCatcher) { c.F(boom, "loading %s", "config") }},
+		{"Check", lineOf(), func(c *ErrorCatcher) { c.Check(boom) }},
+		{"Try", 0, func(c *ErrorCatcher) {
+			var err error
+			defer c.Try()(&err)
+			err = boom
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := &recorder{}
+			config := testConfig()
+			config.Handler = rec
+			tc.call(New(config))
+			reports := rec.reports()
+			if len(reports) != 1 {
+				t.Fatalf("got %d reports, want 1", len(reports))
+			}
+			info := reports[0]
+			if filepath.Base(info.File) != "entrypoints_test.go" || !strings.Contains(info.Function, "TestCatcherMethodsReportCaller") {
+				t.Errorf("reported at %s:%d in %s, want this test", info.File, info.Line, info.Function)
+			}
+			if tc.line != 0 && info.Line != tc.line {
+				t.Errorf("reported at line %d, want %d", info.Line, tc.line)
+			}
+		})
+	}
+}
+
+func TestMustNamesCaller(t *testing.T) {
+	panicked := func(fn func()) (msg string) {
+		defer func() { msg, _ = recover().(string) }()
+		fn()
+		return ""
+	}
+	line, msg := lineOf(), panicked(func() { Must(0, errors.New("boom")) })
+	if want := "Must failed in entrypoints_test.go:" + strconv.Itoa(line); !strings.HasPrefix(msg, want) {
+		t.Errorf("panic = %q, want it to start with %q", msg, want)
+	}
+}
@@ -0,0 +1,73 @@
+//go:build !plan9
+
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// errnoNames covers the errno values that exist on every platform Go's
+// syscall package supports; others are shown by number only
+var errnoNames = map[syscall.Errno]string{
+	syscall.EACCES:       "EACCES",
+	syscall.EAGAIN:       "EAGAIN",
+	syscall.EBADF:        "EBADF",
+	syscall.ECONNREFUSED: "ECONNREFUSED",
+	syscall.ECONNRESET:   "ECONNRESET",
+	syscall.EEXIST:       "EEXIST",
+	syscall.EINTR:        "EINTR",
+	syscall.EINVAL:       "EINVAL",
+	syscall.EIO:          "EIO",
+	syscall.EISDIR:       "EISDIR",
+	syscall.EMFILE:       "EMFILE",
+	syscall.ENOENT:       "ENOENT",
+	syscall.ENOSPC:       "ENOSPC",
+	syscall.ENOTDIR:      "ENOTDIR",
+	syscall.EPERM:        "EPERM",
+	syscall.EPIPE:        "EPIPE",
+	syscall.ETIMEDOUT:    "ETIMEDOUT",
+}
+
+// errnoName formats an errno as NAME/number, e.g. EAGAIN/11
+func errnoName(errno syscall.Errno) string {
+	if name, ok := errnoNames[errno]; ok {
+		return fmt.Sprintf("%s/%d", name, uintptr(errno))
+	}
+	return fmt.Sprintf("%d", uintptr(errno))
+}
+
+// classifyErrno picks a code for the errno values whose generic message
+// gives no hint of what to do
+func classifyErrno(err error) (code, suggestion string, ok bool) {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return "", "", false
+	}
+	// EWOULDBLOCK equals EAGAIN on most platforms, so it can't be a case label
+	switch {
+	case errno == syscall.EAGAIN || errno == syscall.EWOULDBLOCK:
+		return "SYS001", "the resource is busy or a non-blocking call would block; retry later or wait for readiness", true
+	case errno == syscall.EPIPE:
+		return "SYS002", "the reader closed the connection; handle SIGPIPE or check for closed writers", true
+	case errno == syscall.ECONNRESET:
+		return "SYS003", "the peer reset the connection; retry with backoff or check the remote service's logs", true
+	case errno == syscall.EINTR:
+		return "SYS004", "the call was interrupted by a signal; retry it or use a restarting wrapper", true
+	}
+	return "", "", false
+}
+
+// enrichSyscall records the failing syscall and errno in context
+func enrichSyscall(info *ErrorInfo) {
+	var sysErr *os.SyscallError
+	if errors.As(info.Error, &sysErr) {
+		setContext(info, "syscall", sysErr.Syscall)
+	}
+	var errno syscall.Errno
+	if errors.As(info.Error, &errno) {
+		setContext(info, "errno", errnoName(errno))
+	}
+}
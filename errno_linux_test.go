@@ -0,0 +1,39 @@
+package catch
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestErrnoNamesLinux(t *testing.T) {
+	for errno, want := range map[syscall.Errno]string{
+		syscall.EAGAIN:     "EAGAIN/11",
+		syscall.EINTR:      "EINTR/4",
+		syscall.EPIPE:      "EPIPE/32",
+		syscall.ECONNRESET: "ECONNRESET/104",
+	} {
+		if got := errnoName(errno); got != want {
+			t.Errorf("errnoName(%d) = %s, want %s", uintptr(errno), got, want)
+		}
+	}
+}
+
+func TestBrokenPipeReport(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	r.Close()
+	_, err = w.Write([]byte("x"))
+	if !errors.Is(err, syscall.EPIPE) {
+		t.Fatalf("write to a closed pipe: %v, want EPIPE", err)
+	}
+
+	info := handled(t, err)
+	if info.ErrorCode != "SYS002" || info.Context["errno"] != "EPIPE/32" {
+		t.Errorf("code %s, errno %v; want SYS002, EPIPE/32", info.ErrorCode, info.Context["errno"])
+	}
+}
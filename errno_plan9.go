@@ -0,0 +1,11 @@
+//go:build plan9
+
+package catch
+
+// classifyErrno is a no-op on plan9, which reports errors as strings
+func classifyErrno(err error) (code, suggestion string, ok bool) {
+	return "", "", false
+}
+
+// enrichSyscall is a no-op on plan9
+func enrichSyscall(info *ErrorInfo) {}
@@ -0,0 +1,84 @@
+//go:build !plan9
+
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// handled returns the report Err makes of err
+func handled(t *testing.T, err error) ErrorInfo {
+	t.Helper()
+	rec := recordCatch(t, testConfig())
+	Err(err)
+	reports := rec.reports()
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reports))
+	}
+	return reports[0]
+}
+
+func TestErrnoClassification(t *testing.T) {
+	tests := []struct {
+		errno   syscall.Errno
+		code    string
+		suggest string
+	}{
+		{syscall.EAGAIN, "SYS001", "non-blocking call would block"},
+		{syscall.EWOULDBLOCK, "SYS001", "non-blocking call would block"},
+		{syscall.EPIPE, "SYS002", "the reader closed the connection; handle SIGPIPE or check for closed writers"},
+		{syscall.ECONNRESET, "SYS003", "the peer reset the connection"},
+		{syscall.EINTR, "SYS004", "interrupted by a signal"},
+	}
+	for _, tc := range tests {
+		for _, err := range []error{tc.errno, os.NewSyscallError("write", tc.errno), fmt.Errorf("flushing: %w", os.NewSyscallError("write", tc.errno))} {
+			code, suggestion, ok := classifyErrno(err)
+			if !ok || code != tc.code || !strings.Contains(suggestion, tc.suggest) {
+				t.Errorf("classifyErrno(%v) = %s, %q, %v; want %s", err, code, suggestion, ok, tc.code)
+			}
+		}
+	}
+	if code, _, ok := classifyErrno(syscall.EACCES); ok {
+		t.Errorf("EACCES classified as %s; its generic message is enough", code)
+	}
+	if _, _, ok := classifyErrno(errors.New("write: broken pipe")); ok {
+		t.Error("a plain error classified as an errno")
+	}
+}
+
+func TestErrnoNames(t *testing.T) {
+	for errno, name := range errnoNames {
+		if got, want := errnoName(errno), fmt.Sprintf("%s/%d", name, uintptr(errno)); got != want {
+			t.Errorf("errnoName(%d) = %s, want %s", uintptr(errno), got, want)
+		}
+	}
+	unnamed := syscall.Errno(4095)
+	if got := errnoName(unnamed); got != "4095" {
+		t.Errorf("errnoName(4095) = %s, want the number alone", got)
+	}
+}
+
+func TestSyscallErrorContext(t *testing.T) {
+	info := handled(t, fmt.Errorf("sending: %w", os.NewSyscallError("sendto", syscall.ECONNRESET)))
+	if info.Context["syscall"] != "sendto" || info.Context["errno"] != errnoName(syscall.ECONNRESET) {
+		t.Errorf("context = %v, want syscall sendto and errno %s", info.Context, errnoName(syscall.ECONNRESET))
+	}
+	if info.ErrorCode != "SYS003" {
+		t.Errorf("code = %s, want SYS003", info.ErrorCode)
+	}
+
+	info = handled(t, syscall.EINTR)
+	if _, ok := info.Context["syscall"]; ok || info.Context["errno"] != errnoName(syscall.EINTR) {
+		t.Errorf("bare errno context = %v, want errno alone", info.Context)
+	}
+
+	info = handled(t, errors.New("no errno here"))
+	if _, ok := info.Context["errno"]; ok {
+		t.Errorf("errno added to a plain error: %v", info.Context)
+	}
+}
@@ -0,0 +1,36 @@
+// Basic shows the one-line error checks: Err, E, Check and Catch.Set.
+// Run with: go run ./examples/basic
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"catch"
+)
+
+func main() {
+	config := catch.DefaultConfig
+	config.ExitOnError = false // Keep running so every example is shown
+	config.Handler = catch.ConsoleHandler{Writer: os.Stdout}
+	catch.Catch.Configure(config)
+
+	fmt.Println("=== Err: report and return the error ===")
+	_, err := os.Open("missing.txt")
+	if catch.Err(err) != nil {
+		fmt.Println("continuing after the report")
+	}
+
+	fmt.Println("\n=== E: report only ===")
+	_, err = os.ReadFile("missing.txt")
+	catch.E(err)
+
+	fmt.Println("\n=== Check: report and branch ===")
+	if !catch.Check(os.Remove("missing.txt")) {
+		fmt.Println("nothing to remove")
+	}
+
+	fmt.Println("\n=== nil errors are ignored ===")
+	catch.Err(nil)
+	fmt.Println("no report for nil")
+}
@@ -0,0 +1,78 @@
+// Collector shows a custom Handler gathering the reports of a batch run
+// instead of printing each one, then printing them as one group with the
+// context they share listed once.
+// Run with: go run ./examples/collector
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"catch"
+)
+
+// collector keeps every report it is handed
+type collector struct {
+	mu    sync.Mutex
+	infos []catch.ErrorInfo
+}
+
+func (c *collector) Handle(info catch.ErrorInfo, config catch.ErrorConfig) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.infos = append(c.infos, info)
+	return nil
+}
+
+// reports returns what was collected so far
+func (c *collector) reports() []catch.ErrorInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]catch.ErrorInfo(nil), c.infos...)
+}
+
+type record struct {
+	ID    int
+	Email string
+	Age   int
+}
+
+func main() {
+	var col collector
+	config := catch.DefaultConfig
+	config.ExitOnError = false
+	config.ShowSourceCode = false
+	config.Handler = &col
+	catch.Catch.Configure(config)
+
+	records := []record{
+		{1, "ada@example.com", 36},
+		{2, "", 41},
+		{3, "grace@example.com", -1},
+		{4, "not-an-email", 29},
+	}
+	batch := catch.Catch.WithContext("batch", "users-2024-06").WithContext("source", "import.csv")
+	for _, r := range records {
+		row := batch.WithContext("row", r.ID)
+		switch {
+		case r.Email == "":
+			row.Set(fmt.Errorf("record %d: missing email", r.ID))
+		case r.Age < 0:
+			row.Set(fmt.Errorf("record %d: negative age %d", r.ID, r.Age))
+		case !strings.Contains(r.Email, "@"):
+			row.Set(fmt.Errorf("record %d: invalid email %q", r.ID, r.Email))
+		}
+	}
+
+	infos := col.reports()
+	fmt.Printf("=== %d of %d records rejected ===\n", len(infos), len(records))
+	fmt.Print(catch.RenderGroup(infos, config))
+	fmt.Println("\n=== Shared context ===")
+	common := catch.CommonContext(infos)
+	for _, key := range slices.Sorted(maps.Keys(common)) {
+		fmt.Printf("%s = %v\n", key, common[key])
+	}
+}
@@ -0,0 +1,55 @@
+// Config shows how ErrorConfig changes the report: a minimal layout, a
+// log file, compact field selection and error codes under DryRunExit.
+// Run with: go run ./examples/config
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"catch"
+)
+
+func main() {
+	err := errors.New("cache warmup failed: timeout after 3s")
+
+	fmt.Println("=== Default layout ===")
+	show(catch.DefaultConfig, err)
+
+	fmt.Println("\n=== Minimal layout ===")
+	minimal := catch.DefaultConfig
+	minimal.ShowStackTrace = false
+	minimal.ShowSourceCode = false
+	minimal.ShowSuggestions = false
+	minimal.ShowUptime = false
+	show(minimal, err)
+
+	fmt.Println("\n=== Logging to a file ===")
+	dir, cleanup := catch.TempDir("catch-example-")
+	defer cleanup()
+	logged := minimal
+	logged.LogToFile = filepath.Join(dir, "errors.log")
+	show(logged, err)
+	data, _ := os.ReadFile(logged.LogToFile)
+	fmt.Printf("log file holds %d bytes\n", len(data))
+
+	fmt.Println("\n=== Dry-run exit: fatal errors are marked, not fatal ===")
+	dryRun := minimal
+	dryRun.ExitOnError = true
+	dryRun.DryRunExit = true
+	dryRun.ExitCode = 3
+	show(dryRun, err)
+	fmt.Printf("would have exited %d time(s)\n", catch.Summary().WouldExit)
+}
+
+// show reports err with config, printing to stdout
+func show(config catch.ErrorConfig, err error) {
+	if !config.DryRunExit {
+		config.ExitOnError = false
+	}
+	config.Handler = catch.ConsoleHandler{Writer: os.Stdout}
+	catch.Catch.Configure(config)
+	catch.Err(err)
+}
@@ -0,0 +1,39 @@
+// Context shows attaching key/value context to reports, both per call and
+// for everything reported while a scope is active.
+// Run with: go run ./examples/context
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"catch"
+)
+
+func main() {
+	config := catch.DefaultConfig
+	config.ExitOnError = false
+	config.ShowStackTrace = false
+	config.Handler = catch.ConsoleHandler{Writer: os.Stdout}
+	catch.Catch.Configure(config)
+
+	fmt.Println("=== WithContext on a single report ===")
+	userID := 12345
+	catch.Catch.WithContext("user_id", userID).
+		WithContext("operation", "profile_update").
+		Set(errors.New("profile not found"))
+
+	fmt.Println("\n=== Context values passed to Err ===")
+	filename := "settings.json"
+	_, err := os.Open(filename)
+	catch.Err(err, filename)
+
+	fmt.Println("\n=== Scoped context, inherited by nested reports ===")
+	defer catch.Catch.WithContext("request_id", "req-42").Activate()()
+	handle()
+}
+
+func handle() {
+	catch.Err(errors.New("upstream returned 503"))
+}
@@ -0,0 +1,44 @@
+// HTTP shows HTTPMiddleware reporting a handler panic with request context
+// and answering 500, using an in-process server so it runs offline.
+// Run with: go run ./examples/http
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"catch"
+)
+
+func main() {
+	config := catch.DefaultConfig
+	config.ExitOnError = false
+	config.ShowSourceCode = false
+	config.Handler = catch.ConsoleHandler{Writer: os.Stdout}
+	catch.Catch.Configure(config)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "hello")
+	})
+	mux.HandleFunc("/boom", func(w http.ResponseWriter, r *http.Request) {
+		var user map[string]string
+		user["name"] = "x" // Panics: assignment to entry in nil map
+	})
+
+	server := httptest.NewServer(catch.HTTPMiddleware(mux))
+	defer server.Close()
+
+	for _, path := range []string{"/ok", "/boom"} {
+		resp, err := http.Get(server.URL + path)
+		if catch.Err(err) != nil {
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		fmt.Printf("GET %s -> %d %q\n", path, resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,49 @@
+// Recover shows turning panics into errors with Recover and reporting
+// deferred errors with Try.
+// Run with: go run ./examples/recover
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"catch"
+)
+
+func main() {
+	config := catch.DefaultConfig
+	config.ExitOnError = false
+	config.Handler = catch.ConsoleHandler{Writer: os.Stdout}
+	catch.Catch.Configure(config)
+
+	fmt.Println("=== Recover into an error ===")
+	if err := safeIndex([]int{1, 2, 3}, 5); err != nil {
+		fmt.Printf("recovered: %v\n", err)
+	}
+
+	fmt.Println("\n=== Recover and report ===")
+	reportPanic()
+
+	fmt.Println("\n=== Try reports the named result on return ===")
+	_ = save()
+}
+
+// safeIndex returns the panic from an out-of-range index as an error
+func safeIndex(values []int, i int) (err error) {
+	defer catch.Recover()(&err)
+	_ = values[i]
+	return nil
+}
+
+// reportPanic reports the panic itself, since there is no error to fill
+func reportPanic() {
+	defer catch.Recover()(nil)
+	panic("worker state corrupted")
+}
+
+// save fails; Try reports the failure as it returns
+func save() (err error) {
+	defer catch.Try()(&err)
+	return errors.New("disk quota exceeded")
+}
@@ -0,0 +1,33 @@
+// Summary shows the run summary collected across reports, as written by
+// Close for batch schedulers.
+// Run with: go run ./examples/summary
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"catch"
+)
+
+func main() {
+	config := catch.DefaultConfig
+	config.ExitOnError = false
+	config.ErrorBudget = 2
+	config.Handler = catch.HandlerFunc(func(info catch.ErrorInfo, config catch.ErrorConfig) error {
+		fmt.Printf("reported %s: %v\n", info.ErrorCode, info.Error)
+		return nil
+	})
+	catch.Catch.Configure(config)
+
+	for _, name := range []string{"a.csv", "b.csv", "c.csv"} {
+		_, err := os.Open(name)
+		catch.Err(err)
+	}
+	catch.Err(errors.New("parse row 7: unexpected EOF"))
+
+	summary, _ := json.MarshalIndent(catch.Summary(), "", "  ")
+	fmt.Println(string(summary))
+}
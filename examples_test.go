@@ -0,0 +1,21 @@
+package catch
+
+import (
+	"os/exec"
+	"testing"
+)
+
+// TestExamplesBuild keeps the programs under examples/ compiling
+func TestExamplesBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds every example")
+	}
+	gotool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+	out, err := exec.Command(gotool, "vet", "./examples/...").CombinedOutput()
+	if err != nil {
+		t.Fatalf("go vet ./examples/...: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,116 @@
+// Package except is the former name of package catch, kept so programs
+// written against it keep building. Both the old X-style names and the
+// current names are provided; reports point at the caller, not at this
+// package.
+//
+// Deprecated: import "catch" instead. X, Xf, XMust and XCheck are Err,
+// Errf, ErrMust and ErrCheck there.
+package except
+
+import "catch"
+
+// Types and settings shared with package catch
+type (
+	ErrorCatcher      = catch.ErrorCatcher
+	ErrorConfig       = catch.ErrorConfig
+	ErrorInfo         = catch.ErrorInfo
+	ContextualCatcher = catch.ContextualCatcher
+)
+
+// Catch is the global catcher of package catch.
+//
+// Deprecated: use catch.Catch.
+var Catch = catch.Catch
+
+// DefaultConfig is a copy of catch.DefaultConfig.
+//
+// Deprecated: use catch.DefaultConfig.
+var DefaultConfig = catch.DefaultConfig
+
+// X is catch.Err.
+//
+// Deprecated: use catch.Err.
+func X(err error, context ...interface{}) error { return catch.Err(err, context...) }
+
+// Xf is catch.Errf.
+//
+// Deprecated: use catch.Errf.
+func Xf(err error, format string, args ...interface{}) error {
+	return catch.Errf(err, format, args...)
+}
+
+// XMust is catch.ErrMust.
+//
+// Deprecated: use catch.ErrMust.
+func XMust[T any](val T, err error) T { return catch.ErrMust(val, err) }
+
+// XCheck is catch.ErrCheck.
+//
+// Deprecated: use catch.ErrCheck.
+func XCheck(err error) bool { return catch.ErrCheck(err) }
+
+// Err is catch.Err.
+//
+// Deprecated: use catch.Err.
+func Err(err error, context ...interface{}) error { return catch.Err(err, context...) }
+
+// Errf is catch.Errf.
+//
+// Deprecated: use catch.Errf.
+func Errf(err error, format string, args ...interface{}) error {
+	return catch.Errf(err, format, args...)
+}
+
+// ErrMust is catch.ErrMust.
+//
+// Deprecated: use catch.ErrMust.
+func ErrMust[T any](val T, err error) T { return catch.ErrMust(val, err) }
+
+// ErrCheck is catch.ErrCheck.
+//
+// Deprecated: use catch.ErrCheck.
+func ErrCheck(err error) bool { return catch.ErrCheck(err) }
+
+// E is catch.E.
+//
+// Deprecated: use catch.E.
+func E(err error) { catch.E(err) }
+
+// F is catch.F.
+//
+// Deprecated: use catch.F.
+func F(err error, format string, args ...interface{}) { catch.F(err, format, args...) }
+
+// Must is catch.Must.
+//
+// Deprecated: use catch.Must.
+func Must[T any](val T, err error) T { return catch.Must(val, err) }
+
+// Try is catch.Try.
+//
+// Deprecated: use catch.Try.
+func Try() func(*error) { return catch.Try() }
+
+// Assert is catch.Assert.
+//
+// Deprecated: use catch.Assert.
+func Assert(condition bool, message string, args ...interface{}) {
+	catch.Assert(condition, message, args...)
+}
+
+// Check is catch.Check.
+//
+// Deprecated: use catch.Check.
+func Check(err error) bool { return catch.Check(err) }
+
+// Recover is catch.Recover.
+//
+// Deprecated: use catch.Recover.
+func Recover() func(*error) { return catch.Recover() }
+
+// Wrap is catch.Wrap.
+//
+// Deprecated: use catch.Wrap.
+func Wrap(err error, format string, args ...interface{}) error {
+	return catch.Wrap(err, format, args...)
+}
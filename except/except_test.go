@@ -0,0 +1,144 @@
+package except
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"catch"
+)
+
+// lineOf returns the line it is called from
+func lineOf() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+// recordReports configures the global catcher to keep its reports instead
+// of printing them or exiting
+func recordReports(t *testing.T) func() []ErrorInfo {
+	var mu sync.Mutex
+	var infos []ErrorInfo
+	config := catch.DefaultConfig
+	config.ExitOnError = false
+	config.Handler = catch.HandlerFunc(func(info ErrorInfo, _ ErrorConfig) error {
+		mu.Lock()
+		infos = append(infos, info)
+		mu.Unlock()
+		return nil
+	})
+	Catch.Configure(config)
+	t.Cleanup(func() { Catch.Configure(DefaultConfig) })
+	return func() []ErrorInfo {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]ErrorInfo(nil), infos...)
+	}
+}
+
+func TestShimReportsCaller(t *testing.T) {
+	boom := errors.New("boom")
+	tests := []struct {
+		name string
+		line int // 0 when the call spans lines
+		call func()
+	}{
+		{"X", lineOf(), func() { X(boom) }},
+		{"Err", lineOf(), func() { Err(boom) }},
+		{"Xf", lineOf(), func() { Xf(boom, "loading %s", "config") }},
+		{"Errf", lineOf(), func() { Errf(boom, "loading %s", "config") }},
+		{"XMust", lineOf(), func() { XMust(0, boom) }},
+		{"ErrMust", lineOf(), func() { ErrMust(0, boom) }},
+		{"XCheck", lineOf(), func() { XCheck(boom) }},
+		{"ErrCheck", lineOf(), func() { ErrCheck(boom) }},
+		{"E", lineOf(), func() { E(boom) }},
+		{"F", lineOf(), func() { F(boom, "loading %s", "config") }},
+		{"Assert", lineOf(), func() { Assert(false, "items must not be empty") }},
+		{"Check", lineOf(), func() { Check(boom) }},
+		{"Catch.Err", lineOf(), func() { Catch.Err(boom) }},
+		{"Try", 0, func() {
+			var err error
+			defer Try()(&err)
+			err = boom
+		}},
+		{"Recover", 0, func() {
+			defer Recover()(nil)
+			panic(boom)
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			reports := recordReports(t)
+			tc.call()
+			got := reports()
+			if len(got) != 1 {
+				t.Fatalf("got %d reports, want 1", len(got))
+			}
+			info := got[0]
+			if filepath.Base(info.File) != "except_test.go" || !strings.Contains(info.Function, "TestShimReportsCaller") {
+				t.Errorf("reported at %s:%d in %s, want this test", info.File, info.Line, info.Function)
+			}
+			if tc.line != 0 && info.Line != tc.line {
+				t.Errorf("reported at line %d, want %d", info.Line, tc.line)
+			}
+		})
+	}
+}
+
+func TestShimMatchesCatch(t *testing.T) {
+	reports := recordReports(t)
+	boom := errors.New("boom")
+	report := func(x func(error, ...interface{}) error) { x(boom, "user", 7) }
+	report(X)
+	report(catch.Err)
+
+	got := reports()
+	if len(got) != 2 {
+		t.Fatalf("got %d reports, want 2", len(got))
+	}
+	shim, direct := got[0], got[1]
+	if shim.File != direct.File || shim.Line != direct.Line || shim.ErrorCode != direct.ErrorCode || shim.Context["user"] != direct.Context["user"] {
+		t.Errorf("shim report %s:%d %s %v differs from catch's %s:%d %s %v",
+			shim.File, shim.Line, shim.ErrorCode, shim.Context["user"], direct.File, direct.Line, direct.ErrorCode, direct.Context["user"])
+	}
+}
+
+func TestShimMust(t *testing.T) {
+	panicked := func(fn func()) (msg string) {
+		defer func() { msg, _ = recover().(string) }()
+		fn()
+		return ""
+	}
+	line, msg := lineOf(), panicked(func() { Must(0, errors.New("boom")) })
+	if want := "Must failed in except_test.go:" + strconv.Itoa(line); !strings.HasPrefix(msg, want) {
+		t.Errorf("panic = %q, want it to start with %q", msg, want)
+	}
+	if got := Must(7, nil); got != 7 {
+		t.Errorf("Must(7, nil) = %d", got)
+	}
+}
+
+func TestShimPassThrough(t *testing.T) {
+	reports := recordReports(t)
+	boom := errors.New("boom")
+
+	if err := Wrap(boom, "loading %s", "config"); !errors.Is(err, boom) || err.Error() != "loading config: boom" {
+		t.Errorf("Wrap = %v", err)
+	}
+	if Wrap(nil, "loading") != nil || X(nil) != nil || Xf(nil, "x") != nil || !XCheck(nil) || !Check(nil) {
+		t.Error("nil errors not passed through")
+	}
+	if XMust(7, nil) != 7 || ErrMust("ok", nil) != "ok" {
+		t.Error("values not passed through")
+	}
+	if Catch != catch.Catch {
+		t.Error("Catch is not catch.Catch")
+	}
+	if n := len(reports()); n != 0 {
+		t.Errorf("nil errors produced %d reports", n)
+	}
+}
@@ -0,0 +1,39 @@
+package catch
+
+import "strings"
+
+// parseMessageFields splits a leading run of key=value tokens followed by
+// ": " off an error message, e.g. "op=fetch user=42: connection reset".
+// Messages without exactly that shape are left alone.
+func parseMessageFields(msg string) (map[string]string, string, bool) {
+	idx := strings.Index(msg, ": ")
+	if idx <= 0 {
+		return nil, msg, false
+	}
+
+	fields := make(map[string]string)
+	for _, token := range strings.Split(msg[:idx], " ") {
+		key, value, ok := strings.Cut(token, "=")
+		if !ok || !isFieldKey(key) || value == "" || strings.Contains(value, "=") {
+			return nil, msg, false
+		}
+		fields[key] = value
+	}
+	return fields, msg[idx+2:], true
+}
+
+// isFieldKey reports whether s looks like an identifier-style field name
+func isFieldKey(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '.' || r == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package catch
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMessageFields(t *testing.T) {
+	for _, tc := range []struct {
+		msg    string
+		fields map[string]string
+		rest   string
+		ok     bool
+	}{
+		{"op=fetch user=42: connection reset", map[string]string{"op": "fetch", "user": "42"}, "connection reset", true},
+		{"op=fetch: read tcp: timeout", map[string]string{"op": "fetch"}, "read tcp: timeout", true},
+		{"op=fetch user 42: connection reset", nil, "op=fetch user 42: connection reset", false},
+		{"op=fetch user=: connection reset", nil, "op=fetch user=: connection reset", false},
+		{"query a=b=c: failed", nil, "query a=b=c: failed", false},
+		{"open config.yaml: no such file", nil, "open config.yaml: no such file", false},
+		{"connection reset", nil, "connection reset", false},
+	} {
+		fields, rest, ok := parseMessageFields(tc.msg)
+		if ok != tc.ok || rest != tc.rest || !reflect.DeepEqual(fields, tc.fields) {
+			t.Errorf("parseMessageFields(%q) = %v, %q, %v; want %v, %q, %v",
+				tc.msg, fields, rest, ok, tc.fields, tc.rest, tc.ok)
+		}
+	}
+}
+
+func TestMessageFieldsInReport(t *testing.T) {
+	config := testConfig()
+	config.ParseMessageFields = true
+	config.ShowSourceCode = false
+	out := testCatch(t, config)
+
+	Err(errors.New("op=fetch user=42: connection reset"))
+	report := out.String()
+	if countHeadlines(report, "connection reset") != 1 {
+		t.Errorf("headline not shortened:\n%s", report)
+	}
+	for _, want := range []string{"op: fetch\n", "user: 42\n"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("context lacks %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestMessageFieldsKeepRawMessageInJSON(t *testing.T) {
+	config := testConfig()
+	config.ParseMessageFields = true
+	config.Format = FormatJSON
+	out := testCatch(t, config)
+
+	Err(errors.New("op=fetch user=42: connection reset"))
+	for _, want := range []string{`"message":"op=fetch user=42: connection reset"`, `"headline":"connection reset"`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("JSON report lacks %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestMessageFieldsOffByDefault(t *testing.T) {
+	config := testConfig()
+	config.ShowSourceCode = false
+	out := testCatch(t, config)
+
+	Err(errors.New("op=fetch user=42: connection reset"))
+	if countHeadlines(out.String(), "op=fetch user=42: connection reset") != 1 {
+		t.Errorf("message changed without ParseMessageFields:\n%s", out)
+	}
+}
@@ -0,0 +1,86 @@
+package catch
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// FingerprintFrames is the number of main-module frames, counted from the
+// top of the stack, that make up a StackFingerprint
+const FingerprintFrames = 5
+
+// Normalization rules applied to function names before fingerprinting,
+// in order. Like the GroupKey rules they only change with a major version:
+//  1. generic type arguments are dropped: "Map[...]" becomes "Map"
+//  2. closure indices are dropped: "Run.func2.1" becomes "Run.func"
+var fingerprintRules = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`\[[^\]]*\]`), ""},
+	{regexp.MustCompile(`\.func\d+(\.\d+)*`), ".func"},
+}
+
+// mainModule is the main module's path, empty when build info is missing
+var mainModule = func() string {
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		return bi.Main.Path
+	}
+	return ""
+}()
+
+// stackFingerprint hashes the package paths and normalized function names
+// of the top FingerprintFrames main-module frames. Line numbers and file
+// names are left out, so the fingerprint survives edits that only move
+// code, while a renamed function still changes GroupKey but a different
+// call path changes both. It is "" when no main-module frame is found.
+func stackFingerprint(frames []runtime.Frame) string {
+	var parts []string
+	for _, frame := range frames {
+		if len(parts) == FingerprintFrames {
+			break
+		}
+		pkg, fn := splitFuncName(frame.Function)
+		if !inMainModule(pkg) {
+			continue
+		}
+		for _, rule := range fingerprintRules {
+			fn = rule.pattern.ReplaceAllString(fn, rule.replacement)
+		}
+		parts = append(parts, pkg+" "+fn)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	sum := sha1.Sum([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// splitFuncName splits "example.com/app/db.(*T).Open" into its package
+// path and the function name within the package
+func splitFuncName(name string) (pkg, fn string) {
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return "", name
+	}
+	dot += slash + 1
+	return name[:dot], name[dot+1:]
+}
+
+// inMainModule reports whether pkg belongs to the main module. Without
+// build info every package outside the standard library counts.
+func inMainModule(pkg string) bool {
+	switch {
+	case pkg == "main":
+		return true
+	case mainModule != "":
+		return pkg == mainModule || strings.HasPrefix(pkg, mainModule+"/")
+	}
+	first, _, _ := strings.Cut(pkg, "/")
+	return strings.Contains(first, ".")
+}
@@ -0,0 +1,123 @@
+package catch
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"testing"
+)
+
+// withMainModule sets the main module path for one test
+func withMainModule(t *testing.T, path string) {
+	prev := mainModule
+	mainModule = path
+	t.Cleanup(func() { mainModule = prev })
+}
+
+// frames builds a stack from function name and line pairs
+func frames(fnLines ...interface{}) []runtime.Frame {
+	var out []runtime.Frame
+	for i := 0; i+1 < len(fnLines); i += 2 {
+		out = append(out, runtime.Frame{Function: fnLines[i].(string), File: "x.go", Line: fnLines[i+1].(int)})
+	}
+	return out
+}
+
+func TestStackFingerprintIgnoresLines(t *testing.T) {
+	withMainModule(t, "example.com/app")
+	before := frames("example.com/app/db.(*Store).Get", 41, "example.com/app/api.handle", 88, "net/http.HandlerFunc.ServeHTTP", 2136)
+	after := frames("example.com/app/db.(*Store).Get", 42, "example.com/app/api.handle", 89, "net/http.HandlerFunc.ServeHTTP", 2140)
+	if a, b := stackFingerprint(before), stackFingerprint(after); a == "" || a != b {
+		t.Errorf("fingerprints %q and %q differ after a blank line was added", a, b)
+	}
+}
+
+func TestStackFingerprintDivergesOnCallPath(t *testing.T) {
+	withMainModule(t, "example.com/app")
+	viaAPI := stackFingerprint(frames("example.com/app/db.(*Store).Get", 41, "example.com/app/api.handle", 88))
+	viaJob := stackFingerprint(frames("example.com/app/db.(*Store).Get", 41, "example.com/app/jobs.sync", 17))
+	if viaAPI == viaJob {
+		t.Errorf("different call paths share fingerprint %s", viaAPI)
+	}
+	moved := stackFingerprint(frames("example.com/app/db.(*Store).Get", 41, "example.com/app/web.handle", 88))
+	if moved == viaAPI {
+		t.Errorf("a function in another package shares fingerprint %s", moved)
+	}
+}
+
+func TestStackFingerprintNormalization(t *testing.T) {
+	withMainModule(t, "example.com/app")
+	for _, pair := range [][2]string{
+		{"example.com/app/run.Main.func1", "example.com/app/run.Main.func3.2"},
+		{"example.com/app/cache.(*LRU[...]).Get", "example.com/app/cache.(*LRU[go.shape.string]).Get"},
+		{"example.com/app/cache.Map[int,string]", "example.com/app/cache.Map[string,int]"},
+	} {
+		a, b := stackFingerprint(frames(pair[0], 1)), stackFingerprint(frames(pair[1], 2))
+		if a == "" || a != b {
+			t.Errorf("%s and %s fingerprint %q and %q", pair[0], pair[1], a, b)
+		}
+	}
+}
+
+func TestStackFingerprintFrames(t *testing.T) {
+	withMainModule(t, "example.com/app")
+	// Frames outside the main module are skipped, not counted
+	top := frames("example.com/app/a.f", 1, "runtime.gopanic", 2, "example.com/app/b.g", 3)
+	if stackFingerprint(top) != stackFingerprint(frames("example.com/app/a.f", 9, "example.com/app/b.g", 9)) {
+		t.Error("frames outside the main module changed the fingerprint")
+	}
+
+	// Only the top FingerprintFrames main-module frames count
+	var deep, deeper []interface{}
+	for i := 0; i < FingerprintFrames; i++ {
+		deep = append(deep, "example.com/app/a.f", i)
+	}
+	deeper = append(append(deeper, deep...), "example.com/app/b.extra", 1)
+	if stackFingerprint(frames(deep...)) != stackFingerprint(frames(deeper...)) {
+		t.Errorf("a frame below the top %d changed the fingerprint", FingerprintFrames)
+	}
+
+	if got := stackFingerprint(frames("runtime.main", 1, "net/http.(*conn).serve", 2)); got != "" {
+		t.Errorf("fingerprint without main-module frames = %q, want empty", got)
+	}
+}
+
+func TestSplitFuncName(t *testing.T) {
+	for name, want := range map[string][2]string{
+		"example.com/app/db.(*T).Open": {"example.com/app/db", "(*T).Open"},
+		"main.main.func1":              {"main", "main.func1"},
+		"catch.Err":                    {"catch", "Err"},
+		"noPackage":                    {"", "noPackage"},
+	} {
+		if pkg, fn := splitFuncName(name); pkg != want[0] || fn != want[1] {
+			t.Errorf("splitFuncName(%q) = %q, %q; want %q, %q", name, pkg, fn, want[0], want[1])
+		}
+	}
+}
+
+//go:noinline
+func reportFromFetch(err error) { Err(err) }
+
+//go:noinline
+func reportFromSync(err error) { Err(err) }
+
+func TestStackFingerprintInReports(t *testing.T) {
+	if !inMainModule("catch") {
+		t.Skip("the test binary's package is not in its main module")
+	}
+	rec := recordCatch(t, testConfig())
+	reportFromFetch(errors.New("connection refused"))
+	reportFromFetch(errors.New("connection reset"))
+	reportFromSync(errors.New("connection refused"))
+
+	r := rec.reports()
+	if r[0].StackFingerprint == "" || r[0].StackFingerprint != r[1].StackFingerprint {
+		t.Errorf("same call path fingerprints %q and %q", r[0].StackFingerprint, r[1].StackFingerprint)
+	}
+	if r[2].StackFingerprint == r[0].StackFingerprint {
+		t.Errorf("different call paths share fingerprint %q", r[0].StackFingerprint)
+	}
+	if data, _ := MarshalReport(r[0]); !bytes.Contains(data, []byte(`"stack_fingerprint":"`+r[0].StackFingerprint+`"`)) {
+		t.Errorf("JSON lacks the fingerprint:\n%s", data)
+	}
+}
@@ -0,0 +1,171 @@
+package catch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrFixApplied marks an error returned by Err after the user ran its
+// suggested fix, so the caller can retry the operation
+// Usage: if err := catch.Err(err, fix); errors.Is(err, catch.ErrFixApplied) { retry() }
+var ErrFixApplied = errors.New("suggested fix was run")
+
+const (
+	fixTimeout   = time.Minute // Longest a fix command may run
+	maxFixOutput = 4 << 10     // Output bytes of a fix command shown
+)
+
+// FixCommand is a command that remediates an error. Passed as an Option it
+// becomes the report's help; in Interactive mode the user is offered to
+// run it. It is never run otherwise.
+type FixCommand struct {
+	Name string
+	Args []string
+
+	mu     sync.Mutex
+	result FixResult
+}
+
+// FixResult describes a run of a FixCommand
+type FixResult struct {
+	Ran    bool
+	Output string // Combined stdout and stderr, capped at 4 KiB
+	Err    error  // Why the command failed, if it did
+}
+
+// Fix returns a FixCommand for name and args; the command is executed
+// directly, never through a shell
+// Usage: catch.Err(err, catch.Fix("mkdir", "-p", dir))
+func Fix(name string, args ...string) *FixCommand {
+	return &FixCommand{Name: name, Args: args}
+}
+
+func (f *FixCommand) apply(opts *callOptions) {
+	opts.fix, opts.fixed = f, new(bool)
+}
+
+// String renders the command line, quoting arguments that need it
+func (f *FixCommand) String() string {
+	parts := make([]string, 0, 1+len(f.Args))
+	for _, part := range append([]string{f.Name}, f.Args...) {
+		if part == "" || strings.ContainsAny(part, " \t\n'\"\\$`*?;&|<>()") {
+			part = "'" + strings.ReplaceAll(part, "'", `'\''`) + "'"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Result returns the outcome of the latest run of the command, if it was
+// run. Whether a call's error matches ErrFixApplied depends only on a run
+// offered by that call.
+func (f *FixCommand) Result() FixResult {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.result
+}
+
+// suggestion is the help line for the command
+func (f *FixCommand) suggestion() string {
+	return fmt.Sprintf("run `%s` to fix this", f)
+}
+
+// run executes the command, capturing its output
+func (f *FixCommand) run() FixResult {
+	ctx, cancel := context.WithTimeout(context.Background(), fixTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, f.Name, f.Args...).CombinedOutput()
+	if len(out) > maxFixOutput {
+		out = append(out[:maxFixOutput:maxFixOutput], "…"...)
+	}
+	result := FixResult{Ran: true, Output: string(out), Err: err}
+	f.mu.Lock()
+	f.result = result
+	f.mu.Unlock()
+	return result
+}
+
+// input returns where Interactive prompts read answers, os.Stdin unless
+// Input is set
+func (config ErrorConfig) input() io.Reader {
+	if config.Input == nil {
+		return os.Stdin
+	}
+	return config.Input
+}
+
+// offerFix asks whether to run the suggested fix and runs it only on an
+// explicit "r", returning the outcome. The prompt and the command's output
+// go to the console output, never stdout.
+func offerFix(f *FixCommand, config ErrorConfig) FixResult {
+	w := config.output()
+	fmt.Fprintf(w, "  [r] run suggested fix `%s`, [enter] continue: ", f)
+	if answer := readAnswer(config.input()); answer != "r" {
+		fmt.Fprintln(w, "  fix not run")
+		return FixResult{}
+	}
+
+	result := f.run()
+	if result.Output != "" {
+		for _, line := range strings.Split(strings.TrimRight(result.Output, "\n"), "\n") {
+			fmt.Fprintf(w, "  | %s\n", line)
+		}
+	}
+	if result.Err != nil {
+		fmt.Fprintf(w, "  fix failed: %v\n", result.Err)
+	} else {
+		fmt.Fprintln(w, "  fix succeeded")
+	}
+	return result
+}
+
+// readAnswer reads one line from r a byte at a time, so nothing past the
+// answer is consumed
+func readAnswer(r io.Reader) string {
+	var line []byte
+	buf := make([]byte, 1)
+	for len(line) < 256 {
+		n, err := r.Read(buf)
+		if n == 1 {
+			if buf[0] == '\n' {
+				break
+			}
+			line = append(line, buf[0])
+		}
+		if err != nil {
+			break
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(string(line)))
+}
+
+// fixedError is returned by Err once the suggested fix ran successfully.
+// It reads as the original error and matches both it and ErrFixApplied
+// with errors.Is and errors.As.
+type fixedError struct {
+	err error
+}
+
+func (e *fixedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fixedError) Unwrap() []error {
+	return []error{e.err, ErrFixApplied}
+}
+
+// fixOutcome wraps err when the fix carried by opts was run successfully
+// during this call
+func fixOutcome(err error, opts callOptions) error {
+	if opts.fixed != nil && *opts.fixed {
+		return &fixedError{err: err}
+	}
+	return err
+}
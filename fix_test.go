@@ -0,0 +1,99 @@
+package catch
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// echoFix returns a harmless fix command, skipping the test without echo
+func echoFix(t *testing.T) *FixCommand {
+	t.Helper()
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo not available")
+	}
+	return Fix("echo", "fixed it")
+}
+
+func TestFixShownAsHelp(t *testing.T) {
+	config := testConfig()
+	config.ShowSourceCode = false
+	out := testCatch(t, config)
+
+	err := Err(errors.New("missing dir"), Fix("mkdir", "-p", "/var/lib/app"))
+	if !strings.Contains(out.String(), "run `mkdir -p /var/lib/app` to fix this") {
+		t.Errorf("report lacks the fix as help:\n%s", out)
+	}
+	if errors.Is(err, ErrFixApplied) {
+		t.Error("ErrFixApplied without Interactive")
+	}
+}
+
+func TestFixNotRunWithoutInteractive(t *testing.T) {
+	fix := echoFix(t)
+	config := testConfig()
+	config.ShowSourceCode = false
+	config.Input = strings.NewReader("r\n") // An answer nobody asked for
+	out := testCatch(t, config)
+
+	Err(errors.New("missing dir"), fix)
+	if fix.Result().Ran || strings.Contains(out.String(), "[r] run") {
+		t.Errorf("fix offered or run without Interactive:\n%s", out)
+	}
+}
+
+func TestFixRunOnConfirmation(t *testing.T) {
+	fix := echoFix(t)
+	config := testConfig()
+	config.ShowSourceCode = false
+	config.Interactive = true
+	config.Input = strings.NewReader("r\n")
+	out := testCatch(t, config)
+
+	err := Err(errors.New("missing dir"), fix)
+	if !errors.Is(err, ErrFixApplied) || err.Error() != "missing dir" {
+		t.Errorf("err = %v, want the original error matching ErrFixApplied", err)
+	}
+	if result := fix.Result(); !result.Ran || result.Output != "fixed it\n" {
+		t.Errorf("Result = %+v", result)
+	}
+	if !strings.Contains(out.String(), "  | fixed it\n") || !strings.Contains(out.String(), "fix succeeded") {
+		t.Errorf("output lacks the captured fix output:\n%s", out)
+	}
+}
+
+func TestFixRefused(t *testing.T) {
+	fix := echoFix(t)
+	config := testConfig()
+	config.ShowSourceCode = false
+	config.Interactive = true
+	config.Input = strings.NewReader("\n")
+	out := testCatch(t, config)
+
+	if err := Err(errors.New("missing dir"), fix); errors.Is(err, ErrFixApplied) {
+		t.Error("ErrFixApplied after the fix was refused")
+	}
+	if fix.Result().Ran || !strings.Contains(out.String(), "fix not run") {
+		t.Errorf("fix ran after refusal:\n%s", out)
+	}
+}
+
+func TestFixOutcomeIsPerCall(t *testing.T) {
+	fix := echoFix(t)
+	config := testConfig()
+	config.ShowSourceCode = false
+	config.Interactive = true
+	config.Input = strings.NewReader("r\n\n")
+	testCatch(t, config)
+
+	if err := Err(errors.New("first"), fix); !errors.Is(err, ErrFixApplied) {
+		t.Fatalf("first call: err = %v, want ErrFixApplied", err)
+	}
+	if err := Err(errors.New("second"), fix); errors.Is(err, ErrFixApplied) {
+		t.Error("second call matches ErrFixApplied from the first call's run")
+	}
+	if err := Catch.Set(errors.New("third"), fix); errors.Is(err, ErrFixApplied) {
+		t.Error("Set matches ErrFixApplied with nothing run")
+	}
+}
@@ -0,0 +1,186 @@
+package catch
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// packagePrefix is the function name prefix of this package, e.g. "catch.",
+// and shimPrefix that of the deprecated except package forwarding to it
+var (
+	packagePrefix = reflect.TypeOf(ErrorCatcher{}).PkgPath() + "."
+	shimPrefix    = reflect.TypeOf(ErrorCatcher{}).PkgPath() + "/except."
+)
+
+// callers fills pcs with the calling stack; replaceable to simulate builds
+// where caller information is unavailable
+var callers = runtime.Callers
+
+// helpers holds the functions marked with Helper
+var helpers sync.Map
+
+// Helper marks the calling function as an error-reporting helper: reports
+// made through it point at its caller instead, like testing.T.Helper
+// Usage: func mustLoad(path string) { catch.Helper(); catch.Err(load(path)) }
+func Helper() {
+	var pc [1]uintptr
+	if callers(2, pc[:]) == 0 {
+		return
+	}
+	frame, _ := runtime.CallersFrames(pc[:]).Next()
+	helpers.Store(frame.Function, true)
+}
+
+// Frame is a stack frame selected by Caller or Callers
+type Frame struct {
+	File     string
+	Line     int
+	Function string  // Fully qualified, e.g. "example.com/app/db.Open"
+	PC       uintptr // Program counter of the call
+	EntryPC  uintptr // Entry address of Function
+}
+
+// Caller returns the first frame outside this package, package log,
+// Helper functions and the packages in skipPackages (full import paths),
+// which is the frame reports are attributed to. Logging wrappers pass
+// their own package to find their caller.
+// Usage: f, ok := catch.Caller("example.com/app/mylog")
+func Caller(skipPackages ...string) (Frame, bool) {
+	frames := selectFrames(1, skipPackages)
+	if len(frames) == 0 {
+		return Frame{}, false
+	}
+	return newFrame(frames[0]), true
+}
+
+// Callers returns up to max frames starting at the frame Caller selects
+func Callers(max int, skipPackages ...string) []Frame {
+	frames := selectFrames(max, skipPackages)
+	out := make([]Frame, len(frames))
+	for i, frame := range frames {
+		out[i] = newFrame(frame)
+	}
+	return out
+}
+
+// newFrame converts a runtime frame
+func newFrame(frame runtime.Frame) Frame {
+	return Frame{File: frame.File, Line: frame.Line, Function: frame.Function, PC: frame.PC, EntryPC: frame.Entry}
+}
+
+// reportFrames returns up to max frames starting at the reporting frame
+func reportFrames(max int) []runtime.Frame {
+	return selectFrames(max, nil)
+}
+
+// selectFrames returns up to max frames starting at the first one outside
+// this package, the except shim, package log, Helper functions and the
+// skip packages; runtime frames directly above it, such as the panic
+// machinery under Recover, are skipped too. Frames are walked with
+// CallersFrames, which expands inlined calls; fixed skip counts are
+// avoided because inlining changes how many frames a call occupies.
+func selectFrames(max int, skip []string) []runtime.Frame {
+	pcs := make([]uintptr, max+32)
+	n := callers(2, pcs) // Skip runtime.Callers and selectFrames
+	if n == 0 {
+		return nil
+	}
+
+	var stack []runtime.Frame
+	viaShim := false
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if len(stack) > 0 || !isInternalFrame(frame.Function, frame.File, skip) {
+			if len(stack) == 0 && viaShim {
+				recordNotice(NoticeExceptPackage, frame.File, frame.Line)
+			}
+			stack = append(stack, frame)
+		} else if strings.HasPrefix(frame.Function, shimPrefix) {
+			viaShim = true
+		}
+		if !more || len(stack) >= max {
+			return stack
+		}
+	}
+}
+
+// isInternalFrame reports whether a function sits between the user's code
+// and the reporting machinery. Test files are the user's, even gocatch's
+// own, unless their functions are marked with Helper or their package is
+// skipped explicitly.
+func isInternalFrame(function, file string, skip []string) bool {
+	if _, helper := helpers.Load(function); helper {
+		return true
+	}
+	if len(skip) > 0 {
+		pkg, _ := splitFuncName(function)
+		for _, p := range skip {
+			if pkg == p {
+				return true
+			}
+		}
+	}
+	if strings.HasSuffix(file, "_test.go") {
+		return false
+	}
+	return strings.HasPrefix(function, packagePrefix) ||
+		strings.HasPrefix(function, shimPrefix) ||
+		strings.HasPrefix(function, "log.") ||
+		strings.HasPrefix(function, "runtime.")
+}
+
+// shortFuncName strips the package path from a function name, keeping the
+// package name: "example.com/app/db.Open" becomes "db.Open"
+func shortFuncName(name string) string {
+	if lastSlash := strings.LastIndex(name, "/"); lastSlash >= 0 {
+		return name[lastSlash+1:]
+	}
+	return name
+}
+
+// stackFrames converts runtime frames to StackFrames
+func stackFrames(frames []runtime.Frame) []StackFrame {
+	stack := make([]StackFrame, 0, len(frames))
+	for _, frame := range frames {
+		stack = append(stack, StackFrame{
+			File:     frame.File,
+			Line:     frame.Line,
+			Function: shortFuncName(frame.Function),
+		})
+	}
+	return stack
+}
+
+// locate fills in the reporting location and, when enabled, the stack
+func (info *ErrorInfo) locate(config ErrorConfig) []runtime.Frame {
+	frames := reportFrames(locateDepth(config))
+	info.locateAt(frames, config)
+	return frames
+}
+
+// locateDepth is the number of frames locate captures
+func locateDepth(config ErrorConfig) int {
+	if config.MaxStackDepth < 2 {
+		return 2 // Smart analysis looks at the reporting frame's caller
+	}
+	if config.ShowStackTrace && config.filtersStack() {
+		return config.MaxStackDepth + maxHiddenFrames // Room for the frames filters drop
+	}
+	return config.MaxStackDepth
+}
+
+// locateAt fills in the location and stack from frames captured earlier
+func (info *ErrorInfo) locateAt(frames []runtime.Frame, config ErrorConfig) {
+	if len(frames) > 0 && frames[0].File != "" {
+		info.File = frames[0].File
+		info.Line = frames[0].Line
+		info.Function = shortFuncName(frames[0].Function)
+	}
+	info.StackFingerprint = stackFingerprint(frames)
+	if config.ShowStackTrace && len(frames) > 0 {
+		info.Stack = filterStack(frames, config)
+	}
+}
@@ -0,0 +1,98 @@
+package catch
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// noCallers simulates a build without caller information for one test
+func noCallers(t *testing.T) {
+	prev := callers
+	callers = func(int, []uintptr) int { return 0 }
+	t.Cleanup(func() { callers = prev })
+}
+
+func TestLocationUnavailablePretty(t *testing.T) {
+	noCallers(t)
+	out := testCatch(t, testConfig())
+
+	Err(errors.New("no such file or directory"))
+	report := out.String()
+	if !strings.Contains(report, " --> (location unavailable)\n") {
+		t.Errorf("report lacks the unavailable location:\n%s", report)
+	}
+	for _, bad := range []string{"unknown:0", ":0\n", " | "} {
+		if strings.Contains(report, bad) {
+			t.Errorf("report contains %q:\n%s", bad, report)
+		}
+	}
+}
+
+func TestLocationUnavailableJSON(t *testing.T) {
+	noCallers(t)
+	config := testConfig()
+	config.Format = FormatJSON
+	out := testCatch(t, config)
+
+	Err(errors.New("no such file or directory"))
+	for _, want := range []string{`"file":null`, `"line":null`, `"function":null`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("JSON report lacks %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestCallerUnavailable(t *testing.T) {
+	noCallers(t)
+	if f, ok := Caller(); ok {
+		t.Errorf("Caller = %+v, true; want false", f)
+	}
+	if frames := Callers(4); len(frames) != 0 {
+		t.Errorf("Callers = %v, want none", frames)
+	}
+}
+
+// logOuter and logInner are the two layers of a logging wrapper
+func logOuter(max int) (Frame, []Frame) {
+	Helper()
+	return logInner(max)
+}
+
+func logInner(max int) (Frame, []Frame) {
+	Helper()
+	f, _ := Caller()
+	return f, Callers(max)
+}
+
+func TestCallerThroughHelperLayers(t *testing.T) {
+	_, _, line, _ := runtime.Caller(0)
+	f, frames := logOuter(2)
+	line++
+
+	if f.Line != line || !strings.HasSuffix(f.Function, ".TestCallerThroughHelperLayers") || filepath.Base(f.File) != "frames_test.go" {
+		t.Errorf("Caller = %s:%d %s, want frames_test.go:%d in the test", filepath.Base(f.File), f.Line, f.Function, line)
+	}
+	if f.PC < f.EntryPC || f.EntryPC == 0 {
+		t.Errorf("PC %#x, EntryPC %#x", f.PC, f.EntryPC)
+	}
+	if len(frames) != 2 || frames[0] != f {
+		t.Fatalf("Callers = %+v, want %+v first", frames, f)
+	}
+	if !strings.HasPrefix(frames[1].Function, "testing.") {
+		t.Errorf("second frame = %s, want the test runner", frames[1].Function)
+	}
+}
+
+func TestCallerSkipPackages(t *testing.T) {
+	pkg, _ := splitFuncName(packagePrefix + "x")
+	f, ok := Caller(pkg)
+	if !ok || !strings.HasPrefix(f.Function, "testing.") {
+		t.Errorf("Caller(%q) = %s, want the test runner above this package", pkg, f.Function)
+	}
+	if frames := Callers(1, pkg); len(frames) != 1 || frames[0] != f {
+		t.Errorf("Callers(1, %q) = %+v, want %+v", pkg, frames, f)
+	}
+}
@@ -0,0 +1,103 @@
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+)
+
+// FSError annotates an error from a wrapped fs.FS with the filesystem it
+// came from. It unwraps to the original error, so errors.Is(err,
+// fs.ErrNotExist) and errors.As to *fs.PathError keep working.
+type FSError struct {
+	FS   string // Name given to catch.FS
+	Kind string // "embed", "dir" or the Go type of the filesystem
+	Root string // Root directory for os.DirFS filesystems
+	Err  error
+}
+
+func (e *FSError) Error() string {
+	return fmt.Sprintf("%s: %v", e.FS, e.Err)
+}
+
+func (e *FSError) Unwrap() error {
+	return e.Err
+}
+
+// FS wraps fsys so errors from Open, ReadFile, Stat and ReadDir carry the
+// filesystem name (and the root of an os.DirFS) into reports
+// Usage: assets := catch.FS("assets", embeddedFiles)
+func FS(name string, fsys fs.FS) fs.FS {
+	kind, root := describeFS(fsys)
+	return &namedFS{fsys: fsys, name: name, kind: kind, root: root}
+}
+
+// namedFS is the filesystem returned by FS
+type namedFS struct {
+	fsys fs.FS
+	name string
+	kind string
+	root string
+}
+
+func (n *namedFS) wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &FSError{FS: n.name, Kind: n.kind, Root: n.root, Err: err}
+}
+
+func (n *namedFS) Open(name string) (fs.File, error) {
+	f, err := n.fsys.Open(name)
+	return f, n.wrap(err)
+}
+
+func (n *namedFS) ReadFile(name string) ([]byte, error) {
+	data, err := fs.ReadFile(n.fsys, name)
+	return data, n.wrap(err)
+}
+
+func (n *namedFS) Stat(name string) (fs.FileInfo, error) {
+	info, err := fs.Stat(n.fsys, name)
+	return info, n.wrap(err)
+}
+
+func (n *namedFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(n.fsys, name)
+	return entries, n.wrap(err)
+}
+
+// describeFS recognizes embed.FS and os.DirFS filesystems
+func describeFS(fsys fs.FS) (kind, root string) {
+	t := reflect.TypeOf(fsys)
+	switch t.String() {
+	case "embed.FS", "*embed.FS":
+		return "embed", ""
+	case "os.dirFS":
+		return "dir", reflect.ValueOf(fsys).String()
+	}
+	return t.String(), ""
+}
+
+// enrichFS adds the filesystem name and root of an FSError to the context,
+// and points embed misses at the //go:embed pattern
+func enrichFS(info *ErrorInfo) {
+	var fe *FSError
+	if !errors.As(info.Error, &fe) {
+		return
+	}
+
+	setContext(info, "fs_name", fe.FS)
+	if fe.Root != "" {
+		setContext(info, "fs_root", fe.Root)
+	}
+	var pe *fs.PathError
+	if errors.As(fe.Err, &pe) {
+		setContext(info, "path", pe.Path)
+	}
+
+	if fe.Kind == "embed" && errors.Is(fe.Err, fs.ErrNotExist) {
+		info.Suggestion = "the file is not in the embedded filesystem; check that the //go:embed pattern matches it and that the path has no leading slash"
+	}
+}
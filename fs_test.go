@@ -0,0 +1,86 @@
+package catch
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFSAnnotatesMapFS(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	fsys := FS("templates", fstest.MapFS{"index.html": {Data: []byte("<html>")}})
+
+	if _, err := fs.ReadFile(fsys, "index.html"); err != nil {
+		t.Fatalf("ReadFile of an existing file: %v", err)
+	}
+	_, err := fs.ReadFile(fsys, "missing.html")
+	var fe *FSError
+	if !errors.As(err, &fe) || fe.FS != "templates" || fe.Kind != "fstest.MapFS" {
+		t.Fatalf("err = %#v, want an FSError naming templates", err)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Error("FSError hides fs.ErrNotExist")
+	}
+	var pe *fs.PathError
+	if !errors.As(err, &pe) || pe.Path != "missing.html" {
+		t.Errorf("FSError hides the *fs.PathError: %v", err)
+	}
+
+	Err(err)
+	info := rec.reports()[0]
+	if info.Context["fs_name"] != "templates" || info.Context["path"] != "missing.html" {
+		t.Errorf("context = %v, want fs_name and path", info.Context)
+	}
+	if _, ok := info.Context["fs_root"]; ok {
+		t.Errorf("fs_root set for a MapFS: %v", info.Context)
+	}
+}
+
+func TestFSAnnotatesDirFS(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	root := t.TempDir()
+	fsys := FS("uploads", os.DirFS(root))
+
+	for name, call := range map[string]func() error{
+		"Open":     func() error { _, err := fsys.Open("a.txt"); return err },
+		"Stat":     func() error { _, err := fs.Stat(fsys, "a.txt"); return err },
+		"ReadDir":  func() error { _, err := fs.ReadDir(fsys, "sub"); return err },
+		"ReadFile": func() error { _, err := fs.ReadFile(fsys, "a.txt"); return err },
+	} {
+		var fe *FSError
+		if err := call(); !errors.As(err, &fe) || fe.Kind != "dir" || fe.Root != root {
+			t.Errorf("%s: err = %#v, want an FSError with root %s", name, err, root)
+		}
+	}
+
+	_, err := fs.ReadFile(fsys, "a.txt")
+	Err(err)
+	info := rec.reports()[0]
+	if info.Context["fs_name"] != "uploads" || info.Context["fs_root"] != root {
+		t.Errorf("context = %v, want fs_name and fs_root", info.Context)
+	}
+	if strings.Contains(info.Suggestion, "go:embed") {
+		t.Errorf("embed suggestion for a DirFS: %s", info.Suggestion)
+	}
+}
+
+// noFiles is an empty embedded filesystem
+var noFiles embed.FS
+
+func TestFSEmbedMissSuggestsPattern(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	_, err := fs.ReadFile(FS("static", noFiles), "app.css")
+
+	Err(err)
+	info := rec.reports()[0]
+	if !strings.Contains(info.Suggestion, "//go:embed pattern") {
+		t.Errorf("suggestion = %q, want it to mention the //go:embed pattern", info.Suggestion)
+	}
+	if info.Context["fs_name"] != "static" {
+		t.Errorf("context = %v, want fs_name", info.Context)
+	}
+}
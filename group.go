@@ -0,0 +1,128 @@
+package catch
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// DefaultCommonShare is the share of a group used when CommonShare is zero
+const DefaultCommonShare = 0.9
+
+// CommonContext returns the context values that at least CommonShare of
+// infos carry with the same value, such as the host or shard a burst of
+// failures has in common. Values are compared as they are displayed.
+// Fewer than two reports have nothing in common.
+func CommonContext(infos []ErrorInfo) map[string]interface{} {
+	return commonContext(infos, Catch.getConfig().commonShare())
+}
+
+// commonShare returns CommonShare, or its default when unset or out of range
+func (config ErrorConfig) commonShare() float64 {
+	if config.CommonShare <= 0 || config.CommonShare > 1 {
+		return DefaultCommonShare
+	}
+	return config.CommonShare
+}
+
+// commonContext finds the most frequent value of each key and keeps it
+// when its count reaches share of infos and is a majority
+func commonContext(infos []ErrorInfo, share float64) map[string]interface{} {
+	common := make(map[string]interface{})
+	if len(infos) < 2 {
+		return common
+	}
+	type tally struct {
+		value interface{}
+		count int
+	}
+	counts := make(map[string]map[string]*tally)
+	for _, info := range infos {
+		for k, v := range info.Context {
+			if counts[k] == nil {
+				counts[k] = make(map[string]*tally)
+			}
+			text := formatContextValue(v)
+			if counts[k][text] == nil {
+				counts[k][text] = &tally{value: v}
+			}
+			counts[k][text].count++
+		}
+	}
+	needed := int(math.Ceil(share * float64(len(infos))))
+	for k, values := range counts {
+		var best *tally
+		for _, t := range values {
+			if best == nil || t.count > best.count {
+				best = t
+			}
+		}
+		if best.count >= needed && best.count*2 > len(infos) {
+			common[k] = best.value // A strict majority, so never a tie
+		}
+	}
+	return common
+}
+
+// RenderGroup renders reports grouped by a custom Handler as one block:
+// a header with the context they share, listed once, then a line per
+// report with only the context that varies. A report missing a shared
+// value, or holding another, lists its own.
+func RenderGroup(infos []ErrorInfo, config ErrorConfig) string {
+	if len(infos) == 0 {
+		return ""
+	}
+	common := commonContext(infos, config.commonShare())
+	keys := make([]string, 0, len(common))
+	for k := range common {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%d reports", len(infos)))
+	if len(keys) > 0 {
+		quantifier := "all"
+		for _, k := range keys {
+			if !sharedByAll(infos, k, common[k]) {
+				quantifier = "most of"
+				break
+			}
+		}
+		pairs := make([]string, len(keys))
+		for i, k := range keys {
+			pairs[i] = k + "=" + formatContextValue(common[k])
+		}
+		b.WriteString(fmt.Sprintf(", %s %d share: %s", quantifier, len(infos), strings.Join(pairs, ", ")))
+	}
+	b.WriteString("\n")
+
+	for i, info := range infos {
+		line := fmt.Sprintf("  %d. %s[%s]: %s", i+1, info.Severity, info.ErrorCode, info.headline())
+		if info.HasLocation() {
+			line += " (" + info.location(config) + ")"
+		}
+		for _, k := range contextKeys(info) {
+			v := info.Context[k]
+			if shared, ok := common[k]; ok && formatContextValue(shared) == formatContextValue(v) {
+				continue
+			}
+			line += fmt.Sprintf(" %s=%s", k, formatContextValue(v))
+		}
+		b.WriteString(line + "\n")
+	}
+	return cleanOutput(b.String())
+}
+
+// sharedByAll reports whether every info has value at key
+func sharedByAll(infos []ErrorInfo, key string, value interface{}) bool {
+	want := formatContextValue(value)
+	for _, info := range infos {
+		v, ok := info.Context[key]
+		if !ok || formatContextValue(v) != want {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+package catch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// groupInfos returns reports with context built by ctx for each index
+func groupInfos(n int, ctx func(i int) map[string]interface{}) []ErrorInfo {
+	infos := make([]ErrorInfo, n)
+	for i := range infos {
+		infos[i] = ErrorInfo{
+			Error:     fmt.Errorf("write row %d: connection reset", i+1),
+			ErrorCode: "NET003",
+			Context:   ctx(i),
+		}
+	}
+	return infos
+}
+
+func TestRenderGroupListsSharedContextOnce(t *testing.T) {
+	infos := groupInfos(3, func(i int) map[string]interface{} {
+		return map[string]interface{}{"host": "db-3", "op": "write", "row": i + 1}
+	})
+
+	want := "3 reports, all 3 share: host=db-3, op=write\n" +
+		"  1. error[NET003]: write row 1: connection reset row=1\n" +
+		"  2. error[NET003]: write row 2: connection reset row=2\n" +
+		"  3. error[NET003]: write row 3: connection reset row=3\n"
+	if got := RenderGroup(infos, testConfig()); got != want {
+		t.Errorf("group =\n%s\nwant\n%s", got, want)
+	}
+	common := CommonContext(infos)
+	if len(common) != 2 || common["host"] != "db-3" || common["op"] != "write" {
+		t.Errorf("CommonContext = %v, want host and op", common)
+	}
+}
+
+func TestCommonContextShare(t *testing.T) {
+	infos := groupInfos(10, func(i int) map[string]interface{} {
+		host := "db-3"
+		if i == 9 {
+			host = "db-4"
+		}
+		return map[string]interface{}{"host": host, "shard": i % 2}
+	})
+	config := testConfig()
+
+	if got := RenderGroup(infos, config); !strings.HasPrefix(got, "10 reports, most of 10 share: host=db-3\n") {
+		t.Errorf("header at the default share = %q", strings.SplitN(got, "\n", 2)[0])
+	}
+	if !strings.Contains(RenderGroup(infos, config), "  10. error[NET003]: write row 10: connection reset host=db-4 shard=1\n") {
+		t.Error("the report holding another host does not list its own")
+	}
+	config.CommonShare = 1
+	if got := RenderGroup(infos, config); !strings.HasPrefix(got, "10 reports\n") {
+		t.Errorf("header with CommonShare 1 = %q", strings.SplitN(got, "\n", 2)[0])
+	}
+	if got := CommonContext(infos[:1]); len(got) != 0 {
+		t.Errorf("one report shares %v", got)
+	}
+	if RenderGroup(nil, config) != "" {
+		t.Error("an empty group rendered")
+	}
+}
+
+// Workers stopped by one cancellation are collected by a Handler and
+// rendered as a group whose header names the cause they share
+func TestCollectedCancellationsShareCause(t *testing.T) {
+	config := testConfig()
+	config.EnableSmartAnalysis = false
+	rec := recordCatch(t, config)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	var wg sync.WaitGroup
+	started := make(chan struct{})
+	for i := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			started <- struct{}{}
+			<-ctx.Done()
+			ErrCtx(ctx, ctx.Err(), "worker", i, "queue", "ingest")
+		}()
+	}
+	for range 4 {
+		<-started
+	}
+	cancel(errors.New("shutting down"))
+	wg.Wait()
+
+	infos := rec.reports()
+	if len(infos) != 4 {
+		t.Fatalf("collected %d reports, want 4", len(infos))
+	}
+	group := RenderGroup(infos, config)
+	header := strings.SplitN(group, "\n", 2)[0]
+	for _, want := range []string{"4 reports, all 4 share: ", "cause=shutting down", "context_err=context canceled", "queue=ingest"} {
+		if !strings.Contains(header, want) {
+			t.Errorf("header %q lacks %s", header, want)
+		}
+	}
+	for i := range 4 {
+		if !strings.Contains(group, fmt.Sprintf("worker=%d", i)) {
+			t.Errorf("group lacks worker %d:\n%s", i, group)
+		}
+	}
+}
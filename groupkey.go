@@ -0,0 +1,52 @@
+package catch
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// Normalization rules used by Normalize, applied in order. These are part
+// of the GroupKey contract and only change with a major version:
+//  1. quoted strings ("...", '...', `...`) become <str>
+//  2. words containing a path separator become <path>
+//  3. 0x-prefixed hex and runs of 8+ hex digits (with at least one
+//     decimal digit) become <hex>
+//  4. remaining digit runs become <n>
+var normalizeRules = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile("\"[^\"]*\"|'[^']*'|`[^`]*`"), "<str>"},
+	{regexp.MustCompile(`[^\s:,;()\[\]]*[/\\][^\s:,;()\[\]]*`), "<path>"},
+	{regexp.MustCompile(`\b0[xX][0-9a-fA-F]+\b`), "<hex>"},
+	{regexp.MustCompile(`\b[0-9a-fA-F]{8,}\b`), ""}, // only runs containing a digit
+	{regexp.MustCompile(`[0-9]+`), "<n>"},
+}
+
+// Normalize replaces the volatile parts of an error message (quoted
+// strings, paths, hex identifiers and numbers) with placeholders so that
+// messages describing the same failure compare equal
+func Normalize(msg string) string {
+	for _, rule := range normalizeRules {
+		if rule.replacement == "" {
+			msg = rule.pattern.ReplaceAllStringFunc(msg, func(run string) string {
+				if strings.ContainsAny(run, "0123456789") {
+					return "<hex>"
+				}
+				return run
+			})
+			continue
+		}
+		msg = rule.pattern.ReplaceAllString(msg, rule.replacement)
+	}
+	return msg
+}
+
+// groupKey computes a stable key identifying "the same error" from the
+// error code, the reporting function and the normalized message
+func groupKey(info ErrorInfo) string {
+	sum := sha1.Sum([]byte(info.ErrorCode + "|" + info.Function + "|" + Normalize(describeError(info.Error))))
+	return hex.EncodeToString(sum[:8])
+}
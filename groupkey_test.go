@@ -0,0 +1,70 @@
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{`open /var/data/users-17.db: no such file or directory`, `open <path>: no such file or directory`},
+		{`user "ada" not found`, `user <str> not found`},
+		{`request 4f3a9c2e1b7d failed after 3 retries`, `request <hex> failed after <n> retries`},
+		{`bad pointer 0xc000123abc`, `bad pointer <hex>`},
+		{`deadbeef is not a number`, `deadbeef is not a number`},
+		{`dial tcp 10.0.0.7:5432: connection refused`, `dial tcp <n>.<n>.<n>.<n>:<n>: connection refused`},
+	} {
+		if got := Normalize(tc.in); got != tc.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+// loadUser fails the way a real lookup would, from one function
+func loadUser(id int, path string, reason string) error {
+	return Err(fmt.Errorf("load user %d from %s: %s", id, path, reason))
+}
+
+func TestGroupKeySharedAcrossVolatileParts(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+
+	loadUser(17, "/srv/a/users.db", "permission denied")
+	loadUser(90211, "/home/b/cache/users.db", "permission denied")
+	loadUser(17, "/srv/a/users.db", "no such file or directory")
+
+	reports := rec.reports()
+	if len(reports) != 3 {
+		t.Fatalf("got %d reports, want 3", len(reports))
+	}
+	if reports[0].GroupKey == "" || reports[0].GroupKey != reports[1].GroupKey {
+		t.Errorf("group keys differ for the same failure: %q, %q", reports[0].GroupKey, reports[1].GroupKey)
+	}
+	if reports[0].GroupKey == reports[2].GroupKey {
+		t.Errorf("different failure modes share group key %q", reports[0].GroupKey)
+	}
+}
+
+func TestGroupKeyIgnoresLine(t *testing.T) {
+	a := ErrorInfo{Error: errors.New("timeout after 30s"), ErrorCode: "NET002", Function: "db.Open", Line: 10}
+	b := ErrorInfo{Error: errors.New("timeout after 5s"), ErrorCode: "NET002", Function: "db.Open", Line: 99}
+	if groupKey(a) != groupKey(b) {
+		t.Error("group key depends on the line or the number in the message")
+	}
+	b.Function = "db.Close"
+	if groupKey(a) == groupKey(b) {
+		t.Error("group key ignores the reporting function")
+	}
+}
+
+func TestGroupKeyInJSON(t *testing.T) {
+	config := testConfig()
+	config.Format = FormatJSON
+	out := testCatch(t, config)
+
+	Err(errors.New("connection refused"))
+	if !strings.Contains(out.String(), `"group_key":"`) {
+		t.Errorf("JSON report lacks group_key:\n%s", out)
+	}
+}
@@ -0,0 +1,130 @@
+package catch
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Handler receives every handled error after it has been classified and
+// enriched. Setting ErrorConfig.Handler replaces the console output; the
+// log file and exit behavior are unaffected.
+type Handler interface {
+	Handle(info ErrorInfo, config ErrorConfig) error
+}
+
+// OutputFormat selects how ConsoleHandler writes reports
+type OutputFormat string
+
+const (
+	FormatPretty OutputFormat = "pretty" // multi-line Rust-style report (default)
+	FormatJSON   OutputFormat = "json"   // one ReportV1 object per line, never colored
+)
+
+// HandlerFunc adapts a function to the Handler interface
+type HandlerFunc func(info ErrorInfo, config ErrorConfig) error
+
+// Handle calls f(info, config)
+func (f HandlerFunc) Handle(info ErrorInfo, config ErrorConfig) error {
+	return f(info, config)
+}
+
+// ConsoleHandler is the default Handler: it writes the pretty report built
+// by RenderReport, throttled during bursts
+type ConsoleHandler struct {
+	Writer io.Writer // Defaults to ErrorConfig.Output
+}
+
+// writer returns the destination, defaulting to the configured output
+func (h ConsoleHandler) writer(config ErrorConfig) io.Writer {
+	if h.Writer == nil {
+		return config.output()
+	}
+	return h.Writer
+}
+
+// output returns the console destination: Output when set, otherwise
+// os.Stdout under RouteToStdout and os.Stderr by default. Nothing else in
+// the package writes to os.Stdout.
+func (config ErrorConfig) output() io.Writer {
+	switch {
+	case config.Output != nil:
+		return config.Output
+	case config.RouteToStdout:
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
+// consoleMu serializes console reports so concurrent errors do not
+// interleave
+var consoleMu sync.Mutex
+
+// Handle writes the report for info. Colors are only used when the
+// writer is a terminal.
+func (h ConsoleHandler) Handle(info ErrorInfo, config ErrorConfig) error {
+	consoleMu.Lock()
+	defer consoleMu.Unlock()
+
+	w := h.writer(config)
+	config.UseColors = colorsFor(config, w)
+
+	if config.FlushBefore {
+		if err := startLine(w); err != nil {
+			return err
+		}
+	}
+
+	// JSON lines go to log shippers, which handle bursts themselves
+	if config.Format == FormatJSON {
+		start := info.timing.now()
+		data, err := marshalFitted(info, config.Fields, config.MaxReportBytes)
+		info.timing.add(phaseRender, start)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+
+	level, rollup := consoleThrottle.admit(config, w)
+	if rollup != "" {
+		if _, err := fmt.Fprint(w, rollup); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	switch level {
+	case throttleFull:
+		if config.LowMemory && config.MaxReportBytes == 0 && info.DegradedTo == DegradeNone && !config.FlushBefore {
+			err = writeSections(w, info, config)
+			break
+		}
+		start := info.timing.now()
+		report := RenderReport(info, config)
+		info.timing.add(phaseRender, start)
+		report += renderTiming(info, config)
+		report += renderRawError(info, config)
+		report += renderHint(config)
+		if config.FlushBefore {
+			report += reportSeparator
+		}
+		_, err = fmt.Fprint(w, report)
+	case throttleCompact:
+		_, err = fmt.Fprint(w, renderCompact(info, config)+renderRawError(info, config))
+	case throttleRollup:
+		silenced.record(silencedEntry{time: info.Time, reason: SilencedThrottled, code: info.ErrorCode, file: info.File, line: info.Line, err: info.Error}, config)
+	}
+	if err != nil && info.DegradedTo != DegradeMinimal {
+		// The writer failed mid-report; a bare line may still get through
+		if _, retryErr := fmt.Fprint(w, "\n"+renderMinimal(info)); retryErr == nil {
+			if !info.degraded.raise(DegradeMinimal) {
+				noteDegraded(DegradeMinimal, config) // Called outside the pipeline
+			}
+			return nil
+		}
+	}
+	return err
+}
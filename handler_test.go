@@ -0,0 +1,186 @@
+package catch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// captureStdio runs fn with os.Stdout and os.Stderr swapped for files and
+// returns what was written to each
+func captureStdio(t *testing.T, fn func()) (stdout, stderr string) {
+	t.Helper()
+	dir := t.TempDir()
+	outFile, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errFile, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevOut, prevErr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = outFile, errFile
+	func() {
+		defer func() { os.Stdout, os.Stderr = prevOut, prevErr }()
+		fn()
+	}()
+	outFile.Close()
+	errFile.Close()
+
+	out, _ := os.ReadFile(outFile.Name())
+	errOut, _ := os.ReadFile(errFile.Name())
+	return string(out), string(errOut)
+}
+
+// defaultOutputCatch configures Catch for one test with Output unset, so
+// reports go where the package sends them by default
+func defaultOutputCatch(t *testing.T, config ErrorConfig) {
+	t.Helper()
+	testCatch(t, config)
+	Catch.mu.Lock()
+	Catch.Config.Output = nil
+	Catch.mu.Unlock()
+}
+
+// everyEntryPoint handles an error through each public entry point and
+// the paths that print more than the report: prompts, summaries and
+// panics recovered by Try
+func everyEntryPoint(t *testing.T) {
+	stubExit(t)
+	boom := errors.New("connection refused")
+
+	Err(boom, "host", "db-3")
+	Errf(boom, "dialing %s", "db-3")
+	Warn(boom)
+	Fatal(boom)
+	Catch.Set(boom)
+	Check(boom)
+	E(boom)
+	F(boom, "step %d", 2)
+	ErrCheck(boom)
+	Assert(false, "queue empty")
+	AssertSampled(false, 1, "queue empty")
+	Partial(3, 1, []error{boom})
+	Printf(LevelWarn)("library said %s", "no")
+	StdLogger(LevelError).Print("tls: bad certificate")
+	Todo("v2 manifests")
+	Unreachable("state %d", 7)
+	Err(boom, Fix("true"))
+	func() (err error) {
+		defer Try()(&err)
+		panic("worker crashed")
+	}()
+	func() {
+		defer Recover()(nil)
+		panic(boom)
+	}()
+	Close()
+}
+
+func TestDefaultOutputNeverWritesStdout(t *testing.T) {
+	config := testConfig()
+	config.ShowHints = true
+	config.Interactive = true
+	config.Input = strings.NewReader("\n")
+	config.RunSummaryPath = filepath.Join(t.TempDir(), "summary.json")
+	config.StrictMode = true
+	defaultOutputCatch(t, config)
+
+	stdout, stderr := captureStdio(t, func() { everyEntryPoint(t) })
+	if stdout != "" {
+		t.Errorf("%d bytes written to stdout:\n%s", len(stdout), stdout)
+	}
+	for _, want := range []string{"]: connection refused\n", "[r] run suggested fix", "DEV001", "worker crashed"} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("stderr lacks %q:\n%s", want, stderr)
+		}
+	}
+}
+
+func TestRouteToStdout(t *testing.T) {
+	config := testConfig()
+	config.ShowSourceCode = false
+	config.RouteToStdout = true
+	defaultOutputCatch(t, config)
+
+	stdout, stderr := captureStdio(t, func() { Err(errors.New("connection refused")) })
+	if countHeadlines(stdout, "connection refused") != 1 {
+		t.Errorf("stdout lacks the report:\n%s", stdout)
+	}
+	if stderr != "" {
+		t.Errorf("stderr written under RouteToStdout:\n%s", stderr)
+	}
+}
+
+func TestConcurrentReportsStayIntact(t *testing.T) {
+	var out syncBuffer
+	config := testConfig()
+	config.Output = &out
+	config.ShowSourceCode = false
+	testCatch(t, config)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i%10 == 0 {
+				Catch.Configure(config) // Reconfiguring races with reports
+			}
+			Err(fmt.Errorf("worker %d failed", i), "worker", i)
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 100; i++ {
+		if n := countHeadlines(out.String(), fmt.Sprintf("worker %d failed", i)); n != 1 {
+			t.Errorf("worker %d: %d reports", i, n)
+		}
+	}
+	// Every line of a report follows its own header, never another's
+	current := ""
+	for _, line := range strings.Split(out.String(), "\n") {
+		if _, msg, ok := strings.Cut(line, "]: worker "); ok && line[0] != ' ' {
+			current = strings.TrimSuffix(msg, " failed")
+		} else if v, ok := strings.CutPrefix(strings.TrimSpace(line), "worker: "); ok && v != current {
+			t.Errorf("context of worker %s inside the report of worker %s", v, current)
+		}
+	}
+}
+
+func TestOutputReceivesEveryFormat(t *testing.T) {
+	for _, format := range []OutputFormat{FormatPretty, FormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			testCatch(t, testConfig())
+			var buf bytes.Buffer
+			Catch.Configure(ErrorConfig{Output: &buf, Format: format, ExitOnError: false})
+
+			stdout, stderr := captureStdio(t, func() { Err(errors.New("disk full")) })
+			if !strings.Contains(buf.String(), "disk full") {
+				t.Errorf("Output lacks the report:\n%s", buf.String())
+			}
+			if stdout != "" || stderr != "" {
+				t.Errorf("written past Output: stdout %q, stderr %q", stdout, stderr)
+			}
+		})
+	}
+}
+
+func TestConsoleHandlerWriterOverridesOutput(t *testing.T) {
+	var output, writer bytes.Buffer
+	config := testConfig()
+	config.Output = &output
+	config.Handler = ConsoleHandler{Writer: &writer}
+	testCatch(t, config)
+
+	Err(errors.New("disk full"))
+	if countHeadlines(writer.String(), "disk full") != 1 || output.Len() != 0 {
+		t.Errorf("Writer got %d bytes, Output %d", writer.Len(), output.Len())
+	}
+}
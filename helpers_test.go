@@ -0,0 +1,107 @@
+package catch
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testConfig is DefaultConfig without exits, colors or the one-time hint
+func testConfig() ErrorConfig {
+	config := DefaultConfig
+	config.ExitOnError = false
+	config.UseColors = false
+	config.ShowHints = false
+	return config
+}
+
+// testCatch configures Catch for one test: console reports go to the
+// returned buffer and the previous configuration is restored afterwards
+func testCatch(t testing.TB, config ErrorConfig) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	if config.Output == nil {
+		config.Output = &buf
+	}
+	Catch.mu.RLock()
+	prev, hadConfig := Catch.Config, Catch.hasConfig
+	Catch.mu.RUnlock()
+	Catch.Configure(config)
+	t.Cleanup(func() {
+		Catch.mu.Lock()
+		Catch.Config, Catch.hasConfig = prev, hadConfig
+		Catch.mu.Unlock()
+	})
+	return &buf
+}
+
+// stubExit records exit codes instead of exiting, for one test
+func stubExit(t testing.TB) *[]int {
+	t.Helper()
+	var codes []int
+	prev := exit
+	exit = func(code int) { codes = append(codes, code) }
+	t.Cleanup(func() { exit = prev })
+	return &codes
+}
+
+// countHeadlines counts the reports in out whose headline ends in msg
+func countHeadlines(out, msg string) int {
+	n := 0
+	for _, line := range strings.Split(out, "\n") {
+		if line != "" && line[0] != ' ' && strings.Contains(line, "]: "+msg) {
+			n++
+		}
+	}
+	return n
+}
+
+// syncBuffer is a bytes.Buffer safe for reports written by timers and
+// other goroutines
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// recorder is a Handler keeping every report it is given
+type recorder struct {
+	mu    sync.Mutex
+	infos []ErrorInfo
+}
+
+func (r *recorder) Handle(info ErrorInfo, _ ErrorConfig) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.infos = append(r.infos, info)
+	return nil
+}
+
+// reports returns the reports handled so far
+func (r *recorder) reports() []ErrorInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ErrorInfo(nil), r.infos...)
+}
+
+// recordCatch configures Catch for one test like testCatch, handing
+// reports to the returned recorder instead of the console
+func recordCatch(t testing.TB, config ErrorConfig) *recorder {
+	t.Helper()
+	r := &recorder{}
+	config.Handler = r
+	testCatch(t, config)
+	return r
+}
@@ -0,0 +1,50 @@
+package catch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// resetHint lets the one-time hint show again, for one test
+func resetHint(t *testing.T) {
+	hintShown.Store(false)
+	t.Cleanup(func() { hintShown.Store(false) })
+}
+
+func TestDefaultConfigShowsNoHint(t *testing.T) {
+	resetHint(t)
+	config := DefaultConfig
+	config.ExitOnError = false
+	buf := testCatch(t, config)
+	Catch.Err(errors.New("disk full"))
+	if strings.Contains(buf.String(), defaultHint) {
+		t.Errorf("hint shown by default:\n%s", buf)
+	}
+}
+
+func TestHintShownOnce(t *testing.T) {
+	resetHint(t)
+	config := testConfig()
+	config.ShowHints = true
+	config.HintText = "see the runbook"
+	config.ShowSourceCode = false
+	buf := testCatch(t, config)
+	Catch.Err(errors.New("disk full"))
+	Catch.Err(errors.New("disk full"))
+	if got := strings.Count(buf.String(), "see the runbook"); got != 1 {
+		t.Errorf("hint shown %d times, want 1:\n%s", got, buf)
+	}
+}
+
+func TestCatchersFromNewShowNoHint(t *testing.T) {
+	resetHint(t)
+	var out strings.Builder
+	config := testConfig()
+	config.ShowHints = true
+	config.Output = &out
+	New(config).Err(errors.New("disk full"))
+	if strings.Contains(out.String(), defaultHint) {
+		t.Errorf("library catcher showed the hint:\n%s", out.String())
+	}
+}
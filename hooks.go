@@ -0,0 +1,56 @@
+package catch
+
+import "fmt"
+
+// HookAction is what a hook asks of the rest of error handling. Actions
+// combine, e.g. SkipOutput | SkipExit.
+type HookAction int
+
+const (
+	Continue   HookAction = 0 // Handle the error as usual
+	SkipOutput HookAction = 1 // Write nothing: no handler, logger or log file
+	SkipExit   HookAction = 2 // Do not exit, even for a fatal error
+)
+
+// Hook observes an error after its ErrorInfo is built and enriched but
+// before it is rendered. It may change info, for instance to add context
+// for the report, and returns what should happen next.
+type Hook func(info *ErrorInfo) HookAction
+
+// RegisterHook adds a hook run for every error this catcher handles, after
+// the hooks registered before it. This is the place to count errors or
+// forward them to an error tracking service.
+// Usage: catch.Catch.RegisterHook(func(info *catch.ErrorInfo) catch.HookAction { ... })
+func (e *ErrorCatcher) RegisterHook(hook Hook) {
+	if hook == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.hooks = append(e.hooks, hook)
+}
+
+// runHooks runs the registered hooks in order and combines their actions.
+// A panicking hook is noted as a handler issue and counts as Continue.
+func (e *ErrorCatcher) runHooks(info *ErrorInfo, config ErrorConfig) HookAction {
+	e.mu.RLock()
+	hooks := e.hooks
+	e.mu.RUnlock()
+
+	var action HookAction
+	for i, hook := range hooks {
+		action |= callHook(hook, info, config, i)
+	}
+	return action
+}
+
+// callHook runs one hook, turning a panic into a handler issue
+func callHook(hook Hook, info *ErrorInfo, config ErrorConfig, index int) (action HookAction) {
+	defer func() {
+		if r := recover(); r != nil {
+			noteIssue(info, config, fmt.Sprintf("hook %d", index), fmt.Errorf("panic: %v", r))
+			action = Continue
+		}
+	}()
+	return hook(info)
+}
@@ -0,0 +1,118 @@
+package catch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHooksRunInOrderAndMutate(t *testing.T) {
+	var out strings.Builder
+	config := testConfig()
+	config.Output = &out
+	config.ShowSourceCode = false
+	c := New(config)
+	var order []int
+	c.RegisterHook(func(info *ErrorInfo) HookAction {
+		order = append(order, 1)
+		info.Context["tenant"] = "acme"
+		return Continue
+	})
+	c.RegisterHook(nil)
+	c.RegisterHook(func(info *ErrorInfo) HookAction {
+		order = append(order, 2)
+		if info.Context["tenant"] != "acme" {
+			t.Errorf("second hook saw context %v, want the first hook's change", info.Context)
+		}
+		info.Suggestion = "retry against the replica"
+		return Continue
+	})
+
+	c.Err(errors.New("connection refused"))
+	if len(order) != 2 || order[0] != 1 || order[1] != 2 {
+		t.Errorf("hooks ran in order %v, want [1 2]", order)
+	}
+	report := out.String()
+	for _, want := range []string{"tenant: acme", "retry against the replica"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks the hook's %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestHookSkipOutput(t *testing.T) {
+	var out strings.Builder
+	config := testConfig()
+	config.Output = &out
+	config.LogToFile = filepath.Join(t.TempDir(), "errors.log")
+	c := New(config)
+	seen := 0
+	c.RegisterHook(func(*ErrorInfo) HookAction { return SkipOutput })
+	c.RegisterHook(func(*ErrorInfo) HookAction { seen++; return Continue })
+
+	c.Err(errors.New("connection refused"))
+	if out.Len() != 0 {
+		t.Errorf("vetoed report written:\n%s", out.String())
+	}
+	if _, err := os.Stat(config.LogToFile); !os.IsNotExist(err) {
+		t.Errorf("vetoed report reached the log file (stat: %v)", err)
+	}
+	if seen != 1 {
+		t.Errorf("later hook ran %d times, want 1 despite the veto", seen)
+	}
+}
+
+func TestHookSkipExit(t *testing.T) {
+	exits := stubExit(t)
+	var out strings.Builder
+	config := testConfig()
+	config.Output = &out
+	config.ExitOnError = true
+	c := New(config)
+	c.RegisterHook(func(*ErrorInfo) HookAction { return SkipExit })
+
+	c.Err(errors.New("connection refused"))
+	if len(*exits) != 0 {
+		t.Errorf("exited %v despite SkipExit", *exits)
+	}
+	if countHeadlines(out.String(), "connection refused") != 1 {
+		t.Errorf("SkipExit suppressed the report:\n%s", out.String())
+	}
+}
+
+func TestHookActionsCombine(t *testing.T) {
+	exits := stubExit(t)
+	var out strings.Builder
+	config := testConfig()
+	config.Output = &out
+	config.ExitOnError = true
+	c := New(config)
+	c.RegisterHook(func(*ErrorInfo) HookAction { return SkipOutput })
+	c.RegisterHook(func(*ErrorInfo) HookAction { return SkipExit })
+	c.RegisterHook(func(*ErrorInfo) HookAction { return Continue })
+
+	c.Err(errors.New("connection refused"))
+	if out.Len() != 0 || len(*exits) != 0 {
+		t.Errorf("output %q and exits %v, want neither", out.String(), *exits)
+	}
+}
+
+func TestPanickingHookCountsAsContinue(t *testing.T) {
+	exits := stubExit(t)
+	var out strings.Builder
+	config := testConfig()
+	config.Output = &out
+	config.ExitOnError = true
+	c := New(config)
+	c.RegisterHook(func(*ErrorInfo) HookAction { panic("boom") })
+
+	c.Err(errors.New("connection refused"))
+	if countHeadlines(out.String(), "connection refused") != 1 {
+		t.Errorf("panicking hook suppressed the report:\n%s", out.String())
+	}
+	if len(*exits) != 1 {
+		t.Errorf("exits %v, want the usual exit", *exits)
+	}
+}
@@ -0,0 +1,79 @@
+package catch
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// RequestIDHeader is the header HTTPMiddleware reads the request ID from
+const RequestIDHeader = "X-Request-Id"
+
+// HTTPMiddleware recovers panics in next and reports them through the
+// global catcher; see ErrorCatcher.HTTPMiddleware
+// Usage: http.ListenAndServe(addr, catch.HTTPMiddleware(mux))
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return Catch.HTTPMiddleware(next)
+}
+
+// HTTPMiddlewareFunc is HTTPMiddleware for a handler function
+func HTTPMiddlewareFunc(next http.HandlerFunc) http.Handler {
+	return Catch.HTTPMiddlewareFunc(next)
+}
+
+// HTTPMiddleware recovers a panic in next and reports it with the request
+// method, path, remote address and X-Request-Id as context, then answers
+// 500 with the report ID, or the full JSON report under HTTPDebug, and
+// the request's X-Request-Id echoed so clients can quote it. The
+// report never exits the process, whatever ExitOnError says, so one bad
+// request can't take the server down. http.ErrAbortHandler is re-panicked
+// for net/http to handle as usual.
+func (e *ErrorCatcher) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			e.reportHTTPPanic(w, r, panicError(recovered))
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// HTTPMiddlewareFunc is HTTPMiddleware for a handler function
+func (e *ErrorCatcher) HTTPMiddlewareFunc(next http.HandlerFunc) http.Handler {
+	return e.HTTPMiddleware(next)
+}
+
+// reportHTTPPanic reports err for the request r and writes the 500
+func (e *ErrorCatcher) reportHTTPPanic(w http.ResponseWriter, r *http.Request, err error) {
+	info := e.buildErrorInfo(err)
+	info.Context["method"] = r.Method
+	info.Context["path"] = r.URL.Path
+	info.Context["remote_addr"] = r.RemoteAddr
+	if id := r.Header.Get(RequestIDHeader); id != "" {
+		info.Context["request_id"] = id
+		w.Header().Set(RequestIDHeader, id)
+	}
+	var report ErrorInfo
+	info.opts.noExit = true
+	info.opts.report = &report
+	e.handleError(info)
+
+	if e.getConfig().HTTPDebug && report.Error != nil {
+		if data, err := MarshalReport(report); err == nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write(append(data, '\n'))
+			return
+		}
+	}
+	body := http.StatusText(http.StatusInternalServerError)
+	if report.ID != "" {
+		body = fmt.Sprintf("%s (error %s)", body, report.ID)
+	}
+	http.Error(w, body, http.StatusInternalServerError)
+}
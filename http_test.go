@@ -0,0 +1,115 @@
+package catch
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// panicky answers /ok and panics on every other path
+var panicky = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/ok" {
+		w.Write([]byte("ok"))
+		return
+	}
+	panic("nil map write")
+})
+
+// serve runs one request through c's middleware around panicky
+func serve(c *ErrorCatcher, path, requestID string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	if requestID != "" {
+		r.Header.Set(RequestIDHeader, requestID)
+	}
+	w := httptest.NewRecorder()
+	c.HTTPMiddleware(panicky).ServeHTTP(w, r)
+	return w
+}
+
+func TestHTTPMiddlewareRecoversWithoutExit(t *testing.T) {
+	exits := stubExit(t)
+	rec := &recorder{}
+	config := testConfig()
+	config.ExitOnError = true
+	config.Handler = rec
+	c := New(config)
+
+	w := serve(c, "/users/7", "req-42")
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status %d, want 500", w.Code)
+	}
+	if len(*exits) != 0 {
+		t.Errorf("exited %v under ExitOnError", *exits)
+	}
+	reports := rec.reports()
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reports))
+	}
+	info := reports[0]
+	if info.Error.Error() != "panic: nil map write" {
+		t.Errorf("reported %v", info.Error)
+	}
+	for key, want := range map[string]interface{}{"method": "GET", "path": "/users/7", "remote_addr": "192.0.2.1:1234", "request_id": "req-42"} {
+		if info.Context[key] != want {
+			t.Errorf("context %s = %v, want %v", key, info.Context[key], want)
+		}
+	}
+	if body := w.Body.String(); body != "Internal Server Error (error "+info.ID+")\n" {
+		t.Errorf("body %q, want the report ID", body)
+	}
+
+	if w := serve(c, "/ok", ""); w.Code != http.StatusOK || len(rec.reports()) != 1 {
+		t.Errorf("healthy request answered %d with %d reports", w.Code, len(rec.reports()))
+	}
+}
+
+func TestHTTPMiddlewareEchoesRequestID(t *testing.T) {
+	config := testConfig()
+	config.Handler = &recorder{}
+	c := New(config)
+
+	if got := serve(c, "/boom", "req-42").Header().Get(RequestIDHeader); got != "req-42" {
+		t.Errorf("%s = %q, want the request's", RequestIDHeader, got)
+	}
+	if got := serve(c, "/boom", "").Header().Get(RequestIDHeader); got != "" {
+		t.Errorf("%s = %q without one in the request", RequestIDHeader, got)
+	}
+}
+
+func TestHTTPDebugAnswersJSONReport(t *testing.T) {
+	config := testConfig()
+	config.Handler = &recorder{}
+	config.HTTPDebug = true
+	c := New(config)
+
+	w := serve(c, "/boom", "req-42")
+	if w.Code != http.StatusInternalServerError || w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("status %d, content type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if err := ValidateReport(w.Body.Bytes()); err != nil {
+		t.Fatalf("%v\n%s", err, w.Body)
+	}
+	var report ReportV1
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Message != "panic: nil map write" || !strings.Contains(w.Body.String(), `"request_id":"req-42"`) {
+		t.Errorf("report body %s", w.Body)
+	}
+}
+
+func TestHTTPMiddlewareRepanicsAbort(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", r)
+		}
+		if len(rec.reports()) != 0 {
+			t.Errorf("abort reported: %v", rec.reports())
+		}
+	}()
+	HTTPMiddlewareFunc(func(http.ResponseWriter, *http.Request) { panic(http.ErrAbortHandler) }).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,101 @@
+package catch
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// reportSeparator ends each full console report under FlushBefore
+var reportSeparator = strings.Repeat("-", 40) + "\n"
+
+// StdoutWriter proxies application output to stdout and remembers whether
+// the last byte written was a newline, so reports can start at column 0
+type StdoutWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	midLine bool
+	writes  uint64 // Count of non-empty writes, for LiveRegion
+}
+
+// activeStdout is the wrapper returned by WrapStdout, if any
+var activeStdout atomic.Pointer[StdoutWriter]
+
+// WrapStdout returns a writer for application output that FlushBefore
+// consults; the same wrapper is returned on every call
+// Usage: out := catch.WrapStdout(); fmt.Fprint(out, "processing...")
+func WrapStdout() *StdoutWriter {
+	if w := activeStdout.Load(); w != nil {
+		return w
+	}
+	activeStdout.CompareAndSwap(nil, &StdoutWriter{w: os.Stdout})
+	return activeStdout.Load()
+}
+
+// Write writes p to stdout, tracking whether it ended mid-line
+func (s *StdoutWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n, err := s.w.Write(p)
+	if n > 0 {
+		s.midLine = p[n-1] != '\n'
+		s.writes++
+	}
+	return n, err
+}
+
+// MidLine reports whether the last write left the cursor mid-line
+func (s *StdoutWriter) MidLine() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.midLine
+}
+
+// writeCount returns the number of non-empty writes so far
+func (s *StdoutWriter) writeCount() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writes
+}
+
+// lineEnded records that the cursor was moved to column 0 by other output
+func (s *StdoutWriter) lineEnded() {
+	s.mu.Lock()
+	s.midLine = false
+	s.mu.Unlock()
+}
+
+var (
+	sharedOnce   sync.Once
+	sharedStream bool
+)
+
+// SharedStreams reports whether stdout and stderr refer to the same file or
+// terminal, in which case their output interleaves. It is checked once.
+func SharedStreams() bool {
+	sharedOnce.Do(func() {
+		out, err1 := os.Stdout.Stat()
+		errOut, err2 := os.Stderr.Stat()
+		sharedStream = err1 == nil && err2 == nil && os.SameFile(out, errOut)
+	})
+	return sharedStream
+}
+
+// startLine writes a newline to w when the wrapped stdout was left mid-line
+// and w shares its stream
+func startLine(w io.Writer) error {
+	stdout := activeStdout.Load()
+	if stdout == nil || !stdout.MidLine() {
+		return nil
+	}
+	if w != io.Writer(stdout) && !(w == io.Writer(os.Stderr) && SharedStreams()) {
+		return nil
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return err
+	}
+	stdout.lineEnded()
+	return nil
+}
@@ -0,0 +1,136 @@
+package catch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeStdout installs a StdoutWriter over a buffer as the wrapper
+// WrapStdout returns, for the duration of the test
+func fakeStdout(t *testing.T) (*StdoutWriter, *bytes.Buffer) {
+	var buf bytes.Buffer
+	s := &StdoutWriter{w: &buf}
+	prev := activeStdout.Swap(s)
+	t.Cleanup(func() { activeStdout.Store(prev) })
+	return s, &buf
+}
+
+// stripReportBody keeps only the header of each report, with the
+// surrounding application output and separators
+func stripReportBody(s string) string {
+	var b strings.Builder
+	inReport := false
+	for _, line := range strings.SplitAfter(s, "\n") {
+		switch {
+		case strings.HasPrefix(line, "error["):
+			inReport = true
+			b.WriteString(line)
+		case line == reportSeparator:
+			inReport = false
+			b.WriteString(line)
+		case !inReport:
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+func flushConfig(out *StdoutWriter) ErrorConfig {
+	config := testConfig()
+	config.ShowSourceCode = false
+	config.ShowStackTrace = false
+	config.ShowUptime = false
+	config.FlushBefore = true
+	config.Output = out
+	return config
+}
+
+func TestWrapStdoutTracksMidLine(t *testing.T) {
+	s, buf := fakeStdout(t)
+	if WrapStdout() != s || WrapStdout() != s {
+		t.Fatal("WrapStdout did not return the active wrapper")
+	}
+	for _, tc := range []struct {
+		write string
+		mid   bool
+	}{
+		{"", false},
+		{"processing", true},
+		{"...", true},
+		{" done\n", false},
+		{"", false},
+		{"a\nb", true},
+	} {
+		fmt.Fprint(s, tc.write)
+		if s.MidLine() != tc.mid {
+			t.Errorf("after %q MidLine = %v, want %v", tc.write, s.MidLine(), tc.mid)
+		}
+	}
+	if buf.String() != "processing... done\na\nb" {
+		t.Errorf("wrapper passed through %q", buf)
+	}
+}
+
+func TestFlushBeforeInterleavesReports(t *testing.T) {
+	s, buf := fakeStdout(t)
+	testCatch(t, flushConfig(s))
+
+	fmt.Fprint(s, "copying 3/10")
+	Err(errors.New("disk full"))
+	fmt.Fprint(s, "copying 4/10\n")
+	Err(errors.New("disk full again"))
+
+	want := "copying 3/10\n" +
+		"error[GEN000]: disk full\n" + reportSeparator +
+		"copying 4/10\n" +
+		"error[GEN000]: disk full again\n" + reportSeparator
+	if got := stripReportBody(buf.String()); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFlushBeforeOff(t *testing.T) {
+	s, buf := fakeStdout(t)
+	config := flushConfig(s)
+	config.FlushBefore = false
+	testCatch(t, config)
+
+	fmt.Fprint(s, "copying 3/10")
+	Err(errors.New("disk full"))
+	if !strings.HasPrefix(buf.String(), "copying 3/10error[GEN000]: disk full\n") || strings.Contains(buf.String(), reportSeparator) {
+		t.Errorf("output changed without FlushBefore:\n%s", buf)
+	}
+}
+
+func TestFlushBeforeOtherStream(t *testing.T) {
+	s, _ := fakeStdout(t)
+	config := flushConfig(s)
+	config.Output = nil
+	out := testCatch(t, config) // Reports go to a buffer not shared with stdout
+
+	fmt.Fprint(s, "copying 3/10")
+	Err(errors.New("disk full"))
+	if !strings.HasPrefix(out.String(), "error[GEN000]: disk full\n") {
+		t.Errorf("newline written to a separate stream:\n%q", out)
+	}
+	if !s.MidLine() {
+		t.Error("stdout marked as ended by a report on another stream")
+	}
+}
+
+func TestFlushBeforeJSON(t *testing.T) {
+	s, buf := fakeStdout(t)
+	config := flushConfig(s)
+	config.Format = FormatJSON
+	testCatch(t, config)
+
+	fmt.Fprint(s, "copying 3/10")
+	Err(errors.New("disk full"))
+	got := buf.String()
+	if !strings.HasPrefix(got, "copying 3/10\n{") || strings.Contains(got, reportSeparator) {
+		t.Errorf("JSON report not started on a fresh line, or separated:\n%s", got)
+	}
+}
@@ -0,0 +1,99 @@
+// Package keycache holds per-key state for a bounded number of keys. It
+// backs the sampling and dedup state of a catcher and the rate limits of
+// catchtail.
+package keycache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultLimit is the number of keys a Cache holds when given no limit
+const DefaultLimit = 4096
+
+// Cache holds per-key state, such as the counter of an AssertSampled call
+// site, for at most a bounded number of keys. When full, the least
+// recently used key is evicted silently; if it comes back its state starts
+// over. This keeps memory bounded when errors come from an unbounded
+// number of distinct sites. Its zero value is an empty cache.
+type Cache[K comparable, V any] struct {
+	mu    sync.Mutex
+	items map[K]*list.Element
+	order list.List // Front is the most recently used
+}
+
+// Entry is an element of Cache.order
+type Entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// Get returns the state of key, creating it with create when missing and
+// evicting down to limit keys
+func (c *Cache[K, V]) Get(key K, limit int, create func() V) V {
+	value, _ := c.GetEvicting(key, limit, create)
+	return value
+}
+
+// GetEvicting is Get, also returning the states it evicted, for callers
+// that must finish them
+func (c *Cache[K, V]) GetEvicting(key K, limit int, create func() V) (V, []V) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.order.MoveToFront(elem)
+		return elem.Value.(*Entry[K, V]).value, nil
+	}
+	if c.items == nil {
+		c.items = make(map[K]*list.Element)
+	}
+	var evicted []V
+	for len(c.items) >= limit {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		entry := oldest.Value.(*Entry[K, V])
+		delete(c.items, entry.key)
+		evicted = append(evicted, entry.value)
+	}
+	value := create()
+	c.items[key] = c.order.PushFront(&Entry[K, V]{key: key, value: value})
+	return value, evicted
+}
+
+// Sweep removes the keys for which fn returns true and returns their
+// states
+func (c *Cache[K, V]) Sweep(fn func(K, V) bool) []V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var removed []V
+	for elem := c.order.Front(); elem != nil; {
+		next := elem.Next()
+		if entry := elem.Value.(*Entry[K, V]); fn(entry.key, entry.value) {
+			c.order.Remove(elem)
+			delete(c.items, entry.key)
+			removed = append(removed, entry.value)
+		}
+		elem = next
+	}
+	return removed
+}
+
+// Len returns the number of keys held
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// Each calls fn for every key held, most recently used first
+func (c *Cache[K, V]) Each(fn func(K, V)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for elem := c.order.Front(); elem != nil; elem = elem.Next() {
+		entry := elem.Value.(*Entry[K, V])
+		fn(entry.key, entry.value)
+	}
+}
@@ -0,0 +1,79 @@
+package catch
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// HandlerIssue is a failure inside the error handling machinery itself,
+// such as an unwritable log file or a failing Handler. Issues are noted on
+// the report but never classified, never trigger an exit and are never
+// handled as errors in their own right.
+type HandlerIssue struct {
+	Source string // What failed, e.g. "log file" or "handler"
+	Err    error
+}
+
+func (h HandlerIssue) String() string {
+	return fmt.Sprintf("%s: %v", h.Source, h.Err)
+}
+
+// handlerIssueCount counts issues across all catchers
+var handlerIssueCount atomic.Uint64
+
+// HandlerIssueCount returns how many secondary failures occurred while
+// handling errors since the process started
+func HandlerIssueCount() uint64 {
+	return handlerIssueCount.Load()
+}
+
+// noteIssue records a secondary failure on info and reports it to the
+// OnHandlerIssue hook, which must not itself fail loudly
+func noteIssue(info *ErrorInfo, config ErrorConfig, source string, err error) {
+	issue := HandlerIssue{Source: source, Err: err}
+	info.HandlerIssues = append(info.HandlerIssues, issue)
+	handlerIssueCount.Add(1)
+
+	if config.OnHandlerIssue != nil {
+		func() {
+			defer func() { recover() }()
+			config.OnHandlerIssue(issue)
+		}()
+	}
+}
+
+// callHandler runs a Handler, turning a panic into an error
+func callHandler(h Handler, info ErrorInfo, config ErrorConfig) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if panicErr, ok := r.(error); ok {
+				err = fmt.Errorf("panic: %w", panicErr)
+			} else {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}
+	}()
+	return h.Handle(info, config)
+}
+
+// RenderHandlerIssues renders the dim trailing note about secondary failures
+func RenderHandlerIssues(info ErrorInfo, config ErrorConfig) string {
+	if len(info.HandlerIssues) == 0 {
+		return ""
+	}
+
+	parts := make([]string, len(info.HandlerIssues))
+	for i, issue := range info.HandlerIssues {
+		parts[i] = issue.String()
+	}
+	noun := "output"
+	if len(parts) > 1 {
+		noun = "outputs"
+	}
+	line := fmt.Sprintf("note: %d %s failed (%s)", len(parts), noun, strings.Join(parts, "; "))
+	if config.UseColors {
+		return Gray + line + Reset + "\n"
+	}
+	return line + "\n"
+}
@@ -0,0 +1,100 @@
+package catch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// unwritableLog returns a log path that cannot be created, as its parent
+// is a regular file
+func unwritableLog(t *testing.T) string {
+	parent := filepath.Join(t.TempDir(), "not-a-dir")
+	if err := os.WriteFile(parent, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(parent, "app.log")
+}
+
+func TestFailingSinkNotedOnReport(t *testing.T) {
+	var issues []HandlerIssue
+	config := testConfig()
+	config.ShowSourceCode = false
+	config.LogToFile = unwritableLog(t)
+	config.OnHandlerIssue = func(issue HandlerIssue) { issues = append(issues, issue) }
+	out := testCatch(t, config)
+	before := HandlerIssueCount()
+
+	Err(errors.New("connection refused"))
+	report := out.String()
+	if countHeadlines(report, "connection refused") != 1 {
+		t.Errorf("primary report missing:\n%s", report)
+	}
+	lines := strings.Split(strings.TrimSuffix(report, "\n"), "\n")
+	if last := lines[len(lines)-1]; !strings.HasPrefix(last, "note: 1 output failed (log file: ") {
+		t.Errorf("last line = %q, want the handler issue note", last)
+	}
+	if len(issues) != 1 || issues[0].Source != "log file" {
+		t.Errorf("OnHandlerIssue saw %v, want one log file issue", issues)
+	}
+	if HandlerIssueCount() != before+1 {
+		t.Errorf("HandlerIssueCount rose by %d, want 1", HandlerIssueCount()-before)
+	}
+}
+
+func TestFailingNotifierNotedOnReport(t *testing.T) {
+	var out strings.Builder
+	config := testConfig()
+	config.Output = &out
+	config.ShowSourceCode = false
+	c := New(config)
+	c.RegisterHook(func(*ErrorInfo) HookAction { panic("webhook: context deadline exceeded") })
+	c.RegisterHook(func(info *ErrorInfo) HookAction {
+		info.Context["after_failing_hook"] = true
+		return Continue
+	})
+
+	c.Err(errors.New("connection refused"))
+	report := out.String()
+	if countHeadlines(report, "connection refused") != 1 || !strings.Contains(report, "after_failing_hook: true") {
+		t.Errorf("primary report or later hook missing:\n%s", report)
+	}
+	if !strings.HasSuffix(report, "note: 1 output failed (hook 0: panic: webhook: context deadline exceeded)\n") {
+		t.Errorf("report lacks the notifier note:\n%s", report)
+	}
+}
+
+func TestHandlerIssuesInJSON(t *testing.T) {
+	config := testConfig()
+	config.Format = FormatJSON
+	config.LogToFile = unwritableLog(t)
+	out := testCatch(t, config)
+
+	Err(errors.New("connection refused"))
+	if !strings.Contains(out.String(), `"handler_errors":[{"source":"log file","error":"`) {
+		t.Errorf("JSON report lacks handler_errors:\n%s", out)
+	}
+}
+
+func TestHandlerIssuesDoNotEscalate(t *testing.T) {
+	codes := stubExit(t)
+	config := testConfig()
+	config.Handler = HandlerFunc(func(ErrorInfo, ErrorConfig) error { return errors.New("sink closed") })
+	config.OnHandlerIssue = func(HandlerIssue) { panic("observer failed too") }
+	rec := &recorder{}
+	c := New(config)
+	testCatch(t, ErrorConfig{Handler: rec, MaxStackDepth: 1})
+
+	c.Err(errors.New("connection refused"))
+	if len(*codes) != 0 {
+		t.Errorf("exited with %v over a handler issue", *codes)
+	}
+	if n := len(rec.reports()); n != 0 {
+		t.Errorf("handler issue handled as %d errors of its own", n)
+	}
+	if got := c.Summary().ErrorCount; got != 1 {
+		t.Errorf("summary counts %d errors, want only the primary one", got)
+	}
+}
@@ -0,0 +1,123 @@
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// maxJoinedShown caps the sections rendered for one joined error
+const maxJoinedShown = 20
+
+// JoinedError is one error of an errors.Join group, classified on its own
+type JoinedError struct {
+	Error      error
+	ErrorCode  string
+	Suggestion string
+}
+
+// joinedErrors returns the errors joined in err, or nil unless err itself
+// joins two or more
+func joinedErrors(err error) []error {
+	u, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return nil
+	}
+	var errs []error
+	for _, e := range u.Unwrap() {
+		if e != nil {
+			errs = append(errs, e)
+		}
+	}
+	if len(errs) < 2 {
+		return nil
+	}
+	return errs
+}
+
+// enrichJoined classifies each error of a joined group. The group keeps
+// one location, context and stack; the header counts the errors and each
+// gets its own section with its code and suggestion.
+func enrichJoined(info *ErrorInfo) {
+	if info.Error == nil || info.Headline != "" {
+		return
+	}
+	errs := joinedErrors(info.Error)
+	if errs == nil {
+		return
+	}
+	info.Joined = classifyJoined(errs)
+	info.Headline = fmt.Sprintf("%d errors", len(errs))
+	info.Suggestion = "" // Each section carries its own
+	recordNotice(NoticeJoinedReports, info.File, info.Line)
+}
+
+// classifyJoined classifies each of errs on its own
+func classifyJoined(errs []error) []JoinedError {
+	joined := make([]JoinedError, len(errs))
+	for i, err := range errs {
+		code, suggestion := classify(err)
+		joined[i] = JoinedError{Error: err, ErrorCode: code, Suggestion: suggestion}
+	}
+	return joined
+}
+
+// RenderJoined renders one "error[CODE] i of N" section per joined error,
+// with the causes beneath it and its suggestion
+func RenderJoined(info ErrorInfo, config ErrorConfig) string {
+	if len(info.Joined) == 0 {
+		return ""
+	}
+
+	var output strings.Builder
+	color := info.Severity.color()
+	total := len(info.Joined) + info.joinedOmitted
+	for i, joined := range info.Joined {
+		if i == maxJoinedShown {
+			break
+		}
+		levels := errorChain(joined.Error)
+		if len(levels) == 0 {
+			levels = []string{describeError(joined.Error)}
+		}
+		if config.UseColors {
+			output.WriteString(fmt.Sprintf("  %s%s[%s%s%s] %d of %d:%s %s\n",
+				color, info.Severity, Bold, joined.ErrorCode, Reset+color, i+1, total, Reset, levels[0]))
+		} else {
+			output.WriteString(fmt.Sprintf("  %s[%s] %d of %d: %s\n", info.Severity, joined.ErrorCode, i+1, total, levels[0]))
+		}
+		for _, cause := range levels[1:] {
+			output.WriteString(fmt.Sprintf("    caused by: %s\n", cause))
+		}
+		if config.ShowSuggestions && joined.Suggestion != "" {
+			if config.UseColors {
+				output.WriteString(fmt.Sprintf("    %shelp:%s %s\n", Green+Bold, Reset, joined.Suggestion))
+			} else {
+				output.WriteString(fmt.Sprintf("    help: %s\n", joined.Suggestion))
+			}
+		}
+		output.WriteString("\n")
+	}
+	if shown := min(len(info.Joined), maxJoinedShown); shown < total {
+		output.WriteString(fmt.Sprintf("  … %d more\n\n", total-shown))
+	}
+	return output.String()
+}
+
+// joinedV1 converts the joined errors of a report to their JSON form
+func joinedV1(joined []JoinedError) []JoinedErrorV1 {
+	var out []JoinedErrorV1
+	for _, j := range joined {
+		out = append(out, JoinedErrorV1{Code: j.ErrorCode, Message: safeFormat("%v", j.Error), Suggestion: j.Suggestion})
+	}
+	return out
+}
+
+// joinedFromV1 rebuilds joined errors from their JSON form
+func joinedFromV1(joined []JoinedErrorV1) []JoinedError {
+	var out []JoinedError
+	for _, j := range joined {
+		out = append(out, JoinedError{Error: errors.New(j.Message), ErrorCode: j.Code, Suggestion: j.Suggestion})
+	}
+	return out
+}
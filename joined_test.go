@@ -0,0 +1,74 @@
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJoinedErrorsRenderOneSectionEach(t *testing.T) {
+	exits := stubExit(t)
+	config := testConfig()
+	config.ExitOnError = true
+	config.LogToFile = filepath.Join(t.TempDir(), "errors.log")
+	buf := testCatch(t, config)
+
+	Err(errors.Join(errors.New("connection refused"), fmt.Errorf("writing cache: %w", os.ErrPermission)), "batch", 7)
+
+	out := buf.String()
+	for _, want := range []string{"]: 2 errors\n", "] 1 of 2: connection refused\n", "] 2 of 2: writing cache\n", "    caused by: permission denied\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "stack backtrace:"); n != 1 {
+		t.Errorf("%d stack traces, want one for the group", n)
+	}
+	if n := strings.Count(out, "batch: 7"); n != 1 {
+		t.Errorf("context shown %d times, want once for the group", n)
+	}
+	if len(*exits) != 1 {
+		t.Errorf("exits %v, want one for the group", *exits)
+	}
+	logged, err := os.ReadFile(config.LogToFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(logged), "] 2 of 2: writing cache\n") {
+		t.Errorf("log file lacks the sections:\n%s", logged)
+	}
+}
+
+func TestJoinedErrorsAreClassifiedEach(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	first, second := errors.New("connection refused"), os.ErrPermission
+	Err(errors.Join(first, second))
+
+	joined := rec.reports()[0].Joined
+	if len(joined) != 2 {
+		t.Fatalf("%d joined errors, want 2", len(joined))
+	}
+	for i, err := range []error{first, second} {
+		if code, _ := classify(err); joined[i].ErrorCode != code {
+			t.Errorf("error %d classified %s, want %s", i+1, joined[i].ErrorCode, code)
+		}
+	}
+}
+
+func TestJoinedSectionsAreCapped(t *testing.T) {
+	errs := make([]error, maxJoinedShown+5)
+	for i := range errs {
+		errs[i] = fmt.Errorf("item %d failed", i)
+	}
+	info := ErrorInfo{Joined: classifyJoined(errs), Severity: LevelError}
+	out := RenderJoined(info, testConfig())
+	if n := strings.Count(out, " of 25: "); n != maxJoinedShown {
+		t.Errorf("%d sections, want %d", n, maxJoinedShown)
+	}
+	if !strings.Contains(out, "  … 5 more\n") {
+		t.Errorf("no count of the rest:\n%s", out)
+	}
+}
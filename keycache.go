@@ -0,0 +1,26 @@
+package catch
+
+import "catch/internal/keycache"
+
+// DefaultMaxTrackedKeys is the number of keys a catcher tracks when
+// ErrorConfig.MaxTrackedKeys is zero
+const DefaultMaxTrackedKeys = keycache.DefaultLimit
+
+// trackedKind tells apart the features sharing a catcher's key cache
+type trackedKind uint8
+
+const (
+	trackedSampled trackedKind = iota // AssertSampled call site, value *sampledSite
+	trackedDedup                      // DedupWindow window, value *dedupEntry
+)
+
+// trackedKey is a key of ErrorCatcher.keys: a call site PC for sampling,
+// a dedupKey hash for dedup
+type trackedKey struct {
+	kind trackedKind
+	id   uint64
+}
+
+// trackedKeys is the key cache of a catcher, shared by AssertSampled and
+// DedupWindow so that MaxTrackedKeys bounds them together
+type trackedKeys = keycache.Cache[trackedKey, any]
@@ -0,0 +1,30 @@
+//go:build gocatch_lite
+
+package catch
+
+import "context"
+
+// Builds tagged gocatch_lite leave out go/parser, go/ast and reflection
+// over user structs, for targets such as TinyGo and WebAssembly where they
+// don't work or add megabytes. These stubs keep the API identical; see
+// the feature matrix in the README.
+
+// detectContextFromSource is source analysis, which lite builds omit
+func detectContextFromSource(filename string, errorLine int) map[string]interface{} {
+	return map[string]interface{}{}
+}
+
+// callSpan locates the failing call with the AST, which lite builds omit;
+// the caret falls back to the start of the line
+func callSpan(filename string, line int) exprSpan {
+	return exprSpan{}
+}
+
+// enrichNilDeref is the nil_candidates analysis, which lite builds omit
+func enrichNilDeref(ctx context.Context, info *ErrorInfo, config ErrorConfig) {}
+
+// expandStruct is struct expansion, which lite builds omit; structs passed
+// as context are kept as a single value
+func expandStruct(value interface{}, all bool) (map[string]interface{}, bool) {
+	return nil, false
+}
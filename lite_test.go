@@ -0,0 +1,56 @@
+//go:build gocatch_lite
+
+package catch
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type liteRequest struct {
+	Method string `catch:"method"`
+}
+
+func TestLiteStubsReturnNothing(t *testing.T) {
+	if got := detectContextFromSource("lite_test.go", 20); len(got) != 0 {
+		t.Errorf("detectContextFromSource = %v, want empty", got)
+	}
+	if got := callSpan("lite_test.go", 20); got != (exprSpan{}) {
+		t.Errorf("callSpan = %+v, want zero", got)
+	}
+	if fields, ok := expandStruct(liteRequest{Method: "GET"}, true); ok || fields != nil {
+		t.Errorf("expandStruct = %v, %v, want nothing", fields, ok)
+	}
+	info := ErrorInfo{Error: errors.New("runtime error: invalid memory address or nil pointer dereference"), Context: map[string]interface{}{}}
+	enrichNilDeref(context.Background(), &info, testConfig())
+	if len(info.Context) != 0 {
+		t.Errorf("enrichNilDeref added %v", info.Context)
+	}
+}
+
+func TestLiteReportKeepsTheRest(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+
+	Err(errors.New("dial tcp: connection refused"), liteRequest{Method: "GET"})
+
+	reports := rec.reports()
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reports))
+	}
+	info := reports[0]
+	for _, key := range []string{"function_call", "locals_hint", "assigned_var", "method"} {
+		if v, ok := info.Context[key]; ok {
+			t.Errorf("context has %s = %v, which lite builds leave out", key, v)
+		}
+	}
+	if info.Column != 0 || info.Span != 0 {
+		t.Errorf("caret at column %d width %d, want the line start fallback", info.Column, info.Span)
+	}
+	if info.ErrorCode != "NET001" {
+		t.Errorf("code = %s, want NET001", info.ErrorCode)
+	}
+	if len(info.Stack) == 0 || len(info.SourceLines) == 0 {
+		t.Errorf("%d stack frames and %d source lines, want both kept", len(info.Stack), len(info.SourceLines))
+	}
+}
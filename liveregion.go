@@ -0,0 +1,103 @@
+package catch
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// escapePattern matches the SGR and OSC 8 sequences reports may contain
+var escapePattern = regexp.MustCompile("\033\\[[0-9;]*[A-Za-z]|\033\\]8;[^\033]*\033\\\\")
+
+// LiveRegion is a Handler for tools that loop, such as file watchers and
+// pollers. On a terminal it redraws the latest report in place of the
+// previous one and counts repeats of the same error; on anything else it
+// appends like ConsoleHandler. When the application writes through
+// WrapStdout between two reports, the old report is left in the scrollback
+// and the new one starts below it.
+// Usage: config.Handler = &catch.LiveRegion{}
+type LiveRegion struct {
+	Writer io.Writer // Defaults to ErrorConfig.Output
+
+	mu     sync.Mutex
+	rows   int    // Terminal rows drawn by the previous report
+	key    string // Identity of the previous error
+	count  int    // Consecutive occurrences of key
+	stdout uint64 // WrapStdout write count after the previous report
+}
+
+// Handle redraws the region with the report for info
+func (l *LiveRegion) Handle(info ErrorInfo, config ErrorConfig) error {
+	console := ConsoleHandler{Writer: l.Writer}
+	w := console.writer(config)
+	term := terminalFor(w)
+	if !term.TTY || config.Format == FormatJSON {
+		return console.Handle(info, config)
+	}
+
+	config.UseColors = colorsFor(config, w)
+	consoleMu.Lock()
+	defer consoleMu.Unlock()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if key := liveKey(info); key == l.key {
+		l.count++
+	} else {
+		l.key, l.count = key, 1
+	}
+
+	start := info.timing.now()
+	report := RenderReport(info, config)
+	info.timing.add(phaseRender, start)
+	if l.count > 1 {
+		report += fmt.Sprintf("(occurred %d times, last at %s)\n", l.count, info.Time.Format("15:04:05"))
+	}
+
+	if stdout := activeStdout.Load(); stdout != nil {
+		if n := stdout.writeCount(); n != l.stdout {
+			l.rows, l.stdout = 0, n // The application wrote below the region
+			if err := startLine(w); err != nil {
+				return err
+			}
+		}
+	}
+	var b strings.Builder
+	if l.rows > 0 {
+		fmt.Fprintf(&b, "\033[%dF\033[J", l.rows) // Up to the region's first line, clear below
+	}
+	b.WriteString(report)
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		l.rows = 0
+		return err
+	}
+	l.rows = terminalRows(report, term.Width)
+	return nil
+}
+
+// liveKey identifies repeats of the same error: the code and stack
+// fingerprint, or the code and location without a stack
+func liveKey(info ErrorInfo) string {
+	if info.StackFingerprint != "" {
+		return info.ErrorCode + "|" + info.StackFingerprint
+	}
+	return fmt.Sprintf("%s|%s:%d", info.ErrorCode, info.File, info.Line)
+}
+
+// terminalRows counts the rows text occupies, including lines the
+// terminal wraps at width
+func terminalRows(text string, width int) int {
+	rows := 0
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		n := len([]rune(escapePattern.ReplaceAllString(line, "")))
+		if width > 0 && n > width {
+			rows += (n + width - 1) / width
+		} else {
+			rows++
+		}
+	}
+	return rows
+}
@@ -0,0 +1,105 @@
+package catch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// liveCatch configures Catch for one test with a LiveRegion over the
+// returned buffer, which is a terminal when tty is set
+func liveCatch(t *testing.T, tty bool) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	t.Cleanup(SetTerminalDetectorForTesting(func(w io.Writer) Terminal {
+		return Terminal{TTY: tty && w == io.Writer(&buf), Width: 80}
+	}))
+	config := testConfig()
+	config.ShowSourceCode = false
+	config.Handler = &LiveRegion{Writer: &buf}
+	testCatch(t, config)
+	return &buf
+}
+
+// failTimes reports msg n times from one site
+func failTimes(n int, msg string) {
+	for i := 0; i < n; i++ {
+		Err(errors.New(msg))
+	}
+}
+
+func TestLiveRegionRedrawsInPlace(t *testing.T) {
+	buf := liveCatch(t, true)
+
+	var first string
+	for i := 0; i < 3; i++ {
+		Err(errors.New("poll failed"))
+		if i == 0 {
+			first = buf.String()
+			buf.Reset()
+		}
+	}
+	rows := terminalRows(first, 80)
+	out := buf.String()
+	up := fmt.Sprintf("\033[%dF\033[J", rows)
+	if !strings.HasPrefix(out, up) {
+		t.Fatalf("redraw doesn't start by moving up %d rows: %q", rows, out[:min(len(out), 20)])
+	}
+	if !strings.Contains(out, "(occurred 2 times, last at ") || !strings.Contains(out, "(occurred 3 times, last at ") {
+		t.Errorf("no repeat counter:\n%s", out)
+	}
+	if strings.Contains(first, "occurred") {
+		t.Errorf("counter on the first report:\n%s", first)
+	}
+}
+
+func TestLiveRegionResetsCounterOnNewError(t *testing.T) {
+	buf := liveCatch(t, true)
+	failTimes(2, "poll failed")
+	buf.Reset()
+
+	Err(errors.New("parse failed"))
+	if out := buf.String(); strings.Contains(out, "occurred") || !strings.HasPrefix(out, "\033[") {
+		t.Errorf("new error not drawn fresh in place:\n%q", out)
+	}
+}
+
+func TestLiveRegionAppendsWithoutTerminal(t *testing.T) {
+	buf := liveCatch(t, false)
+	failTimes(2, "poll failed")
+
+	out := buf.String()
+	if strings.Contains(out, "\033[") || strings.Contains(out, "occurred") {
+		t.Errorf("cursor movement or counter off a terminal:\n%q", out)
+	}
+	if countHeadlines(out, "poll failed") != 2 {
+		t.Errorf("reports not appended:\n%s", out)
+	}
+}
+
+func TestLiveRegionKeepsReportAboveStdout(t *testing.T) {
+	buf := liveCatch(t, true)
+	stdout, _ := fakeStdout(t)
+
+	for i := 0; i < 2; i++ {
+		Err(errors.New("poll failed"))
+		if i == 0 {
+			fmt.Fprintln(stdout, "tick")
+			buf.Reset()
+		}
+	}
+
+	if out := buf.String(); strings.Contains(out, "F\033[J") {
+		t.Errorf("redrew over application output:\n%q", out)
+	}
+}
+
+func TestTerminalRowsCountsWrappedLines(t *testing.T) {
+	text := strings.Repeat("x", 100) + "\n\033[31mshort\033[0m\n"
+	if got := terminalRows(text, 40); got != 4 {
+		t.Errorf("terminalRows = %d, want 4: 3 for the long line and 1 for the colored one", got)
+	}
+}
@@ -0,0 +1,190 @@
+//go:build !gocatch_lite
+
+package catch
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxLocalsHint     = 8  // Names listed in locals_hint at most
+	maxLocalsStmtSpan = 10 // Statements spanning more lines are skipped
+)
+
+// builtinIdents are predeclared names that never count as locals
+var builtinIdents = map[string]bool{
+	"append": true, "cap": true, "clear": true, "close": true, "complex": true,
+	"copy": true, "delete": true, "imag": true, "len": true, "make": true,
+	"max": true, "min": true, "new": true, "panic": true, "print": true,
+	"println": true, "real": true, "recover": true, "nil": true, "true": true,
+	"false": true, "iota": true,
+}
+
+// localsHint lists the variables read by the statement that produced the
+// error at errorLine, e.g. "filename, mode, retries"
+func localsHint(fset *token.FileSet, file *ast.File, errorLine int) string {
+	stmt, parents := statementAt(fset, file, errorLine)
+	if stmt == nil {
+		return ""
+	}
+
+	packages := importNames(file)
+	names := readIdents(stmt, packages)
+
+	// The line is usually the handler call itself (catch.Err(err)); the
+	// failing statement is then the one before it, or the enclosing header
+	if len(names) == 0 {
+		if prev := previousStatement(stmt, parents); prev != nil {
+			stmt = prev
+			names = readIdents(stmt, packages)
+		}
+	}
+
+	start, end := fset.Position(stmt.Pos()).Line, fset.Position(stmt.End()).Line
+	if len(names) == 0 || end-start > maxLocalsStmtSpan {
+		return ""
+	}
+	if len(names) > maxLocalsHint {
+		names = names[:maxLocalsHint]
+	}
+	return strings.Join(names, ", ")
+}
+
+// statementAt finds the innermost non-block statement spanning line and
+// returns it with its ancestors, outermost first
+func statementAt(fset *token.FileSet, file *ast.File, line int) (ast.Stmt, []ast.Node) {
+	var found ast.Stmt
+	var foundParents, stack []ast.Node
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return false
+		}
+		if fset.Position(n.Pos()).Line > line || fset.Position(n.End()).Line < line {
+			return false
+		}
+		if stmt, ok := n.(ast.Stmt); ok {
+			if _, isBlock := n.(*ast.BlockStmt); !isBlock {
+				found = stmt
+				foundParents = append([]ast.Node(nil), stack...)
+			}
+		}
+		stack = append(stack, n)
+		return true
+	})
+
+	return found, foundParents
+}
+
+// previousStatement returns the statement preceding stmt in its block, or
+// the header of the enclosing if/range/for/switch when stmt comes first
+func previousStatement(stmt ast.Stmt, parents []ast.Node) ast.Stmt {
+	for i := len(parents) - 1; i >= 0; i-- {
+		switch p := parents[i].(type) {
+		case *ast.BlockStmt:
+			for j, s := range p.List {
+				if s == stmt && j > 0 {
+					return p.List[j-1]
+				}
+			}
+		case *ast.IfStmt, *ast.RangeStmt, *ast.ForStmt, *ast.SwitchStmt:
+			return p.(ast.Stmt)
+		case *ast.FuncDecl, *ast.FuncLit:
+			return nil
+		}
+	}
+	return nil
+}
+
+// readIdents collects identifiers read by a statement, skipping err, _,
+// builtins, package names, assignment targets and nested bodies
+func readIdents(stmt ast.Stmt, packages map[string]bool) []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	var visit func(n ast.Node)
+	visit = func(n ast.Node) {
+		ast.Inspect(n, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.BlockStmt, *ast.FuncLit:
+				return false
+			case *ast.CallExpr:
+				// Called functions aren't locals, but method receivers are
+				if _, isIdent := node.Fun.(*ast.Ident); !isIdent {
+					visit(node.Fun)
+				}
+				for _, arg := range node.Args {
+					visit(arg)
+				}
+				return false
+			case *ast.SelectorExpr:
+				visit(node.X)
+				return false
+			case *ast.KeyValueExpr:
+				visit(node.Value)
+				return false
+			case *ast.Ident:
+				name := node.Name
+				if name == "err" || name == "_" || builtinIdents[name] || packages[name] || seen[name] {
+					return false
+				}
+				seen[name] = true
+				names = append(names, name)
+			}
+			return true
+		})
+	}
+
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		for _, expr := range s.Rhs {
+			visit(expr)
+		}
+		if s.Tok != token.DEFINE && s.Tok != token.ASSIGN {
+			for _, expr := range s.Lhs {
+				visit(expr)
+			}
+		}
+	case *ast.IfStmt:
+		if s.Init != nil {
+			names = append(names, readIdents(s.Init, packages)...)
+			for _, name := range names {
+				seen[name] = true
+			}
+		}
+		visit(s.Cond)
+	case *ast.RangeStmt:
+		visit(s.X)
+	case *ast.ForStmt:
+		if s.Cond != nil {
+			visit(s.Cond)
+		}
+	case *ast.SwitchStmt:
+		if s.Tag != nil {
+			visit(s.Tag)
+		}
+	default:
+		visit(stmt)
+	}
+	return names
+}
+
+// importNames returns the names under which a file refers to its imports
+func importNames(file *ast.File) map[string]bool {
+	names := make(map[string]bool)
+	for _, imp := range file.Imports {
+		if imp.Name != nil {
+			names[imp.Name.Name] = true
+			continue
+		}
+		if p, err := strconv.Unquote(imp.Path.Value); err == nil {
+			names[path.Base(p)] = true
+		}
+	}
+	return names
+}
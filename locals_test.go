@@ -0,0 +1,99 @@
+//go:build !gocatch_lite
+
+package catch
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+// localsFixture is parsed by the locals tests; the line of each case is
+// found by the marker comment ending it
+const localsFixture = `package fixture
+
+import (
+	"os"
+	"strconv"
+)
+
+func assign(filename string, mode os.FileMode, retries int) error {
+	f, err := os.OpenFile(filename, os.O_RDONLY, mode+os.FileMode(retries)) // assign
+	_ = f
+	return err
+}
+
+func ifInit(raw string, base int) {
+	if n, err := strconv.ParseInt(raw, base, 64); err != nil { // ifinit
+		_ = n
+	}
+}
+
+func rangeLoop(paths []string, limit int) {
+	for i, p := range paths { // range
+		if i > limit {
+			_ = p
+		}
+	}
+}
+
+func handlerCall(config map[string]string, key string) {
+	value, err := lookup(config, key)
+	Err(err) // handler
+	_ = value
+}
+
+func many(a, b, c, d, e, f, g, h, i, j int) {
+	total := sum(a, b, c, d, e, f, g, h, i, j) // many
+	_ = total
+}
+
+func huge(a, b int) {
+	x := compute(a, // huge
+		1,
+		2,
+		3,
+		4,
+		5,
+		6,
+		7,
+		8,
+		9,
+		10,
+		b)
+	_ = x
+}
+`
+
+// localsAt returns the locals hint for the fixture line marked by marker
+func localsAt(t *testing.T, marker string) string {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "fixture.go", localsFixture, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, line := range strings.Split(localsFixture, "\n") {
+		if strings.HasSuffix(line, "// "+marker) {
+			return localsHint(fset, file, i+1)
+		}
+	}
+	t.Fatalf("no fixture line marked %q", marker)
+	return ""
+}
+
+func TestLocalsHint(t *testing.T) {
+	for _, tc := range []struct{ marker, want string }{
+		{"assign", "filename, mode, retries"},
+		{"ifinit", "raw, base"},
+		{"range", "paths"},
+		{"handler", "config, key"},
+		{"many", "a, b, c, d, e, f, g, h"},
+		{"huge", ""},
+	} {
+		if got := localsAt(t, tc.marker); got != tc.want {
+			t.Errorf("%s: locals hint = %q, want %q", tc.marker, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package catch
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// renderLogEntry renders a report for the log file. Under LogJSONL it is
+// the report's JSON object on one line; otherwise the pretty report with a
+// "fields:" line after the header, so a one-line grep for a request ID
+// shows enough to find the full entry.
+func renderLogEntry(info ErrorInfo, config ErrorConfig) (string, error) {
+	if config.LogFormat == LogJSONL {
+		data, err := marshalFitted(info, config.Fields, config.MaxReportBytes)
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	}
+	report := RenderReport(info, config)
+	header, rest, found := strings.Cut(report, "\n")
+	if !found {
+		return report, nil
+	}
+	return header + "\n" + cleanOutput(renderFieldsLine(info, config)) + rest, nil
+}
+
+// renderFieldsLine lists the time, severity, code, the HeaderContextKeys present in
+// context, the error ID and the location as key=value pairs
+func renderFieldsLine(info ErrorInfo, config ErrorConfig) string {
+	var b strings.Builder
+	b.WriteString("fields:")
+	add := func(key, value string) {
+		b.WriteString(" " + key + "=" + quoteField(value))
+	}
+
+	if !info.Time.IsZero() {
+		add("time", info.Time.Format(time.RFC3339Nano))
+	}
+	add("level", info.Severity.String())
+	add("code", info.ErrorCode)
+	for _, key := range config.HeaderContextKeys {
+		if value, ok := info.Context[key]; ok {
+			add(key, formatContextValue(value))
+		}
+	}
+	add("err_id", info.ID)
+	if info.HasLocation() {
+		add("file", info.displayPath(info.File, config))
+		add("line", strconv.Itoa(info.Line))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// quoteField quotes values that would break key=value splitting
+func quoteField(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		return strconv.Quote(value)
+	}
+	return value
+}
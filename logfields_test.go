@@ -0,0 +1,146 @@
+package catch
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// grepLog returns the lines of the log file at path containing s
+func grepLog(t *testing.T, path, s string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if strings.Contains(scanner.Text(), s) {
+			lines = append(lines, scanner.Text())
+		}
+	}
+	return lines
+}
+
+// fieldsLogConfig logs text entries to a file under the test's
+// temporary directory, promoting request_id and tenant
+func fieldsLogConfig(t *testing.T) (ErrorConfig, string) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	config := testConfig()
+	config.ShowSourceCode = false
+	config.LogToFile = path
+	config.HeaderContextKeys = []string{"request_id", "tenant"}
+	return config, path
+}
+
+func TestFieldsLineGrep(t *testing.T) {
+	defer SetClockForTesting(NewManualClock(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)))()
+	config, path := fieldsLogConfig(t)
+	rec := &recorder{}
+	config.Handler = rec
+	testCatch(t, config)
+
+	Err(errors.New("connection refused"), "request_id", "9f3a", "tenant", "acme corp", "attempt", 3)
+	Err(errors.New("connection refused"), "request_id", "77c0")
+
+	lines := grepLog(t, path, "request_id=9f3a")
+	if len(lines) != 1 {
+		t.Fatalf("grep request_id=9f3a matched %d lines, want the fields line alone:\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+	info := rec.reports()[0]
+	want := "fields: time=2026-03-01T09:00:00Z level=error code=" + info.ErrorCode +
+		` request_id=9f3a tenant="acme corp" err_id=` + info.ID +
+		" file=logfields_test.go line=" + strconv.Itoa(info.Line)
+	if lines[0] != want {
+		t.Errorf("fields line =\n%s\nwant\n%s", lines[0], want)
+	}
+	if strings.Contains(lines[0], "attempt") {
+		t.Error("context key not in HeaderContextKeys promoted")
+	}
+
+	// The line follows the entry's header, so the full block is found from it
+	header := grepLog(t, path, "error[")
+	if len(header) != 2 || !strings.HasSuffix(header[0], "connection refused") {
+		t.Errorf("headers = %q", header)
+	}
+}
+
+func TestFieldsLineWithoutPromotedKeys(t *testing.T) {
+	config, path := fieldsLogConfig(t)
+	config.Handler = &recorder{}
+	testCatch(t, config)
+
+	Err(errors.New("connection refused"))
+	lines := grepLog(t, path, "fields:")
+	if len(lines) != 1 || strings.Contains(lines[0], "request_id") || !strings.Contains(lines[0], " err_id=") {
+		t.Errorf("fields lines = %q", lines)
+	}
+}
+
+func TestLogJSONLRecords(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	defer SetClockForTesting(NewManualClock(at))()
+	path := filepath.Join(t.TempDir(), "app.jsonl")
+	config := testConfig()
+	config.LogToFile = path
+	config.LogFormat = LogJSONL
+	out := testCatch(t, config)
+
+	for _, err := range []error{errors.New("connection refused"), errors.New("disk full")} {
+		Err(err, "request_id", "9f3a")
+	}
+	if countHeadlines(out.String(), "connection refused") != 1 {
+		t.Errorf("console report not pretty under LogJSONL:\n%s", out)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("%d lines, want one per report:\n%s", len(lines), data)
+	}
+	for i, line := range lines {
+		if err := ValidateReport([]byte(line)); err != nil {
+			t.Errorf("line %d: %v", i+1, err)
+		}
+	}
+	var report ReportV1
+	if err := json.Unmarshal([]byte(lines[0]), &report); err != nil {
+		t.Fatal(err)
+	}
+	if !report.Time.Equal(at) || report.Message != "connection refused" || report.Code == "" {
+		t.Errorf("record time %v, message %q, code %q", report.Time, report.Message, report.Code)
+	}
+	if report.File == nil || filepath.Base(*report.File) != "logfields_test.go" || report.Line == nil || report.Function == nil {
+		t.Errorf("record lacks its location: %s", lines[0])
+	}
+	if !slices.Contains(report.Context, ContextEntry{"request_id", "9f3a"}) || report.Suggestion == "" || len(report.Stack) == 0 {
+		t.Errorf("record lacks context, suggestion or stack: %s", lines[0])
+	}
+}
+
+func TestQuoteField(t *testing.T) {
+	for value, want := range map[string]string{
+		"9f3a":       "9f3a",
+		"":           `""`,
+		"acme corp":  `"acme corp"`,
+		"a=b":        `"a=b"`,
+		`say "hi"`:   `"say \"hi\""`,
+		"two\nlines": `"two\nlines"`,
+	} {
+		if got := quoteField(value); got != want {
+			t.Errorf("quoteField(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
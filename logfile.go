@@ -0,0 +1,131 @@
+package catch
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// LogFormat selects how reports are written to LogToFile
+type LogFormat string
+
+const (
+	LogText  LogFormat = "text"  // the uncolored pretty report with a fields: line (default)
+	LogJSONL LogFormat = "jsonl" // one ReportV1 object per line, as FormatJSON writes
+)
+
+// logFile is the handle of one LogToFile path, kept open between reports
+// and reopened only after the file is rotated or closed
+type logFile struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	size int64
+}
+
+// logFiles holds the handles by path, so catchers logging to the same
+// file share one and agree on its size and when to rotate it
+var logFiles struct {
+	sync.Mutex
+	byPath map[string]*logFile
+}
+
+// sharedLogFile returns the handle for path, creating it on first use
+func sharedLogFile(path string) *logFile {
+	logFiles.Lock()
+	defer logFiles.Unlock()
+	if l, ok := logFiles.byPath[path]; ok {
+		return l
+	}
+	if logFiles.byPath == nil {
+		logFiles.byPath = make(map[string]*logFile)
+	}
+	l := &logFile{path: path}
+	logFiles.byPath[path] = l
+	return l
+}
+
+// closeLogFile closes the handle for path, if one is open; another
+// catcher still writing there reopens it with its next report
+func closeLogFile(path string) {
+	logFiles.Lock()
+	l := logFiles.byPath[path]
+	logFiles.Unlock()
+	if l != nil {
+		l.mu.Lock()
+		l.close()
+		l.mu.Unlock()
+	}
+}
+
+// open makes sure the file is open for appending
+func (l *logFile) open() error {
+	if l.file != nil {
+		return nil
+	}
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	l.file, l.size = file, info.Size()
+	return nil
+}
+
+func (l *logFile) close() {
+	if l.file != nil {
+		l.file.Close()
+		l.file = nil
+	}
+}
+
+// rotate renames the file to path.1, shifting older backups up and
+// dropping the one past backups, then starts a fresh file. If the file
+// cannot be renamed, writing goes on in it.
+func (l *logFile) rotate(backups int) error {
+	path := l.path
+	l.close()
+	for i := backups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1)) // Gaps are fine
+	}
+	renameErr := os.Rename(path, path+".1")
+	if err := l.open(); err != nil {
+		return err
+	}
+	return renameErr
+}
+
+// write appends message, rotating first when it would take the file past
+// MaxLogSizeBytes. A failed rotation is returned along with writing the
+// message anyway, so the report is never lost to it.
+func (l *logFile) write(message string, config ErrorConfig) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.open(); err != nil {
+		return err
+	}
+	var rotateErr error
+	if limit := config.MaxLogSizeBytes; limit > 0 && l.size > 0 && l.size+int64(len(message)) > limit {
+		backups := config.MaxLogBackups
+		if backups <= 0 {
+			backups = 1
+		}
+		if err := l.rotate(backups); err != nil {
+			rotateErr = fmt.Errorf("rotate: %w", err)
+			if l.file == nil {
+				return rotateErr
+			}
+		}
+	}
+	n, err := l.file.WriteString(message)
+	l.size += int64(n)
+	if err != nil {
+		return err
+	}
+	return rotateErr
+}
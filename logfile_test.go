@@ -0,0 +1,125 @@
+package catch
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// logConfig returns a configuration writing JSON lines to path, rotated
+// past limit bytes with backups kept
+func logConfig(path string, limit int64, backups int) ErrorConfig {
+	config := testConfig()
+	config.Output = io.Discard
+	config.ShowSourceCode = false
+	config.ShowStackTrace = false
+	config.LogToFile = path
+	config.LogFormat = LogJSONL
+	config.MaxLogSizeBytes = limit
+	config.MaxLogBackups = backups
+	return config
+}
+
+// logLines returns the lines of path and the backups rotation made, oldest
+// first, and fails the test if any file is past limit or a backup past
+// backups was kept
+func logLines(t *testing.T, path string, limit int64, backups int) []string {
+	t.Helper()
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Fatalf("log never rotated: %v", err)
+	}
+	if _, err := os.Stat(fmt.Sprintf("%s.%d", path, backups+1)); !os.IsNotExist(err) {
+		t.Errorf("backup past MaxLogBackups kept (stat: %v)", err)
+	}
+	var lines []string
+	for i := backups; i >= 0; i-- {
+		name := path
+		if i > 0 {
+			name = fmt.Sprintf("%s.%d", path, i)
+		}
+		data, err := os.ReadFile(name)
+		if os.IsNotExist(err) && i > 1 {
+			continue
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if int64(len(data)) > limit {
+			t.Errorf("%s holds %d bytes, past the limit of %d", filepath.Base(name), len(data), limit)
+		}
+		lines = append(lines, strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")...)
+	}
+	return lines
+}
+
+func TestLogFileRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.log")
+	c := New(logConfig(path, 4096, 2))
+	defer c.Close()
+
+	for i := range 100 {
+		c.Err(fmt.Errorf("job %d failed", i))
+	}
+	lines := logLines(t, path, 4096, 2)
+	if last := lines[len(lines)-1]; !strings.Contains(last, "job 99 failed") {
+		t.Errorf("newest line %q, want the last report", last)
+	}
+	if strings.Contains(strings.Join(lines, "\n"), `"job 0 failed"`) {
+		t.Error("oldest report survived rotating past two backups")
+	}
+}
+
+func TestCatchersShareLogFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.log")
+	const limit, backups, perCatcher = 16 << 10, 10, 50
+	catchers := []*ErrorCatcher{New(logConfig(path, limit, backups)), New(logConfig(path, limit, backups))}
+	if sharedLogFile(resolvePath(path)) != sharedLogFile(resolvePath(path)) {
+		t.Fatal("one path has two handles")
+	}
+
+	var wg sync.WaitGroup
+	for i, c := range catchers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range perCatcher {
+				c.Err(fmt.Errorf("catcher %d job %d failed", i, j))
+			}
+		}()
+	}
+	wg.Wait()
+	for _, c := range catchers {
+		c.Close()
+	}
+
+	lines := logLines(t, path, limit, backups)
+	if len(lines) != len(catchers)*perCatcher {
+		t.Errorf("%d lines logged, want %d", len(lines), len(catchers)*perCatcher)
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "{") || !strings.HasSuffix(line, "}") {
+			t.Fatalf("interleaved line %q", line)
+		}
+	}
+}
+
+func TestClosedLogFileReopens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.log")
+	first, second := New(logConfig(path, 0, 0)), New(logConfig(path, 0, 0))
+
+	first.Err(fmt.Errorf("before close"))
+	first.Close()
+	second.Err(fmt.Errorf("after close"))
+	second.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "before close") || !strings.Contains(string(data), "after close") {
+		t.Errorf("log lacks a report:\n%s", data)
+	}
+}
@@ -0,0 +1,50 @@
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// StdLogger returns a *log.Logger whose output is handled as errors of the
+// given severity, for libraries that only accept a logger
+// Usage: srv := &http.Server{ErrorLog: catch.StdLogger(catch.LevelWarn)}
+func StdLogger(severity Severity) *log.Logger {
+	return log.New(&logWriter{severity: severity}, "", 0)
+}
+
+// Printf returns a printf-style callback that handles each message as an
+// error of the given severity
+// Usage: client.OnError = catch.Printf(catch.LevelWarn)
+func Printf(severity Severity) func(string, ...interface{}) {
+	return func(format string, args ...interface{}) {
+		handleLogLine(fmt.Sprintf(format, args...), severity)
+	}
+}
+
+// logWriter turns each Write call into one handled error
+type logWriter struct {
+	severity Severity
+}
+
+// Write handles p as a single error; multi-line writes are kept together
+func (w *logWriter) Write(p []byte) (int, error) {
+	handleLogLine(string(p), w.severity)
+	return len(p), nil
+}
+
+// handleLogLine builds and handles an error from a logged message. It
+// never exits, whatever the severity and ExitOnError: a library's log line
+// is not the application's decision to stop.
+func handleLogLine(line string, severity Severity) {
+	msg := strings.TrimRight(line, "\r\n")
+	if strings.TrimSpace(msg) == "" {
+		return
+	}
+
+	info := Catch.buildErrorInfo(errors.New(msg))
+	info.Severity = severity
+	info.opts.noExit = true
+	Catch.handleError(info)
+}
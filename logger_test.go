@@ -0,0 +1,89 @@
+package catch
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStdLoggerHandlesEachWrite(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+
+	logger := StdLogger(LevelWarn)
+	logger.Print("http: TLS handshake error from 10.0.0.7:51234: connection refused")
+
+	reports := rec.reports()
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reports))
+	}
+	info := reports[0]
+	if got, want := info.Error.Error(), "http: TLS handshake error from 10.0.0.7:51234: connection refused"; got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+	if info.Severity != LevelWarn {
+		t.Errorf("severity = %v, want %v", info.Severity, LevelWarn)
+	}
+	if info.ErrorCode != "NET001" {
+		t.Errorf("code = %s, want NET001 from classification", info.ErrorCode)
+	}
+	if filepath.Base(info.File) != "logger_test.go" {
+		t.Errorf("file = %s, want the logging call site", info.File)
+	}
+}
+
+func TestStdLoggerCoalescesMultiLineWrites(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+
+	StdLogger(LevelError).Writer().Write([]byte("query failed:\n  relation \"users\" does not exist\n"))
+
+	reports := rec.reports()
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want 1 for one Write", len(reports))
+	}
+	if got, want := reports[0].Error.Error(), "query failed:\n  relation \"users\" does not exist"; got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+}
+
+func TestStdLoggerSkipsBlankWrites(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+
+	StdLogger(LevelError).Writer().Write([]byte("\n"))
+	if n := len(rec.reports()); n != 0 {
+		t.Errorf("got %d reports for a blank line, want 0", n)
+	}
+}
+
+func TestPrintfCallback(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+
+	onError := Printf(LevelWarn)
+	onError("retrying %s after %d attempts", "upload", 3)
+
+	reports := rec.reports()
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reports))
+	}
+	if got, want := reports[0].Error.Error(), "retrying upload after 3 attempts"; got != want {
+		t.Errorf("message = %q, want %q", got, want)
+	}
+	if reports[0].Severity != LevelWarn {
+		t.Errorf("severity = %v, want %v", reports[0].Severity, LevelWarn)
+	}
+}
+
+func TestLoggedLinesNeverExit(t *testing.T) {
+	codes := stubExit(t)
+	config := testConfig()
+	config.ExitOnError = true
+	rec := recordCatch(t, config)
+
+	StdLogger(LevelError).Print("tls: bad certificate")
+	Printf(LevelFatal)("worker %d crashed", 3)
+
+	if len(*codes) != 0 {
+		t.Errorf("exited with %v for logged lines", *codes)
+	}
+	if n := len(rec.reports()); n != 2 {
+		t.Errorf("got %d reports, want 2", n)
+	}
+}
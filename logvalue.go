@@ -0,0 +1,67 @@
+package catch
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+)
+
+// logValueDepth is how many frames LogValue captures for the location and
+// fingerprint; LogValueFrames captures more when asked
+const logValueDepth = 16
+
+// errorValuer is the slog.LogValuer behind LogValue. The frames are
+// captured when it is created; classification waits until a handler
+// resolves the value, so records that are filtered out cost no more.
+type errorValuer struct {
+	err    error
+	frames []runtime.Frame
+	stack  int // Frames listed in the group, 0 for none
+}
+
+// LogValue returns err as a slog value that expands into a group with the
+// message, code, location of the LogValue call and stack fingerprint,
+// instead of the flat message. The stack is left out.
+// Usage: slog.Error("request failed", "err", catch.LogValue(err))
+func LogValue(err error) slog.Value {
+	return LogValueFrames(err, 0)
+}
+
+// LogValueFrames is LogValue with the top frames of the stack included,
+// as "function file:line" strings
+// Usage: slog.Error("request failed", "err", catch.LogValueFrames(err, 3))
+func LogValueFrames(err error, frames int) slog.Value {
+	if err == nil {
+		return slog.AnyValue(nil)
+	}
+	return slog.AnyValue(errorValuer{err: err, frames: reportFrames(max(logValueDepth, frames)), stack: frames})
+}
+
+// LogValue implements slog.LogValuer
+func (v errorValuer) LogValue() slog.Value {
+	code, _ := classify(v.err)
+	attrs := []slog.Attr{
+		slog.String("message", describeError(v.err)),
+		slog.String("code", code),
+	}
+	if root := rootCause(v.err); root != v.err {
+		attrs = append(attrs, slog.String("cause", describeError(root)))
+	}
+	if len(v.frames) > 0 && v.frames[0].File != "" {
+		attrs = append(attrs,
+			slog.String("location", fmt.Sprintf("%s:%d", filepath.Base(v.frames[0].File), v.frames[0].Line)),
+			slog.String("function", shortFuncName(v.frames[0].Function)))
+	}
+	if fingerprint := stackFingerprint(v.frames); fingerprint != "" {
+		attrs = append(attrs, slog.String("fingerprint", fingerprint))
+	}
+	if v.stack > 0 {
+		var stack []string
+		for _, frame := range v.frames[:min(v.stack, len(v.frames))] {
+			stack = append(stack, fmt.Sprintf("%s %s:%d", shortFuncName(frame.Function), filepath.Base(frame.File), frame.Line))
+		}
+		attrs = append(attrs, slog.Any("stack", stack))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,85 @@
+package catch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// logRecord logs one record through a JSON handler and returns its "err"
+// attribute
+func logRecord(t *testing.T, value slog.Value) map[string]interface{} {
+	t.Helper()
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Error("request failed", "err", value)
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("%v: %s", err, buf.String())
+	}
+	group, ok := record["err"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("err is %T, want a group: %s", record["err"], buf.String())
+	}
+	return group
+}
+
+func TestLogValueExpandsIntoGroup(t *testing.T) {
+	err := fmt.Errorf("loading profile: %w", os.ErrNotExist)
+	value := LogValue(err)
+	_, _, line, _ := runtime.Caller(0)
+
+	group := logRecord(t, value)
+	code, _ := classify(err)
+	if group["message"] != "loading profile: file does not exist" || group["code"] != code {
+		t.Errorf("message and code %v", group)
+	}
+	if group["cause"] != "file does not exist" {
+		t.Errorf("cause %v", group["cause"])
+	}
+	if want := fmt.Sprintf("logvalue_test.go:%d", line-1); group["location"] != want {
+		t.Errorf("location %v, want %s", group["location"], want)
+	}
+	if !strings.HasSuffix(fmt.Sprint(group["function"]), "TestLogValueExpandsIntoGroup") || group["fingerprint"] == "" {
+		t.Errorf("function %v, fingerprint %v", group["function"], group["fingerprint"])
+	}
+	if _, ok := group["stack"]; ok {
+		t.Error("stack included by default")
+	}
+}
+
+func TestLogValueFramesListsTopFrames(t *testing.T) {
+	group := logRecord(t, LogValueFrames(errors.New("boom"), 2))
+	stack, _ := group["stack"].([]interface{})
+	if len(stack) != 2 || !strings.HasPrefix(fmt.Sprint(stack[0]), "catch.TestLogValueFramesListsTopFrames logvalue_test.go:") {
+		t.Errorf("stack %v, want the top 2 frames", group["stack"])
+	}
+}
+
+func TestLogValueClassifiesLazily(t *testing.T) {
+	clearCodes(t)
+	calls := 0
+	RegisterCode(func(error) bool { calls++; return false }, "NEVER", "")
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelError}))
+	logger.Info("filtered out", "err", LogValue(errors.New("boom")))
+	if calls != 0 {
+		t.Errorf("classified %d times for a record never handled", calls)
+	}
+	logger.Error("kept", "err", LogValue(errors.New("boom")))
+	if calls != 1 {
+		t.Errorf("classified %d times for one handled record", calls)
+	}
+}
+
+func TestLogValueOfNil(t *testing.T) {
+	if v := LogValue(nil); !v.Equal(slog.AnyValue(nil)) {
+		t.Errorf("LogValue(nil) = %v", v)
+	}
+}
@@ -0,0 +1,121 @@
+package catch
+
+import (
+	"io"
+	"unsafe"
+
+	"catch/internal/keycache"
+)
+
+// Limits applied under ErrorConfig.LowMemory
+const (
+	lowMemorySilenced       = 8   // Silenced events kept
+	lowMemoryTimingSamples  = 64  // Timing samples kept per phase
+	lowMemoryContextEntries = 8   // Context entries kept per report
+	lowMemoryContextValue   = 128 // Bytes kept of each string context value
+)
+
+// processLowMemory reports whether the global Catch runs under LowMemory,
+// which sizes the buffers shared by every catcher: the silenced-event
+// history and the timing samples. Each catcher's own reports follow its
+// own LowMemory.
+func processLowMemory() bool {
+	return Catch.getConfig().LowMemory
+}
+
+// lowMemoryContext trims the context of a report to the LowMemory limits:
+// the first entries in display order, with long strings cut short
+func lowMemoryContext(info *ErrorInfo) {
+	keys := contextKeys(*info)
+	for i, k := range keys {
+		if i >= lowMemoryContextEntries {
+			delete(info.Context, k)
+			info.contextOmitted++
+			continue
+		}
+		if s, ok := info.Context[k].(string); ok && len(s) > lowMemoryContextValue {
+			info.Context[k] = truncateUTF8(s, lowMemoryContextValue) + "…"
+		}
+	}
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a character
+func truncateUTF8(s string, n int) string {
+	for n > 0 && n < len(s) && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n]
+}
+
+// writeSections writes a full report to w section by section, so the
+// whole report is never held in memory at once
+func writeSections(w io.Writer, info ErrorInfo, config ErrorConfig) error {
+	sections := []func(ErrorInfo, ErrorConfig) string{
+		RenderHeader, RenderLocation, RenderSource, RenderDetails, RenderCauses, RenderCauseTree, RenderJoined,
+		RenderContext, RenderHelp, RenderStack, RenderFooter, RenderHandlerIssues, renderTiming,
+	}
+	for _, section := range sections {
+		text, ok := renderSection(section, info, config)
+		if !ok {
+			info.degraded.raise(DegradeSections) // Streamed sections can't be taken back
+			continue
+		}
+		if _, err := io.WriteString(w, cleanOutput(text)); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, renderRawError(info, config)+renderHint(config)) // The raw error stays untouched
+	return err
+}
+
+// renderSection renders one section, or reports that it panicked
+func renderSection(section func(ErrorInfo, ErrorConfig) string, info ErrorInfo, config ErrorConfig) (text string, ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return section(info, config), true
+}
+
+// MemoryFootprint estimates the bytes held by gocatch's own structures:
+// silenced events, timing samples, the global catcher's run statistics
+// and sampled call sites, errors buffered before startup completes, and
+// tracked resources. The errors and values those structures reference are
+// not counted.
+func MemoryFootprint() int64 {
+	var total int64
+
+	silenced.mu.Lock()
+	total += int64(min(silenced.total, silenced.capacity())) * int64(unsafe.Sizeof(silencedEntry{}))
+	silenced.mu.Unlock()
+
+	timings.mu.Lock()
+	for _, samples := range timings.samples {
+		total += int64(cap(samples)) * int64(unsafe.Sizeof(samples[0]))
+	}
+	timings.mu.Unlock()
+
+	Catch.stats.mu.Lock()
+	for code := range Catch.stats.codes {
+		total += int64(len(code)) + int64(unsafe.Sizeof(code)) + 8
+	}
+	for _, entry := range []*SummaryError{Catch.stats.first, Catch.stats.last} {
+		if entry != nil {
+			total += int64(unsafe.Sizeof(*entry)) + int64(len(entry.Message))
+		}
+	}
+	Catch.stats.mu.Unlock()
+
+	total += int64(Catch.keys.Len()) * int64(unsafe.Sizeof(sampledSite{})+unsafe.Sizeof(keycache.Entry[trackedKey, any]{}))
+
+	Catch.startup.mu.Lock()
+	total += int64(len(Catch.startup.buffered)) * int64(unsafe.Sizeof(ErrorInfo{}))
+	Catch.startup.mu.Unlock()
+
+	Catch.resources.Range(func(_, _ any) bool {
+		total += int64(unsafe.Sizeof(trackedCloser{}))
+		return true
+	})
+	return total
+}
@@ -0,0 +1,43 @@
+package catch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLowMemoryIsPerCatcher(t *testing.T) {
+	long := strings.Repeat("x", 2*lowMemoryContextValue)
+	config := testConfig()
+	config.ShowSourceCode = false
+	global := testCatch(t, config)
+
+	var own strings.Builder
+	lowConfig := config
+	lowConfig.Output = &own
+	lowConfig.LowMemory = true
+	low := New(lowConfig)
+
+	low.Err(errors.New("small report"), "payload", long)
+	Err(errors.New("full report"), "payload", long)
+
+	if strings.Contains(own.String(), long) || !strings.Contains(own.String(), "…") {
+		t.Errorf("LowMemory catcher kept the long context value:\n%s", own.String())
+	}
+	if !strings.Contains(global.String(), long) {
+		t.Errorf("global Catch trimmed context after New(LowMemory):\n%s", global)
+	}
+	if processLowMemory() {
+		t.Error("shared buffers shrank for a LowMemory catcher from New")
+	}
+}
+
+func TestLowMemoryOnGlobalShrinksSharedBuffers(t *testing.T) {
+	config := testConfig()
+	config.LowMemory = true
+	testCatch(t, config)
+
+	if got := silenced.capacity(); got != lowMemorySilenced {
+		t.Errorf("silenced capacity = %d, want %d under LowMemory on Catch", got, lowMemorySilenced)
+	}
+}
@@ -0,0 +1,127 @@
+package catch
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSpanLines caps the lines a multi-line span may cover; longer
+// expressions are marked on their first line only
+const maxSpanLines = 10
+
+// spanLabel ends the bracket under a multi-line span
+const spanLabel = "failing call"
+
+// exprSpan is where the failing expression sits on the error line: a
+// 1-based byte column and a width in characters, or for an expression
+// running onto later lines, the line and byte column of its last character
+type exprSpan struct {
+	Column    int
+	Width     int
+	EndLine   int
+	EndColumn int
+}
+
+// span returns the marked expression of info
+func (info ErrorInfo) span() exprSpan {
+	return exprSpan{Column: info.Column, Width: info.Span, EndLine: info.EndLine, EndColumn: info.EndColumn}
+}
+
+// setSpan marks the expression of info, clearing any multi-line end
+func (info *ErrorInfo) setSpan(mark exprSpan) {
+	info.Column, info.Span, info.EndLine, info.EndColumn = mark.Column, mark.Width, mark.EndLine, mark.EndColumn
+}
+
+// spanOf marks the first occurrence of text on the error line of lines,
+// for reports pointing into non-Go sources; the zero span when absent
+func spanOf(lines []SourceLine, text string) exprSpan {
+	for _, line := range lines {
+		if !line.IsError || text == "" {
+			continue
+		}
+		if i := strings.Index(line.Content, text); i >= 0 {
+			return exprSpan{Column: i + 1, Width: utf8.RuneCountInString(text)}
+		}
+	}
+	return exprSpan{}
+}
+
+// expandTabs replaces tabs with four spaces, returning the line and the
+// visual column of the 1-based byte column
+func expandTabs(line string, column int) (string, int) {
+	var b strings.Builder
+	visual := 0
+	for i, r := range line {
+		if i == column-1 {
+			visual = b.Len() + 1
+		}
+		if r == '\t' {
+			b.WriteString("    ")
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	if visual == 0 {
+		visual = b.Len() + 1
+	}
+	return b.String(), visual
+}
+
+// renderMultilineSnippet renders source lines with a bracket in the gutter
+// along the lines the span covers, from its first character to its last,
+// where the label goes. Tabs are expanded so the underscores line up.
+func renderMultilineSnippet(lines []SourceLine, mark exprSpan, config ErrorConfig) string {
+	padding := len(fmt.Sprintf("%d", lines[len(lines)-1].Number))
+	spaces := strings.Repeat(" ", padding)
+	mark1, mark2 := "", ""
+	if config.UseColors {
+		mark1, mark2 = Red+Bold, Reset
+	}
+
+	var output strings.Builder
+	blank := lineMarker(config, markBlank)
+	output.WriteString(blank + "  |\n")
+	startLine := 0
+	for _, sourceLine := range lines {
+		if sourceLine.IsError {
+			startLine = sourceLine.Number
+		}
+		inSpan := startLine > 0 && sourceLine.Number > startLine && sourceLine.Number <= mark.EndLine
+		gutter, marker := "  ", lineMarker(config, markContext)
+		if sourceLine.IsError || inSpan {
+			marker = lineMarker(config, markError)
+		}
+		if inSpan {
+			gutter = mark1 + "|" + mark2 + " "
+		}
+
+		lineNumStr := fmt.Sprintf("%*d", padding, sourceLine.Number)
+		if config.UseColors {
+			color := Blue
+			if sourceLine.IsError || inSpan {
+				color = Red + Bold
+			}
+			lineNumStr = color + lineNumStr + Reset
+		}
+
+		switch {
+		case sourceLine.IsError:
+			content, at := expandTabs(sourceLine.Content, mark.Column)
+			output.WriteString(fmt.Sprintf("%s%s | %s%s\n", marker, lineNumStr, gutter, content))
+			output.WriteString(fmt.Sprintf("%s%s |  %s%s^%s\n", blank, spaces, mark1, strings.Repeat("_", at), mark2))
+		case inSpan && sourceLine.Number == mark.EndLine:
+			content, at := expandTabs(sourceLine.Content, mark.EndColumn)
+			output.WriteString(fmt.Sprintf("%s%s | %s%s\n", marker, lineNumStr, gutter, content))
+			output.WriteString(fmt.Sprintf("%s%s | %s|%s^ %s%s\n", blank, spaces, mark1, strings.Repeat("_", at), spanLabel, mark2))
+		default:
+			content, _ := expandTabs(sourceLine.Content, 0)
+			if config.UseColors && !inSpan {
+				content = Gray + content + Reset
+			}
+			output.WriteString(fmt.Sprintf("%s%s | %s%s\n", marker, lineNumStr, gutter, content))
+		}
+	}
+	output.WriteString(blank + "  |\n")
+	return output.String()
+}
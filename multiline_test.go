@@ -0,0 +1,70 @@
+//go:build !gocatch_lite
+
+package catch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// chainFixture holds a failing call chained over three lines
+const chainFixture = `package fixture
+
+func send() {
+	err := client.Request().
+		Header("a", "b").
+		Send()
+	return
+}
+`
+
+// writeChainFixture writes chainFixture and returns its path
+func writeChainFixture(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixture.go")
+	if err := os.WriteFile(path, []byte(chainFixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCallSpanCoversChainedLines(t *testing.T) {
+	got := callSpan(writeChainFixture(t), 4)
+	want := exprSpan{Column: strings.Index("\terr := client", "client") + 1, EndLine: 6, EndColumn: len("\t\tSend()")}
+	if got != want {
+		t.Errorf("callSpan = %+v, want %+v", got, want)
+	}
+}
+
+func TestMultilineSpanWidensWindow(t *testing.T) {
+	config := testConfig()
+	config.ContextLines = 1
+	info := ErrorInfo{File: writeChainFixture(t), Line: 4}
+	Catch.loadSources(&info, config)
+
+	var numbers []int
+	for _, line := range info.SourceLines {
+		numbers = append(numbers, line.Number)
+	}
+	if len(numbers) != 4 || numbers[0] != 3 || numbers[3] != 6 {
+		t.Errorf("window covers lines %v, want 3 to 6", numbers)
+	}
+}
+
+func TestMultilineSpanIsBracketed(t *testing.T) {
+	config := testConfig()
+	info := ErrorInfo{File: writeChainFixture(t), Line: 4}
+	Catch.loadSources(&info, config)
+	out := RenderSource(info, config)
+
+	want := "4 |       err := client.Request().\n" +
+		"  |  ____________^\n" +
+		"5 | |         Header(\"a\", \"b\").\n" +
+		"6 | |         Send()\n" +
+		"  | |______________^ failing call\n"
+	if !strings.Contains(out, want) {
+		t.Errorf("span not bracketed over lines 4 to 6:\n%s", out)
+	}
+}
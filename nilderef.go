@@ -0,0 +1,102 @@
+//go:build !gocatch_lite
+
+package catch
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+)
+
+// maxNilCandidates caps the expressions listed in nil_candidates
+const maxNilCandidates = 5
+
+// enrichNilDeref names the expressions on the panic line that could have
+// been nil, for recovered nil pointer dereferences. This is a heuristic
+// from the source alone: every operand of a field selector or pointer
+// indirection is a candidate, minus those that are only a prefix of a
+// longer candidate.
+func enrichNilDeref(ctx context.Context, info *ErrorInfo, config ErrorConfig) {
+	if ctx.Err() != nil || config.LowMemory || !info.HasLocation() || !strings.Contains(safeFormat("%v", info.Error), "nil pointer dereference") {
+		return
+	}
+	candidates := nilCandidates(mapSourcePath(info.File, config.SourcePathMap), info.Line)
+	if len(candidates) == 0 {
+		return
+	}
+
+	list := strings.Join(candidates, ", ")
+	setContext(info, "nil_candidates", list)
+	if len(candidates) == 1 {
+		info.Suggestion = candidates[0] + " was nil here; initialize it or check it for nil before use"
+	} else {
+		info.Suggestion = "one of " + list + " was nil here; check each for nil before use"
+	}
+}
+
+// nilCandidates returns the dereferenced expressions on a source line
+func nilCandidates(filename string, line int) []string {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, 0)
+	if err != nil {
+		return nil
+	}
+	packages := importNames(file)
+
+	var found []string
+	add := func(x ast.Expr) {
+		if ident, ok := x.(*ast.Ident); ok && packages[ident.Name] {
+			return // pkg.Name is not a dereference
+		}
+		start, end := fset.Position(x.Pos()).Offset, fset.Position(x.End()).Offset
+		text := string(src[start:end])
+		for _, f := range found {
+			if f == text {
+				return
+			}
+		}
+		found = append(found, text)
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n == nil || fset.Position(n.Pos()).Line > line || fset.Position(n.End()).Line < line {
+			return false
+		}
+		if fset.Position(n.Pos()).Line != line {
+			return true
+		}
+		switch node := n.(type) {
+		case *ast.SelectorExpr:
+			add(node.X)
+		case *ast.StarExpr:
+			add(node.X)
+		}
+		return true
+	})
+
+	// Drop prefixes: cfg and cfg.Server are implied by cfg.Server.TLS
+	var candidates []string
+	for _, c := range found {
+		implied := false
+		for _, other := range found {
+			if other != c && strings.HasPrefix(other, c+".") {
+				implied = true
+				break
+			}
+		}
+		if !implied {
+			candidates = append(candidates, c)
+		}
+	}
+	if len(candidates) > maxNilCandidates {
+		candidates = candidates[:maxNilCandidates]
+	}
+	return candidates
+}
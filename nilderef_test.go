@@ -0,0 +1,128 @@
+//go:build !gocatch_lite
+
+package catch
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// nilFixture is read by the nil candidate tests; the line of each case is
+// found by the marker comment ending it
+const nilFixture = `package fixture
+
+import "strings"
+
+func one(cfg *Config) string {
+	return cfg.Server.TLS.Cert // one
+}
+
+func two(conn *Conn, cfg *Config) int {
+	return conn.state + len(cfg.Name) // two
+}
+
+func star(p *int, q *int) int {
+	return *p + *q // star
+}
+
+func pkg(u *User) string {
+	return strings.ToUpper(u.Name) // pkg
+}
+
+func repeated(u *User) string {
+	return u.First + u.Last + u.First // repeated
+}
+
+func many(a, b, c, d, e, f, g *T) int {
+	return a.n + b.n + c.n + d.n + e.n + f.n + g.n // many
+}
+
+func none(n int) int {
+	return n * 2 // none
+}
+`
+
+// nilCandidatesAt returns the candidates for the fixture line marked by
+// marker
+func nilCandidatesAt(t *testing.T, marker string) []string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixture.go")
+	if err := os.WriteFile(path, []byte(nilFixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for i, line := range strings.Split(nilFixture, "\n") {
+		if strings.HasSuffix(line, "// "+marker) {
+			return nilCandidates(path, i+1)
+		}
+	}
+	t.Fatalf("no fixture line marked %q", marker)
+	return nil
+}
+
+func TestNilCandidates(t *testing.T) {
+	for _, tc := range []struct {
+		marker string
+		want   []string
+	}{
+		{"one", []string{"cfg.Server.TLS"}},
+		{"two", []string{"conn", "cfg"}},
+		{"star", []string{"p", "q"}},
+		{"pkg", []string{"u"}},
+		{"repeated", []string{"u"}},
+		{"many", []string{"a", "b", "c", "d", "e"}},
+		{"none", nil},
+	} {
+		if got := nilCandidatesAt(t, tc.marker); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: candidates = %q, want %q", tc.marker, got, tc.want)
+		}
+	}
+}
+
+type nilTLS struct{ Cert string }
+
+type nilServer struct {
+	Name string
+	TLS  *nilTLS
+}
+
+type nilConfig struct{ Server *nilServer }
+
+func certOf(cfg *nilConfig) (cert string, err error) {
+	defer Try()(&err)
+	return cfg.Server.TLS.Cert, nil
+}
+
+func namesOf(primary, backup *nilServer) (names string, err error) {
+	defer Try()(&err)
+	return primary.Name + "," + backup.Name, nil
+}
+
+func TestNilDerefReport(t *testing.T) {
+	config := testConfig()
+	config.ShowSourceCode = false
+	rec := recordCatch(t, config)
+
+	certOf(&nilConfig{Server: &nilServer{}})
+	namesOf(&nilServer{}, nil)
+	reports := rec.reports()
+	if len(reports) != 2 {
+		t.Fatalf("got %d reports, want 2", len(reports))
+	}
+
+	one, two := reports[0], reports[1]
+	if one.ErrorCode != "LOGIC002" || one.Context["nil_candidates"] != "cfg.Server.TLS" {
+		t.Errorf("one candidate: code %s, candidates %v", one.ErrorCode, one.Context["nil_candidates"])
+	}
+	if want := "cfg.Server.TLS was nil here; initialize it or check it for nil before use"; one.Suggestion != want {
+		t.Errorf("one candidate: suggestion %q, want %q", one.Suggestion, want)
+	}
+	if two.ErrorCode != "LOGIC002" || two.Context["nil_candidates"] != "primary, backup" {
+		t.Errorf("two candidates: code %s, candidates %v", two.ErrorCode, two.Context["nil_candidates"])
+	}
+	if want := "one of primary, backup was nil here; check each for nil before use"; two.Suggestion != want {
+		t.Errorf("two candidates: suggestion %q, want %q", two.Suggestion, want)
+	}
+}
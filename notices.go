@@ -0,0 +1,104 @@
+package catch
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// NoticeKind tells a change in behavior from a deprecation
+type NoticeKind string
+
+const (
+	NoticeBehavior    NoticeKind = "behavior-change"
+	NoticeDeprecation NoticeKind = "deprecation"
+)
+
+// Notice records that a program ran a code path whose behavior changed,
+// or that is deprecated. Each is recorded once per process, at the first
+// place it was hit.
+type Notice struct {
+	ID      string     `json:"id"` // Stable, e.g. "BEH001"
+	Kind    NoticeKind `json:"kind"`
+	Message string     `json:"message"`
+	File    string     `json:"file,omitempty"`
+	Line    int        `json:"line,omitempty"`
+	Time    time.Time  `json:"time"`
+}
+
+// Notice IDs; an ID is never reused for another notice
+const (
+	NoticeExceptPackage = "DEP001"
+	NoticeTryRecovers   = "BEH001"
+	NoticeJoinedReports = "BEH002"
+	NoticeCauseTreeCode = "BEH003"
+)
+
+// noticeDefs describes each notice
+var noticeDefs = map[string]struct {
+	kind    NoticeKind
+	message string
+}{
+	NoticeExceptPackage: {NoticeDeprecation, `package except is deprecated; import "catch" and use Err, Errf, ErrMust and ErrCheck`},
+	NoticeTryRecovers:   {NoticeBehavior, "Try recovers a panic in the function deferring it and reports it as an error; the panic used to propagate"},
+	NoticeJoinedReports: {NoticeBehavior, "errors.Join results are reported with one section and code per joined error, under an \"N errors\" headline"},
+	NoticeCauseTreeCode: {NoticeBehavior, "an error with errors joined below the top takes its code from one of them, chosen by PrimaryCode, instead of GEN000"},
+}
+
+// noticeLog holds the notices recorded so far and those waiting to be
+// reported under PromoteNotices
+var noticeLog struct {
+	sync.Mutex
+	recorded []Notice
+	pending  []Notice
+}
+
+// Notices returns the notices recorded in this process, in the order they
+// were first hit
+func Notices() []Notice {
+	noticeLog.Lock()
+	defer noticeLog.Unlock()
+	return slices.Clone(noticeLog.recorded)
+}
+
+// recordNotice records notice id the first time it is hit
+func recordNotice(id, file string, line int) {
+	noticeLog.Lock()
+	defer noticeLog.Unlock()
+	if slices.ContainsFunc(noticeLog.recorded, func(n Notice) bool { return n.ID == id }) {
+		return
+	}
+	def := noticeDefs[id]
+	notice := Notice{ID: id, Kind: def.kind, Message: def.message, File: file, Line: line, Time: now()}
+	noticeLog.recorded = append(noticeLog.recorded, notice)
+	noticeLog.pending = append(noticeLog.pending, notice)
+}
+
+// reportNotices reports the notices recorded since the last call whose
+// IDs are in PromoteNotices, as warnings located where they were hit.
+// It runs after the report that hit them, never within it.
+func (e *ErrorCatcher) reportNotices(config ErrorConfig) {
+	noticeLog.Lock()
+	pending := noticeLog.pending
+	noticeLog.pending = nil
+	noticeLog.Unlock()
+
+	for _, notice := range pending {
+		if !slices.Contains(config.PromoteNotices, notice.ID) {
+			continue
+		}
+		info := ErrorInfo{
+			Error:      errors.New(string(notice.Kind) + ": " + notice.Message),
+			ErrorCode:  notice.ID,
+			Suggestion: "see catch.Notices for the notices recorded in this run",
+			Context:    make(map[string]interface{}),
+			Uptime:     now().Sub(processStart),
+			File:       notice.File,
+			Line:       notice.Line,
+			Severity:   LevelWarn,
+		}
+		e.loadSources(&info, config)
+		e.handleError(info)
+	}
+}
@@ -0,0 +1,86 @@
+package catch
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// resetNotices clears the notices recorded so far for one test and
+// restores them afterwards
+func resetNotices(t testing.TB) {
+	t.Helper()
+	noticeLog.Lock()
+	recorded, pending := noticeLog.recorded, noticeLog.pending
+	noticeLog.recorded, noticeLog.pending = nil, nil
+	noticeLog.Unlock()
+	t.Cleanup(func() {
+		noticeLog.Lock()
+		noticeLog.recorded, noticeLog.pending = recorded, pending
+		noticeLog.Unlock()
+	})
+}
+
+func TestNoticesRecordedOnceEach(t *testing.T) {
+	resetNotices(t)
+	testCatch(t, testConfig())
+
+	for range 2 {
+		tryPanic("index out of range")
+		Err(errors.Join(errors.New("connection refused"), errors.New("disk full")))
+	}
+
+	notices := Notices()
+	if len(notices) != 2 {
+		t.Fatalf("got %d notices, want 2: %+v", len(notices), notices)
+	}
+	for i, want := range []string{"BEH001", "BEH002"} {
+		n := notices[i]
+		if n.ID != want || n.Kind != NoticeBehavior || n.Message == "" {
+			t.Errorf("notice %d = %+v, want behavior change %s", i, n, want)
+		}
+		if n.Line == 0 || !strings.HasSuffix(filepath.Base(n.File), "_test.go") {
+			t.Errorf("notice %s located at %s:%d, want the test that hit it", n.ID, n.File, n.Line)
+		}
+	}
+}
+
+func TestPromotedNoticeReportedAsWarning(t *testing.T) {
+	resetNotices(t)
+	config := testConfig()
+	config.PromoteNotices = []string{NoticeJoinedReports}
+	rec := recordCatch(t, config)
+
+	for range 2 {
+		Err(errors.Join(errors.New("connection refused"), errors.New("disk full")))
+	}
+	tryPanic("index out of range")
+
+	var warnings []ErrorInfo
+	for _, info := range rec.reports() {
+		if info.Severity == LevelWarn {
+			warnings = append(warnings, info)
+		}
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("got %d warnings, want one for the promoted notice", len(warnings))
+	}
+	if w := warnings[0]; w.ErrorCode != NoticeJoinedReports || !strings.HasPrefix(w.Error.Error(), "behavior-change: ") {
+		t.Errorf("warning %s: %v, want the joined reports notice", w.ErrorCode, w.Error)
+	}
+	if len(Notices()) != 2 {
+		t.Errorf("notices %+v, want both recorded though one was promoted", Notices())
+	}
+}
+
+func TestDebugConfigListsNotices(t *testing.T) {
+	resetNotices(t)
+	recordNotice(NoticeExceptPackage, "main.go", 12)
+
+	var dump strings.Builder
+	New(testConfig()).DebugConfig(&dump)
+	if !strings.Contains(dump.String(), "    DEP001 (deprecation): package except is deprecated") {
+		t.Errorf("DebugConfig lacks the notice:\n%s", dump.String())
+	}
+}
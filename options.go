@@ -0,0 +1,133 @@
+package catch
+
+// Option adjusts how a single call reports its error. Options can be
+// mixed into the context arguments of Err and Errf, or passed to Set and
+// Check; they never appear in the report's context.
+// Usage: catch.Err(err, catch.AsWarn, catch.NoStack, catch.Code("PAY042"))
+type Option interface {
+	apply(*callOptions)
+}
+
+// callOptions collects the Options given to one call
+type callOptions struct {
+	severity    Severity
+	hasSeverity bool
+	noStack     bool
+	showStack   bool // Forced on by Todo and Unreachable
+	noSource    bool
+	exitNow     bool
+	noExit      bool // Set on reports that must never exit, such as leaks
+	code        string
+	fix         *FixCommand
+	fixed       *bool      // Set once fix was run successfully for this call
+	report      *ErrorInfo // Receives the report once prepared, for HTTPMiddleware
+}
+
+type severityOption Severity
+
+func (o severityOption) apply(opts *callOptions) {
+	opts.severity, opts.hasSeverity = Severity(o), true
+}
+
+type flagOption int
+
+const (
+	flagNoStack flagOption = iota
+	flagNoSource
+	flagExitNow
+)
+
+func (o flagOption) apply(opts *callOptions) {
+	switch o {
+	case flagNoStack:
+		opts.noStack = true
+	case flagNoSource:
+		opts.noSource = true
+	case flagExitNow:
+		opts.exitNow = true
+	}
+}
+
+type codeOption string
+
+func (o codeOption) apply(opts *callOptions) {
+	opts.code = string(o)
+}
+
+var (
+	AsWarn  Option = severityOption(LevelWarn)  // Report as a warning
+	AsError Option = severityOption(LevelError) // Report as an error
+	AsFatal Option = severityOption(LevelFatal) // Report as fatal, exiting afterwards
+
+	NoStack  Option = flagOption(flagNoStack)  // Leave out the stack backtrace
+	NoSource Option = flagOption(flagNoSource) // Leave out the source snippet
+
+	// ExitNow exits after the report whatever the severity and ExitOnError;
+	// DryRunExit still only marks the report
+	ExitNow Option = flagOption(flagExitNow)
+)
+
+// Code forces the error code instead of the classified one
+func Code(code string) Option {
+	return codeOption(code)
+}
+
+// splitOptions separates Options from ordinary context values. The
+// context slice is returned as is when it holds no Options.
+func splitOptions(context []interface{}) (callOptions, []interface{}) {
+	var opts callOptions
+	var rest []interface{}
+	for i, v := range context {
+		opt, ok := v.(Option)
+		if !ok {
+			if rest != nil {
+				rest = append(rest, v)
+			}
+			continue
+		}
+		if rest == nil {
+			rest = append(make([]interface{}, 0, len(context)-1), context[:i]...)
+		}
+		opt.apply(&opts)
+	}
+	if rest == nil {
+		return opts, context
+	}
+	return opts, rest
+}
+
+// collectOptions applies typed Options
+func collectOptions(options []Option) callOptions {
+	var opts callOptions
+	for _, opt := range options {
+		if opt != nil {
+			opt.apply(&opts)
+		}
+	}
+	return opts
+}
+
+// applyTo sets the forced severity and code on info and drops the
+// suppressed sections from it and from config
+func (opts callOptions) applyTo(info *ErrorInfo, config *ErrorConfig) {
+	if opts.hasSeverity {
+		info.Severity = opts.severity
+	}
+	if opts.code != "" {
+		info.ErrorCode = opts.code
+	}
+	if opts.fix != nil {
+		info.Suggestion = opts.fix.suggestion()
+	}
+	if opts.showStack {
+		config.ShowStackTrace = true
+	}
+	if opts.noStack {
+		config.ShowStackTrace = false
+		info.Stack, info.OriginStack = nil, nil
+	}
+	if opts.noSource {
+		config.ShowSourceCode = false
+		info.SourceLines, info.OriginSourceLines = nil, nil
+	}
+}
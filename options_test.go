@@ -0,0 +1,84 @@
+package catch
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCallOptions(t *testing.T) {
+	errBoom := errors.New("boom")
+	tests := []struct {
+		name     string
+		call     func()
+		severity Severity
+		code     string
+		noStack  bool
+		noSource bool
+		exits    bool
+		context  map[string]interface{}
+	}{
+		{name: "AsWarn", call: func() { Err(errBoom, AsWarn) }, severity: LevelWarn},
+		{name: "AsError", call: func() { Err(errBoom, AsError) }, severity: LevelError},
+		{name: "AsFatal", call: func() { Err(errBoom, AsFatal) }, severity: LevelFatal, exits: true},
+		{name: "Code", call: func() { Err(errBoom, Code("PAY042")) }, severity: LevelError, code: "PAY042"},
+		{name: "NoStack", call: func() { Err(errBoom, NoStack) }, severity: LevelError, noStack: true},
+		{name: "NoSource", call: func() { Err(errBoom, NoSource) }, severity: LevelError, noSource: true},
+		{name: "ExitNow", call: func() { Err(errBoom, AsWarn, ExitNow) }, severity: LevelWarn, exits: true},
+		{
+			name:     "combined with context",
+			call:     func() { Err(errBoom, "user", 7, AsWarn, NoStack, Code("PAY042")) },
+			severity: LevelWarn, code: "PAY042", noStack: true,
+			context: map[string]interface{}{"user": 7},
+		},
+		{name: "Errf", call: func() { Errf(errBoom, "loading %s", "cfg", Code("CFG001")) }, severity: LevelError, code: "CFG001"},
+		{name: "Set", call: func() { Catch.Set(errBoom, AsWarn, NoSource) }, severity: LevelWarn, noSource: true},
+		{name: "Check", call: func() { Check(errBoom, Code("PAY042")) }, severity: LevelError, code: "PAY042"},
+		{
+			name:     "ContextualCatcher",
+			call:     func() { Catch.WithContext("order", 12).WithContext("step", "pay").Set(errBoom, AsWarn, Code("PAY042")) },
+			severity: LevelWarn, code: "PAY042",
+			context: map[string]interface{}{"order": 12, "step": "pay"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exits := stubExit(t)
+			r := recordCatch(t, testConfig())
+			tt.call()
+
+			reports := r.reports()
+			if len(reports) != 1 {
+				t.Fatalf("%d reports, want 1", len(reports))
+			}
+			info := reports[0]
+			if info.Severity != tt.severity {
+				t.Errorf("severity %v, want %v", info.Severity, tt.severity)
+			}
+			if tt.code != "" && info.ErrorCode != tt.code {
+				t.Errorf("code %q, want %q", info.ErrorCode, tt.code)
+			}
+			if tt.noStack && info.Stack != nil {
+				t.Error("stack kept under NoStack")
+			}
+			if !tt.noStack && info.Stack == nil {
+				t.Error("stack dropped")
+			}
+			if tt.noSource && info.SourceLines != nil {
+				t.Error("source kept under NoSource")
+			}
+			if got := len(*exits) > 0; got != tt.exits {
+				t.Errorf("exited %v, want %v", got, tt.exits)
+			}
+			for k, v := range info.Context {
+				if _, ok := v.(Option); ok {
+					t.Errorf("option %v in context under %q", v, k)
+				}
+			}
+			for k, want := range tt.context {
+				if info.Context[k] != want {
+					t.Errorf("context %q = %v, want %v", k, info.Context[k], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package catch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// provenanceRank orders context keys by where they came from: keys given
+// with the error first, then scope keys, then inherited ones
+var provenanceRank = map[string]int{"": 0, ProvenanceScope: 1, ProvenanceInherited: 2}
+
+// contextKeys returns the context keys in display order: by provenance,
+// then alphabetically. Pretty, compact and JSON output all use it, so the
+// same report always serializes to the same bytes.
+func contextKeys(info ErrorInfo) []string {
+	keys := make([]string, 0, len(info.Context))
+	for k := range info.Context {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ri, rj := provenanceRank[info.Provenance[keys[i]]], provenanceRank[info.Provenance[keys[j]]]
+		if ri != rj {
+			return ri < rj
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// ContextEntry is one key of a ReportV1 context
+type ContextEntry struct {
+	Key   string
+	Value interface{}
+}
+
+// ContextV1 is the context object of a ReportV1. It is a list rather than
+// a map so the JSON keeps the display order of the pretty report.
+type ContextV1 []ContextEntry
+
+// MarshalJSON encodes the entries as a JSON object in order
+func (c ContextV1) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, entry := range c {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(entry.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(entry.Value)
+		if err != nil {
+			// One value that won't marshal mustn't cost the whole report
+			value, _ = json.Marshal(formatContextValue(entry.Value))
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// UnmarshalJSON decodes a JSON object, keeping its key order
+func (c *ContextV1) UnmarshalJSON(data []byte) error {
+	entries, err := decodeObject(data)
+	if err != nil {
+		return err
+	}
+	*c = (*c)[:0]
+	for _, entry := range entries {
+		var value interface{}
+		if err := json.Unmarshal(entry.raw, &value); err != nil {
+			return err
+		}
+		*c = append(*c, ContextEntry{Key: entry.key, Value: value})
+	}
+	return nil
+}
+
+// Get returns the value of key
+func (c ContextV1) Get(key string) (interface{}, bool) {
+	for _, entry := range c {
+		if entry.Key == key {
+			return entry.Value, true
+		}
+	}
+	return nil, false
+}
+
+// rawEntry is a key of a JSON object with its undecoded value
+type rawEntry struct {
+	key string
+	raw json.RawMessage
+}
+
+// decodeObject splits a JSON object into its entries in document order
+func decodeObject(data []byte) ([]rawEntry, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+	var entries []rawEntry
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		entries = append(entries, rawEntry{key: key, raw: raw})
+	}
+	return entries, nil
+}
+
+// encodeObject joins entries back into a JSON object
+func encodeObject(entries []rawEntry) []byte {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, entry := range entries {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(entry.key)
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(entry.raw)
+	}
+	b.WriteByte('}')
+	return b.Bytes()
+}
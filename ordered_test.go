@@ -0,0 +1,114 @@
+package catch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// busyInfo is a report with many context keys of mixed provenance and
+// nested maps, so any map iteration leaking into the output shows
+func busyInfo() ErrorInfo {
+	info := ErrorInfo{
+		Error:      errors.New("connection refused"),
+		File:       "client.go",
+		Line:       42,
+		Function:   "api.(*Client).Get",
+		ErrorCode:  "NET001",
+		Severity:   LevelError,
+		GroupKey:   "0123456789abcdef",
+		ID:         "01TEST",
+		Time:       time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		Context:    map[string]interface{}{},
+		Provenance: map[string]string{},
+		Stack:      []StackFrame{{Function: "main.a", File: "a.go", Line: 1}, {Function: "main.b", File: "b.go", Line: 2}},
+		SourceLines: []SourceLine{
+			{Number: 41, Content: "func get() {"},
+			{Number: 42, Content: "\treturn c.Get(url)", IsError: true},
+		},
+	}
+	for i := 0; i < 30; i++ {
+		key := fmt.Sprintf("key_%02d", (i*7)%30)
+		info.Context[key] = map[string]int{"z": i, "a": i, "m": i}
+		switch i % 3 {
+		case 1:
+			info.Provenance[key] = ProvenanceScope
+		case 2:
+			info.Provenance[key] = ProvenanceInherited
+		}
+	}
+	return info
+}
+
+func TestMarshalReportIsStable(t *testing.T) {
+	info := busyInfo()
+	first, err := MarshalReport(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		data, err := MarshalReport(info)
+		if err != nil || !bytes.Equal(data, first) {
+			t.Fatalf("marshal %d differs:\n%s\nfirst:\n%s", i, data, first)
+		}
+	}
+
+	// Given keys first, then scope, then inherited; each group sorted; nested
+	// maps rendered with sorted keys
+	given := strings.Index(string(first), `"key_00":"map[a:0 m:0 z:0]"`)
+	scope := strings.Index(string(first), `"key_01":`)
+	inherited := strings.Index(string(first), `"key_02":`)
+	if given < 0 || !(given < scope && scope < inherited) {
+		t.Errorf("context not in provenance order:\n%s", first)
+	}
+}
+
+func TestRenderedReportIsStable(t *testing.T) {
+	info := busyInfo()
+	config := testConfig()
+	first := RenderReport(info, config)
+	compact := renderCompact(info, ErrorConfig{Fields: FieldSelector{Include: []Field{FieldMessage, FieldContext}}})
+	for i := 0; i < 100; i++ {
+		if got := RenderReport(info, config); got != first {
+			t.Fatalf("render %d differs:\n%s\nfirst:\n%s", i, got, first)
+		}
+		if got := renderCompact(info, ErrorConfig{Fields: FieldSelector{Include: []Field{FieldMessage, FieldContext}}}); got != compact {
+			t.Fatalf("compact render %d differs:\n%s\nfirst:\n%s", i, got, compact)
+		}
+	}
+}
+
+func TestGroupKeyAndFingerprintAreStable(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	for i := 0; i < 100; i++ {
+		Err(errors.New("connection refused"), "host", "db-1", "attempt", i, "labels", map[string]string{"b": "2", "a": "1"})
+	}
+	reports := rec.reports()
+	for _, info := range reports[1:] {
+		if info.GroupKey != reports[0].GroupKey || info.StackFingerprint != reports[0].StackFingerprint {
+			t.Fatalf("group key %s, fingerprint %s; first %s, %s",
+				info.GroupKey, info.StackFingerprint, reports[0].GroupKey, reports[0].StackFingerprint)
+		}
+	}
+}
+
+func TestContextV1RoundTripKeepsOrder(t *testing.T) {
+	data := []byte(`{"zeta":1,"alpha":{"y":true,"x":null},"mid":"s"}`)
+	var ctx ContextV1
+	if err := ctx.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(ctx) != 3 || ctx[0].Key != "zeta" || ctx[1].Key != "alpha" || ctx[2].Key != "mid" {
+		t.Fatalf("decoded %v, want document order", ctx)
+	}
+	out, err := ctx.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"zeta":1,"alpha":{"x":null,"y":true},"mid":"s"}`; string(out) != want {
+		t.Errorf("re-encoded %s, want %s", out, want)
+	}
+}
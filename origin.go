@@ -0,0 +1,117 @@
+package catch
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+)
+
+// SourceFocus selects where the source snippet and source analysis of an
+// error carrying its creation stack are taken from
+type SourceFocus string
+
+const (
+	SourceHandled SourceFocus = "handled" // where the error was handled (default)
+	SourceOrigin  SourceFocus = "origin"  // where the error was created
+	SourceBoth    SourceFocus = "both"    // a snippet for each
+)
+
+// originSite returns where err was created, when it carries its creation
+// stack and config looks there
+func originSite(err error, config ErrorConfig) (file string, line int, ok bool) {
+	if config.OriginSource != SourceOrigin && config.OriginSource != SourceBoth {
+		return "", 0, false
+	}
+	frames := originFrames(err, 1)
+	if len(frames) == 0 || frames[0].File == "" {
+		return "", 0, false
+	}
+	return frames[0].File, frames[0].Line, true
+}
+
+// callersProvider matches errors that captured their creation stack as
+// program counters, like those of many error libraries
+type callersProvider interface {
+	Callers() []uintptr
+}
+
+// originStack returns the creation stack recorded by any error in the
+// chain, filtered and capped as config asks
+func originStack(err error, config ErrorConfig) []StackFrame {
+	frames := originFrames(err, 0)
+	if frames == nil {
+		return nil
+	}
+	return filterStack(frames, config)
+}
+
+// originFrames returns up to maxDepth frames (all for 0) of the creation
+// stack recorded by any error in the chain, preferring the innermost.
+// Errors are detected by method shape: Callers() []uintptr, or
+// StackTrace() returning a slice of uintptr-based frames as
+// github.com/pkg/errors does.
+func originFrames(err error, maxDepth int) []runtime.Frame {
+	var pcs []uintptr
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if found := stackPCs(e); len(found) > 0 {
+			pcs = found
+		}
+	}
+	if len(pcs) == 0 {
+		return nil
+	}
+	return framesFromPCs(pcs, maxDepth)
+}
+
+// stackPCs extracts program counters from an error exposing a stack
+func stackPCs(err error) []uintptr {
+	if cp, ok := err.(callersProvider); ok {
+		return cp.Callers()
+	}
+
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil
+	}
+	out := method.Type().Out(0)
+	if out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	trace := method.Call(nil)[0]
+	pcs := make([]uintptr, trace.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(trace.Index(i).Uint())
+	}
+	return pcs
+}
+
+// framesFromPCs resolves program counters into up to maxDepth frames
+// (all for 0)
+func framesFromPCs(pcs []uintptr, maxDepth int) []runtime.Frame {
+	var stack []runtime.Frame
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" || frame.File != "" {
+			stack = append(stack, frame)
+		}
+		if !more || (maxDepth > 0 && len(stack) >= maxDepth) {
+			break
+		}
+	}
+	return stack
+}
+
+// sameStack reports whether two stacks list the same frames
+func sameStack(a, b []StackFrame) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
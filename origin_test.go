@@ -0,0 +1,149 @@
+package catch
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// callersError records its creation stack the way error libraries do,
+// exposing it through Callers
+type callersError struct {
+	msg string
+	pcs []uintptr
+}
+
+func (e *callersError) Error() string      { return e.msg }
+func (e *callersError) Callers() []uintptr { return e.pcs }
+
+// newCallersError creates a callersError whose stack starts at its caller
+func newCallersError(msg string) error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	return &callersError{msg: msg, pcs: pcs[:n]}
+}
+
+// traceFrame and traceStack mirror the StackTrace shape of
+// github.com/pkg/errors without importing it
+type (
+	traceFrame uintptr
+	traceStack []traceFrame
+)
+
+// tracedError exposes its creation stack through StackTrace
+type tracedError struct {
+	msg   string
+	stack traceStack
+}
+
+func (e *tracedError) Error() string          { return e.msg }
+func (e *tracedError) StackTrace() traceStack { return e.stack }
+
+// newTracedError creates a tracedError whose stack starts at its caller
+func newTracedError(msg string) error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	stack := make(traceStack, n)
+	for i, pc := range pcs[:n] {
+		stack[i] = traceFrame(pc)
+	}
+	return &tracedError{msg: msg, stack: stack}
+}
+
+// openStore fails deep in a call path, creating err there
+func openStore(create func(string) error) error {
+	return create("store locked")
+}
+
+func TestOriginStackWins(t *testing.T) {
+	for name, create := range map[string]func(string) error{
+		"Callers":    newCallersError,
+		"StackTrace": newTracedError,
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := recordCatch(t, testConfig())
+
+			err := fmt.Errorf("starting: %w", openStore(create))
+			Err(err)
+
+			info := rec.reports()[0]
+			if len(info.OriginStack) == 0 || info.OriginStack[0].Function != "catch.openStore" {
+				t.Fatalf("origin stack = %+v, want it to start in openStore", info.OriginStack)
+			}
+			if len(info.Stack) == 0 || !strings.HasPrefix(info.Stack[0].Function, "catch.TestOriginStackWins") {
+				t.Errorf("handled-at stack = %+v, want it to start in the test", info.Stack)
+			}
+		})
+	}
+}
+
+func TestOriginStackRenderedWithHandledAt(t *testing.T) {
+	config := testConfig()
+	config.ShowSourceCode = false
+	out := testCatch(t, config)
+
+	Err(openStore(newCallersError))
+	report := out.String()
+	origin := strings.Index(report, "stack backtrace (origin):")
+	handled := strings.Index(report, "stack backtrace (handled at):")
+	if origin < 0 || handled < origin {
+		t.Fatalf("report lacks origin then handled-at stacks:\n%s", report)
+	}
+	if !strings.Contains(report[origin:handled], "catch.openStore") {
+		t.Errorf("origin stack lacks openStore:\n%s", report)
+	}
+}
+
+func TestNoOriginStackForPlainErrors(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	Err(fmt.Errorf("plain"))
+	if got := rec.reports()[0].OriginStack; got != nil {
+		t.Errorf("origin stack = %+v for an error without one", got)
+	}
+}
+
+func TestOriginSourceFocus(t *testing.T) {
+	tests := []struct {
+		focus           SourceFocus
+		origin, handled bool
+	}{
+		{SourceHandled, false, true},
+		{SourceOrigin, true, false},
+		{SourceBoth, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.focus), func(t *testing.T) {
+			config := testConfig()
+			config.OriginSource = tt.focus
+			rec := recordCatch(t, config)
+
+			Err(fmt.Errorf("starting: %w", openStore(newCallersError)))
+
+			info := rec.reports()[0]
+			if got := errorLineContains(info.OriginSourceLines, `create("store locked")`); got != tt.origin {
+				t.Errorf("origin snippet at openStore: %v, want %v (%+v)", got, tt.origin, info.OriginSourceLines)
+			}
+			if got := errorLineContains(info.SourceLines, "Err("); got != tt.handled {
+				t.Errorf("handled snippet at the Err call: %v, want %v", got, tt.handled)
+			}
+			out := RenderSource(info, config)
+			if got := strings.Contains(out, "error originated here"); got != tt.origin {
+				t.Errorf("origin header shown: %v, want %v:\n%s", got, tt.origin, out)
+			}
+			if got := strings.Contains(out, "handled here"); got != (tt.focus == SourceBoth) {
+				t.Errorf("handled header shown: %v:\n%s", got, out)
+			}
+		})
+	}
+}
+
+// errorLineContains reports whether the error line of lines contains s
+func errorLineContains(lines []SourceLine, s string) bool {
+	for _, line := range lines {
+		if line.IsError {
+			return strings.Contains(line.Content, s)
+		}
+	}
+	return false
+}
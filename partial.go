@@ -0,0 +1,93 @@
+package catch
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultPartialListed is the number of failures a Partial report lists
+// when PartialMaxListed is zero
+const DefaultPartialListed = 10
+
+// OperationCounts totals the operations reported through Partial
+type OperationCounts struct {
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+// Partial reports a batch in which failed of total operations failed, as
+// one report with a section per failure, like an errors.Join group, up to
+// PartialMaxListed of them. Partial failure is a warning; when every
+// operation failed it is an error, and only then does ExitOnError apply.
+// Nothing is reported when failed is zero. The counts appear in the
+// report's context and in the run summary. It returns the failures joined
+// with errors.Join, so errors.Is and errors.As match any of them, or nil.
+// Usage: catch.Partial(len(shards), len(errs), errs, "import", run)
+func Partial(total, failed int, errs []error, context ...interface{}) error {
+	return Catch.Partial(total, failed, errs, context...)
+}
+
+// Partial is the package-level Partial reporting through this catcher
+func (e *ErrorCatcher) Partial(total, failed int, errs []error, context ...interface{}) error {
+	if failed <= 0 {
+		e.stats.recordOperations(total, 0)
+		return nil
+	}
+	if total < failed {
+		total = failed
+	}
+	err := errors.Join(errs...)
+	if err == nil {
+		err = fmt.Errorf("%d operation(s) failed", failed)
+	}
+
+	opts, context := splitOptions(context)
+	info := e.buildSmartErrorInfo(err, context...)
+	info.opts = opts
+	info.ErrorCode = "PART001"
+	info.Severity = LevelWarn
+	info.Headline = fmt.Sprintf("partial failure: %d of %d operations failed", failed, total)
+	info.Suggestion = "retry or fix the failed operations listed; the others succeeded"
+	if failed == total {
+		info.Severity = LevelError
+		info.Headline = fmt.Sprintf("all %d operations failed", total)
+		info.Suggestion = "every operation failed, so the cause is likely shared; start with the first failure listed"
+	}
+	info.Context["total"] = total
+	info.Context["failed"] = failed
+	info.Context["succeeded"] = total - failed
+
+	failures := nonNilErrors(errs)
+	max := e.getConfig().PartialMaxListed
+	if max <= 0 {
+		max = DefaultPartialListed
+	}
+	if len(failures) > max {
+		info.joinedOmitted = len(failures) - max
+		failures = failures[:max]
+	}
+	info.Joined = classifyJoined(failures)
+
+	e.stats.recordOperations(total, failed)
+	e.handleError(info)
+	return err
+}
+
+// nonNilErrors returns the non-nil errors in errs
+func nonNilErrors(errs []error) []error {
+	var out []error
+	for _, err := range errs {
+		if err != nil {
+			out = append(out, err)
+		}
+	}
+	return out
+}
+
+// recordOperations adds a batch's counts to the run statistics
+func (s *runStats) recordOperations(total, failed int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.succeeded += total - failed
+	s.failed += failed
+}
@@ -0,0 +1,135 @@
+package catch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// partialCatcher returns a catcher from New writing to the returned buffer
+func partialCatcher(config ErrorConfig) (*ErrorCatcher, *strings.Builder) {
+	var out strings.Builder
+	config.Output = &out
+	config.ShowSourceCode = false
+	config.ShowStackTrace = false
+	return New(config), &out
+}
+
+func TestPartialAllSucceeded(t *testing.T) {
+	c, out := partialCatcher(testConfig())
+
+	if err := c.Partial(10, 0, nil); err != nil {
+		t.Errorf("Partial = %v, want nil", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("report written for a batch without failures:\n%s", out)
+	}
+	if ops := c.Summary().Operations; ops == nil || *ops != (OperationCounts{Succeeded: 10}) {
+		t.Errorf("Operations = %+v, want 10 succeeded", ops)
+	}
+}
+
+func TestPartialSomeFailed(t *testing.T) {
+	codes := stubExit(t)
+	config := testConfig()
+	config.ExitOnError = true
+	c, out := partialCatcher(config)
+	global := testCatch(t, testConfig())
+
+	errs := []error{errors.New("shard 3: connection refused"), errors.New("shard 7: permission denied")}
+	err := c.Partial(10, 2, errs, "run", "nightly")
+
+	if !errors.Is(err, errs[0]) || !errors.Is(err, errs[1]) {
+		t.Errorf("Partial = %v, want both failures joined", err)
+	}
+	for _, want := range []string{
+		"warning[PART001]: partial failure: 2 of 10 operations failed\n",
+		"warning[NET001] 1 of 2: shard 3: connection refused\n",
+		"warning[FS002] 2 of 2: shard 7: permission denied\n",
+		"failed: 2", "succeeded: 8", "run: nightly",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report lacks %q:\n%s", want, out)
+		}
+	}
+	if len(*codes) != 0 {
+		t.Errorf("exited with %v for a partial failure", *codes)
+	}
+	if global.Len() != 0 {
+		t.Errorf("report went to the global Catch:\n%s", global)
+	}
+	if ops := c.Summary().Operations; ops == nil || *ops != (OperationCounts{Succeeded: 8, Failed: 2}) {
+		t.Errorf("Operations = %+v, want 8 succeeded and 2 failed", ops)
+	}
+}
+
+func TestPartialJSONGroupsFailures(t *testing.T) {
+	config := testConfig()
+	config.Format = FormatJSON
+	c, out := partialCatcher(config)
+
+	c.Partial(3, 2, []error{errors.New("connection refused"), errors.New("permission denied")})
+
+	var report ReportV1
+	if err := json.Unmarshal([]byte(out.String()), &report); err != nil {
+		t.Fatalf("report is not JSON: %v\n%s", err, out)
+	}
+	if report.Code != "PART001" || len(report.Errors) != 2 {
+		t.Fatalf("code %s with %d errors, want PART001 with 2", report.Code, len(report.Errors))
+	}
+	if report.Errors[0].Code != "NET001" || report.Errors[1].Code != "FS002" {
+		t.Errorf("errors = %+v, want each failure classified", report.Errors)
+	}
+	for _, entry := range report.Context {
+		if entry.Key == "root_error" {
+			t.Errorf("root_error %v singles out one failure", entry.Value)
+		}
+	}
+}
+
+func TestPartialAllFailedExits(t *testing.T) {
+	codes := stubExit(t)
+	config := testConfig()
+	config.ExitOnError = true
+	c, out := partialCatcher(config)
+
+	c.Partial(2, 2, []error{errors.New("a failed"), errors.New("b failed")})
+	if !strings.Contains(out.String(), "error[PART001]: all 2 operations failed\n") {
+		t.Errorf("report lacks the all-failed header:\n%s", out)
+	}
+	if len(*codes) != 1 {
+		t.Errorf("exit codes = %v, want one exit when every operation failed", *codes)
+	}
+}
+
+func TestPartialMaxListed(t *testing.T) {
+	var errs []error
+	for i := 1; i <= 5; i++ {
+		errs = append(errs, fmt.Errorf("item %d failed", i))
+	}
+
+	config := testConfig()
+	config.PartialMaxListed = 2
+	c, out := partialCatcher(config)
+	c.Partial(20, 5, errs)
+
+	if !strings.Contains(out.String(), "2 of 5: item 2 failed\n") || strings.Contains(out.String(), "item 3 failed") {
+		t.Errorf("report does not stop after two failures:\n%s", out)
+	}
+	if !strings.Contains(out.String(), "  … 3 more\n") {
+		t.Errorf("report lacks the count of failures left out:\n%s", out)
+	}
+
+	config.Format = FormatJSON
+	c, out = partialCatcher(config)
+	c.Partial(20, 5, errs)
+	var report ReportV1
+	if err := json.Unmarshal([]byte(out.String()), &report); err != nil {
+		t.Fatalf("report is not JSON: %v", err)
+	}
+	if len(report.Errors) != 2 || report.ErrorsOmitted != 3 {
+		t.Errorf("%d errors with %d omitted, want 2 and 3", len(report.Errors), report.ErrorsOmitted)
+	}
+}
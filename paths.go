@@ -0,0 +1,138 @@
+package catch
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// PathStyle controls how file paths are displayed in reports
+type PathStyle string
+
+const (
+	PathBase     PathStyle = "base"     // file name only (default)
+	PathRelative PathStyle = "relative" // relative to the working directory at startup
+	PathAbsolute PathStyle = "absolute" // full path
+	PathSmart    PathStyle = "smart"    // shortest suffix unique within the report, see smartPath
+)
+
+// isWindows selects Windows path rules; a variable so the helpers can be
+// exercised for either platform
+var isWindows = runtime.GOOS == "windows"
+
+// homeDir is the user's home directory, resolved once
+var homeDir, _ = os.UserHomeDir()
+
+// startDir is the working directory at startup, empty if it couldn't be
+// determined. Relative paths resolve against it rather than the current
+// directory, so a later os.Chdir, or a working directory that has since
+// been deleted, doesn't change what they refer to.
+var startDir, _ = os.Getwd()
+
+// resolvePath makes a relative path absolute against startDir, leaving it
+// unchanged when startDir is unknown
+func resolvePath(path string) string {
+	if path == "" || filepath.IsAbs(path) || startDir == "" {
+		return path
+	}
+	return filepath.Join(startDir, path)
+}
+
+// displayPath formats a source path for display according to the config
+func displayPath(path string, config ErrorConfig) string {
+	if path == "" {
+		return path
+	}
+	if isWindows {
+		path = filepath.FromSlash(path)
+	}
+
+	switch config.PathStyle {
+	case PathAbsolute:
+	case PathRelative:
+		// Without a known start directory the path is shown in full
+		if startDir != "" {
+			if rel, err := filepath.Rel(startDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+	default:
+		return filepath.Base(path)
+	}
+
+	if config.ShortenHome {
+		path = abbreviateHome(path, homeDir, isWindows)
+	}
+	return path
+}
+
+// displayContextValue shortens the home directory in path-like context
+// values when ShortenHome is on
+func displayContextValue(key string, v interface{}, config ErrorConfig) interface{} {
+	s, ok := v.(string)
+	if !ok || !config.ShortenHome || !isPathKey(key) {
+		return v
+	}
+	return abbreviateHome(s, homeDir, isWindows)
+}
+
+// isPathKey reports whether a context key holds a path by the same
+// heuristics used when naming context values
+func isPathKey(key string) bool {
+	return key == "path" || key == "file" || strings.HasSuffix(key, "_file") || strings.HasSuffix(key, "_path")
+}
+
+// abbreviateHome replaces a leading home directory with ~, or with
+// %USERPROFILE% on Windows where matching ignores case and accepts either
+// separator
+func abbreviateHome(path, home string, windows bool) string {
+	if home == "" {
+		return path
+	}
+	if !hasPathPrefix(path, home, windows) {
+		return path
+	}
+	if windows {
+		return "%USERPROFILE%" + path[len(home):]
+	}
+	return "~" + path[len(home):]
+}
+
+// hasPathPrefix reports whether path lies under prefix, comparing whole
+// path elements. Windows comparisons ignore case and separator style.
+func hasPathPrefix(path, prefix string, windows bool) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+	head := path[:len(prefix)]
+	if windows {
+		norm := func(s string) string { return strings.ToLower(strings.ReplaceAll(s, `\`, "/")) }
+		if norm(head) != norm(prefix) {
+			return false
+		}
+	} else if head != prefix {
+		return false
+	}
+	if len(path) == len(prefix) || strings.HasSuffix(prefix, "/") || strings.HasSuffix(prefix, `\`) {
+		return true
+	}
+	next := path[len(prefix)]
+	return next == '/' || (windows && next == '\\')
+}
+
+// mapSourcePath rewrites a build-time source path through SourcePathMap so
+// sources can be found when the binary was built elsewhere. Relative
+// results, e.g. from -trimpath builds, resolve against startDir.
+func mapSourcePath(path string, pathMap map[string]string) string {
+	best := ""
+	for from := range pathMap {
+		if len(from) > len(best) && hasPathPrefix(path, from, isWindows) {
+			best = from
+		}
+	}
+	if best != "" {
+		path = pathMap[best] + path[len(best):]
+	}
+	return resolvePath(path)
+}
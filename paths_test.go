@@ -0,0 +1,98 @@
+package catch
+
+import "testing"
+
+func TestAbbreviateHome(t *testing.T) {
+	for _, tc := range []struct {
+		path, home string
+		windows    bool
+		want       string
+	}{
+		{"/home/ada/src/app/main.go", "/home/ada", false, "~/src/app/main.go"},
+		{"/home/ada", "/home/ada", false, "~"},
+		{"/home/adam/main.go", "/home/ada", false, "/home/adam/main.go"},
+		{"/srv/app/main.go", "/home/ada", false, "/srv/app/main.go"},
+		{"/home/ada/main.go", "", false, "/home/ada/main.go"},
+		{`C:\Users\Ada\src\app\main.go`, `C:\Users\Ada`, true, `%USERPROFILE%\src\app\main.go`},
+		{`c:\users\ada\src\main.go`, `C:\Users\Ada`, true, `%USERPROFILE%\src\main.go`},
+		{`C:/Users/Ada/src/main.go`, `C:\Users\Ada`, true, `%USERPROFILE%/src/main.go`},
+		{`C:\Users\Adam\main.go`, `C:\Users\Ada`, true, `C:\Users\Adam\main.go`},
+		{`/home/ADA/main.go`, "/home/ada", false, `/home/ADA/main.go`},
+	} {
+		if got := abbreviateHome(tc.path, tc.home, tc.windows); got != tc.want {
+			t.Errorf("abbreviateHome(%q, %q, %v) = %q, want %q", tc.path, tc.home, tc.windows, got, tc.want)
+		}
+	}
+}
+
+func TestHasPathPrefix(t *testing.T) {
+	for _, tc := range []struct {
+		path, prefix string
+		windows      bool
+		want         bool
+	}{
+		{"/build/app/main.go", "/build/app", false, true},
+		{"/build/app/main.go", "/build/app/", false, true},
+		{"/build/application/main.go", "/build/app", false, false},
+		{`/build/app\main.go`, "/build/app", false, false},
+		{`D:\a\app\main.go`, `D:\a\app`, true, true},
+		{`d:\A\APP\main.go`, `D:\a\app`, true, true},
+		{`D:/a/app/main.go`, `D:\a\app`, true, true},
+		{`D:\a\application\main.go`, `D:\a\app`, true, false},
+	} {
+		if got := hasPathPrefix(tc.path, tc.prefix, tc.windows); got != tc.want {
+			t.Errorf("hasPathPrefix(%q, %q, %v) = %v, want %v", tc.path, tc.prefix, tc.windows, got, tc.want)
+		}
+	}
+}
+
+// withWindows switches the path helpers to Windows rules for one test
+func withWindows(t *testing.T, windows bool) {
+	prev := isWindows
+	isWindows = windows
+	t.Cleanup(func() { isWindows = prev })
+}
+
+func TestMapSourcePath(t *testing.T) {
+	pathMap := map[string]string{
+		"/build":          "/local/other",
+		"/build/app":      "/local/app",
+		`D:\a\svc`:        "/local/svc",
+		"/build/appendix": "/local/appendix",
+	}
+	for _, tc := range []struct {
+		path    string
+		windows bool
+		want    string
+	}{
+		{"/build/app/cmd/main.go", false, "/local/app/cmd/main.go"},
+		{"/build/lib/util.go", false, "/local/other/lib/util.go"},
+		{"/elsewhere/main.go", false, "/elsewhere/main.go"},
+		{`d:\A\SVC\main.go`, true, `/local/svc\main.go`},
+		{"/BUILD/app/main.go", false, "/BUILD/app/main.go"},
+	} {
+		withWindows(t, tc.windows)
+		if got := mapSourcePath(tc.path, pathMap); got != tc.want {
+			t.Errorf("mapSourcePath(%q, windows=%v) = %q, want %q", tc.path, tc.windows, got, tc.want)
+		}
+	}
+}
+
+func TestDisplayContextValueShortensPathKeys(t *testing.T) {
+	prev := homeDir
+	homeDir = "/home/ada"
+	t.Cleanup(func() { homeDir = prev })
+	withWindows(t, false)
+	config := ErrorConfig{ShortenHome: true}
+
+	if got := displayContextValue("config_path", "/home/ada/.app.yaml", config); got != "~/.app.yaml" {
+		t.Errorf("config_path = %v, want ~/.app.yaml", got)
+	}
+	if got := displayContextValue("query", "/home/ada/.app.yaml", config); got != "/home/ada/.app.yaml" {
+		t.Errorf("non-path key changed: %v", got)
+	}
+	config.ShortenHome = false
+	if got := displayContextValue("path", "/home/ada/x", config); got != "/home/ada/x" {
+		t.Errorf("shortened with ShortenHome off: %v", got)
+	}
+}
@@ -0,0 +1,91 @@
+package catch
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ContextValue is implemented by context values that render themselves
+// in the context block instead of being formatted with %v
+type ContextValue interface {
+	ContextString() string
+}
+
+// BodyPreview holds the first bytes of a reader for use as error context
+type BodyPreview struct {
+	Data        []byte // Up to limit bytes from the start of the body
+	Truncated   bool   // The body continued past limit
+	ContentType string // Sniffed with http.DetectContentType
+
+	reader io.Reader
+}
+
+// Preview reads at most limit bytes from r (plus one to detect truncation)
+// and returns a context value describing them. The caller must keep using
+// the reader returned by Reader, which replays the consumed bytes.
+// Usage: p := catch.Preview(req.Body, 512); req.Body = io.NopCloser(p.Reader())
+func Preview(r io.Reader, limit int) *BodyPreview {
+	if limit < 0 {
+		limit = 0
+	}
+
+	buf := make([]byte, limit+1)
+	n, _ := io.ReadFull(r, buf)
+	buf = buf[:n]
+
+	p := &BodyPreview{
+		Data:        buf[:min(n, limit)],
+		Truncated:   n > limit,
+		ContentType: http.DetectContentType(buf),
+		reader:      io.MultiReader(bytes.NewReader(buf), r),
+	}
+	return p
+}
+
+// Reader returns a reader yielding the full original body
+func (p *BodyPreview) Reader() io.Reader {
+	return p.reader
+}
+
+// ContextString renders printable previews verbatim and binary ones as hex
+func (p *BodyPreview) ContextString() string {
+	var body string
+	if isPrintable(p.Data) {
+		body = strings.NewReplacer("\n", `\n`, "\r", `\r`).Replace(string(p.Data))
+	} else {
+		body = fmt.Sprintf("% x", p.Data)
+	}
+
+	more := ""
+	if p.Truncated {
+		more = " …"
+	}
+	return fmt.Sprintf("[%s, %d bytes%s] %s%s", p.ContentType, len(p.Data), more, body, more)
+}
+
+// isPrintable reports whether data is valid UTF-8 without control characters
+// other than common whitespace
+func isPrintable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// formatContextValue renders a single context value for display
+func formatContextValue(v interface{}) string {
+	if cv, ok := v.(ContextValue); ok {
+		return cv.ContextString()
+	}
+	return fmt.Sprintf("%v", v)
+}
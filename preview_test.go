@@ -0,0 +1,91 @@
+package catch
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestPreviewTextBody(t *testing.T) {
+	body := `{"user":"ada","items":[1,2,3]}` + "\n" + strings.Repeat("x", 100)
+	p := Preview(strings.NewReader(body), 16)
+
+	if !p.Truncated {
+		t.Error("Truncated = false for a body past the limit")
+	}
+	if got, want := string(p.Data), body[:16]; got != want {
+		t.Errorf("Data = %q, want %q", got, want)
+	}
+	if want := `[text/plain; charset=utf-8, 16 bytes …] {"user":"ada","i …`; p.ContextString() != want {
+		t.Errorf("ContextString = %q, want %q", p.ContextString(), want)
+	}
+	rest, _ := io.ReadAll(p.Reader())
+	if string(rest) != body {
+		t.Errorf("Reader lost data: got %d bytes, want %d", len(rest), len(body))
+	}
+}
+
+func TestPreviewBinaryBody(t *testing.T) {
+	body := []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a, 0x00, 0x00, 0x00, 0x0d}
+	p := Preview(bytes.NewReader(body), 8)
+
+	if p.ContentType != "image/png" {
+		t.Errorf("ContentType = %q, want image/png", p.ContentType)
+	}
+	if want := "[image/png, 8 bytes …] 89 50 4e 47 0d 0a 1a 0a …"; p.ContextString() != want {
+		t.Errorf("ContextString = %q, want %q", p.ContextString(), want)
+	}
+	rest, _ := io.ReadAll(p.Reader())
+	if !bytes.Equal(rest, body) {
+		t.Errorf("Reader = % x, want % x", rest, body)
+	}
+}
+
+func TestPreviewShortBody(t *testing.T) {
+	p := Preview(strings.NewReader("ok\n"), 512)
+
+	if p.Truncated {
+		t.Error("Truncated = true for a body within the limit")
+	}
+	if want := `[text/plain; charset=utf-8, 3 bytes] ok\n`; p.ContextString() != want {
+		t.Errorf("ContextString = %q, want %q", p.ContextString(), want)
+	}
+	rest, _ := io.ReadAll(p.Reader())
+	if string(rest) != "ok\n" {
+		t.Errorf("Reader = %q, want %q", rest, "ok\n")
+	}
+}
+
+// countingReader counts the bytes read from it
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestPreviewReadsNoFurther(t *testing.T) {
+	src := &countingReader{r: strings.NewReader(strings.Repeat("a", 1<<20))}
+	Preview(src, 64)
+	if src.n > 65 {
+		t.Errorf("read %d bytes for a 64-byte preview", src.n)
+	}
+}
+
+func TestPreviewRenderedAsContext(t *testing.T) {
+	config := testConfig()
+	config.ShowSourceCode = false
+	out := testCatch(t, config)
+
+	p := Preview(strings.NewReader("name=ada"), 64)
+	Catch.WithContext("body", p).Set(errors.New("bad form"))
+	if !strings.Contains(out.String(), "body: [text/plain; charset=utf-8, 8 bytes] name=ada\n") {
+		t.Errorf("report lacks the preview:\n%s", out)
+	}
+}
@@ -0,0 +1,111 @@
+package catch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+)
+
+// Prober is the view of the system enrichment steps probe to explain an
+// error: whether a path exists, what a directory holds, a resource limit
+// or what a host resolves to. Enrichments never touch the system except
+// through it, so a fake can stand in for it.
+type Prober interface {
+	Stat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	// Rlimit returns the soft and hard limit of a resource named as by
+	// ulimit, e.g. "nofile" for open files
+	Rlimit(resource string) (soft, hard uint64, err error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// errRlimitUnsupported is returned by RealProber.Rlimit for resources, or
+// on platforms, it cannot read
+var errRlimitUnsupported = errors.New("resource limit not supported")
+
+// RealProber probes the running system. It is the default Prober.
+type RealProber struct{}
+
+func (RealProber) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+func (RealProber) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (RealProber) Rlimit(resource string) (uint64, uint64, error) {
+	return rlimit(resource)
+}
+func (RealProber) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return net.DefaultResolver.LookupHost(ctx, host)
+}
+
+// EnrichFunc is an enrichment step built on probes. Like the built-in
+// ones it runs under EnrichmentBudget and may only add context and
+// change the suggestion of info.
+type EnrichFunc func(info *ErrorInfo, p Prober)
+
+// prober returns the configured Prober or RealProber
+func (config ErrorConfig) prober() Prober {
+	if config.Prober != nil {
+		return config.Prober
+	}
+	return RealProber{}
+}
+
+// maxProbedEntries bounds the directory listing searched for a near match
+const maxProbedEntries = 1000
+
+// enrichMissingPath looks around a path that does not exist: the nearest
+// existing parent directory, and a file there with a similar name
+func enrichMissingPath(info *ErrorInfo, p Prober) {
+	var pe *fs.PathError
+	if !errors.As(info.Error, &pe) || !errors.Is(pe.Err, fs.ErrNotExist) || pe.Path == "" {
+		return
+	}
+
+	dir, base := filepath.Dir(pe.Path), filepath.Base(pe.Path)
+	if _, err := p.Stat(dir); err != nil {
+		for parent := filepath.Dir(dir); parent != dir; dir, parent = parent, filepath.Dir(parent) {
+			if _, err := p.Stat(parent); err == nil {
+				setContext(info, "missing_dir", dir)
+				setContext(info, "existing_parent", parent)
+				return
+			}
+		}
+		return
+	}
+
+	entries, err := p.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	if len(entries) > maxProbedEntries {
+		entries = entries[:maxProbedEntries]
+	}
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	sort.Strings(names)
+	if best := closestCandidate(base, names); best != "" && best != base {
+		match := filepath.Join(dir, best)
+		setContext(info, "did_you_mean", match)
+		info.Suggestion = fmt.Sprintf("%s does not exist, but %s does; check the name for a typo", pe.Path, match)
+	}
+}
+
+// enrichFileLimit adds the open files limit to errors from running out of
+// file descriptors
+func enrichFileLimit(info *ErrorInfo, p Prober) {
+	if !errors.Is(info.Error, syscall.EMFILE) {
+		return
+	}
+	soft, hard, err := p.Rlimit("nofile")
+	if err != nil {
+		return
+	}
+	setContext(info, "open_files_limit", fmt.Sprintf("%d (hard %d)", soft, hard))
+	info.Suggestion = fmt.Sprintf("the process hit its limit of %d open files; close files and connections when done, or raise the limit with ulimit -n (at most %d)", soft, hard)
+}
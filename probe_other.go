@@ -0,0 +1,8 @@
+//go:build !unix
+
+package catch
+
+// rlimit is unsupported without Unix resource limits
+func rlimit(resource string) (uint64, uint64, error) {
+	return 0, 0, errRlimitUnsupported
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package catch
+
+import "syscall"
+
+// rlimits maps the resources Rlimit reads to their syscall numbers
+var rlimits = map[string]int{"nofile": syscall.RLIMIT_NOFILE}
+
+// rlimit reads a resource limit of the process
+func rlimit(resource string) (uint64, uint64, error) {
+	number, ok := rlimits[resource]
+	if !ok {
+		return 0, 0, errRlimitUnsupported
+	}
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(number, &limit); err != nil {
+		return 0, 0, err
+	}
+	return uint64(limit.Cur), uint64(limit.Max), nil
+}
@@ -0,0 +1,38 @@
+package catch
+
+import "strings"
+
+// maxRawErrorBytes is the safety cap on each raw error block
+const maxRawErrorBytes = 64 << 10
+
+// renderRawError renders the ShowRawError block: the untouched Error()
+// text, and the %+v text when ShowVerboseError is on and it differs,
+// fenced so it can be copied exactly
+func renderRawError(info ErrorInfo, config ErrorConfig) string {
+	if !config.ShowRawError || info.Error == nil {
+		return ""
+	}
+	var b strings.Builder
+	msg := safeFormat("%v", info.Error)
+	writeRawBlock(&b, "----- raw error -----", msg)
+	if config.ShowVerboseError {
+		if verbose := safeFormat("%+v", info.Error); verbose != msg {
+			writeRawBlock(&b, "----- raw error (%+v) -----", verbose)
+		}
+	}
+	b.WriteString("----- end raw error -----\n")
+	return b.String()
+}
+
+// writeRawBlock writes a marker line and text, capped at
+// maxRawErrorBytes, leaving the text otherwise as it is
+func writeRawBlock(b *strings.Builder, marker, text string) {
+	b.WriteString(marker + "\n")
+	if len(text) > maxRawErrorBytes {
+		text = text[:maxRawErrorBytes] + "\n[raw error truncated]"
+	}
+	b.WriteString(text)
+	if !strings.HasSuffix(text, "\n") {
+		b.WriteString("\n")
+	}
+}
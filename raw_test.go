@@ -0,0 +1,92 @@
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// rawBlock returns the text between the raw error markers of out
+func rawBlock(t *testing.T, out, marker string) string {
+	t.Helper()
+	_, after, ok := strings.Cut(out, marker+"\n")
+	if !ok {
+		t.Fatalf("no %q block:\n%s", marker, out)
+	}
+	block, _, _ := strings.Cut(after, "\n-----")
+	return block
+}
+
+// verboseError prints more under %+v than its Error text
+type verboseError struct{}
+
+func (verboseError) Error() string { return "short" }
+
+func (e verboseError) Format(s fmt.State, verb rune) {
+	if s.Flag('+') {
+		fmt.Fprint(s, "short\n\x1b[1mdetail\x1b[0m")
+		return
+	}
+	fmt.Fprint(s, e.Error())
+}
+
+func TestRawErrorKeepsExactText(t *testing.T) {
+	const msg = "vendor said: \x1b[31mE_QUOTA\x1b[0m\x00\n\tline two"
+	for _, lowMemory := range []bool{false, true} {
+		t.Run(fmt.Sprintf("LowMemory=%v", lowMemory), func(t *testing.T) {
+			config := testConfig()
+			config.ShowRawError = true
+			config.LowMemory = lowMemory
+			buf := testCatch(t, config)
+
+			Err(errors.New(msg))
+
+			out := buf.String()
+			if got := rawBlock(t, out, "----- raw error -----"); got != msg {
+				t.Errorf("raw block %q, want %q", got, msg)
+			}
+			if !strings.Contains(out, "----- end raw error -----\n") {
+				t.Errorf("no end marker:\n%s", out)
+			}
+			if headline, _, _ := strings.Cut(out, "\n"); !strings.Contains(headline, `\x00`) {
+				t.Errorf("headline not sanitized: %q", headline)
+			}
+		})
+	}
+}
+
+func TestRawErrorVerboseBlock(t *testing.T) {
+	config := testConfig()
+	config.ShowRawError = true
+	config.ShowVerboseError = true
+
+	out := renderRawError(ErrorInfo{Error: verboseError{}}, config)
+	if got := rawBlock(t, out, "----- raw error (%+v) -----"); got != "short\n\x1b[1mdetail\x1b[0m" {
+		t.Errorf("verbose block %q", got)
+	}
+
+	out = renderRawError(ErrorInfo{Error: errors.New("plain")}, config)
+	if strings.Contains(out, "(%+v)") {
+		t.Errorf("verbose block repeated the message:\n%s", out)
+	}
+}
+
+func TestRawErrorIsCapped(t *testing.T) {
+	config := testConfig()
+	config.ShowRawError = true
+	out := renderRawError(ErrorInfo{Error: errors.New(strings.Repeat("x", 2*maxRawErrorBytes))}, config)
+	if len(out) > maxRawErrorBytes+200 || !strings.Contains(out, "[raw error truncated]") {
+		t.Errorf("%d bytes rendered without the truncation note", len(out))
+	}
+}
+
+func TestRawErrorOffByDefault(t *testing.T) {
+	config := testConfig()
+	config.ShowSourceCode = false // The snippet would quote this test
+	buf := testCatch(t, config)
+	Err(errors.New("plain"))
+	if strings.Contains(buf.String(), "----- raw error") {
+		t.Errorf("raw block without ShowRawError:\n%s", buf)
+	}
+}
@@ -0,0 +1,445 @@
+package catch
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// The Render functions below produce the sections of the built-in pretty
+// report, each honoring the colors and toggles in the config. Custom
+// Handlers can combine them with their own output. Their signatures are
+// stable; the exact text they produce may evolve between versions.
+
+// RenderReport renders the full Rust-style report, fitted to
+// MaxReportBytes when a budget is configured, or the simpler form chosen
+// by info.DegradedTo. A level that panics is abandoned for the next one
+// down the Degradation ladder.
+func RenderReport(info ErrorInfo, config ErrorConfig) string {
+	if config.accessible() {
+		config.UseColors = false // Also for handlers that set it themselves
+	}
+	info.DegradedTo = max(info.DegradedTo, info.degraded.get())
+	for ; info.DegradedTo < DegradeMinimal; info.DegradedTo++ {
+		if report, ok := renderAt(info, config); ok {
+			info.degraded.raise(info.DegradedTo)
+			return report
+		}
+	}
+	info.degraded.raise(DegradeMinimal)
+	return renderMinimal(info)
+}
+
+// renderAt renders info at info.DegradedTo, or reports that it panicked
+func renderAt(info ErrorInfo, config ErrorConfig) (report string, ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	switch {
+	case info.DegradedTo != DegradeNone:
+		return cleanOutput(renderDegraded(info, config)), true
+	case config.MaxReportBytes > 0:
+		return cleanOutput(fitReport(info, config, config.MaxReportBytes)), true
+	}
+	return cleanOutput(joinSections(info, config)), true
+}
+
+// joinSections concatenates every section of the report in order
+func joinSections(info ErrorInfo, config ErrorConfig) string {
+	return RenderHeader(info, config) +
+		RenderLocation(info, config) +
+		RenderSource(info, config) +
+		RenderDetails(info, config) +
+		RenderCauses(info, config) +
+		RenderCauseTree(info, config) +
+		RenderJoined(info, config) +
+		RenderContext(info, config) +
+		RenderHelp(info, config) +
+		RenderStack(info, config) +
+		RenderFooter(info, config) +
+		RenderHandlerIssues(info, config)
+}
+
+// RenderHeader renders the "error[CODE]: message" line
+func RenderHeader(info ErrorInfo, config ErrorConfig) string {
+	color := info.Severity.color()
+	if config.UseColors {
+		return fmt.Sprintf("%s%s[%s%s%s]: %s%s%s%s\n",
+			color, info.Severity, Bold, info.ErrorCode, Reset+color, Bold, info.headline(), Reset,
+			wouldExitMarker(info, Magenta+Bold, Reset))
+	}
+	return fmt.Sprintf("%s[%s]: %s%s\n", info.Severity, info.ErrorCode, info.headline(),
+		wouldExitMarker(info, "", ""))
+}
+
+// wouldExitMarker returns the DryRunExit note appended to the header
+func wouldExitMarker(info ErrorInfo, on, off string) string {
+	if info.WouldExit == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" %s(would exit with code %d)%s", on, info.WouldExit, off)
+}
+
+// RenderLocation renders the " --> file:line" arrow
+func RenderLocation(info ErrorInfo, config ErrorConfig) string {
+	location := info.location(config)
+	if config.UseColors {
+		return fmt.Sprintf(" %s-->%s %s\n", Blue+Bold, Reset, location)
+	}
+	return fmt.Sprintf(" --> %s\n", location)
+}
+
+// RenderSource renders the source snippet with the error pointer. With
+// an origin snippet, each is headed by where it was taken from.
+func RenderSource(info ErrorInfo, config ErrorConfig) string {
+	if !config.ShowSourceCode {
+		return ""
+	}
+	if len(info.OriginSourceLines) == 0 {
+		return renderSnippet(info.SourceLines, info.span(), config)
+	}
+
+	origin := fmt.Sprintf("error originated here: %s:%d", info.displayPath(info.OriginFile, config), info.OriginLine)
+	output := renderNote(origin, config) + renderSnippet(info.OriginSourceLines, exprSpan{}, config)
+	if len(info.SourceLines) > 0 {
+		output += renderNote("handled here", config) + renderSnippet(info.SourceLines, info.span(), config)
+	}
+	return output
+}
+
+// renderNote renders a "= note" line heading a section
+func renderNote(note string, config ErrorConfig) string {
+	if config.UseColors {
+		return fmt.Sprintf("  %s=%s %s%s%s\n", Blue+Bold, Reset, Gray, note, Reset)
+	}
+	return fmt.Sprintf("  = %s\n", note)
+}
+
+// renderSnippet renders source lines with carets under the marked
+// expression on the error line. Without a column the caret goes under the
+// first non-blank character; an expression running onto later lines is
+// bracketed instead.
+func renderSnippet(lines []SourceLine, mark exprSpan, config ErrorConfig) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	if mark.EndLine > 0 && mark.Column > 0 {
+		return renderMultilineSnippet(lines, mark, config)
+	}
+	column, span := mark.Column, mark.Width
+
+	var output strings.Builder
+	output.WriteString(lineMarker(config, markBlank) + "  |\n")
+
+	// Calculate padding for line numbers
+	maxLineNum := lines[len(lines)-1].Number
+	padding := len(fmt.Sprintf("%d", maxLineNum))
+
+	for _, sourceLine := range lines {
+		lineNumStr := fmt.Sprintf("%*d", padding, sourceLine.Number)
+
+		if sourceLine.IsError {
+			if config.UseColors {
+				output.WriteString(fmt.Sprintf("%s%s%s |%s %s\n",
+					Red+Bold, lineNumStr, Reset, Reset, sourceLine.Content))
+			} else {
+				output.WriteString(fmt.Sprintf("%s%s | %s\n", lineMarker(config, markError), lineNumStr, sourceLine.Content))
+			}
+
+			// Add error pointer, under the expression when known
+			spaces := strings.Repeat(" ", padding)
+			at := column
+			if at <= 0 {
+				at = len(sourceLine.Content) - len(strings.TrimLeft(sourceLine.Content, " \t")) + 1
+			}
+			indent := caretIndent(sourceLine.Content, at)
+			carets := strings.Repeat("^", max(span, 1))
+			if config.UseColors {
+				output.WriteString(fmt.Sprintf("%s |%s %s%s%s%s\n",
+					spaces, Reset, indent, Red+Bold, carets, Reset))
+			} else {
+				output.WriteString(fmt.Sprintf("%s%s | %s%s\n", lineMarker(config, markBlank), spaces, indent, carets))
+			}
+		} else {
+			if config.UseColors {
+				output.WriteString(fmt.Sprintf("%s%s%s |%s %s%s%s\n",
+					Blue, lineNumStr, Reset, Reset, Gray, sourceLine.Content, Reset))
+			} else {
+				output.WriteString(fmt.Sprintf("%s%s | %s\n", lineMarker(config, markContext), lineNumStr, sourceLine.Content))
+			}
+		}
+	}
+	output.WriteString(lineMarker(config, markBlank) + "  |\n")
+	return output.String()
+}
+
+// caretIndent returns the padding placing a caret under the 1-based
+// column of line, keeping tabs so it lines up in any tab width
+func caretIndent(line string, column int) string {
+	if column <= 1 {
+		return ""
+	}
+	var b strings.Builder
+	for i, r := range line {
+		if i >= column-1 {
+			break
+		}
+		if r == '\t' {
+			b.WriteByte('\t') // Keep tabs so the caret lines up at any tab width
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
+
+// RenderContext renders the "= context:" block
+func RenderContext(info ErrorInfo, config ErrorConfig) string {
+	if len(info.Context) == 0 {
+		return ""
+	}
+
+	var output strings.Builder
+	if config.UseColors {
+		output.WriteString(fmt.Sprintf("  %s=%s %scontext:%s\n", Blue+Bold, Reset, Yellow+Bold, Reset))
+	} else {
+		output.WriteString("  = context:\n")
+	}
+
+	for _, k := range contextKeys(info) {
+		v := displayContextValue(k, info.Context[k], config)
+		var note string
+		if info.Provenance[k] == ProvenanceInherited {
+			note = " (inherited)"
+		}
+		if config.UseColors {
+			output.WriteString(fmt.Sprintf("    %s%s%s: %s%s%s%s\n", Cyan, k, Reset, formatContextValue(v), Gray, note, Reset))
+		} else {
+			output.WriteString(fmt.Sprintf("    %s: %s%s\n", k, formatContextValue(v), note))
+		}
+	}
+	if info.contextOmitted > 0 {
+		output.WriteString(fmt.Sprintf("    … %d more\n", info.contextOmitted))
+	}
+	output.WriteString("\n")
+	return output.String()
+}
+
+// RenderHelp renders the "= help:" suggestion
+func RenderHelp(info ErrorInfo, config ErrorConfig) string {
+	if !config.ShowSuggestions || info.Suggestion == "" {
+		return ""
+	}
+	if config.UseColors {
+		return fmt.Sprintf("  %s=%s %shelp:%s %s\n\n",
+			Blue+Bold, Reset, Green+Bold, Reset, info.Suggestion)
+	}
+	return fmt.Sprintf("  = help: %s\n\n", info.Suggestion)
+}
+
+// RenderStack renders the "= stack backtrace:" block. When the error
+// carries its own creation stack, that origin stack is shown first and the
+// handling stack only if it differs.
+func RenderStack(info ErrorInfo, config ErrorConfig) string {
+	if !config.ShowStackTrace {
+		return ""
+	}
+
+	if len(info.OriginStack) == 0 {
+		return renderFrames("stack backtrace", info.Stack, info.stackOmitted, info, config)
+	}
+	output := renderFrames("stack backtrace (origin)", info.OriginStack, info.originOmitted, info, config)
+	if !sameStack(info.OriginStack, info.Stack) {
+		output += renderFrames("stack backtrace (handled at)", info.Stack, info.stackOmitted, info, config)
+	}
+	return output
+}
+
+// renderFrames renders one titled list of stack frames
+func renderFrames(title string, stack []StackFrame, omitted int, info ErrorInfo, config ErrorConfig) string {
+	if len(stack) == 0 {
+		return ""
+	}
+
+	var output strings.Builder
+	if config.UseColors {
+		output.WriteString(fmt.Sprintf("  %s=%s %s%s:%s\n",
+			Blue+Bold, Reset, Yellow+Bold, title, Reset))
+	} else {
+		output.WriteString(fmt.Sprintf("  = %s:\n", title))
+	}
+
+	i := 0
+	for _, frame := range stack {
+		if frame.Hidden > 0 {
+			if config.UseColors {
+				output.WriteString(fmt.Sprintf("   %s... %d %s hidden ...%s\n", Gray, frame.Hidden, framesWord(frame.Hidden), Reset))
+			} else {
+				output.WriteString(fmt.Sprintf("   ... %d %s hidden ...\n", frame.Hidden, framesWord(frame.Hidden)))
+			}
+			continue
+		}
+		frameFile := info.displayPath(frame.File, config)
+		if config.UseColors {
+			output.WriteString(fmt.Sprintf("   %s%2d:%s %s%s%s\n          at %s%s:%d%s\n",
+				Gray, i, Reset, Bold, frame.Function, Reset,
+				Gray, frameFile, frame.Line, Reset))
+		} else {
+			output.WriteString(fmt.Sprintf("   %2d: %s\n          at %s:%d\n",
+				i, frame.Function, frameFile, frame.Line))
+		}
+		i++
+	}
+	if omitted > 0 {
+		output.WriteString(fmt.Sprintf("   … %d more frames\n", omitted))
+	}
+	output.WriteString("\n")
+	return output.String()
+}
+
+// RenderFooter renders the trailing uptime line of errors and fatal
+// errors; warnings have none
+func RenderFooter(info ErrorInfo, config ErrorConfig) string {
+	if !config.ShowUptime || info.Uptime <= 0 || info.Severity < LevelError {
+		return ""
+	}
+	if config.UseColors {
+		return fmt.Sprintf("  %s=%s %suptime: %s%s\n\n",
+			Blue+Bold, Reset, Gray, formatUptime(info.Uptime), Reset)
+	}
+	return fmt.Sprintf("  = uptime: %s\n\n", formatUptime(info.Uptime))
+}
+
+// defaultHint is the ShowHints note unless HintText replaces it
+const defaultHint = "note: error output controlled by gocatch; call catch.Catch.Configure to adjust it, or set GOCATCH_DEBUG=1 to print the active configuration"
+
+// hintShown makes the hint appear at most once per process
+var hintShown atomic.Bool
+
+// renderHint returns the ShowHints note the first time it is called with
+// hints enabled, and "" afterwards
+func renderHint(config ErrorConfig) string {
+	if !config.ShowHints || !hintShown.CompareAndSwap(false, true) {
+		return ""
+	}
+	hint := config.HintText
+	if hint == "" {
+		hint = defaultHint
+	}
+	if config.UseColors {
+		return Gray + hint + Reset + "\n"
+	}
+	return hint + "\n"
+}
+
+// fitReport drops sections until the report fits in max bytes, in the
+// order of shrinkReport. A trailing note lists what was dropped.
+func fitReport(info ErrorInfo, config ErrorConfig, max int) string {
+	report := joinSections(info, config)
+	if len(report) <= max {
+		return report
+	}
+
+	var note string
+	_, fits := shrinkReport(&info, func(dropped []string) bool {
+		report = joinSections(info, config)
+		note = truncationNote(max, dropped)
+		return len(report)+len(note) <= max
+	})
+
+	// Nothing left to drop: hard-cut what remains
+	if cut := max - len(note); !fits && cut > 0 && cut < len(report) {
+		report = strings.ToValidUTF8(report[:cut], "") + "\n"
+	}
+	return report + note
+}
+
+// shrinkReport drops parts of info until fits accepts it, in order: stack
+// frames from the tail, then origin frames, the details, the source
+// blocks, and context entries beyond the first. fits is given the parts
+// dropped so far; the counts of dropped items are kept on info. It returns
+// the parts dropped and whether info fits in the end.
+func shrinkReport(info *ErrorInfo, fits func(dropped []string) bool) ([]string, bool) {
+	var dropped []string
+
+	if len(info.Stack) > 0 || len(info.OriginStack) > 0 {
+		dropped = append(dropped, "stack")
+		for len(info.Stack) > 0 {
+			info.stackOmitted++
+			info.Stack = info.Stack[:len(info.Stack)-1]
+			if fits(dropped) {
+				return dropped, true
+			}
+		}
+		for len(info.OriginStack) > 0 {
+			info.originOmitted++
+			info.OriginStack = info.OriginStack[:len(info.OriginStack)-1]
+			if fits(dropped) {
+				return dropped, true
+			}
+		}
+	}
+
+	if info.Details != "" {
+		info.Details = ""
+		dropped = append(dropped, "details")
+		if fits(dropped) {
+			return dropped, true
+		}
+	}
+
+	if len(info.SourceLines) > 0 || len(info.OriginSourceLines) > 0 {
+		info.sourceOmitted = len(info.SourceLines) + len(info.OriginSourceLines)
+		info.SourceLines, info.OriginSourceLines = nil, nil
+		dropped = append(dropped, "source")
+		if fits(dropped) {
+			return dropped, true
+		}
+	}
+
+	// Context overflow: keep trimming entries from the end
+	if len(info.Context) > 1 {
+		dropped = append(dropped, "context")
+		keys := contextKeys(*info)
+		trimmed := copyFields(info.Context)
+		info.Context = trimmed
+		for i := len(keys) - 1; i > 0; i-- {
+			delete(trimmed, keys[i])
+			info.contextOmitted++
+			if fits(dropped) {
+				return dropped, true
+			}
+		}
+	}
+	return dropped, false
+}
+
+// truncationNote explains that a report was cut down to fit
+func truncationNote(max int, dropped []string) string {
+	size := fmt.Sprintf("%d bytes", max)
+	if max%1024 == 0 {
+		size = fmt.Sprintf("%d KiB", max/1024)
+	}
+	if len(dropped) == 0 {
+		return fmt.Sprintf("… report truncated to fit %s\n", size)
+	}
+	return fmt.Sprintf("… report truncated to fit %s (dropped: %s)\n", size, strings.Join(dropped, ", "))
+}
+
+// Snippet line markers under Accessible, standing in for the red error
+// line and gray context of a colored snippet
+const (
+	markError   = ">> "
+	markContext = "|  "
+	markBlank   = "   "
+)
+
+// lineMarker returns marker under Accessible, "" otherwise
+func lineMarker(config ErrorConfig, marker string) string {
+	if !config.accessible() {
+		return ""
+	}
+	return marker
+}
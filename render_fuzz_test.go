@@ -0,0 +1,73 @@
+package catch
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func FuzzRender(f *testing.F) {
+	for _, seed := range pathologicalSeeds {
+		f.Add(seed, "path", seed, true)
+		f.Add("disk full", seed, "v", false)
+	}
+	f.Fuzz(func(t *testing.T, msg, key, value string, colors bool) {
+		info := ErrorInfo{
+			Error:    errors.New(msg),
+			File:     "main.go",
+			Line:     12,
+			Function: "main.run",
+			Context:  map[string]interface{}{key: value},
+			Time:     time.Unix(0, 0).UTC(),
+		}
+		info.ErrorCode, info.Suggestion = classify(info.Error)
+		config := testConfig()
+		config.UseColors = colors
+
+		for _, report := range []string{RenderReport(info, config), renderCompact(info, config), renderMinimal(info)} {
+			if !utf8.ValidString(report) {
+				t.Fatalf("invalid UTF-8 in %q", report)
+			}
+			if strings.IndexByte(report, 0) >= 0 {
+				t.Fatalf("NUL in %q", report)
+			}
+		}
+
+		data, err := MarshalReport(info)
+		if err != nil {
+			t.Fatalf("MarshalReport: %v", err)
+		}
+		if !json.Valid(data) {
+			t.Fatalf("invalid JSON %q", data)
+		}
+		back, err := Import(data)
+		if err != nil {
+			t.Fatalf("Import of our own report: %v", err)
+		}
+		if got := back.Error.Error(); utf8.ValidString(msg) && got != msg || !utf8.ValidString(got) {
+			t.Errorf("message %q came back as %q", msg, got)
+		}
+	})
+}
+
+func FuzzStripANSI(f *testing.F) {
+	for _, seed := range pathologicalSeeds {
+		f.Add(seed)
+	}
+	f.Add(Red + "error" + Reset + Bold + Gray)
+	f.Add("\033[\033[0m0m")
+	f.Fuzz(func(t *testing.T, s string) {
+		out := Catch.stripANSI(s)
+		for _, escape := range []string{Reset, Bold, Red, Green, Yellow, Blue, Magenta, Cyan, White, BrightRed, Gray} {
+			if strings.Contains(out, escape) {
+				t.Fatalf("stripANSI(%q) = %q still holds %q", s, out, escape)
+			}
+		}
+		if !strings.Contains(s, "\033") && out != s {
+			t.Errorf("stripANSI changed text without escapes: %q -> %q", s, out)
+		}
+	})
+}
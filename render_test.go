@@ -0,0 +1,264 @@
+package catch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// oversizedInfo is a report with a long stack, a source block and many
+// context entries
+func oversizedInfo() ErrorInfo {
+	info := ErrorInfo{
+		Error:     errors.New("write /var/spool/out.dat: no space left on device"),
+		File:      "/src/app/spool.go",
+		Line:      42,
+		Function:  "spool.Flush",
+		ErrorCode: "FS006",
+		GroupKey:  "0123456789abcdef",
+		Context:   map[string]interface{}{},
+	}
+	for i := 0; i < 40; i++ {
+		info.Stack = append(info.Stack, StackFrame{
+			File:     fmt.Sprintf("/src/app/layer%02d.go", i),
+			Line:     100 + i,
+			Function: fmt.Sprintf("app.layer%02d", i),
+		})
+	}
+	for i := 37; i <= 47; i++ {
+		info.SourceLines = append(info.SourceLines, SourceLine{
+			Number:  i,
+			Content: fmt.Sprintf("\tn, err := w.Write(buf[%d:]) // line %d", i, i),
+			IsError: i == 42,
+		})
+	}
+	for i := 0; i < 30; i++ {
+		info.Context[fmt.Sprintf("key_%02d", i)] = strings.Repeat("v", 20)
+	}
+	return info
+}
+
+func TestFitReportDropOrder(t *testing.T) {
+	config := testConfig()
+	full := RenderReport(oversizedInfo(), config)
+
+	for _, tc := range []struct {
+		max     int
+		dropped string
+		kept    []string
+		gone    []string
+	}{
+		{len(full) - 200, "(dropped: stack)", []string{"stack backtrace:", "more frames", "42 |", "key_29:"}, nil},
+		{1200, "(dropped: stack, source)", []string{"key_29:"}, []string{"42 |", "at layer"}},
+		{600, "(dropped: stack, source, context)", []string{"key_00:", "more\n"}, []string{"key_29:", "42 |"}},
+	} {
+		config.MaxReportBytes = tc.max
+		report := RenderReport(oversizedInfo(), config)
+		if len(report) > tc.max {
+			t.Errorf("max %d: report is %d bytes", tc.max, len(report))
+		}
+		if !strings.HasSuffix(report, tc.dropped+"\n") || !strings.Contains(report, "… report truncated to fit ") {
+			t.Errorf("max %d: report does not end with %q:\n%s", tc.max, tc.dropped, report)
+		}
+		if !strings.HasPrefix(report, "error[FS006]: write /var/spool/out.dat: no space left on device\n") {
+			t.Errorf("max %d: header lost:\n%s", tc.max, report)
+		}
+		for _, want := range tc.kept {
+			if !strings.Contains(report, want) {
+				t.Errorf("max %d: report lacks %q:\n%s", tc.max, want, report)
+			}
+		}
+		for _, bad := range tc.gone {
+			if strings.Contains(report, bad) {
+				t.Errorf("max %d: report still has %q:\n%s", tc.max, bad, report)
+			}
+		}
+	}
+}
+
+func TestFitReportWithinBudgetUnchanged(t *testing.T) {
+	config := testConfig()
+	full := RenderReport(oversizedInfo(), config)
+	config.MaxReportBytes = len(full)
+	if got := RenderReport(oversizedInfo(), config); got != full {
+		t.Errorf("report within budget changed:\n%s", got)
+	}
+}
+
+func TestFitReportJSONStaysValid(t *testing.T) {
+	full, _ := MarshalReport(oversizedInfo())
+
+	for _, max := range []int{len(full) - 100, 1500, 700} {
+		data, err := marshalFitted(oversizedInfo(), FieldSelector{}, max)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) > max {
+			t.Errorf("max %d: JSON is %d bytes", max, len(data))
+		}
+		if err := ValidateReport(data); err != nil {
+			t.Fatalf("max %d: %v\n%s", max, err, data)
+		}
+		var r ReportV1
+		if err := json.Unmarshal(data, &r); err != nil {
+			t.Fatal(err)
+		}
+		if len(r.Stack)+r.StackOmitted != 40 || len(r.Source)+r.SourceOmitted != 11 || len(r.Context)+r.ContextOmitted != 30 {
+			t.Errorf("max %d: kept and omitted counts don't add up: %d+%d frames, %d+%d lines, %d+%d context",
+				max, len(r.Stack), r.StackOmitted, len(r.Source), r.SourceOmitted, len(r.Context), r.ContextOmitted)
+		}
+		if r.StackOmitted == 0 {
+			t.Errorf("max %d: stack not dropped first", max)
+		}
+		if r.SourceOmitted > 0 && len(r.Stack) > 0 || r.ContextOmitted > 0 && len(r.Source) > 0 {
+			t.Errorf("max %d: dropped out of order: %s", max, data)
+		}
+	}
+}
+
+func TestFitReportJSONConsole(t *testing.T) {
+	config := testConfig()
+	config.Format = FormatJSON
+	config.MaxReportBytes = 900
+	out := testCatch(t, config)
+
+	info := oversizedInfo()
+	Catch.handleError(info)
+	line := strings.TrimSuffix(out.String(), "\n")
+	if len(line) > 900 {
+		t.Errorf("JSON line is %d bytes", len(line))
+	}
+	if err := ValidateReport([]byte(line)); err != nil {
+		t.Errorf("%v\n%s", err, line)
+	}
+	if !strings.Contains(line, `"stack_omitted":40`) {
+		t.Errorf("line lacks stack_omitted:\n%s", line)
+	}
+}
+
+// sectionInfo is a small report with every section the custom handler uses
+func sectionInfo() ErrorInfo {
+	return ErrorInfo{
+		Error:     errors.New("open config.yaml: permission denied"),
+		File:      "/src/app/config.go",
+		Line:      12,
+		Function:  "app.load",
+		ErrorCode: "FS002",
+		Severity:  LevelError,
+		Context:   map[string]interface{}{"path": "config.yaml", "user": "ada"},
+		Stack:     []StackFrame{{File: "/src/app/config.go", Line: 12, Function: "app.load"}},
+		SourceLines: []SourceLine{
+			{Number: 11, Content: "func load() error {"},
+			{Number: 12, Content: "\tf, err := os.Open(\"config.yaml\")", IsError: true},
+			{Number: 13, Content: "\tdefer f.Close()"},
+		},
+		Suggestion: "check file permissions",
+	}
+}
+
+// Goldens of the sections of sectionInfo without colors
+const (
+	goldenHeader   = "error[FS002]: open config.yaml: permission denied\n"
+	goldenLocation = " --> config.go:12\n"
+	goldenSource   = "  |\n" +
+		"11 | func load() error {\n" +
+		"12 | \tf, err := os.Open(\"config.yaml\")\n" +
+		"   | \t^\n" +
+		"13 | \tdefer f.Close()\n" +
+		"  |\n"
+	goldenContext = "  = context:\n    path: config.yaml\n    user: ada\n\n"
+	goldenHelp    = "  = help: check file permissions\n\n"
+)
+
+func TestRenderSectionGoldens(t *testing.T) {
+	info, config := sectionInfo(), testConfig()
+	for name, tc := range map[string]struct{ got, want string }{
+		"header":   {RenderHeader(info, config), goldenHeader},
+		"location": {RenderLocation(info, config), goldenLocation},
+		"source":   {RenderSource(info, config), goldenSource},
+		"context":  {RenderContext(info, config), goldenContext},
+		"help":     {RenderHelp(info, config), goldenHelp},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s = %q, want %q", name, tc.got, tc.want)
+		}
+	}
+}
+
+// goldenAccessibleSource is goldenSource under Accessible, the error line
+// marked in text where color would mark it
+const goldenAccessibleSource = "     |\n" +
+	"|  11 | func load() error {\n" +
+	">> 12 | \tf, err := os.Open(\"config.yaml\")\n" +
+	"      | \t^\n" +
+	"|  13 | \tdefer f.Close()\n" +
+	"     |\n"
+
+func TestAccessibleGoldens(t *testing.T) {
+	info, plain := sectionInfo(), testConfig()
+	plain.ShowUptime = false
+	accessible := plain
+	accessible.Accessible = true
+	accessible.Colors = ColorAlways // Accessible wins over forced colors
+	accessible.UseColors = true
+
+	rest := goldenContext + goldenHelp
+	if got := RenderReport(info, plain); !strings.HasPrefix(got, goldenHeader+goldenLocation+goldenSource+rest) {
+		t.Errorf("plain report =\n%s", got)
+	}
+	got := RenderReport(info, accessible)
+	if !strings.HasPrefix(got, goldenHeader+goldenLocation+goldenAccessibleSource+rest) {
+		t.Errorf("accessible report =\n%s\nwant it to start with\n%s", got, goldenHeader+goldenLocation+goldenAccessibleSource+rest)
+	}
+	if strings.Contains(got, "\x1b[") {
+		t.Errorf("accessible report is colored: %q", got)
+	}
+
+	info.Severity = LevelWarn
+	if got := RenderReport(info, accessible); !strings.HasPrefix(got, "warning[FS002]: ") {
+		t.Errorf("accessible warning header = %q", strings.SplitN(got, "\n", 2)[0])
+	}
+}
+
+func TestAccessibleFromEnvironment(t *testing.T) {
+	prev := accessibleEnv
+	accessibleEnv = true // As GOCATCH_ACCESSIBLE=1 sets it
+	t.Cleanup(func() { accessibleEnv = prev })
+
+	if got := RenderSource(sectionInfo(), testConfig()); got != goldenAccessibleSource {
+		t.Errorf("source under GOCATCH_ACCESSIBLE = %q, want %q", got, goldenAccessibleSource)
+	}
+}
+
+func TestCustomHandlerComposesSections(t *testing.T) {
+	var out strings.Builder
+	config := testConfig()
+	config.Handler = HandlerFunc(func(info ErrorInfo, config ErrorConfig) error {
+		out.WriteString(RenderHeader(info, config) +
+			RenderContext(info, config) +
+			RenderHelp(info, config) +
+			RenderLocation(info, config) +
+			RenderSource(info, config))
+		return nil
+	})
+	c := New(config)
+
+	c.handleError(sectionInfo())
+	if want := goldenHeader + goldenContext + goldenHelp + goldenLocation + goldenSource; out.String() != want {
+		t.Errorf("custom report =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestRenderReportJoinsSections(t *testing.T) {
+	info, config := sectionInfo(), testConfig()
+	config.ShowUptime = false
+	report := RenderReport(info, config)
+	if !strings.HasPrefix(report, goldenHeader+goldenLocation+goldenSource+goldenContext+goldenHelp) {
+		t.Errorf("report does not start with the sections in order:\n%s", report)
+	}
+	if !strings.Contains(report, "stack backtrace:") {
+		t.Errorf("report lacks the stack:\n%s", report)
+	}
+}
@@ -0,0 +1,117 @@
+package catch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sleep waits between Retry attempts; replaceable so retries can be
+// observed without waiting
+var sleep = time.Sleep
+
+// Retry calls fn until it succeeds or maxAttempts calls have failed,
+// doubling the delay after each failure. Failed attempts before the last
+// are reported as warnings; the last failure is reported as an error with
+// the time spent across attempts. Reports carry the attempt, max_attempts,
+// retry_in and elapsed context keys. It returns fn's last error.
+// Usage: err := catch.Retry(5, 100*time.Millisecond, func() error { return dial(addr) }, "addr", addr)
+func Retry(maxAttempts int, delay time.Duration, fn func() error, context ...interface{}) error {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	start := now()
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		opts, ctx := splitOptions(context)
+		info := Catch.buildSmartErrorInfo(err, ctx...)
+		info.opts = opts
+		info.Context["attempt"] = attempt
+		info.Context["max_attempts"] = maxAttempts
+		if attempt < maxAttempts {
+			info.Severity = LevelWarn
+			info.Context["retry_in"] = delay
+		} else {
+			info.Context["elapsed"] = now().Sub(start).Round(time.Millisecond)
+		}
+		Catch.handleError(info)
+		if attempt < maxAttempts {
+			sleep(delay)
+			delay *= 2
+		}
+	}
+	return err
+}
+
+// retryFunctionWords mark a calling function as a retry loop
+var retryFunctionWords = []string{"retry", "backoff"}
+
+// enrichRetry adapts the suggestion for errors raised inside a retry
+// loop, known from the attempt and max_attempts context keys or from a
+// function on the stack named like a retry or backoff helper. Earlier
+// attempts get a brief note; the final attempt is pointed at the retry
+// budget and at why the error persists, rather than at timeouts.
+func enrichRetry(info *ErrorInfo) {
+	attempt, hasAttempt := contextInt(info.Context["attempt"])
+	max, hasMax := contextInt(info.Context["max_attempts"])
+	if !hasAttempt || !hasMax {
+		if fn := retryFunction(info); fn != "" {
+			info.Suggestion = "this failed inside " + fn + ", a retry loop; if it fails on every attempt the cause is persistent, so examine it rather than raising timeouts"
+		}
+		return
+	}
+
+	if attempt < max {
+		note := fmt.Sprintf("attempt %d/%d failed; will retry", attempt, max)
+		if delay, ok := info.Context["retry_in"]; ok {
+			note += fmt.Sprintf(" in %v", delay)
+		}
+		info.Suggestion = note
+		return
+	}
+
+	note := fmt.Sprintf("all %d attempts failed", max)
+	if elapsed, ok := info.Context["elapsed"]; ok {
+		note += fmt.Sprintf(" over %v", elapsed)
+	}
+	info.Suggestion = note + "; raise the attempt budget only if the failures are transient, otherwise find out why the error persists"
+}
+
+// retryFunction returns the first function on the stack named like a
+// retry helper, or ""
+func retryFunction(info *ErrorInfo) string {
+	functions := []string{info.Function}
+	for _, frame := range info.Stack {
+		functions = append(functions, frame.Function)
+	}
+	for _, fn := range functions {
+		lower := strings.ToLower(fn)
+		for _, word := range retryFunctionWords {
+			if strings.Contains(lower, word) && !strings.HasPrefix(fn, "catch.") {
+				return fn
+			}
+		}
+	}
+	return ""
+}
+
+// contextInt reads an integer context value given as a number or numeric
+// string
+func contextInt(v interface{}) (int, bool) {
+	switch v := v.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,104 @@
+package catch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubSleep advances clock instead of sleeping, for one test, and
+// returns the delays slept
+func stubSleep(t *testing.T, clock *ManualClock) *[]time.Duration {
+	t.Helper()
+	var delays []time.Duration
+	prev := sleep
+	sleep = func(d time.Duration) {
+		delays = append(delays, d)
+		clock.Advance(d)
+	}
+	t.Cleanup(func() { sleep = prev })
+	return &delays
+}
+
+func TestRetryReportsEachAttempt(t *testing.T) {
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer SetClockForTesting(clock)()
+	delays := stubSleep(t, clock)
+	rec := recordCatch(t, testConfig())
+
+	errBusy := errors.New("server busy")
+	calls := 0
+	err := Retry(3, 100*time.Millisecond, func() error {
+		calls++
+		return errBusy
+	}, "addr", "db:5432")
+
+	if err != errBusy || calls != 3 {
+		t.Fatalf("Retry = %v after %d calls, want the last error after 3", err, calls)
+	}
+	if want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}; len(*delays) != 2 || (*delays)[0] != want[0] || (*delays)[1] != want[1] {
+		t.Errorf("slept %v, want %v", *delays, want)
+	}
+	reports := rec.reports()
+	if len(reports) != 3 {
+		t.Fatalf("%d reports, want 3", len(reports))
+	}
+	for i, info := range reports[:2] {
+		if info.Severity != LevelWarn || info.Context["attempt"] != i+1 || info.Context["max_attempts"] != 3 {
+			t.Errorf("attempt %d: severity %v, context %v", i+1, info.Severity, info.Context)
+		}
+	}
+	if got := reports[1].Suggestion; got != "attempt 2/3 failed; will retry in 200ms" {
+		t.Errorf("second suggestion %q", got)
+	}
+	last := reports[2]
+	if last.Severity != LevelError || last.Context["elapsed"] != 300*time.Millisecond || last.Context["addr"] != "db:5432" {
+		t.Errorf("last report: severity %v, context %v", last.Severity, last.Context)
+	}
+	if !strings.HasPrefix(last.Suggestion, "all 3 attempts failed over 300ms; raise the attempt budget") {
+		t.Errorf("final suggestion %q", last.Suggestion)
+	}
+}
+
+func TestRetryStopsOnSuccess(t *testing.T) {
+	stubSleep(t, NewManualClock(time.Time{}))
+	rec := recordCatch(t, testConfig())
+
+	calls := 0
+	err := Retry(5, time.Millisecond, func() error {
+		if calls++; calls < 2 {
+			return errors.New("flaky")
+		}
+		return nil
+	})
+	if err != nil || calls != 2 || len(rec.reports()) != 1 {
+		t.Errorf("Retry = %v after %d calls with %d reports", err, calls, len(rec.reports()))
+	}
+}
+
+func TestRetrySuggestionFromContext(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+
+	Err(errors.New("i/o timeout"), map[string]interface{}{"attempt": 2, "max_attempts": "4", "retry_in": "1s"})
+	Err(errors.New("i/o timeout"), map[string]interface{}{"attempt": 4, "max_attempts": 4})
+
+	reports := rec.reports()
+	if got := reports[0].Suggestion; got != "attempt 2/4 failed; will retry in 1s" {
+		t.Errorf("early attempt suggestion %q", got)
+	}
+	if got := reports[1].Suggestion; !strings.HasPrefix(got, "all 4 attempts failed;") || strings.Contains(got, "timeout duration") {
+		t.Errorf("final attempt suggestion %q", got)
+	}
+}
+
+func TestRetryFunctionOnStack(t *testing.T) {
+	info := &ErrorInfo{Function: "main.upload", Stack: []StackFrame{{Function: "main.upload"}, {Function: "main.withBackoff"}}}
+	enrichRetry(info)
+	if !strings.Contains(info.Suggestion, "inside main.withBackoff, a retry loop") {
+		t.Errorf("suggestion %q", info.Suggestion)
+	}
+	if fn := retryFunction(&ErrorInfo{Function: "catch.Retry"}); fn != "" {
+		t.Errorf("the package's own Retry taken for a caller's loop: %q", fn)
+	}
+}
@@ -0,0 +1,171 @@
+package catch
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"unsafe"
+)
+
+// sampledSite counts the violations of one AssertSampled call site
+type sampledSite struct {
+	location string // "file.go:42"
+	count    atomic.Uint64
+}
+
+// AssertSampled is Assert for hot loops: every violation is counted per
+// call site, but only the first and every everyN-th produce a report,
+// which includes the running count. Counts appear in Summary. When cond
+// holds the cost is a single branch and nothing is allocated, as args are
+// only read, never kept: a violation formats deep copies of them, which
+// keep their types and so their Error and String methods.
+// Usage: catch.AssertSampled(rec.ID != "", 1000, "record without ID at offset %d", off)
+func AssertSampled(cond bool, everyN int, message string, args ...interface{}) {
+	if cond {
+		return
+	}
+	Catch.assertSampled(everyN, message, detachArgs(args))
+}
+
+// detachArgs copies args into values owned by the heap. Reading them only
+// through reflect keeps args from escaping, so callers needn't allocate
+// the boxes of their arguments on every call.
+func detachArgs(args []interface{}) []interface{} {
+	detached := make([]interface{}, len(args))
+	for i, arg := range args {
+		detached[i] = detachValue(reflect.ValueOf(arg))
+	}
+	return detached
+}
+
+// detachValue copies v as a value of its own dynamic type, so fmt still
+// uses its String and Error methods
+func detachValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	detached := reflect.New(v.Type()).Elem()
+	copyValue(detached, v, 0)
+	return detached.Interface()
+}
+
+// maxDetachDepth bounds how deep copyValue follows nested values
+const maxDetachDepth = 8
+
+// copyValue deep-copies src into dst, a settable value of the same type,
+// reading src only through reflect: pointers lead to new copies of what
+// they point to. Funcs, channels and values nested past maxDetachDepth
+// are left zero.
+func copyValue(dst, src reflect.Value, depth int) {
+	if depth > maxDetachDepth {
+		return
+	}
+	switch src.Kind() {
+	case reflect.Bool:
+		dst.SetBool(src.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		dst.SetInt(src.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		dst.SetUint(src.Uint())
+	case reflect.Float32, reflect.Float64:
+		dst.SetFloat(src.Float())
+	case reflect.Complex64, reflect.Complex128:
+		dst.SetComplex(src.Complex())
+	case reflect.String:
+		dst.SetString(strings.Clone(src.String()))
+	case reflect.Pointer:
+		if !src.IsNil() {
+			elem := reflect.New(src.Type().Elem())
+			copyValue(elem.Elem(), src.Elem(), depth+1)
+			dst.Set(elem)
+		}
+	case reflect.Interface:
+		if !src.IsNil() {
+			elem := reflect.New(src.Elem().Type()).Elem()
+			copyValue(elem, src.Elem(), depth+1)
+			dst.Set(elem)
+		}
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			copyValue(settable(dst.Field(i)), src.Field(i), depth+1)
+		}
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			copyValue(dst.Index(i), src.Index(i), depth+1)
+		}
+	case reflect.Slice:
+		if !src.IsNil() {
+			elems := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+			for i := 0; i < src.Len(); i++ {
+				copyValue(elems.Index(i), src.Index(i), depth+1)
+			}
+			dst.Set(elems)
+		}
+	case reflect.Map:
+		if !src.IsNil() {
+			entries := reflect.MakeMapWithSize(src.Type(), src.Len())
+			for _, k := range src.MapKeys() {
+				key, value := reflect.New(src.Type().Key()).Elem(), reflect.New(src.Type().Elem()).Elem()
+				copyValue(key, k, depth+1)
+				copyValue(value, src.MapIndex(k), depth+1)
+				entries.SetMapIndex(key, value)
+			}
+			dst.Set(entries)
+		}
+	}
+}
+
+// settable returns field, a field of an addressable struct, in a form
+// that can be set even when the field is unexported
+func settable(field reflect.Value) reflect.Value {
+	if field.CanSet() {
+		return field
+	}
+	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+}
+
+// assertSampled counts a violation and reports it when due
+func (e *ErrorCatcher) assertSampled(everyN int, message string, args []interface{}) {
+	frames := reportFrames(1)
+	if len(frames) == 0 {
+		return
+	}
+	location := fmt.Sprintf("%s:%d", filepath.Base(frames[0].File), frames[0].Line)
+	config := e.getConfig()
+	value, evicted := e.keys.GetEvicting(trackedKey{trackedSampled, uint64(frames[0].PC)}, config.MaxTrackedKeys, func() any {
+		return &sampledSite{location: location}
+	})
+	e.closeEvicted(evicted)
+	site := value.(*sampledSite)
+	n := site.count.Add(1)
+	if n != 1 && (everyN <= 0 || n%uint64(everyN) != 0) {
+		silenced.record(silencedEntry{time: now(), reason: SilencedSampled, location: site.location, format: message, args: args}, config)
+		return
+	}
+
+	info := e.buildErrorInfo(fmt.Errorf("assertion failed: "+message, args...))
+	info.Context["violations"] = n
+	if everyN > 0 {
+		info.Context["reported_every"] = everyN
+	}
+	e.handleError(info)
+}
+
+// sampledCounts returns the violation count of each tracked
+// AssertSampled site
+func (e *ErrorCatcher) sampledCounts() map[string]uint64 {
+	var counts map[string]uint64
+	e.keys.Each(func(_ trackedKey, value any) {
+		site, ok := value.(*sampledSite)
+		if !ok {
+			return
+		}
+		if counts == nil {
+			counts = make(map[string]uint64)
+		}
+		counts[site.location] += site.count.Load()
+	})
+	return counts
+}
@@ -0,0 +1,93 @@
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAssertSampledCadence(t *testing.T) {
+	buf := testCatch(t, testConfig())
+	for i := 1; i <= 25; i++ {
+		AssertSampled(false, 10, "bad record %d", i)
+	}
+	site := func() string {
+		for location, n := range Summary().SampledAssertions {
+			if n == 25 && strings.HasPrefix(location, "sampled_test.go:") {
+				return location
+			}
+		}
+		return ""
+	}()
+	if site == "" {
+		t.Fatalf("no site counted 25 violations: %v", Summary().SampledAssertions)
+	}
+
+	out := buf.String()
+	if got := strings.Count(out, "assertion failed"); got != 3 {
+		t.Fatalf("got %d reports, want 3 (violations 1, 10 and 20):\n%s", got, out)
+	}
+	for _, want := range []string{"bad record 1\n", "bad record 10\n", "bad record 20\n", "violations: 20"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("reports lack %q", want)
+		}
+	}
+}
+
+func TestAssertSampledSilencesBetweenReports(t *testing.T) {
+	testCatch(t, testConfig())
+	for i := 1; i <= 3; i++ {
+		AssertSampled(false, 100, "silenced record %d", i)
+	}
+	events := Silenced(1)
+	if len(events) != 1 || events[0].Reason != SilencedSampled || events[0].Message != "silenced record 3" {
+		t.Fatalf("newest silenced event = %+v, want record 3 sampled", events)
+	}
+}
+
+func TestAssertSampledFormatsDetachedArgs(t *testing.T) {
+	type point struct{ X, Y int }
+	type labels map[string][]string
+	wrapped := fmt.Errorf("flush: %w", errors.New("boom"))
+	args := detachArgs([]interface{}{42, "id", 1.5, true, point{1, 2}, &point{3, 4}, labels{"a": {"b"}}, errors.New("boom"), wrapped, 1500 * time.Millisecond, nil})
+	got := fmt.Sprintf("%d %s %v %v %v %v %v %v %v %s %v", args...)
+	want := "42 id 1.5 true {1 2} &{3 4} map[a:[b]] boom flush: boom 1.5s <nil>"
+	if got != want {
+		t.Fatalf("detached args = %q, want %q", got, want)
+	}
+}
+
+func TestAssertSampledKeepsArgMethods(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	AssertSampled(false, 1, "took %s err=%v", 1500*time.Millisecond, errors.New("boom"))
+
+	reports := rec.reports()
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reports))
+	}
+	if got := reports[0].Error.Error(); got != "assertion failed: took 1.5s err=boom" {
+		t.Errorf("message %q, want the Duration and error formatted by their methods", got)
+	}
+}
+
+func TestAssertSampledHoldsWithoutAllocating(t *testing.T) {
+	testCatch(t, testConfig())
+	id, offset := "rec-1", 123456
+	allocs := testing.AllocsPerRun(1000, func() {
+		AssertSampled(id != "", 1000, "record %s without data at offset %d", id, offset)
+	})
+	if allocs != 0 {
+		t.Fatalf("AssertSampled allocated %v times per passing call", allocs)
+	}
+}
+
+func BenchmarkAssertSampledHolds(b *testing.B) {
+	testCatch(b, testConfig())
+	id := "rec-1"
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		AssertSampled(id != "", 1000, "record without ID at offset %d (%s)", i, id)
+	}
+}
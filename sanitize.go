@@ -0,0 +1,17 @@
+package catch
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// cleanOutput makes report text safe to print whatever an error message
+// or context value held: invalid UTF-8 becomes U+FFFD and NUL bytes,
+// which truncate output in terminals and C-based log tools, are shown
+// as \x00. The text is returned as is when there is nothing to clean.
+func cleanOutput(s string) string {
+	if utf8.ValidString(s) && strings.IndexByte(s, 0) < 0 {
+		return s
+	}
+	return strings.ReplaceAll(strings.ToValidUTF8(s, "�"), "\x00", `\x00`)
+}
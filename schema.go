@@ -0,0 +1,339 @@
+package catch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// SchemaV1 identifies version 1 of the JSON report layout. It is bumped
+// only for breaking changes; fields may be added within a version.
+const SchemaV1 = "gocatch/1"
+
+// ReportV1 is the JSON form of a report. The JSON output, the log file and
+// Import all go through this type so they cannot drift apart.
+type ReportV1 struct {
+	Schema       string          `json:"schema"`
+	ID           string          `json:"id"`
+	Time         time.Time       `json:"time"`
+	Severity     string          `json:"severity"`
+	Code         string          `json:"code"`
+	Message      string          `json:"message"`
+	Headline     string          `json:"headline,omitempty"`
+	Causes       []string        `json:"causes,omitempty"` // Wrapped errors, innermost last
+	Errors       []JoinedErrorV1 `json:"errors,omitempty"` // The errors of an errors.Join group
+	CauseTree    *CauseV1        `json:"cause_tree,omitempty"`
+	File         *string         `json:"file"`     // null when the location is unavailable
+	Line         *int            `json:"line"`     // null when the location is unavailable
+	Function     *string         `json:"function"` // null when unknown
+	Suggestion   string          `json:"suggestion,omitempty"`
+	Context      ContextV1       `json:"context,omitempty"`
+	Details      string          `json:"details,omitempty"`
+	Source       []SourceLineV1  `json:"source,omitempty"`
+	OriginSource []SourceLineV1  `json:"origin_source,omitempty"`
+	Stack        []FrameV1       `json:"stack,omitempty"`
+	OriginStack  []FrameV1       `json:"origin_stack,omitempty"`
+	GroupKey     string          `json:"group_key"`
+	Fingerprint  string          `json:"stack_fingerprint,omitempty"`
+	UptimeMS     int64           `json:"uptime_ms"`
+	WouldExit    int             `json:"would_exit,omitempty"` // DryRunExit exit code
+	Catcher      string          `json:"catcher,omitempty"`    // Child catcher a forwarded report came from
+	DegradedTo   string          `json:"degraded_to,omitempty"`
+
+	TimingUS map[string]int64 `json:"timing_us,omitempty"` // Phase durations when timing is enabled
+
+	// Items dropped from the arrays above to fit MaxReportBytes
+	StackOmitted       int `json:"stack_omitted,omitempty"`
+	OriginStackOmitted int `json:"origin_stack_omitted,omitempty"`
+	SourceOmitted      int `json:"source_omitted,omitempty"`
+	ContextOmitted     int `json:"context_omitted,omitempty"`
+	ErrorsOmitted      int `json:"errors_omitted,omitempty"` // Failures past PartialMaxListed
+
+	HandlerErrors []HandlerIssueV1 `json:"handler_errors,omitempty"`
+}
+
+// HandlerIssueV1 is a secondary failure in a ReportV1
+type HandlerIssueV1 struct {
+	Source string `json:"source"`
+	Error  string `json:"error"`
+}
+
+// JoinedErrorV1 is one error of a joined group in a ReportV1
+type JoinedErrorV1 struct {
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// FrameV1 is a stack frame in a ReportV1
+type FrameV1 struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// SourceLineV1 is a source line in a ReportV1
+type SourceLineV1 struct {
+	Number  int    `json:"number"`
+	Content string `json:"content"`
+	IsError bool   `json:"isError"`
+}
+
+// NewReportV1 converts an ErrorInfo to its JSON form
+func NewReportV1(info ErrorInfo) ReportV1 {
+	r := ReportV1{
+		Schema:      SchemaV1,
+		ID:          info.ID,
+		Time:        info.Time,
+		Severity:    info.Severity.String(),
+		Code:        info.ErrorCode,
+		Suggestion:  info.Suggestion,
+		Details:     info.Details,
+		Causes:      info.Causes,
+		Errors:      joinedV1(info.Joined),
+		CauseTree:   causeV1(info.CauseTree),
+		GroupKey:    info.GroupKey,
+		Fingerprint: info.StackFingerprint,
+		UptimeMS:    info.Uptime.Milliseconds(),
+		WouldExit:   info.WouldExit,
+		Catcher:     info.Catcher,
+
+		StackOmitted:       info.stackOmitted,
+		OriginStackOmitted: info.originOmitted,
+		SourceOmitted:      info.sourceOmitted,
+		ContextOmitted:     info.contextOmitted,
+		ErrorsOmitted:      info.joinedOmitted,
+	}
+	if info.DegradedTo != DegradeNone {
+		r.DegradedTo = info.DegradedTo.String()
+	}
+	r.TimingUS = info.timing.microseconds()
+	if info.Error != nil {
+		r.Message = safeFormat("%v", info.Error)
+		if headline := info.headline(); headline != r.Message {
+			r.Headline = headline
+		}
+	}
+	if info.HasLocation() {
+		file, line := info.File, info.Line
+		r.File, r.Line = &file, &line
+	}
+	if info.Function != "" {
+		function := info.Function
+		r.Function = &function
+	}
+	for _, k := range contextKeys(info) {
+		r.Context = append(r.Context, ContextEntry{Key: k, Value: jsonContextValue(info.Context[k])})
+	}
+	r.Source = sourceLinesV1(info.SourceLines)
+	r.OriginSource = sourceLinesV1(info.OriginSourceLines)
+	r.Stack = framesV1(info.Stack)
+	r.OriginStack = framesV1(info.OriginStack)
+	for _, issue := range info.HandlerIssues {
+		r.HandlerErrors = append(r.HandlerErrors, HandlerIssueV1{Source: issue.Source, Error: safeFormat("%v", issue.Err)})
+	}
+	return r
+}
+
+// ErrorInfo converts a ReportV1 back to an ErrorInfo. The error value is
+// rebuilt from the message, so only its text survives.
+func (r ReportV1) ErrorInfo() ErrorInfo {
+	info := ErrorInfo{
+		Error:            errors.New(r.Message),
+		ID:               r.ID,
+		Time:             r.Time,
+		Severity:         parseSeverity(r.Severity),
+		ErrorCode:        r.Code,
+		Headline:         r.Headline,
+		Suggestion:       r.Suggestion,
+		Details:          r.Details,
+		Causes:           r.Causes,
+		Joined:           joinedFromV1(r.Errors),
+		CauseTree:        causeNode(r.CauseTree),
+		GroupKey:         r.GroupKey,
+		StackFingerprint: r.Fingerprint,
+		Uptime:           time.Duration(r.UptimeMS) * time.Millisecond,
+		WouldExit:        r.WouldExit,
+		Catcher:          r.Catcher,
+		DegradedTo:       parseDegradation(r.DegradedTo),
+		Context:          make(map[string]interface{}, len(r.Context)),
+
+		stackOmitted:   r.StackOmitted,
+		originOmitted:  r.OriginStackOmitted,
+		sourceOmitted:  r.SourceOmitted,
+		contextOmitted: r.ContextOmitted,
+		joinedOmitted:  r.ErrorsOmitted,
+	}
+	if r.File != nil {
+		info.File = *r.File
+	}
+	if r.Line != nil {
+		info.Line = *r.Line
+	}
+	if r.Function != nil {
+		info.Function = *r.Function
+	}
+	for _, entry := range r.Context {
+		info.Context[entry.Key] = entry.Value
+	}
+	info.SourceLines = sourceLines(r.Source)
+	info.OriginSourceLines = sourceLines(r.OriginSource)
+	for _, f := range r.Stack {
+		info.Stack = append(info.Stack, StackFrame{Function: f.Function, File: f.File, Line: f.Line})
+	}
+	for _, f := range r.OriginStack {
+		info.OriginStack = append(info.OriginStack, StackFrame{Function: f.Function, File: f.File, Line: f.Line})
+	}
+	for _, issue := range r.HandlerErrors {
+		info.HandlerIssues = append(info.HandlerIssues, HandlerIssue{Source: issue.Source, Err: errors.New(issue.Error)})
+	}
+	return info
+}
+
+// MarshalReport encodes info as a single-line ReportV1 JSON object
+func MarshalReport(info ErrorInfo) ([]byte, error) {
+	return json.Marshal(NewReportV1(info))
+}
+
+// Import decodes a JSON report produced by this package, validating it
+// against its declared schema first
+func Import(raw []byte) (ErrorInfo, error) {
+	if err := ValidateReport(raw); err != nil {
+		return ErrorInfo{}, err
+	}
+	var r ReportV1
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return ErrorInfo{}, err
+	}
+	return r.ErrorInfo(), nil
+}
+
+// ValidateReport checks that raw is a JSON report with the required
+// fields and types for its declared schema version
+func ValidateReport(raw []byte) error {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("invalid report: %w", err)
+	}
+
+	schema, ok := doc["schema"].(string)
+	if !ok {
+		return errors.New("invalid report: missing schema")
+	}
+	if schema != SchemaV1 {
+		return fmt.Errorf("invalid report: unsupported schema %q", schema)
+	}
+
+	for _, key := range []string{"severity", "group_key"} {
+		if _, ok := doc[key].(string); !ok {
+			return fmt.Errorf("invalid report: %q must be a string", key)
+		}
+	}
+	if parseSeverity(doc["severity"].(string)).String() != doc["severity"] {
+		return fmt.Errorf("invalid report: unknown severity %q", doc["severity"])
+	}
+
+	// Other fields may be left out by a FieldSelector but must be well typed
+	for key, want := range map[string]string{"id": "string", "code": "string", "message": "string", "suggestion": "string"} {
+		if v, present := doc[key]; present && jsonType(v) != want {
+			return fmt.Errorf("invalid report: %q must be a %s", key, want)
+		}
+	}
+	for key, want := range map[string]string{"file": "string", "line": "number", "function": "string"} {
+		if v, present := doc[key]; present && v != nil && jsonType(v) != want {
+			return fmt.Errorf("invalid report: %q must be a %s or null", key, want)
+		}
+	}
+	if v, present := doc["context"]; present && jsonType(v) != "object" {
+		return errors.New(`invalid report: "context" must be an object`)
+	}
+	for _, key := range []string{"stack", "origin_stack"} {
+		v, present := doc[key]
+		if !present {
+			continue
+		}
+		frames, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("invalid report: %q must be an array", key)
+		}
+		for i, f := range frames {
+			frame, ok := f.(map[string]interface{})
+			if !ok || jsonType(frame["function"]) != "string" || jsonType(frame["file"]) != "string" || jsonType(frame["line"]) != "number" {
+				return fmt.Errorf("invalid report: %s[%d] must have function, file and line", key, i)
+			}
+		}
+	}
+	return nil
+}
+
+// jsonType names the JSON type of a decoded value
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case nil:
+		return "null"
+	}
+	return "unknown"
+}
+
+// jsonContextValue converts a context value into something that always
+// marshals: JSON scalars stay as they are, everything else is rendered,
+// including NaN and infinities, which JSON has no numbers for
+func jsonContextValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil, bool, string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return v
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return formatContextValue(v)
+		}
+		return v
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return formatContextValue(v)
+		}
+		return v
+	}
+	return formatContextValue(v)
+}
+
+// sourceLinesV1 converts source lines to their JSON form
+func sourceLinesV1(lines []SourceLine) []SourceLineV1 {
+	var out []SourceLineV1
+	for _, line := range lines {
+		out = append(out, SourceLineV1{Number: line.Number, Content: line.Content, IsError: line.IsError})
+	}
+	return out
+}
+
+// sourceLines converts source lines back from their JSON form
+func sourceLines(lines []SourceLineV1) []SourceLine {
+	var out []SourceLine
+	for _, line := range lines {
+		out = append(out, SourceLine{Number: line.Number, Content: line.Content, IsError: line.IsError})
+	}
+	return out
+}
+
+// framesV1 converts stack frames to their JSON form
+func framesV1(stack []StackFrame) []FrameV1 {
+	var frames []FrameV1
+	for _, f := range stack {
+		if f.Hidden > 0 {
+			continue // Placeholders for filtered frames are display only
+		}
+		frames = append(frames, FrameV1{Function: f.Function, File: f.File, Line: f.Line})
+	}
+	return frames
+}
@@ -0,0 +1,138 @@
+package catch
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripInfo is a report using most of the ReportV1 fields
+func roundTripInfo() ErrorInfo {
+	return ErrorInfo{
+		Error:            errors.New("load: open app.yaml: permission denied"),
+		ID:               "00000000000000ff",
+		Time:             time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		Severity:         LevelWarn,
+		ErrorCode:        "FS002",
+		Headline:         "load",
+		Causes:           []string{"open app.yaml", "permission denied"},
+		Suggestion:       "check file permissions",
+		File:             "/src/app/load.go",
+		Line:             17,
+		Function:         "app.load",
+		Context:          map[string]interface{}{"path": "app.yaml", "attempt": float64(2), "dry_run": true},
+		Stack:            []StackFrame{{File: "/src/app/load.go", Line: 17, Function: "app.load"}, {File: "/src/app/main.go", Line: 9, Function: "main.main"}},
+		SourceLines:      []SourceLine{{Number: 17, Content: "\tf, err := os.Open(path)", IsError: true}},
+		GroupKey:         "0123456789abcdef",
+		StackFingerprint: "fedcba9876543210",
+		Uptime:           1500 * time.Millisecond,
+		WouldExit:        1,
+		Catcher:          "db",
+	}
+}
+
+func TestReportV1RoundTrip(t *testing.T) {
+	want := roundTripInfo()
+	data, err := MarshalReport(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Import(data)
+	if err != nil {
+		t.Fatalf("Import: %v\n%s", err, data)
+	}
+
+	msg := got.Error.Error()
+	if msg != want.Error.Error() {
+		t.Errorf("message = %q, want %q", msg, want.Error)
+	}
+	got.Error, want.Error = nil, nil
+	got.Provenance = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip changed the report:\n got %+v\nwant %+v", got, want)
+	}
+
+	got.Error = errors.New(msg)
+	if again, _ := MarshalReport(got); string(again) != string(data) {
+		t.Errorf("second encoding differs:\n%s\n%s", again, data)
+	}
+}
+
+func TestValidateReportRejectsMangledPayloads(t *testing.T) {
+	valid, _ := MarshalReport(roundTripInfo())
+	if err := ValidateReport(valid); err != nil {
+		t.Fatalf("valid report rejected: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		mangle func(map[string]interface{})
+		want   string
+	}{
+		{"no schema", func(d map[string]interface{}) { delete(d, "schema") }, "missing schema"},
+		{"future schema", func(d map[string]interface{}) { d["schema"] = "gocatch/v9" }, "unsupported schema"},
+		{"numeric severity", func(d map[string]interface{}) { d["severity"] = 2 }, `"severity" must be a string`},
+		{"unknown severity", func(d map[string]interface{}) { d["severity"] = "catastrophic" }, "unknown severity"},
+		{"no group key", func(d map[string]interface{}) { delete(d, "group_key") }, `"group_key" must be a string`},
+		{"line as string", func(d map[string]interface{}) { d["line"] = "17" }, `"line" must be a number or null`},
+		{"message as number", func(d map[string]interface{}) { d["message"] = 5 }, `"message" must be a string`},
+		{"context as array", func(d map[string]interface{}) { d["context"] = []interface{}{"a"} }, `"context" must be an object`},
+		{"stack as object", func(d map[string]interface{}) { d["stack"] = map[string]interface{}{} }, `"stack" must be an array`},
+		{"frame without file", func(d map[string]interface{}) {
+			d["stack"].([]interface{})[1].(map[string]interface{})["file"] = nil
+		}, "stack[1] must have function, file and line"},
+	} {
+		var doc map[string]interface{}
+		json.Unmarshal(valid, &doc)
+		tc.mangle(doc)
+		raw, _ := json.Marshal(doc)
+
+		err := ValidateReport(raw)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err = %v, want %q", tc.name, err, tc.want)
+		}
+		if _, err := Import(raw); err == nil {
+			t.Errorf("%s: Import accepted the payload", tc.name)
+		}
+	}
+
+	if err := ValidateReport([]byte(`{"schema":`)); err == nil || !strings.Contains(err.Error(), "invalid report") {
+		t.Errorf("truncated JSON: err = %v", err)
+	}
+}
+
+func TestValidateReportAcceptsNullLocationAndSelectedFields(t *testing.T) {
+	info := roundTripInfo()
+	info.File, info.Line, info.Function = "", 0, ""
+	data, _ := MarshalReport(info)
+	if err := ValidateReport(data); err != nil {
+		t.Errorf("report without a location rejected: %v\n%s", err, data)
+	}
+
+	data, _ = MarshalReportFields(roundTripInfo(), FieldSelector{Include: []Field{FieldMessage}})
+	if err := ValidateReport(data); err != nil {
+		t.Errorf("report restricted to the message rejected: %v\n%s", err, data)
+	}
+}
+
+func TestReportV1NonFiniteContext(t *testing.T) {
+	info := roundTripInfo()
+	info.Context = map[string]interface{}{"ratio": math.NaN(), "limit": math.Inf(1), "scale": float32(math.Inf(-1))}
+	data, err := MarshalReport(info)
+	if err != nil {
+		t.Fatalf("MarshalReport: %v", err)
+	}
+	got, err := Import(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"ratio": "NaN", "limit": "+Inf", "scale": "-Inf"} {
+		if got.Context[key] != want {
+			t.Errorf("context[%s] = %v, want %q", key, got.Context[key], want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package catch
+
+import (
+	"bytes"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// Context provenance labels recorded in ErrorInfo.Provenance
+const (
+	ProvenanceScope     = "scope"     // from an Activate scope on the same goroutine
+	ProvenanceInherited = "inherited" // captured from the goroutine that spawned this one
+)
+
+// scopeRegistry tracks activated contextual fields per goroutine. An
+// activated scope is removed when its deactivate function is called, or
+// once that function is garbage collected without being called, so the
+// scopes of goroutines that exited without deactivating don't pile up.
+type scopeRegistry struct {
+	mu        sync.Mutex
+	active    map[uint64][]*scopeEntry
+	inherited map[uint64]map[string]interface{}
+	size      atomic.Int64 // goroutines with scopes, for a cheap empty check
+}
+
+type scopeEntry struct {
+	fields map[string]interface{}
+}
+
+// scopeHandle is what a deactivate function holds on to; its cleanup ends
+// the scope if the function is dropped
+type scopeHandle struct {
+	once sync.Once
+	key  scopeKey
+}
+
+// scopeKey identifies an activated scope in the registry
+type scopeKey struct {
+	gid   uint64
+	entry *scopeEntry
+}
+
+var scopes = &scopeRegistry{
+	active:    make(map[uint64][]*scopeEntry),
+	inherited: make(map[uint64]map[string]interface{}),
+}
+
+// Activate makes the catcher's context apply to every error handled on the
+// current goroutine, and to goroutines started from it with Go or Bind,
+// until the returned function is called. A scope whose deactivate
+// function is dropped without being called ends once the function is
+// garbage collected.
+// Usage: defer catch.Catch.WithContext("request_id", id).Activate()()
+func (c *ContextualCatcher) Activate() (deactivate func()) {
+	key := scopeKey{gid: goroutineID(), entry: &scopeEntry{fields: copyFields(c.context)}}
+
+	scopes.mu.Lock()
+	if len(scopes.active[key.gid]) == 0 {
+		scopes.size.Add(1)
+	}
+	scopes.active[key.gid] = append(scopes.active[key.gid], key.entry)
+	scopes.mu.Unlock()
+
+	handle := &scopeHandle{key: key}
+	runtime.AddCleanup(handle, scopes.remove, key)
+	return func() {
+		handle.once.Do(func() { scopes.remove(handle.key) })
+	}
+}
+
+// remove ends an activated scope; it does nothing for one already ended
+func (r *scopeRegistry) remove(key scopeKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := r.active[key.gid]
+	i := slices.Index(list, key.entry)
+	if i < 0 {
+		return
+	}
+	list = slices.Delete(list, i, i+1)
+	if len(list) == 0 {
+		delete(r.active, key.gid)
+		r.size.Add(-1)
+	} else {
+		r.active[key.gid] = list
+	}
+}
+
+// Bind returns fn wrapped so that, wherever it runs, errors handled inside
+// it inherit the fields active on the current goroutine plus the catcher's
+// own context
+// Usage: go cc.Bind(worker)()
+func (c *ContextualCatcher) Bind(fn func()) func() {
+	fields := activeFields(goroutineID())
+	for k, v := range c.context {
+		fields[k] = v
+	}
+	return func() { runInherited(fields, fn) }
+}
+
+// Go starts fn on a new goroutine that inherits the caller's activated
+// context; a panic in fn is reported instead of crashing with raw output
+// Usage: catch.Go(func() { process(job) })
+func Go(fn func()) {
+	fields := activeFields(goroutineID())
+	go runInherited(fields, func() {
+		defer Recover()(nil)
+		fn()
+	})
+}
+
+// runInherited runs fn with fields registered as inherited context
+func runInherited(fields map[string]interface{}, fn func()) {
+	if len(fields) == 0 {
+		fn()
+		return
+	}
+
+	gid := goroutineID()
+	scopes.mu.Lock()
+	prev, hadPrev := scopes.inherited[gid]
+	if !hadPrev {
+		scopes.size.Add(1)
+	}
+	scopes.inherited[gid] = fields
+	scopes.mu.Unlock()
+
+	defer func() {
+		scopes.mu.Lock()
+		defer scopes.mu.Unlock()
+		if hadPrev {
+			scopes.inherited[gid] = prev
+		} else {
+			delete(scopes.inherited, gid)
+			scopes.size.Add(-1)
+		}
+	}()
+	fn()
+}
+
+// activeFields returns the inherited and activated fields of a goroutine,
+// later scopes overriding earlier ones
+func activeFields(gid uint64) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if scopes.size.Load() == 0 {
+		return fields
+	}
+
+	scopes.mu.Lock()
+	defer scopes.mu.Unlock()
+	for k, v := range scopes.inherited[gid] {
+		fields[k] = v
+	}
+	for _, entry := range scopes.active[gid] {
+		for k, v := range entry.fields {
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+// applyScopes merges the current goroutine's scoped context into info
+// without overriding explicit context
+func applyScopes(info *ErrorInfo) {
+	if scopes.size.Load() == 0 {
+		return
+	}
+
+	gid := goroutineID()
+	scopes.mu.Lock()
+	defer scopes.mu.Unlock()
+
+	add := func(fields map[string]interface{}, provenance string) {
+		for k, v := range fields {
+			if _, exists := info.Context[k]; exists {
+				continue
+			}
+			info.Context[k] = v
+			if info.Provenance == nil {
+				info.Provenance = make(map[string]string)
+			}
+			info.Provenance[k] = provenance
+		}
+	}
+	list := scopes.active[gid]
+	for i := len(list) - 1; i >= 0; i-- {
+		add(list[i].fields, ProvenanceScope)
+	}
+	add(scopes.inherited[gid], ProvenanceInherited)
+}
+
+// copyFields returns a shallow copy of a context map
+func copyFields(m map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// goroutineID parses the current goroutine's ID from its stack header
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	id, _ := parseGoroutineHeader(buf[:n])
+	return id
+}
+
+// parseGoroutineHeader parses the ID from a stack header line:
+// "goroutine 7 [running]:"
+func parseGoroutineHeader(line []byte) (uint64, bool) {
+	field, ok := bytes.CutPrefix(line, []byte("goroutine "))
+	if !ok {
+		return 0, false
+	}
+	if i := bytes.IndexByte(field, ' '); i > 0 {
+		field = field[:i]
+	}
+	id, err := strconv.ParseUint(string(field), 10, 64)
+	return id, err == nil
+}
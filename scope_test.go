@@ -0,0 +1,108 @@
+package catch
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scopedReport reports err on the current goroutine and returns the
+// context and provenance it got
+func scopedReport(t *testing.T) ErrorInfo {
+	t.Helper()
+	info := Catch.buildErrorInfo(errors.New("boom"))
+	applyScopes(&info)
+	return info
+}
+
+func TestActivateScopesContextToGoroutine(t *testing.T) {
+	deactivate := Catch.WithContext("request_id", "r-1").Activate()
+	info := scopedReport(t)
+	if info.Context["request_id"] != "r-1" || info.Provenance["request_id"] != ProvenanceScope {
+		t.Errorf("context %v, provenance %v", info.Context, info.Provenance)
+	}
+
+	done := make(chan ErrorInfo)
+	go func() { done <- scopedReport(t) }()
+	if other := <-done; other.Context["request_id"] != nil {
+		t.Errorf("scope leaked to another goroutine: %v", other.Context)
+	}
+
+	deactivate()
+	deactivate() // Idempotent
+	if info := scopedReport(t); info.Context["request_id"] != nil {
+		t.Errorf("scope still active: %v", info.Context)
+	}
+}
+
+func TestGoInheritsActivatedContext(t *testing.T) {
+	defer Catch.WithContext("job", 7).Activate()()
+	done := make(chan ErrorInfo)
+	Go(func() { done <- scopedReport(t) })
+	info := <-done
+	if info.Context["job"] != 7 || info.Provenance["job"] != ProvenanceInherited {
+		t.Errorf("context %v, provenance %v", info.Context, info.Provenance)
+	}
+
+	bound := Catch.WithContext("step", "load").Bind(func() { done <- scopedReport(t) })
+	go bound()
+	info = <-done
+	if info.Context["job"] != 7 || info.Context["step"] != "load" {
+		t.Errorf("Bind context %v", info.Context)
+	}
+}
+
+func TestDroppedScopesEndWhenCollected(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Catch.WithContext("leaked", i).Activate() // Never deactivated
+		}()
+	}
+	wg.Wait()
+	defer Catch.WithContext("kept", true).Activate()()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		runtime.GC()
+		scopes.mu.Lock()
+		n := len(scopes.active)
+		scopes.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines hold scopes, want only this one", n)
+		}
+		time.Sleep(time.Millisecond) // Cleanups run after the collection
+	}
+	if info := scopedReport(t); info.Context["kept"] != true {
+		t.Errorf("collection ended a live scope: %v", info.Context)
+	}
+}
+
+func TestDeactivateAfterCleanupIsHarmless(t *testing.T) {
+	deactivate := Catch.WithContext("twice", true).Activate()
+	gid := goroutineID()
+	scopes.mu.Lock()
+	entry := scopes.active[gid][0]
+	scopes.mu.Unlock()
+	scopes.remove(scopeKey{gid, entry}) // As the cleanup would
+	deactivate()
+	if info := scopedReport(t); info.Context["twice"] != nil {
+		t.Errorf("scope still active: %v", info.Context)
+	}
+}
+
+func TestParseGoroutineHeader(t *testing.T) {
+	if id, ok := parseGoroutineHeader([]byte("goroutine 42 [running]:")); !ok || id != 42 {
+		t.Errorf("got %d, %t", id, ok)
+	}
+	if _, ok := parseGoroutineHeader([]byte("created by main.main in goroutine 1")); ok {
+		t.Error("parsed a frame line")
+	}
+}
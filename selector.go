@@ -0,0 +1,149 @@
+package catch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Field names a part of a report for FieldSelector
+type Field string
+
+// Fields understood by FieldSelector. Schema, severity, time, group_key,
+// stack_fingerprint and uptime_ms are always present in JSON output.
+const (
+	FieldMessage    Field = "message"    // message and headline
+	FieldCode       Field = "code"       // error code
+	FieldLocation   Field = "location"   // file and line
+	FieldFunction   Field = "function"   // reporting function
+	FieldContext    Field = "context"    // context map
+	FieldStack      Field = "stack"      // stack and origin stack
+	FieldSource     Field = "source"     // source snippet
+	FieldSuggestion Field = "suggestion" // help text
+	FieldID         Field = "id"         // occurrence ID
+)
+
+// jsonKeys maps each Field to the ReportV1 keys it controls
+var jsonKeys = map[Field][]string{
+	FieldMessage:    {"message", "headline", "causes", "errors", "errors_omitted", "cause_tree"},
+	FieldCode:       {"code"},
+	FieldLocation:   {"file", "line"},
+	FieldFunction:   {"function"},
+	FieldContext:    {"context", "context_omitted"},
+	FieldStack:      {"stack", "origin_stack", "stack_omitted", "origin_stack_omitted"},
+	FieldSource:     {"source", "origin_source", "source_omitted"},
+	FieldSuggestion: {"suggestion"},
+	FieldID:         {"id"},
+}
+
+// FieldSelector chooses which fields the JSON, LogJSONL and compact formats emit.
+// A non-empty Include lists the only fields kept; Exclude then removes
+// fields. Excluded fields are omitted entirely rather than left empty.
+// The pretty format keeps using the Show* booleans.
+type FieldSelector struct {
+	Include []Field
+	Exclude []Field
+}
+
+// Has reports whether f is selected, given its default when Include is empty
+func (s FieldSelector) Has(f Field, dflt bool) bool {
+	selected := dflt
+	if len(s.Include) > 0 {
+		selected = containsField(s.Include, f)
+	}
+	return selected && !containsField(s.Exclude, f)
+}
+
+// isZero reports whether the selector changes nothing
+func (s FieldSelector) isZero() bool {
+	return len(s.Include) == 0 && len(s.Exclude) == 0
+}
+
+func containsField(fields []Field, f Field) bool {
+	for _, field := range fields {
+		if field == f {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalReportFields encodes info as a ReportV1 JSON object restricted to
+// the selected fields
+func MarshalReportFields(info ErrorInfo, sel FieldSelector) ([]byte, error) {
+	data, err := MarshalReport(info)
+	if err != nil || sel.isZero() {
+		return data, err
+	}
+
+	drop := make(map[string]bool)
+	for field, keys := range jsonKeys {
+		if !sel.Has(field, true) {
+			for _, key := range keys {
+				drop[key] = true
+			}
+		}
+	}
+	entries, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+	kept := entries[:0]
+	for _, entry := range entries {
+		if !drop[entry.key] {
+			kept = append(kept, entry)
+		}
+	}
+	return encodeObject(kept), nil
+}
+
+// marshalFitted is MarshalReportFields, dropping parts of the report in
+// the order of shrinkReport until it fits in max bytes. The JSON stays
+// valid: what was dropped is counted in the *_omitted keys. A report
+// that cannot be made to fit is written as small as it got.
+func marshalFitted(info ErrorInfo, sel FieldSelector, max int) ([]byte, error) {
+	data, err := MarshalReportFields(info, sel)
+	if err != nil || max <= 0 || len(data) <= max {
+		return data, err
+	}
+	shrinkReport(&info, func([]string) bool {
+		data, err = MarshalReportFields(info, sel)
+		return err != nil || len(data) <= max
+	})
+	return data, err
+}
+
+// renderCompact renders an error as a single line with the fields chosen
+// by config.Fields (by default code, message and location)
+func renderCompact(info ErrorInfo, config ErrorConfig) string {
+	sel := config.Fields
+	var b strings.Builder
+
+	b.WriteString(info.Severity.String())
+	if sel.Has(FieldCode, true) {
+		b.WriteString("[" + info.ErrorCode + "]")
+	}
+	if sel.Has(FieldMessage, true) {
+		b.WriteString(": " + info.chainMessage())
+	}
+	if sel.Has(FieldLocation, true) {
+		b.WriteString(" (" + info.location(config) + ")")
+	}
+	if sel.Has(FieldFunction, false) && info.Function != "" {
+		b.WriteString(" in " + info.Function)
+	}
+	b.WriteString(" group=" + info.GroupKey)
+	b.WriteString(wouldExitMarker(info, "", ""))
+	if sel.Has(FieldID, false) {
+		b.WriteString(" id=" + info.ID)
+	}
+	if sel.Has(FieldContext, false) {
+		for _, k := range contextKeys(info) {
+			b.WriteString(fmt.Sprintf(" %s=%s", k, formatContextValue(info.Context[k])))
+		}
+	}
+	if sel.Has(FieldSuggestion, false) && info.Suggestion != "" {
+		b.WriteString(" help: " + info.Suggestion)
+	}
+	b.WriteString("\n")
+	return cleanOutput(b.String())
+}
@@ -0,0 +1,109 @@
+package catch
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// reportKeys returns the sorted top-level keys of a JSON report
+func reportKeys(t *testing.T, data []byte) []string {
+	t.Helper()
+	entries, err := decodeObject(bytes.TrimSpace(data))
+	if err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+	keys := make([]string, len(entries))
+	for i, entry := range entries {
+		keys[i] = entry.key
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestFieldSelectorJSONKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		sel  FieldSelector
+		want []string
+	}{
+		{"code message id", FieldSelector{Include: []Field{FieldCode, FieldMessage, FieldID}},
+			[]string{"code", "group_key", "id", "message", "schema", "severity", "stack_fingerprint", "time", "uptime_ms"}},
+		{"no source or stack", FieldSelector{Exclude: []Field{FieldSource, FieldStack}},
+			[]string{"code", "context", "file", "function", "group_key", "id", "line", "message",
+				"schema", "severity", "stack_fingerprint", "suggestion", "time", "uptime_ms"}},
+		{"include minus exclude", FieldSelector{Include: []Field{FieldLocation, FieldContext}, Exclude: []Field{FieldContext}},
+			[]string{"file", "group_key", "line", "schema", "severity", "stack_fingerprint", "time", "uptime_ms"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logPath := filepath.Join(t.TempDir(), "app.jsonl")
+			config := testConfig()
+			config.Format = FormatJSON
+			config.Fields = tc.sel
+			config.LogToFile = logPath
+			config.LogFormat = LogJSONL
+			out := testCatch(t, config)
+
+			Catch.WithContext("host", "db-1").Set(errors.New("connection refused"))
+			if got := reportKeys(t, out.Bytes()); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("console keys = %v\nwant %v", got, tc.want)
+			}
+			logged, err := os.ReadFile(logPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := reportKeys(t, logged); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("jsonl keys = %v\nwant %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFieldSelectorCompact(t *testing.T) {
+	info := ErrorInfo{
+		Error:      errors.New("connection refused"),
+		ErrorCode:  "NET001",
+		Severity:   LevelError,
+		File:       "db.go",
+		Line:       42,
+		Function:   "main.connect",
+		Context:    map[string]interface{}{"host": "db-1"},
+		Suggestion: "check that the database is running",
+		GroupKey:   "g1",
+		ID:         "id1",
+	}
+	tests := []struct {
+		sel  FieldSelector
+		want string
+	}{
+		{FieldSelector{}, "error[NET001]: connection refused (db.go:42) group=g1\n"},
+		{FieldSelector{Include: []Field{FieldCode, FieldMessage, FieldID}}, "error[NET001]: connection refused group=g1 id=id1\n"},
+		{FieldSelector{Include: []Field{FieldMessage, FieldFunction, FieldContext, FieldSuggestion}},
+			"error: connection refused in main.connect group=g1 host=db-1 help: check that the database is running\n"},
+		{FieldSelector{Exclude: []Field{FieldLocation}}, "error[NET001]: connection refused group=g1\n"},
+	}
+	for _, tc := range tests {
+		if got := renderCompact(info, ErrorConfig{Fields: tc.sel}); got != tc.want {
+			t.Errorf("renderCompact with %+v = %q, want %q", tc.sel, got, tc.want)
+		}
+	}
+}
+
+func TestFieldSelectorLeavesPrettyAlone(t *testing.T) {
+	config := testConfig()
+	config.Fields = FieldSelector{Include: []Field{FieldCode}}
+	out := testCatch(t, config)
+
+	Catch.WithContext("host", "db-1").Set(errors.New("connection refused"))
+	for _, want := range []string{"connection refused", "-->", "host: db-1"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("pretty report lacks %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package catch
+
+// Severity describes how serious a handled error is
+type Severity int
+
+// Severity levels, ordered from least to most severe. The zero value is
+// LevelError so an ErrorInfo built without a level behaves as before.
+const (
+	LevelWarn Severity = iota - 1
+	LevelError
+	LevelFatal
+)
+
+// Leveler supplies the minimum severity for ErrorConfig.MinLevel, in the
+// manner of slog.Leveler; a nil Leveler lets every severity through
+type Leveler interface {
+	Level() Severity
+}
+
+// Level returns s itself, making a Severity a fixed Leveler
+func (s Severity) Level() Severity {
+	return s
+}
+
+// minLevel returns the severity below which reports are dropped: MinLevel
+// when set, otherwise LevelWarn so nothing is
+func (config ErrorConfig) minLevel() Severity {
+	if config.MinLevel == nil {
+		return LevelWarn
+	}
+	return config.MinLevel.Level()
+}
+
+// String returns the label used in report headers
+func (s Severity) String() string {
+	switch {
+	case s <= LevelWarn:
+		return "warning"
+	case s >= LevelFatal:
+		return "fatal"
+	default:
+		return "error"
+	}
+}
+
+// color returns the header color for the severity
+func (s Severity) color() string {
+	if s <= LevelWarn {
+		return Yellow
+	}
+	return BrightRed
+}
+
+// shouldExit reports whether handling an error of this severity terminates
+// the process: warnings never do, fatal errors always do
+func (s Severity) shouldExit(config ErrorConfig) bool {
+	switch {
+	case s <= LevelWarn:
+		return false
+	case s >= LevelFatal:
+		return true
+	default:
+		return config.ExitOnError
+	}
+}
+
+// exitCode returns the configured exit status for fatal errors
+func (config ErrorConfig) exitCode() int {
+	if config.ExitCode != 0 {
+		return config.ExitCode
+	}
+	return 1
+}
+
+// parseSeverity is the inverse of Severity.String
+func parseSeverity(s string) Severity {
+	switch s {
+	case "warning":
+		return LevelWarn
+	case "fatal":
+		return LevelFatal
+	default:
+		return LevelError
+	}
+}
@@ -0,0 +1,90 @@
+package catch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWarnRendersWithoutStackOrExit(t *testing.T) {
+	exits := stubExit(t)
+	config := testConfig()
+	config.ExitOnError = true
+	config.Colors = ColorAlways
+	out := testCatch(t, config)
+
+	Warn(errors.New("disk 80% full"))
+	report := out.String()
+	if !strings.Contains(report, Yellow) || !strings.Contains(Catch.stripANSI(report), "warning[") {
+		t.Errorf("warning not yellow or not labelled:\n%q", report)
+	}
+	if strings.Contains(report, "stack backtrace:") {
+		t.Errorf("warning has a stack backtrace:\n%s", report)
+	}
+	if len(*exits) != 0 {
+		t.Errorf("warning exited %v under ExitOnError", *exits)
+	}
+}
+
+func TestFatalExitsRegardlessOfExitOnError(t *testing.T) {
+	exits := stubExit(t)
+	config := testConfig()
+	config.ExitCode = 3
+	config.ShowSourceCode = false
+	out := testCatch(t, config)
+
+	Fatal(errors.New("config missing"))
+	if !strings.Contains(out.String(), "fatal[") {
+		t.Errorf("report not labelled fatal:\n%s", out)
+	}
+	if len(*exits) != 1 || (*exits)[0] != 3 {
+		t.Errorf("exits %v, want [3] with ExitOnError off", *exits)
+	}
+}
+
+func TestMinLevelDropsWarnings(t *testing.T) {
+	config := testConfig()
+	config.MinLevel = LevelError
+	rec := recordCatch(t, config)
+
+	Warn(errors.New("disk 80% full"))
+	Err(errors.New("disk full"))
+	reports := rec.reports()
+	if len(reports) != 1 || reports[0].Severity != LevelError {
+		t.Errorf("reports %v, want only the error", reports)
+	}
+}
+
+func TestLevelReachesLogFileAndHooks(t *testing.T) {
+	stubExit(t)
+	var levels []Severity
+	var out strings.Builder
+	config := testConfig()
+	config.Output = &out
+	config.LogToFile = filepath.Join(t.TempDir(), "errors.log")
+	c := New(config)
+	c.RegisterHook(func(info *ErrorInfo) HookAction {
+		levels = append(levels, info.Severity)
+		return Continue
+	})
+
+	c.Warn(errors.New("disk 80% full"))
+	c.Err(errors.New("disk full"))
+	c.Fatal(errors.New("disk gone"))
+
+	if want := []Severity{LevelWarn, LevelError, LevelFatal}; !slices.Equal(levels, want) {
+		t.Errorf("hooks saw %v, want %v", levels, want)
+	}
+	logged, err := os.ReadFile(config.LogToFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"warning[", "error[", "fatal["} {
+		if !strings.Contains(string(logged), want) {
+			t.Errorf("log file lacks %q:\n%s", want, logged)
+		}
+	}
+}
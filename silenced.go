@@ -0,0 +1,163 @@
+package catch
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SilenceReason names the mechanism that kept a report from being shown
+type SilenceReason string
+
+const (
+	SilencedSampled   SilenceReason = "sampled"   // skipped by AssertSampled between reports
+	SilencedThrottled SilenceReason = "throttled" // rolled up on the console during a burst
+	SilencedLevel     SilenceReason = "level"     // below ErrorConfig.MinLevel
+	SilencedRepeated  SilenceReason = "repeated"  // a repeat within ErrorConfig.DedupWindow
+)
+
+// SilencedEvent is an error that was handled without a console report
+type SilencedEvent struct {
+	Time     time.Time
+	Reason   SilenceReason
+	Code     string // Empty for sampled assertions, which are never classified
+	Message  string // Capped at maxSilencedMessage bytes
+	Location string // "file.go:42" when known
+}
+
+const (
+	silencedCapacity   = 64  // Events kept; older ones are overwritten
+	maxSilencedMessage = 200 // Message bytes kept per event
+)
+
+// silencedEntry is a SilencedEvent whose message is formatted only when
+// it is read, keeping the silencing path cheap
+type silencedEntry struct {
+	time     time.Time
+	reason   SilenceReason
+	code     string
+	location string // Preformatted, or built from file and line
+	file     string
+	line     int
+	err      error // The message is err, or format and args
+	format   string
+	args     []interface{}
+}
+
+// silencedRing is the process-wide history of silenced events, kept apart
+// from the reports themselves so a burst can't push them out
+type silencedRing struct {
+	mu      sync.Mutex
+	entries [silencedCapacity]silencedEntry
+	next    int
+	total   int
+
+	sampled   atomic.Uint64
+	throttled atomic.Uint64
+	belowMin  atomic.Uint64
+	repeated  atomic.Uint64
+}
+
+var silenced = &silencedRing{}
+
+// capacity returns how many entries are kept, fewer when the global Catch
+// runs under LowMemory
+func (r *silencedRing) capacity() int {
+	if processLowMemory() {
+		return lowMemorySilenced
+	}
+	return silencedCapacity
+}
+
+// record adds an entry, overwriting the oldest once full. When the
+// recording catcher runs under LowMemory the message is formatted now so
+// the error and arguments aren't retained.
+func (r *silencedRing) record(entry silencedEntry, config ErrorConfig) {
+	if config.LowMemory {
+		msg := entry.event().Message
+		entry.err, entry.format, entry.args = nil, "%s", []interface{}{msg}
+	}
+	switch entry.reason {
+	case SilencedSampled:
+		r.sampled.Add(1)
+	case SilencedThrottled:
+		r.throttled.Add(1)
+	case SilencedLevel:
+		r.belowMin.Add(1)
+	case SilencedRepeated:
+		r.repeated.Add(1)
+	}
+	r.mu.Lock()
+	capacity := r.capacity()
+	if r.next >= capacity {
+		r.next = 0
+	}
+	r.entries[r.next] = entry
+	r.next = (r.next + 1) % capacity
+	r.total++
+	r.mu.Unlock()
+}
+
+// Silenced returns up to n of the most recently silenced events, newest
+// first; n <= 0 returns all that are kept. Use it to find out where an
+// error went when no report appeared.
+func Silenced(n int) []SilencedEvent {
+	return silenced.recent(n)
+}
+
+// recent formats the newest n entries
+func (r *silencedRing) recent(n int) []SilencedEvent {
+	r.mu.Lock()
+	capacity := r.capacity()
+	kept := min(r.total, capacity)
+	if n <= 0 || n > kept {
+		n = kept
+	}
+	entries := make([]silencedEntry, n)
+	for i := range entries {
+		entries[i] = r.entries[(r.next-1-i+2*capacity)%capacity]
+	}
+	r.mu.Unlock()
+
+	events := make([]SilencedEvent, n)
+	for i, entry := range entries {
+		events[i] = entry.event()
+	}
+	return events
+}
+
+// event formats the entry's message
+func (entry silencedEntry) event() SilencedEvent {
+	var msg string
+	if entry.err != nil {
+		msg = safeFormat("%v", entry.err)
+	} else {
+		msg = fmt.Sprintf(entry.format, entry.args...)
+	}
+	if len(msg) > maxSilencedMessage {
+		msg = strings.ToValidUTF8(msg[:maxSilencedMessage], "") + "…"
+	}
+	location := entry.location
+	if location == "" && entry.file != "" {
+		location = fmt.Sprintf("%s:%d", filepath.Base(entry.file), entry.line)
+	}
+	return SilencedEvent{
+		Time:     entry.time,
+		Reason:   entry.reason,
+		Code:     entry.code,
+		Message:  msg,
+		Location: location,
+	}
+}
+
+// summary counts silenced events by reason for DebugConfig, "" if none
+func (r *silencedRing) summary() string {
+	sampled, throttled, belowMin, repeated := r.sampled.Load(), r.throttled.Load(), r.belowMin.Load(), r.repeated.Load()
+	if sampled == 0 && throttled == 0 && belowMin == 0 && repeated == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d sampled, %d throttled, %d below MinLevel, %d repeated", sampled, throttled, belowMin, repeated)
+}
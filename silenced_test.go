@@ -0,0 +1,94 @@
+package catch
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingError counts how often its message is formatted
+type countingError struct{ calls *int }
+
+func (e countingError) Error() string {
+	*e.calls++
+	return "counted"
+}
+
+func TestSilencedRingWrapsNewestFirst(t *testing.T) {
+	r := &silencedRing{}
+	for i := 0; i < silencedCapacity+6; i++ {
+		r.record(silencedEntry{reason: SilencedSampled, format: "event %d", args: []interface{}{i}}, testConfig())
+	}
+	events := r.recent(0)
+	if len(events) != silencedCapacity {
+		t.Fatalf("%d events kept, want %d", len(events), silencedCapacity)
+	}
+	if events[0].Message != "event 69" || events[silencedCapacity-1].Message != "event 6" {
+		t.Errorf("newest %q, oldest %q; want event 69 and event 6", events[0].Message, events[silencedCapacity-1].Message)
+	}
+	if got := r.recent(2); len(got) != 2 || got[1].Message != "event 68" {
+		t.Errorf("recent(2) = %v", got)
+	}
+}
+
+func TestSilencedFormatsLazily(t *testing.T) {
+	r := &silencedRing{}
+	calls := 0
+	r.record(silencedEntry{reason: SilencedLevel, err: countingError{&calls}}, testConfig())
+	if calls != 0 {
+		t.Fatalf("message formatted %d times while recording", calls)
+	}
+	if events := r.recent(1); events[0].Message != "counted" || calls != 1 {
+		t.Errorf("message %q after %d formats", events[0].Message, calls)
+	}
+}
+
+func TestSilencedCapsMessages(t *testing.T) {
+	r := &silencedRing{}
+	r.record(silencedEntry{reason: SilencedLevel, err: errors.New(strings.Repeat("x", 5*maxSilencedMessage))}, testConfig())
+	if msg := r.recent(1)[0].Message; len(msg) > maxSilencedMessage+len("…") {
+		t.Errorf("message of %d bytes kept", len(msg))
+	}
+}
+
+func TestSilencedRecordsEachMechanism(t *testing.T) {
+	resetThrottle(t)
+	defer SetClockForTesting(NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))()
+	config := testConfig()
+	config.MinLevel = LevelError
+	config.DedupWindow = time.Hour
+	config.ThrottleCompactAfter = 1
+	config.ThrottleRollupAfter = 1
+	config.Output = &bytes.Buffer{}
+	c := New(config)
+
+	c.Warn(errors.New("below min"))
+	for i := 0; i < 2; i++ {
+		c.Err(errors.New("repeated")) // One site, so the second is a repeat
+	}
+	c.Err(errors.New("rolled up")) // Second full report this second
+	for i := 0; i < 2; i++ {
+		c.assertSampled(0, "sampled %d", []interface{}{i})
+	}
+
+	reasons := make(map[string]SilenceReason)
+	for _, event := range Silenced(5) {
+		reasons[event.Message] = event.Reason
+	}
+	want := map[string]SilenceReason{
+		"below min": SilencedLevel,
+		"repeated":  SilencedRepeated,
+		"rolled up": SilencedThrottled,
+		"sampled 1": SilencedSampled,
+	}
+	for msg, reason := range want {
+		if reasons[msg] != reason {
+			t.Errorf("%q silenced as %q, want %q (all: %v)", msg, reasons[msg], reason, reasons)
+		}
+	}
+	if silenced.summary() == "" {
+		t.Error("no silenced summary for DebugConfig")
+	}
+}
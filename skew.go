@@ -0,0 +1,62 @@
+package catch
+
+import (
+	"crypto/x509"
+	"errors"
+	"strings"
+	"time"
+)
+
+// clockSkewSuggestion is the help text for TIME001
+const clockSkewSuggestion = "the system clock may be wrong; check NTP sync (timedatectl status, chronyc tracking) before suspecting the certificate, token or file"
+
+// clockSkewPhrases are messages produced when a timestamp is judged
+// against a clock that is off
+var clockSkewPhrases = []string{
+	"token used before issued",
+	"token is not valid yet",
+	"not yet valid",
+	"modification time in the future",
+	"clock skew",
+}
+
+// classifyClockSkew recognizes errors that usually mean the local clock is
+// off: certificates outside their validity window, JWTs used before their
+// issue time and timestamps in the future
+func classifyClockSkew(err error) (code, suggestion string, ok bool) {
+	var certErr x509.CertificateInvalidError
+	if errors.As(err, &certErr) && certErr.Reason == x509.Expired {
+		return "TIME001", clockSkewSuggestion, true
+	}
+	msg := strings.ToLower(classificationText(err))
+	for _, phrase := range clockSkewPhrases {
+		if strings.Contains(msg, phrase) {
+			return "TIME001", clockSkewSuggestion, true
+		}
+	}
+	return "", "", false
+}
+
+// enrichClockSkew records the system time and, for certificates, the
+// validity window and how far the clock is outside it
+func enrichClockSkew(info *ErrorInfo) {
+	if info.ErrorCode != "TIME001" {
+		return
+	}
+	current := now()
+	setContext(info, "system_time", current.UTC().Format(time.RFC3339))
+
+	var certErr x509.CertificateInvalidError
+	if !errors.As(info.Error, &certErr) || certErr.Cert == nil {
+		return
+	}
+	cert := certErr.Cert
+	setContext(info, "not_before", cert.NotBefore.UTC().Format(time.RFC3339))
+	setContext(info, "not_after", cert.NotAfter.UTC().Format(time.RFC3339))
+	switch {
+	case current.Before(cert.NotBefore):
+		setContext(info, "skew", "clock is "+cert.NotBefore.Sub(current).Round(time.Second).String()+" behind not_before")
+	case current.After(cert.NotAfter):
+		setContext(info, "skew", "clock is "+current.Sub(cert.NotAfter).Round(time.Second).String()+" past not_after")
+	}
+}
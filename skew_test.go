@@ -0,0 +1,121 @@
+package catch
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// selfSigned returns a CA certificate valid from notBefore to notAfter
+func selfSigned(t *testing.T, notBefore, notAfter time.Time) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "skew.test"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// verifyAt verifies cert against itself as the root at the given time
+func verifyAt(cert *x509.Certificate, at time.Time) error {
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	_, err := cert.Verify(x509.VerifyOptions{Roots: roots, CurrentTime: at})
+	return err
+}
+
+func TestCertificateNotYetValid(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	defer SetClockForTesting(NewManualClock(at))()
+	cert := selfSigned(t, at.Add(2*time.Hour), at.AddDate(1, 0, 0))
+	err := verifyAt(cert, at)
+	if err == nil {
+		t.Fatal("certificate verified before NotBefore")
+	}
+
+	rec := recordCatch(t, testConfig())
+	Err(fmt.Errorf("dialing api.example.com: %w", err))
+	info := rec.reports()[0]
+	if info.ErrorCode != "TIME001" || info.Suggestion != clockSkewSuggestion {
+		t.Errorf("code %s, suggestion %q; want TIME001 and the NTP suggestion", info.ErrorCode, info.Suggestion)
+	}
+	for key, want := range map[string]string{
+		"system_time": "2026-03-01T09:00:00Z",
+		"not_before":  "2026-03-01T11:00:00Z",
+		"not_after":   "2027-03-01T09:00:00Z",
+		"skew":        "clock is 2h0m0s behind not_before",
+	} {
+		if got := info.Context[key]; got != want {
+			t.Errorf("%s = %v, want %s", key, got, want)
+		}
+	}
+}
+
+func TestCertificateExpired(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	defer SetClockForTesting(NewManualClock(at))()
+	cert := selfSigned(t, at.AddDate(-1, 0, 0), at.Add(-90*time.Second))
+
+	rec := recordCatch(t, testConfig())
+	Err(verifyAt(cert, at))
+	info := rec.reports()[0]
+	if info.ErrorCode != "TIME001" || info.Context["skew"] != "clock is 1m30s past not_after" {
+		t.Errorf("code %s, skew %v; want TIME001, 1m30s past not_after", info.ErrorCode, info.Context["skew"])
+	}
+}
+
+func TestClockSkewMessages(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	defer SetClockForTesting(NewManualClock(at))()
+	rec := recordCatch(t, testConfig())
+
+	for _, msg := range []string{
+		"token has invalid claims: Token used before issued",
+		"jwt: token is not valid yet",
+		"build cache: modification time in the future",
+	} {
+		Err(errors.New(msg))
+	}
+	for _, info := range rec.reports() {
+		if info.ErrorCode != "TIME001" || info.Context["system_time"] != "2026-03-01T09:00:00Z" {
+			t.Errorf("%v: code %s, system_time %v", info.Error, info.ErrorCode, info.Context["system_time"])
+		}
+		if _, ok := info.Context["skew"]; ok {
+			t.Errorf("%v: skew recorded without timestamps to compare", info.Error)
+		}
+	}
+}
+
+func TestOtherCertificateErrorsNotSkew(t *testing.T) {
+	err := x509.CertificateInvalidError{Reason: x509.NotAuthorizedToSign}
+	if code, _, ok := classifyClockSkew(err); ok {
+		t.Errorf("NotAuthorizedToSign classified as %s", code)
+	}
+	if code, _, ok := classifyClockSkew(errors.New("connection refused")); ok {
+		t.Errorf("plain error classified as %s", code)
+	}
+}
@@ -0,0 +1,117 @@
+package catch
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+)
+
+// slogLevel maps a severity to the slog level of its record
+func slogLevel(s Severity) slog.Level {
+	if s <= LevelWarn {
+		return slog.LevelWarn
+	}
+	return slog.LevelError
+}
+
+// logToSlog emits info as one record on config.Logger, with the code,
+// location, suggestion, context as a group and the stack as a list
+func logToSlog(info ErrorInfo, config ErrorConfig) error {
+	ctx := context.Background()
+	level := slogLevel(info.Severity)
+	handler := config.Logger.Handler()
+	if !handler.Enabled(ctx, level) {
+		return nil
+	}
+
+	r := slog.NewRecord(info.Time, level, info.chainMessage(), 0)
+	r.AddAttrs(
+		slog.String("code", info.ErrorCode),
+		slog.String("severity", info.Severity.String()),
+		slog.String("id", info.ID),
+	)
+	if info.HasLocation() {
+		r.AddAttrs(slog.String("file", info.File), slog.Int("line", info.Line))
+	}
+	if info.Function != "" {
+		r.AddAttrs(slog.String("function", info.Function))
+	}
+	if info.Suggestion != "" {
+		r.AddAttrs(slog.String("suggestion", info.Suggestion))
+	}
+	if len(info.Context) > 0 {
+		var fields []any
+		for _, k := range contextKeys(info) {
+			fields = append(fields, slog.Any(k, jsonContextValue(info.Context[k])))
+		}
+		r.AddAttrs(slog.Group("context", fields...))
+	}
+	if len(info.Stack) > 0 {
+		var stack []string
+		for _, frame := range info.Stack {
+			if frame.Hidden == 0 {
+				stack = append(stack, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+			}
+		}
+		r.AddAttrs(slog.Any("stack", stack))
+	}
+	return handler.Handle(ctx, r)
+}
+
+// SlogHandler wraps a slog.Handler, expanding every error-valued attribute
+// into the group LogValue produces, located at the logging call
+// Usage: slog.SetDefault(slog.New(catch.NewSlogHandler(slog.NewJSONHandler(os.Stderr, nil))))
+type SlogHandler struct {
+	inner slog.Handler
+}
+
+// NewSlogHandler returns a SlogHandler wrapping inner
+func NewSlogHandler(inner slog.Handler) *SlogHandler {
+	return &SlogHandler{inner: inner}
+}
+
+// Enabled reports whether the wrapped handler handles level
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle expands error attributes of r and passes it on
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	var frames []runtime.Frame
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		frames = []runtime.Frame{frame}
+	}
+	out := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(expandErrorAttr(a, frames))
+		return true
+	})
+	return h.inner.Handle(ctx, out)
+}
+
+// WithAttrs returns a SlogHandler over the inner handler with attrs added
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		expanded[i] = expandErrorAttr(a, nil)
+	}
+	return &SlogHandler{inner: h.inner.WithAttrs(expanded)}
+}
+
+// WithGroup returns a SlogHandler over the inner handler with the group
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	return &SlogHandler{inner: h.inner.WithGroup(name)}
+}
+
+// expandErrorAttr replaces an error value with its LogValue group
+func expandErrorAttr(a slog.Attr, frames []runtime.Frame) slog.Attr {
+	if a.Value.Kind() != slog.KindAny {
+		return a
+	}
+	if err, ok := a.Value.Any().(error); ok && err != nil {
+		a.Value = slog.AnyValue(errorValuer{err: err, frames: frames})
+	}
+	return a
+}
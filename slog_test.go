@@ -0,0 +1,99 @@
+package catch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// jsonRecords decodes the JSON lines of a slog JSON handler
+func jsonRecords(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("%v: %s", err, line)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLoggerReceivesStructuredRecord(t *testing.T) {
+	var logged bytes.Buffer
+	config := testConfig()
+	config.Logger = slog.New(slog.NewJSONHandler(&logged, nil))
+	console := testCatch(t, config)
+
+	Err(errors.New("connection refused"), "user", 7)
+	Warn(errors.New("cache cold"))
+
+	records := jsonRecords(t, &logged)
+	if len(records) != 2 {
+		t.Fatalf("%d records, want 2", len(records))
+	}
+	r := records[0]
+	if r["level"] != "ERROR" || r["msg"] != "connection refused" || r["code"] == "" || r["suggestion"] == "" {
+		t.Errorf("record %v", r)
+	}
+	if !strings.HasSuffix(fmt.Sprint(r["file"]), "slog_test.go") || r["line"] == nil || r["function"] == nil {
+		t.Errorf("location %v:%v in %v", r["file"], r["line"], r["function"])
+	}
+	if ctx, _ := r["context"].(map[string]interface{}); ctx["user"] != float64(7) {
+		t.Errorf("context group %v", r["context"])
+	}
+	if stack, _ := r["stack"].([]interface{}); len(stack) == 0 {
+		t.Error("no stack list")
+	}
+	if records[1]["level"] != "WARN" {
+		t.Errorf("warning logged at %v", records[1]["level"])
+	}
+	if countHeadlines(console.String(), "connection refused") != 1 {
+		t.Errorf("console report missing without LoggerOnly:\n%s", console)
+	}
+}
+
+func TestLoggerOnlySkipsConsole(t *testing.T) {
+	var logged bytes.Buffer
+	config := testConfig()
+	config.Logger = slog.New(slog.NewJSONHandler(&logged, nil))
+	config.LoggerOnly = true
+	console := testCatch(t, config)
+
+	Err(errors.New("connection refused"))
+	if console.Len() != 0 || len(jsonRecords(t, &logged)) != 1 {
+		t.Errorf("console %q, logged %q", console, logged.String())
+	}
+}
+
+func TestSlogHandlerExpandsErrors(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(NewSlogHandler(slog.NewJSONHandler(&out, nil)))
+
+	logger.With("base", errors.New("setup failed")).Error("request failed", "err", errors.New("connection refused"), "n", 3)
+	_, _, line, _ := runtime.Caller(0)
+
+	r := jsonRecords(t, &out)[0]
+	group, ok := r["err"].(map[string]interface{})
+	if !ok || group["message"] != "connection refused" || group["code"] == "" {
+		t.Fatalf("err attribute %v", r["err"])
+	}
+	if want := fmt.Sprintf("slog_test.go:%d", line-1); group["location"] != want {
+		t.Errorf("location %v, want %s", group["location"], want)
+	}
+	if base, ok := r["base"].(map[string]interface{}); !ok || base["message"] != "setup failed" {
+		t.Errorf("error added With not expanded: %v", r["base"])
+	}
+	if r["n"] != float64(3) {
+		t.Errorf("other attribute changed: %v", r["n"])
+	}
+}
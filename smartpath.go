@@ -0,0 +1,82 @@
+package catch
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// strippedPaths caches stripRoot, which looks for go.mod files on disk
+var strippedPaths sync.Map
+
+// stripRoot removes the part of path that says nothing about the file:
+// the module cache up to the module path, or the root of the module
+// holding it, which for the standard library is GOROOT/src
+func stripRoot(path string) string {
+	if cached, ok := strippedPaths.Load(path); ok {
+		return cached.(string)
+	}
+	stripped := filepath.ToSlash(path)
+	if i := strings.LastIndex(stripped, "/pkg/mod/"); i >= 0 {
+		stripped = stripped[i+len("/pkg/mod/"):]
+	} else if root := filepath.ToSlash(moduleRoot(path)); root != "" && len(stripped) > len(root) {
+		stripped = strings.TrimPrefix(stripped[len(root):], "/")
+	}
+	strippedPaths.Store(path, stripped)
+	return stripped
+}
+
+// reportPaths lists the source paths shown in a report, the peers among
+// which a smart path must be unique
+func (info ErrorInfo) reportPaths() []string {
+	paths := []string{info.File, info.OriginFile}
+	for _, stack := range [][]StackFrame{info.Stack, info.OriginStack} {
+		for _, frame := range stack {
+			paths = append(paths, frame.File)
+		}
+	}
+	return paths
+}
+
+// smartPath returns the shortest trailing run of path elements, after
+// stripRoot, that no other path in peers ends with: the base name when it
+// is unique, storage/client.go beside api/client.go. It depends only on
+// the set of peers, so the same report always renders the same way.
+func smartPath(path string, peers []string) string {
+	elems := strings.Split(stripRoot(path), "/")
+	var others [][]string
+	for _, peer := range peers {
+		if peer != "" && peer != path {
+			others = append(others, strings.Split(stripRoot(peer), "/"))
+		}
+	}
+
+	for n := 1; n < len(elems); n++ {
+		suffix := elems[len(elems)-n:]
+		unique := true
+		for _, other := range others {
+			if len(other) >= n && slices.Equal(other[len(other)-n:], suffix) {
+				unique = false
+				break
+			}
+		}
+		if unique {
+			return strings.Join(suffix, "/")
+		}
+	}
+	return strings.Join(elems, "/")
+}
+
+// displayPath formats a path shown in this report. PathSmart needs the
+// report's other paths; every other style is displayPath.
+func (info ErrorInfo) displayPath(path string, config ErrorConfig) string {
+	if config.PathStyle != PathSmart || path == "" {
+		return displayPath(path, config)
+	}
+	path = smartPath(path, info.reportPaths())
+	if isWindows {
+		path = filepath.FromSlash(path)
+	}
+	return path
+}
@@ -0,0 +1,88 @@
+package catch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeModule creates a module root holding go.mod under a temporary
+// directory and returns its path
+func fakeModule(t *testing.T, rel string) string {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), rel)
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestStripRoot(t *testing.T) {
+	app := fakeModule(t, "app")
+	gopath := filepath.Join(t.TempDir(), "go")
+	for _, tc := range []struct {
+		name, path, want string
+	}{
+		{"module root", filepath.Join(app, "internal", "store", "client.go"), "internal/store/client.go"},
+		{"vendor", filepath.Join(app, "vendor", "github.com", "lib", "pq", "conn.go"), "vendor/github.com/lib/pq/conn.go"},
+		{"module cache", filepath.Join(gopath, "pkg", "mod", "github.com", "lib", "pq@v1.10.9", "conn.go"), "github.com/lib/pq@v1.10.9/conn.go"},
+		{"GOPATH without go.mod", filepath.Join(gopath, "src", "github.com", "lib", "pq", "conn.go"), filepath.ToSlash(filepath.Join(gopath, "src", "github.com", "lib", "pq", "conn.go"))},
+		{"relative", "store/client.go", "store/client.go"},
+	} {
+		if got := stripRoot(tc.path); got != tc.want {
+			t.Errorf("%s: stripRoot(%q) = %q, want %q", tc.name, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestSmartPathShortestUniqueSuffix(t *testing.T) {
+	app := fakeModule(t, "app")
+	gopath := filepath.Join(t.TempDir(), "go")
+	main := filepath.Join(app, "cmd", "server", "main.go")
+	apiClient := filepath.Join(app, "api", "client.go")
+	storeClient := filepath.Join(app, "storage", "client.go")
+	vendored := filepath.Join(app, "vendor", "github.com", "acme", "storage", "client.go")
+	cached := filepath.Join(gopath, "pkg", "mod", "github.com", "acme", "sdk@v1.2.0", "api", "client.go")
+
+	for _, tc := range []struct {
+		name  string
+		path  string
+		peers []string
+		want  string
+	}{
+		{"unique base", main, []string{main, apiClient}, "main.go"},
+		{"shared base", apiClient, []string{apiClient, storeClient}, "api/client.go"},
+		{"beside vendor", storeClient, []string{storeClient, vendored}, "storage/client.go"},
+		{"vendored", vendored, []string{storeClient, vendored}, "acme/storage/client.go"},
+		{"module cache", cached, []string{cached, apiClient}, "sdk@v1.2.0/api/client.go"},
+		{"alone", storeClient, nil, "client.go"},
+	} {
+		if got := smartPath(tc.path, tc.peers); got != tc.want {
+			t.Errorf("%s: smartPath = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSmartPathPerReport(t *testing.T) {
+	app := fakeModule(t, "app")
+	config := testConfig()
+	config.PathStyle = PathSmart
+	info := ErrorInfo{
+		File:  filepath.Join(app, "api", "client.go"),
+		Stack: []StackFrame{{File: filepath.Join(app, "storage", "client.go")}, {File: filepath.Join(app, "main.go")}},
+	}
+
+	for path, want := range map[string]string{
+		info.File:          filepath.FromSlash("api/client.go"),
+		info.Stack[0].File: filepath.FromSlash("storage/client.go"),
+		info.Stack[1].File: "main.go",
+		"":                 "",
+	} {
+		if got := info.displayPath(path, config); got != want {
+			t.Errorf("displayPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
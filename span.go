@@ -0,0 +1,124 @@
+//go:build !gocatch_lite
+
+package catch
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"strconv"
+	"unicode/utf8"
+)
+
+// catchPackages are the import paths whose calls report errors rather
+// than produce them
+var catchPackages = map[string]bool{"catch": true, "except": true}
+
+// callSpan finds the call on line that produced the error and returns
+// where it is, or a zero exprSpan. The call is the outermost one starting
+// on the line, unless that is a call into this package, as in
+// catch.Err(json.Unmarshal(data, &v)), where the first call among its
+// arguments is the one that failed. A call ending on a later line gets
+// its end position; one running past maxSpanLines is marked to the end of
+// its first line instead.
+func callSpan(filename string, line int) exprSpan {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return exprSpan{}
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, 0)
+	if err != nil {
+		return exprSpan{}
+	}
+
+	var outer *ast.CallExpr
+	ast.Inspect(file, func(n ast.Node) bool {
+		if outer != nil || n == nil {
+			return false
+		}
+		start, end := fset.Position(n.Pos()), fset.Position(n.End())
+		if start.Line > line || end.Line < line {
+			return false
+		}
+		if call, ok := n.(*ast.CallExpr); ok && start.Line == line {
+			outer = call
+			return false
+		}
+		return true
+	})
+	if outer == nil {
+		return exprSpan{}
+	}
+
+	target := outer
+	if isCatchCall(file, outer) {
+		for _, arg := range outer.Args {
+			if call := firstCall(arg); call != nil {
+				target = call
+				break
+			}
+		}
+	}
+
+	if fset.Position(target.Pos()).Line != line {
+		target = outer // Mark the lines from where the error was reported
+	}
+
+	start, end := fset.Position(target.Pos()), fset.Position(target.End())
+	if end.Line != start.Line && end.Line-start.Line < maxSpanLines {
+		return exprSpan{Column: start.Column, EndLine: end.Line, EndColumn: end.Column - 1}
+	}
+	stop := end.Offset
+	if end.Line != start.Line {
+		stop = start.Offset
+		for stop < len(src) && src[stop] != '\n' {
+			stop++
+		}
+	}
+	return exprSpan{Column: start.Column, Width: utf8.RuneCount(src[start.Offset:stop])}
+}
+
+// isCatchCall reports whether call is pkg.Func on this package or except
+func isCatchCall(file *ast.File, call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	for _, imp := range file.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || !catchPackages[path.Base(p)] {
+			continue
+		}
+		name := path.Base(p)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if name == x.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// firstCall returns the outermost call in expr, if any
+func firstCall(expr ast.Expr) *ast.CallExpr {
+	var found *ast.CallExpr
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		if call, ok := n.(*ast.CallExpr); ok {
+			found = call
+			return false
+		}
+		return true
+	})
+	return found
+}
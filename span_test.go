@@ -0,0 +1,81 @@
+//go:build !gocatch_lite
+
+package catch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// spanFixture is parsed, never compiled
+const spanFixture = `package fixture
+
+import (
+	"encoding/json"
+	"os"
+
+	"catch"
+)
+
+func load(name string) {
+	data, err := os.ReadFile(name)
+	catch.Err(json.Unmarshal(data, &v))
+	x := "héllo"; _, err = os.Stat(x)
+	return
+}
+`
+
+func TestCallSpanFindsFailingCall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.go")
+	if err := os.WriteFile(path, []byte(spanFixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(spanFixture, "\n")
+	at := func(line int, call string) exprSpan {
+		return exprSpan{Column: strings.Index(lines[line-1], call) + 1, Width: len([]rune(call))}
+	}
+	tests := []struct {
+		name string
+		line int
+		want exprSpan
+	}{
+		{"assignment", 11, at(11, "os.ReadFile(name)")},
+		{"inside a catch call", 12, at(12, "json.Unmarshal(data, &v)")},
+		{"after multibyte text", 13, at(13, "os.Stat(x)")},
+		{"no call", 14, exprSpan{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := callSpan(path, tt.line); got != tt.want {
+				t.Errorf("callSpan = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaretsUnderlineSpanAfterTabs(t *testing.T) {
+	line := "\tcatch.Err(json.Unmarshal(data, &v))"
+	lines := []SourceLine{{Number: 12, Content: line, IsError: true}}
+	out := renderSnippet(lines, exprSpan{Column: 12, Width: 24}, testConfig())
+	if want := " | \t          " + strings.Repeat("^", 24) + "\n"; !strings.Contains(out, want) {
+		t.Errorf("carets not under the call:\n%s", out)
+	}
+
+	out = renderSnippet(lines, exprSpan{}, testConfig())
+	if !strings.Contains(out, " | \t^\n") {
+		t.Errorf("fallback caret not at the first non-blank character:\n%s", out)
+	}
+}
+
+func TestReportUnderlinesFailingCall(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	_, err := os.ReadFile(filepath.Join(t.TempDir(), "missing"))
+	Err(err)
+
+	info := rec.reports()[0]
+	if info.Column == 0 || info.Span != len("Err(err)") {
+		t.Errorf("column %d, width %d; want the Err(err) call", info.Column, info.Span)
+	}
+}
@@ -0,0 +1,84 @@
+package catch
+
+import (
+	"runtime"
+	"strings"
+)
+
+// maxHiddenFrames is how many frames beyond MaxStackDepth are captured
+// when filters may hide some
+const maxHiddenFrames = 64
+
+// runtimeFramePrefixes are the function prefixes HideRuntimeFrames hides:
+// the runtime itself and the testing harness that calls test functions
+var runtimeFramePrefixes = []string{"runtime.", "testing."}
+
+// filtersStack reports whether any stack filter is configured
+func (config ErrorConfig) filtersStack() bool {
+	return config.HideRuntimeFrames || config.HidePackageFrames || len(config.StackFilter) > 0
+}
+
+// hideFrame reports whether the configured filters hide function
+func hideFrame(function string, config ErrorConfig) bool {
+	if config.HideRuntimeFrames {
+		for _, prefix := range runtimeFramePrefixes {
+			if strings.HasPrefix(function, prefix) {
+				return true
+			}
+		}
+	}
+	if config.HidePackageFrames && (strings.HasPrefix(function, packagePrefix) || strings.HasPrefix(function, shimPrefix)) {
+		return true
+	}
+	if len(config.StackFilter) > 0 {
+		for _, prefix := range config.StackFilter {
+			if strings.HasPrefix(function, prefix) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// framesWord is "frame" or "frames" for n
+func framesWord(n int) string {
+	if n == 1 {
+		return "frame"
+	}
+	return "frames"
+}
+
+// filterStack converts frames to StackFrames, replacing each run of frames
+// the filters hide with one placeholder, and keeps up to MaxStackDepth
+// visible frames
+func filterStack(frames []runtime.Frame, config ErrorConfig) []StackFrame {
+	if !config.filtersStack() {
+		if len(frames) > config.MaxStackDepth {
+			frames = frames[:config.MaxStackDepth]
+		}
+		return stackFrames(frames)
+	}
+
+	var stack []StackFrame
+	visible, hidden := 0, 0
+	for _, frame := range frames {
+		if visible == config.MaxStackDepth {
+			break
+		}
+		if hideFrame(frame.Function, config) {
+			hidden++
+			continue
+		}
+		if hidden > 0 {
+			stack = append(stack, StackFrame{Hidden: hidden})
+			hidden = 0
+		}
+		stack = append(stack, stackFrames([]runtime.Frame{frame})...)
+		visible++
+	}
+	if hidden > 0 {
+		stack = append(stack, StackFrame{Hidden: hidden})
+	}
+	return stack
+}
@@ -0,0 +1,95 @@
+package catch
+
+import (
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// syntheticFrames is a stack from a handler in main through this package
+// and a library down to the runtime
+var syntheticFrames = func() []runtime.Frame {
+	var frames []runtime.Frame
+	for _, fn := range []string{"catch.Err", "catch/except.X", "main.handle", "github.com/acme/lib.Do", "main.main", "runtime.main", "testing.tRunner", "runtime.goexit"} {
+		frames = append(frames, runtime.Frame{Function: fn, File: "x.go", Line: 1})
+	}
+	return frames
+}()
+
+// stackShape lists each frame's short function name, or "-N" for N
+// hidden frames
+func stackShape(stack []StackFrame) []string {
+	var shape []string
+	for _, frame := range stack {
+		if frame.Hidden > 0 {
+			shape = append(shape, "-"+strconv.Itoa(frame.Hidden))
+			continue
+		}
+		shape = append(shape, frame.Function)
+	}
+	return shape
+}
+
+func TestFilterStack(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*ErrorConfig)
+		want      []string
+	}{
+		{
+			name:      "no filters",
+			configure: func(*ErrorConfig) {},
+			want:      []string{"catch.Err", "except.X", "main.handle", "lib.Do", "main.main", "runtime.main", "testing.tRunner", "runtime.goexit"},
+		},
+		{
+			name:      "HideRuntimeFrames",
+			configure: func(c *ErrorConfig) { c.HideRuntimeFrames = true },
+			want:      []string{"catch.Err", "except.X", "main.handle", "lib.Do", "main.main", "-3"},
+		},
+		{
+			name:      "HidePackageFrames",
+			configure: func(c *ErrorConfig) { c.HidePackageFrames = true },
+			want:      []string{"-2", "main.handle", "lib.Do", "main.main", "runtime.main", "testing.tRunner", "runtime.goexit"},
+		},
+		{
+			name:      "StackFilter",
+			configure: func(c *ErrorConfig) { c.StackFilter = []string{"main."} },
+			want:      []string{"-2", "main.handle", "-1", "main.main", "-3"},
+		},
+		{
+			name: "MaxStackDepth counts visible frames",
+			configure: func(c *ErrorConfig) {
+				c.HidePackageFrames = true
+				c.MaxStackDepth = 2
+			},
+			want: []string{"-2", "main.handle", "lib.Do"},
+		},
+		{
+			name:      "MaxStackDepth without filters",
+			configure: func(c *ErrorConfig) { c.MaxStackDepth = 2 },
+			want:      []string{"catch.Err", "except.X"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig()
+			config.MaxStackDepth = 20
+			tt.configure(&config)
+			if got := stackShape(filterStack(syntheticFrames, config)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stack %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHiddenFramesAreSummarized(t *testing.T) {
+	config := testConfig()
+	config.HideRuntimeFrames = true
+	config.MaxStackDepth = 20
+	info := ErrorInfo{Stack: filterStack(syntheticFrames, config)}
+	if out := RenderStack(info, config); !strings.Contains(out, "   ... 3 frames hidden ...\n") {
+		t.Errorf("no summary of the hidden frames:\n%s", out)
+	}
+}
@@ -0,0 +1,76 @@
+package catch
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// StartupBuffer makes the global catcher hold errors handled before the
+// first Configure (or Ready) call, such as those from init functions. They
+// are printed as one-liners right away and replayed to the log file, the
+// Logger, a FormatJSON console and any custom Handler once configuration
+// arrives. Only fatal errors exit
+// while buffering. Set GOCATCH_STARTUP_BUFFER=1, or set this variable
+// from a package initialized before the code that may fail.
+var StartupBuffer = os.Getenv("GOCATCH_STARTUP_BUFFER") == "1"
+
+// maxStartupBuffer bounds how many early errors are kept for replay
+const maxStartupBuffer = 100
+
+// startupState holds errors buffered before configuration
+type startupState struct {
+	mu       sync.Mutex
+	ready    bool
+	buffered []ErrorInfo
+}
+
+// Ready ends startup buffering for the global catcher without changing its
+// configuration, replaying buffered errors
+func Ready() {
+	Catch.ready()
+}
+
+// buffering reports whether errors are currently held for replay
+func (e *ErrorCatcher) buffering() bool {
+	if !StartupBuffer || e != Catch {
+		return false
+	}
+	e.startup.mu.Lock()
+	defer e.startup.mu.Unlock()
+	return !e.startup.ready
+}
+
+// bufferStartup prints a minimal line for info and keeps it for replay
+func (e *ErrorCatcher) bufferStartup(info ErrorInfo) {
+	fmt.Fprint(e.getConfig().output(), renderCompact(info, ErrorConfig{}))
+
+	e.startup.mu.Lock()
+	if len(e.startup.buffered) < maxStartupBuffer {
+		e.startup.buffered = append(e.startup.buffered, info)
+	}
+	e.startup.mu.Unlock()
+}
+
+// ready ends buffering and replays held errors through the configured
+// outputs. A pretty console is skipped, as it already has the one-liners.
+func (e *ErrorCatcher) ready() {
+	e.startup.mu.Lock()
+	if e.startup.ready {
+		e.startup.mu.Unlock()
+		return
+	}
+	e.startup.ready = true
+	buffered := e.startup.buffered
+	e.startup.buffered = nil
+	e.startup.mu.Unlock()
+
+	config := e.getConfig()
+	if config.Handler == nil && config.Format != FormatJSON {
+		config.Handler = HandlerFunc(func(ErrorInfo, ErrorConfig) error { return nil })
+	}
+	for i := range buffered {
+		e.writeOutputs(&buffered[i], config)
+		e.stats.record(buffered[i])
+	}
+}
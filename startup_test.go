@@ -0,0 +1,156 @@
+package catch
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// startupCatch returns Catch to its state before main, buffering under
+// StartupBuffer, with stderr going to the returned file; everything is
+// restored after the test
+func startupCatch(t *testing.T) *os.File {
+	Catch.mu.Lock()
+	prevConfig, prevHas := Catch.Config, Catch.hasConfig
+	Catch.Config, Catch.hasConfig = ErrorConfig{}, false
+	Catch.mu.Unlock()
+	prevBuffer, prevStderr := StartupBuffer, os.Stderr
+	StartupBuffer = true
+	Catch.startup.mu.Lock()
+	Catch.startup.ready, Catch.startup.buffered = false, nil
+	Catch.startup.mu.Unlock()
+
+	stderr, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stderr = stderr
+	t.Cleanup(func() {
+		os.Stderr, StartupBuffer = prevStderr, prevBuffer
+		stderr.Close()
+		Catch.startup.mu.Lock()
+		Catch.startup.ready, Catch.startup.buffered = true, nil
+		Catch.startup.mu.Unlock()
+		Catch.mu.Lock()
+		Catch.Config, Catch.hasConfig = prevConfig, prevHas
+		Catch.mu.Unlock()
+	})
+	return stderr
+}
+
+// initLoad stands in for an init function that fails
+func initLoad() {
+	Err(errors.New("init: no such file or directory"))
+}
+
+func TestStartupBufferReplaysOnConfigure(t *testing.T) {
+	codes := stubExit(t)
+	stderr := startupCatch(t)
+
+	initLoad()
+	if len(*codes) != 0 {
+		t.Fatalf("exited with %v while buffering", *codes)
+	}
+	early, _ := os.ReadFile(stderr.Name())
+	if got := string(early); !strings.HasPrefix(got, "error[FS001]: init: no such file or directory") || strings.Count(got, "\n") != 1 {
+		t.Errorf("early output = %q, want one compact line", got)
+	}
+
+	logPath := filepath.Join(t.TempDir(), "app.jsonl")
+	rec := &recorder{}
+	config := testConfig()
+	config.Handler = rec
+	config.LogToFile = logPath
+	config.LogFormat = LogJSONL
+	Catch.Configure(config)
+
+	reports := rec.reports()
+	if len(reports) != 1 || reports[0].Error.Error() != "init: no such file or directory" {
+		t.Fatalf("replayed %+v, want the init error", reports)
+	}
+	if filepath.Base(reports[0].File) != "startup_test.go" {
+		t.Errorf("replayed report at %s, want the init call site", reports[0].File)
+	}
+	logged, _ := os.ReadFile(logPath)
+	if !strings.Contains(string(logged), `"message":"init: no such file or directory"`) {
+		t.Errorf("log file lacks the replayed error:\n%s", logged)
+	}
+
+	Err(errors.New("after configure"))
+	if n := len(rec.reports()); n != 2 {
+		t.Errorf("%d reports after configuration, want 2", n)
+	}
+}
+
+func TestStartupBufferReady(t *testing.T) {
+	stubExit(t)
+	startupCatch(t)
+
+	initLoad()
+	if !Catch.buffering() {
+		t.Fatal("not buffering before Ready")
+	}
+	Ready()
+	if Catch.buffering() {
+		t.Error("still buffering after Ready")
+	}
+}
+
+func TestStartupBufferFatalExits(t *testing.T) {
+	codes := stubExit(t)
+	startupCatch(t)
+
+	Fatal(errors.New("init: cannot continue"))
+	if len(*codes) != 1 {
+		t.Errorf("exit calls = %v, want one for a fatal error", *codes)
+	}
+}
+
+func TestStartupBufferReplaysToLoggerAndJSONConsole(t *testing.T) {
+	stubExit(t)
+	stderr := startupCatch(t)
+
+	initLoad()
+	var logged, console bytes.Buffer
+	config := testConfig()
+	config.Logger = slog.New(slog.NewJSONHandler(&logged, nil))
+	config.Format = FormatJSON
+	config.Output = &console
+	Catch.mu.Lock()
+	Catch.Config, Catch.hasConfig = config, true
+	Catch.mu.Unlock()
+	if logged.Len() != 0 {
+		t.Fatalf("logged before Ready:\n%s", logged.String())
+	}
+	Ready()
+
+	if !strings.Contains(logged.String(), "init: no such file or directory") {
+		t.Errorf("logger lacks the replayed error:\n%s", logged.String())
+	}
+	if !strings.Contains(console.String(), `"init: no such file or directory"`) {
+		t.Errorf("JSON console lacks the replayed error:\n%s", console.String())
+	}
+	early, _ := os.ReadFile(stderr.Name())
+	if strings.Count(string(early), "\n") != 1 {
+		t.Errorf("early output = %q, want only the one-liner", early)
+	}
+}
+
+func TestStartupBufferSkipsPrettyConsoleOnReplay(t *testing.T) {
+	stubExit(t)
+	startupCatch(t)
+
+	initLoad()
+	var console bytes.Buffer
+	config := testConfig()
+	config.Output = &console
+	Catch.Configure(config)
+
+	if console.Len() != 0 {
+		t.Errorf("replay repeated the one-liner on the console:\n%s", console.String())
+	}
+}
@@ -0,0 +1,157 @@
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// Reader wraps r so that its first failure is reported with the bytes read
+// so far, the time since wrapping and the given context. The report points
+// at the line that wrapped the reader, since reads often happen far away
+// inside io.Copy or a decoder. Data and errors pass through unchanged;
+// io.EOF is never reported. Failures are reported at warning severity
+// unless an Option such as AsError is among the context values.
+// Usage: body := catch.Reader(resp.Body, "url", url)
+func Reader(r io.Reader, context ...interface{}) io.Reader {
+	return &streamReader{r: r, watch: newStreamWatch("read", context)}
+}
+
+// Writer is Reader for writes
+// Usage: out := catch.Writer(f, "path", path)
+func Writer(w io.Writer, context ...interface{}) io.Writer {
+	return &streamWriter{w: w, watch: newStreamWatch("write", context)}
+}
+
+// streamWatch holds what a stream wrapper reports on its first failure
+type streamWatch struct {
+	op       string // "read" or "write"
+	context  []interface{}
+	opts     callOptions
+	frames   []runtime.Frame // Where the stream was wrapped
+	start    time.Time
+	bytes    atomic.Int64
+	reported atomic.Bool
+}
+
+func newStreamWatch(op string, context []interface{}) *streamWatch {
+	s := &streamWatch{op: op, start: now()}
+	s.opts, s.context = splitOptions(context)
+	s.frames = reportFrames(locateDepth(Catch.getConfig()))
+	return s
+}
+
+// observe counts n transferred bytes and reports err the first time
+func (s *streamWatch) observe(n int64, err error) {
+	s.bytes.Add(n)
+	if err == nil || errors.Is(err, io.EOF) || !s.reported.CompareAndSwap(false, true) {
+		return
+	}
+
+	config := Catch.getConfig()
+	info := ErrorInfo{
+		Error:   err,
+		Context: parseProvidedContext(make(map[string]interface{}), config, s.context...),
+		Uptime:  now().Sub(processStart),
+	}
+	info.locateAt(s.frames, config)
+	info.ErrorCode, info.Suggestion = classify(err)
+	info.Severity = LevelWarn
+	info.opts = s.opts
+	info.Context["error_type"] = errorTypeName(err)
+	info.Context["bytes_"+s.op+"_before_failure"] = s.bytes.Load()
+	info.Context["elapsed"] = now().Sub(s.start).Round(time.Millisecond).String()
+	setContext(&info, "operation", fmt.Sprintf("%s on wrapped stream", s.op))
+	if config.ShowSourceCode {
+		info.SourceLines = Catch.loadSourceContext(info.File, info.Line, config.ContextLines)
+	}
+	Catch.handleError(info)
+}
+
+// streamReader is the io.Reader returned by Reader
+type streamReader struct {
+	r     io.Reader
+	watch *streamWatch
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	s.watch.observe(int64(n), err)
+	return n, err
+}
+
+// WriteTo keeps the wrapped reader's io.WriterTo fast path. Failures of
+// w are returned but not reported, as they aren't the stream's.
+func (s *streamReader) WriteTo(w io.Writer) (int64, error) {
+	if wt, ok := s.r.(io.WriterTo); ok {
+		dst := &otherWriter{w: w}
+		n, err := wt.WriteTo(dst)
+		s.watch.observe(n, ownError(err, dst.err))
+		return n, err
+	}
+	return io.Copy(w, struct{ io.Reader }{s})
+}
+
+// streamWriter is the io.Writer returned by Writer
+type streamWriter struct {
+	w     io.Writer
+	watch *streamWatch
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	n, err := s.w.Write(p)
+	s.watch.observe(int64(n), err)
+	return n, err
+}
+
+// ReadFrom keeps the wrapped writer's io.ReaderFrom fast path. Failures
+// of r are returned but not reported, as they aren't the stream's.
+func (s *streamWriter) ReadFrom(r io.Reader) (int64, error) {
+	if rf, ok := s.w.(io.ReaderFrom); ok {
+		src := &otherReader{r: r}
+		n, err := rf.ReadFrom(src)
+		s.watch.observe(n, ownError(err, src.err))
+		return n, err
+	}
+	return io.Copy(struct{ io.Writer }{s}, r)
+}
+
+// otherWriter and otherReader record the last failure of the other side
+// of a copy, hiding its own fast-path interfaces
+type otherWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (o *otherWriter) Write(p []byte) (int, error) {
+	n, err := o.w.Write(p)
+	if err != nil {
+		o.err = err
+	}
+	return n, err
+}
+
+type otherReader struct {
+	r   io.Reader
+	err error
+}
+
+func (o *otherReader) Read(p []byte) (int, error) {
+	n, err := o.r.Read(p)
+	if err != nil && err != io.EOF {
+		o.err = err
+	}
+	return n, err
+}
+
+// ownError is err unless it came from the other side of a copy, whose
+// last failure is other
+func ownError(err, other error) error {
+	if other != nil && errors.Is(err, other) {
+		return nil
+	}
+	return err
+}
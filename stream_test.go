@@ -0,0 +1,104 @@
+package catch
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// failingWriter fails every write with err
+type failingWriter struct{ err error }
+
+func (f failingWriter) Write([]byte) (int, error) { return 0, f.err }
+
+// failingReader fails every read with err
+type failingReader struct{ err error }
+
+func (f failingReader) Read([]byte) (int, error) { return 0, f.err }
+
+// writerToReader is a reader with the io.WriterTo fast path
+type writerToReader struct{ *strings.Reader }
+
+// readerFromWriter is a writer with the io.ReaderFrom fast path
+type readerFromWriter struct{ *bytes.Buffer }
+
+func TestReaderReportsItsFailureOnce(t *testing.T) {
+	buf := testCatch(t, testConfig())
+	r := Reader(io.MultiReader(strings.NewReader("abc"), failingReader{errors.New("connection reset")}), "url", "http://example.com")
+	io.Copy(io.Discard, r)
+	r.Read(make([]byte, 1))
+	out := buf.String()
+	if got := countHeadlines(out, "connection reset"); got != 1 {
+		t.Fatalf("%d reports, want 1:\n%s", got, out)
+	}
+	for _, want := range []string{"bytes_read_before_failure: 3", "url: http://example.com", "stream_test.go"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestReaderIgnoresEOF(t *testing.T) {
+	buf := testCatch(t, testConfig())
+	io.Copy(io.Discard, Reader(strings.NewReader("abc")))
+	if buf.Len() != 0 {
+		t.Errorf("EOF reported:\n%s", buf)
+	}
+}
+
+func TestReaderWriteToBlamesOnlyTheReader(t *testing.T) {
+	buf := testCatch(t, testConfig())
+	r := Reader(writerToReader{strings.NewReader("abc")})
+	n, err := r.(io.WriterTo).WriteTo(failingWriter{errors.New("disk full")})
+	if n != 0 || err == nil || err.Error() != "disk full" {
+		t.Fatalf("WriteTo = %d, %v; want the writer's error", n, err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("the writer's failure was reported on the reader:\n%s", buf)
+	}
+}
+
+func TestWriterReadFromBlamesOnlyTheWriter(t *testing.T) {
+	buf := testCatch(t, testConfig())
+	w := Writer(readerFromWriter{new(bytes.Buffer)})
+	_, err := w.(io.ReaderFrom).ReadFrom(failingReader{errors.New("connection reset")})
+	if err == nil || err.Error() != "connection reset" {
+		t.Fatalf("ReadFrom error = %v; want the reader's error", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("the reader's failure was reported on the writer:\n%s", buf)
+	}
+}
+
+func TestWriterReportsItsFailure(t *testing.T) {
+	buf := testCatch(t, testConfig())
+	w := Writer(failingWriter{errors.New("disk full")}, "path", "out.txt")
+	io.Copy(w, strings.NewReader("abc"))
+	if !strings.Contains(buf.String(), "disk full") || !strings.Contains(buf.String(), "write on wrapped stream") {
+		t.Errorf("write failure not reported:\n%s", buf)
+	}
+}
+
+func TestStreamTakesSeverityOptions(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	io.Copy(io.Discard, Reader(failingReader{errors.New("connection reset")}, "url", "http://example.com"))
+	io.Copy(Writer(failingWriter{errors.New("disk full")}, AsError, "path", "out.txt"), strings.NewReader("abc"))
+
+	reports := rec.reports()
+	if len(reports) != 2 {
+		t.Fatalf("%d reports, want 2", len(reports))
+	}
+	if reports[0].Severity != LevelWarn || reports[1].Severity != LevelError {
+		t.Errorf("severities %v and %v, want warning then error", reports[0].Severity, reports[1].Severity)
+	}
+	for key, value := range reports[1].Context {
+		if _, ok := value.(Option); ok {
+			t.Errorf("context[%s] holds the option %v", key, value)
+		}
+	}
+	if reports[1].Context["path"] != "out.txt" {
+		t.Errorf("context = %v, want the path", reports[1].Context)
+	}
+}
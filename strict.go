@@ -0,0 +1,208 @@
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Misuse patterns detected under StrictMode, each reported at most once
+const (
+	misuseRecoverNotCalled = "Recover result not called"
+	misuseTryNil           = "Try result called with nil"
+	misuseOddContext       = "odd number of key-value context arguments"
+	misuseHintText         = "HintText set with ShowHints off"
+)
+
+// misuseUsage shows the correct usage for each pattern
+var misuseUsage = map[string]string{
+	misuseRecoverNotCalled: "call the closure Recover returns: defer catch.Recover()(&err), not defer catch.Recover()",
+	misuseTryNil:           "pass the address of the named error result: func f() (err error) { defer catch.Try()(&err) ... }",
+	misuseOddContext:       `pass context as key, value pairs: catch.Err(err, "user", id, "attempt", n)`,
+	misuseHintText:         "set ShowHints to true for HintText to be shown, or drop HintText",
+}
+
+// misuseReported holds the patterns already reported
+var misuseReported sync.Map
+
+// reportMisuse reports a misuse pattern once, at Warn severity, located at
+// file:line when known
+func (e *ErrorCatcher) reportMisuse(pattern, file string, line int) {
+	if _, reported := misuseReported.LoadOrStore(pattern, true); reported {
+		return
+	}
+	config := e.getConfig()
+	info := ErrorInfo{
+		Error:      errors.New("API misuse: " + pattern),
+		ErrorCode:  "API001",
+		Suggestion: misuseUsage[pattern],
+		Context:    make(map[string]interface{}),
+		Uptime:     now().Sub(processStart),
+		File:       file,
+		Line:       line,
+		Severity:   LevelWarn,
+	}
+	e.loadSources(&info, config)
+	e.handleError(info)
+}
+
+// reportMisuseHere reports a misuse pattern at the reporting frame
+func (e *ErrorCatcher) reportMisuseHere(pattern string) {
+	var file string
+	var line int
+	if frames := reportFrames(1); len(frames) > 0 {
+		file, line = frames[0].File, frames[0].Line
+	}
+	e.reportMisuse(pattern, file, line)
+}
+
+// checkConfigMisuse reports contradictory settings in config
+func (e *ErrorCatcher) checkConfigMisuse(config ErrorConfig) {
+	if config.StrictMode && config.HintText != "" && !config.ShowHints {
+		e.reportMisuseHere(misuseHintText)
+	}
+}
+
+// checkRendering reports each problem CheckRendering finds in config as a
+// warning located at the Configure call
+func (e *ErrorCatcher) checkRendering(config ErrorConfig) {
+	problems := CheckRendering(config)
+	if len(problems) == 0 {
+		return
+	}
+	var file string
+	var line int
+	if frames := reportFrames(1); len(frames) > 0 {
+		file, line = frames[0].File, frames[0].Line
+	}
+	for _, problem := range problems {
+		info := ErrorInfo{
+			Error:      fmt.Errorf("configuration renders badly: %v", problem),
+			ErrorCode:  "CFG001",
+			Suggestion: "fix the named sink's settings before the first real report goes through it",
+			Context:    make(map[string]interface{}),
+			Uptime:     now().Sub(processStart),
+			File:       file,
+			Line:       line,
+			Severity:   LevelWarn,
+		}
+		e.loadSources(&info, config)
+		e.handleError(info)
+	}
+}
+
+// checkContextMisuse reports key-value context missing its last value:
+// an odd count of three or more arguments with a string at every key
+// position
+func (e *ErrorCatcher) checkContextMisuse(context []interface{}) {
+	if len(context) < 3 || len(context)%2 == 0 || !e.getConfig().StrictMode {
+		return
+	}
+	for i := 0; i < len(context); i += 2 {
+		if _, ok := context[i].(string); !ok {
+			return
+		}
+	}
+	e.reportMisuseHere(misuseOddContext)
+}
+
+// pendingRecover is the drop guard of a Recover result not yet called. A
+// deferred Recover() without the trailing call leaves one behind once the
+// function that called Recover returns; the catcher that issued it reports
+// it when the same goroutine next handles an error or calls Close.
+type pendingRecover struct {
+	id        uint64
+	goroutine uint64
+	catcher   *ErrorCatcher
+	function  string
+	file      string
+	line      int
+}
+
+// maxPendingRecovers bounds the guards kept, since those of goroutines
+// that never handle another error are never checked
+const maxPendingRecovers = 1024
+
+// pendingRecovers holds the pending Recover results under StrictMode by ID
+var (
+	pendingRecovers     sync.Map // uint64 -> pendingRecover
+	pendingRecoverCount atomic.Int64
+	recoverIDs          atomic.Uint64
+)
+
+// trackRecover issues a drop guard for a Recover call, or returns nil
+// outside StrictMode, once the misuse has been reported, or when too many
+// guards are pending
+func (e *ErrorCatcher) trackRecover() *pendingRecover {
+	if !e.getConfig().StrictMode {
+		return nil
+	}
+	if _, reported := misuseReported.Load(misuseRecoverNotCalled); reported {
+		return nil
+	}
+	if pendingRecoverCount.Load() >= maxPendingRecovers {
+		return nil
+	}
+	frames := reportFrames(1)
+	if len(frames) == 0 {
+		return nil
+	}
+	p := &pendingRecover{id: recoverIDs.Add(1), goroutine: goroutineID(), catcher: e, function: frames[0].Function, file: frames[0].File, line: frames[0].Line}
+	pendingRecovers.Store(p.id, *p)
+	pendingRecoverCount.Add(1)
+	return p
+}
+
+// untrackRecover records that a Recover result was called
+func untrackRecover(p *pendingRecover) {
+	if p != nil {
+		dropRecover(p.id)
+	}
+}
+
+// dropRecover forgets a guard, reporting whether it was still pending
+func dropRecover(id uint64) bool {
+	if _, pending := pendingRecovers.LoadAndDelete(id); pending {
+		pendingRecoverCount.Add(-1)
+		return true
+	}
+	return false
+}
+
+// checkRecovers reports the guards e issued on the current goroutine whose
+// calling function has returned without calling the Recover result. Other
+// goroutines' guards are checked when they next handle an error.
+func (e *ErrorCatcher) checkRecovers() {
+	var stack map[string]bool
+	gid := goroutineID()
+	pendingRecovers.Range(func(key, value interface{}) bool {
+		p := value.(pendingRecover)
+		if p.goroutine != gid || p.catcher != e {
+			return true
+		}
+		if stack == nil {
+			stack = stackFunctions()
+		}
+		if !stack[p.function] && dropRecover(p.id) {
+			e.reportMisuse(misuseRecoverNotCalled, p.file, p.line)
+		}
+		return true
+	})
+}
+
+// stackFunctions returns the functions on the current goroutine's stack
+func stackFunctions() map[string]bool {
+	pcs := make([]uintptr, 128)
+	n := runtime.Callers(1, pcs)
+	functions := make(map[string]bool)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		functions[frame.Function] = true
+		if !more {
+			return functions
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package catch
+
+import (
+	"errors"
+	"testing"
+)
+
+// strictCatch configures Catch under StrictMode, sending reports to the
+// returned channel
+func strictCatch(t *testing.T) <-chan ErrorInfo {
+	reports := make(chan ErrorInfo, 16)
+	config := testConfig()
+	config.StrictMode = true
+	config.Handler = HandlerFunc(func(info ErrorInfo, _ ErrorConfig) error {
+		reports <- info
+		return nil
+	})
+	testCatch(t, config)
+	clearRecovers := func() {
+		misuseReported.Delete(misuseRecoverNotCalled)
+		pendingRecovers.Range(func(key, _ interface{}) bool {
+			dropRecover(key.(uint64))
+			return true
+		})
+	}
+	clearRecovers()
+	t.Cleanup(clearRecovers)
+	return reports
+}
+
+// pendingCount counts the Recover results not yet called
+func pendingCount() int {
+	n := 0
+	pendingRecovers.Range(func(_, _ interface{}) bool { n++; return true })
+	return n
+}
+
+func TestRecoverCalledLeavesNothingPending(t *testing.T) {
+	strictCatch(t)
+	err := func() (err error) {
+		defer Recover()(&err)
+		panic(errors.New("boom"))
+	}()
+	if err == nil || err.Error() != "boom" {
+		t.Fatalf("err = %v", err)
+	}
+	if n := pendingCount(); n != 0 {
+		t.Errorf("%d Recover results pending", n)
+	}
+}
+
+//go:noinline
+func recoverNotCalled() {
+	defer Recover() // The misuse: the returned closure is never called
+}
+
+// misuseReports drains reports, returning those of misuse
+func misuseReports(reports <-chan ErrorInfo) []ErrorInfo {
+	var misuse []ErrorInfo
+	for {
+		select {
+		case info := <-reports:
+			if info.ErrorCode == "API001" {
+				misuse = append(misuse, info)
+			}
+		default:
+			return misuse
+		}
+	}
+}
+
+func TestRecoverNotCalledIsReportedAtNextError(t *testing.T) {
+	reports := strictCatch(t)
+	recoverNotCalled()
+	recoverNotCalled()
+	Err(errors.New("next failure"))
+
+	misuse := misuseReports(reports)
+	if len(misuse) != 1 || misuse[0].Error.Error() != "API misuse: "+misuseRecoverNotCalled {
+		t.Fatalf("misuse reports = %v, want exactly one for the uncalled Recover", misuse)
+	}
+	if misuse[0].Line == 0 {
+		t.Error("misuse report lacks the Recover call site")
+	}
+	if n := pendingCount(); n != 0 {
+		t.Errorf("%d Recover results still pending", n)
+	}
+}
+
+func TestRecoverNotCalledIsReportedAtClose(t *testing.T) {
+	reports := strictCatch(t)
+	recoverNotCalled()
+	Catch.Close()
+
+	if misuse := misuseReports(reports); len(misuse) != 1 {
+		t.Fatalf("misuse reports = %v, want one at Close", misuse)
+	}
+}
+
+func TestRecoverGuardIsCheckedByItsCatcher(t *testing.T) {
+	reports := strictCatch(t)
+	recoverNotCalled()
+
+	config := testConfig()
+	config.StrictMode = true
+	config.Handler = HandlerFunc(func(ErrorInfo, ErrorConfig) error { return nil })
+	New(config).Err(errors.New("library failure"))
+	if n := pendingCount(); n != 1 {
+		t.Errorf("%d Recover results pending after another catcher's error, want 1", n)
+	}
+
+	Err(errors.New("application failure"))
+	if misuse := misuseReports(reports); len(misuse) != 1 {
+		t.Errorf("misuse reports = %v, want one from the issuing catcher", misuse)
+	}
+}
+
+func TestRecoverGuardStillRunningIsNotReported(t *testing.T) {
+	reports := strictCatch(t)
+	func() {
+		defer Recover()(nil)
+		Err(errors.New("inside the guarded function"))
+	}()
+	if misuse := misuseReports(reports); len(misuse) != 0 {
+		t.Errorf("misuse reported for a Recover still deferred: %v", misuse)
+	}
+}
+
+func TestStrictModeOffTracksNothing(t *testing.T) {
+	testCatch(t, testConfig())
+	recoverNotCalled()
+	if n := pendingCount(); n != 0 {
+		t.Errorf("%d Recover results pending outside StrictMode", n)
+	}
+}
@@ -0,0 +1,70 @@
+//go:build !gocatch_lite
+
+package catch
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// expandStruct turns a struct or pointer to struct passed as context into
+// per-field entries. Fields tagged `catch:"name"` are always expanded;
+// with all set, untagged exported fields are too, under their snake_case
+// name. `catch:"-"` skips a field and `catch:",redact"` masks its value.
+// Nested structs are not expanded further. ok is false when value is not
+// a struct or nothing was expanded.
+func expandStruct(value interface{}, all bool) (fields map[string]interface{}, ok bool) {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	t := v.Type()
+	fields = make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag, tagged := field.Tag.Lookup("catch")
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" || (!tagged && !all) {
+			continue
+		}
+		if name == "" {
+			name = snakeCase(field.Name)
+		}
+		if opts == "redact" {
+			fields[name] = "[REDACTED]"
+			continue
+		}
+		fields[name] = v.Field(i).Interface()
+	}
+	return fields, len(fields) > 0
+}
+
+// snakeCase converts a Go field name such as UserID to user_id
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a word at a lower-to-upper change or at the last
+			// capital of an acronym followed by lowercase (IDValue -> id_value)
+			if i > 0 && (unicode.IsLower(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,108 @@
+//go:build !gocatch_lite
+
+package catch
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type taggedRequest struct {
+	Method   string `catch:"method"`
+	UserID   int    `catch:"user"`
+	Password string `catch:"password,redact"`
+	Token    string `catch:"-"`
+	Body     []byte
+	internal string
+}
+
+type plainRequest struct {
+	RequestID string
+	HTTPPath  string
+	Retries   int
+	Limits    struct{ Max int } // Nested structs stay one value
+	secret    string
+}
+
+// structContext returns the context parsed from value passed alone
+func structContext(expandAll bool, value interface{}) map[string]interface{} {
+	config := testConfig()
+	config.ExpandStructs = expandAll
+	return parseProvidedContext(make(map[string]interface{}), config, value)
+}
+
+func TestTaggedStructContext(t *testing.T) {
+	req := taggedRequest{Method: "POST", UserID: 7, Password: "hunter2", Token: "t0k", Body: []byte("{}"), internal: "x"}
+	want := map[string]interface{}{"method": "POST", "user": 7, "password": "[REDACTED]"}
+	for _, value := range []interface{}{req, &req} {
+		if got := structContext(false, value); !reflect.DeepEqual(got, want) {
+			t.Errorf("context of %T = %v, want %v", value, got, want)
+		}
+	}
+
+	// ExpandStructs adds the untagged exported fields; tags still apply
+	got := structContext(true, req)
+	want["body"] = []byte("{}")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expanded context = %v, want %v", got, want)
+	}
+}
+
+func TestUntaggedStructContext(t *testing.T) {
+	req := plainRequest{RequestID: "9f3a", HTTPPath: "/users", Retries: 2, secret: "s"}
+	req.Limits.Max = 5
+
+	// Without tags or ExpandStructs the struct stays one value
+	got := structContext(false, req)
+	if len(got) != 1 || !reflect.DeepEqual(got["plainrequest"], req) {
+		t.Errorf("context = %v, want the struct under plainrequest", got)
+	}
+
+	got = structContext(true, req)
+	want := map[string]interface{}{"request_id": "9f3a", "http_path": "/users", "retries": 2, "limits": req.Limits}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expanded context = %v, want %v", got, want)
+	}
+}
+
+func TestNilStructPointerContext(t *testing.T) {
+	for _, all := range []bool{false, true} {
+		var req *taggedRequest
+		got := structContext(all, req)
+		if len(got) != 1 || got["taggedrequest"] != req {
+			t.Errorf("ExpandStructs %v: context = %v, want the nil pointer as one value", all, got)
+		}
+	}
+}
+
+func TestStructContextRendered(t *testing.T) {
+	config := testConfig()
+	config.ShowSourceCode = false
+	out := testCatch(t, config)
+	Err(errors.New("request failed"), &taggedRequest{Method: "GET", UserID: 7, Password: "hunter2"})
+	for _, want := range []string{"method: GET", "user: 7", "password: [REDACTED]"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out.String(), "hunter2") {
+		t.Errorf("redacted field shown:\n%s", out)
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"UserID":    "user_id",
+		"IDValue":   "id_value",
+		"HTTPPath":  "http_path",
+		"Retries":   "retries",
+		"X":         "x",
+		"already_x": "already_x",
+	} {
+		if got := snakeCase(name); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package catch
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// RunSummarySchema identifies the layout of RunSummary; it only changes
+// when a field is removed or changes meaning
+const RunSummarySchema = "gocatch-run/1"
+
+// Exit reasons recorded in a RunSummary
+const (
+	ExitNormal = "normal" // Close was called without a fatal error
+	ExitFatal  = "fatal"  // The process exited through the error handler
+	ExitSignal = "signal" // CloseSignal was called from a signal handler
+)
+
+// RunSummary describes how a run ended, for batch schedulers and wrappers
+type RunSummary struct {
+	Schema     string         `json:"schema"`
+	Status     string         `json:"status"` // "ok", "errors", "budget_exceeded" or "failed"
+	ExitReason string         `json:"exit_reason"`
+	Signal     string         `json:"signal,omitempty"`
+	StartedAt  time.Time      `json:"started_at"`
+	DurationMS int64          `json:"duration_ms"`
+	ErrorCount int            `json:"error_count"`
+	Codes      map[string]int `json:"codes"`
+	FirstError *SummaryError  `json:"first_error,omitempty"`
+	LastError  *SummaryError  `json:"last_error,omitempty"`
+	LogPath    string         `json:"log_path,omitempty"`
+	Budget     *BudgetStatus  `json:"budget,omitempty"`
+	WouldExit  int            `json:"would_exit,omitempty"` // Exits skipped under DryRunExit
+
+	// SampledAssertions counts AssertSampled violations by call site,
+	// including those that were not reported
+	SampledAssertions map[string]uint64 `json:"sampled_assertions,omitempty"`
+	TrackedKeys       int               `json:"tracked_keys,omitempty"` // Sampling and dedup keys held, at most MaxTrackedKeys
+
+	Operations *OperationCounts `json:"operations,omitempty"` // Batch counts from Partial
+}
+
+// SummaryError is the short form of an error stored in a RunSummary
+type SummaryError struct {
+	Code    string    `json:"code"`
+	Message string    `json:"message"`
+	File    string    `json:"file"`
+	Line    int       `json:"line"`
+	Time    time.Time `json:"time"`
+}
+
+// BudgetStatus reports usage of ErrorConfig.ErrorBudget
+type BudgetStatus struct {
+	Limit    int  `json:"limit"`
+	Used     int  `json:"used"`
+	Exceeded bool `json:"exceeded"`
+}
+
+// runStats accumulates the data behind a RunSummary
+type runStats struct {
+	mu     sync.Mutex
+	count  int
+	codes  map[string]int
+	first  *SummaryError
+	last   *SummaryError
+	reason string
+	signal string
+	would  int
+
+	succeeded, failed int // Operations reported through Partial
+}
+
+// record adds a handled error to the statistics
+func (s *runStats) record(info ErrorInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := &SummaryError{
+		Code:    info.ErrorCode,
+		Message: describeError(info.Error),
+		File:    info.File,
+		Line:    info.Line,
+		Time:    now(),
+	}
+	if s.codes == nil {
+		s.codes = make(map[string]int)
+	}
+	s.count++
+	s.codes[info.ErrorCode]++
+	if info.WouldExit != 0 {
+		s.would++
+	}
+	if s.first == nil {
+		s.first = entry
+	}
+	s.last = entry
+}
+
+// Summary returns the run summary of the global catcher so far
+func Summary() RunSummary {
+	return Catch.Summary()
+}
+
+// Summary returns the run summary of this catcher so far
+func (e *ErrorCatcher) Summary() RunSummary {
+	config := e.getConfig()
+	s := &e.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summary := RunSummary{
+		Schema:     RunSummarySchema,
+		Status:     "ok",
+		ExitReason: s.reason,
+		Signal:     s.signal,
+		StartedAt:  processStart,
+		DurationMS: now().Sub(processStart).Milliseconds(),
+		ErrorCount: s.count,
+		Codes:      make(map[string]int, len(s.codes)),
+		FirstError: s.first,
+		LastError:  s.last,
+		LogPath:    config.LogToFile,
+		WouldExit:  s.would,
+
+		SampledAssertions: e.sampledCounts(),
+		TrackedKeys:       e.keys.Len(),
+	}
+	for code, n := range s.codes {
+		summary.Codes[code] = n
+	}
+	if s.succeeded+s.failed > 0 {
+		summary.Operations = &OperationCounts{Succeeded: s.succeeded, Failed: s.failed}
+	}
+	if summary.ExitReason == "" {
+		summary.ExitReason = ExitNormal
+	}
+	if s.count > 0 {
+		summary.Status = "errors"
+	}
+	if config.ErrorBudget > 0 {
+		summary.Budget = &BudgetStatus{
+			Limit:    config.ErrorBudget,
+			Used:     s.count,
+			Exceeded: s.count > config.ErrorBudget,
+		}
+		if summary.Budget.Exceeded {
+			summary.Status = "budget_exceeded"
+		}
+	}
+	if s.reason == ExitFatal || s.reason == ExitSignal {
+		summary.Status = "failed"
+	}
+	return summary
+}
+
+// WriteRunSummary writes the global catcher's run summary as JSON to path
+func WriteRunSummary(path string) error {
+	return Catch.WriteRunSummary(path)
+}
+
+// WriteRunSummary writes this catcher's run summary as JSON to path
+func (e *ErrorCatcher) WriteRunSummary(path string) error {
+	data, err := json.MarshalIndent(e.Summary(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Close finishes the run of the global catcher, reporting leaked tracked
+// resources and writing the run summary when RunSummaryPath is configured
+// Usage: defer catch.Close()
+func Close() error {
+	return Catch.Close()
+}
+
+// Close finishes the run, reporting resources registered with Track that
+// are still open and writing the run summary when RunSummaryPath is
+// configured
+func (e *ErrorCatcher) Close() error {
+	if e.getConfig().StrictMode {
+		e.checkRecovers()
+	}
+	e.flushDedup()
+	consoleThrottle.flush(time.Time{})
+	e.reportLeaks()
+	e.setExitReason(ExitNormal, "")
+	err := e.writeConfiguredSummary()
+	if path := e.getConfig().LogToFile; path != "" {
+		closeLogFile(resolvePath(path))
+	}
+	return err
+}
+
+// CloseSignal is Close for use from a signal handler; the summary records
+// that the run was interrupted by sig
+func CloseSignal(sig os.Signal) error {
+	Catch.setExitReason(ExitSignal, sig.String())
+	return Catch.writeConfiguredSummary()
+}
+
+// setExitReason records why the run ended, keeping the first reason given
+func (e *ErrorCatcher) setExitReason(reason, signal string) {
+	e.stats.mu.Lock()
+	defer e.stats.mu.Unlock()
+	if e.stats.reason == "" {
+		e.stats.reason = reason
+		e.stats.signal = signal
+	}
+}
+
+// writeConfiguredSummary writes the summary if RunSummaryPath is set
+func (e *ErrorCatcher) writeConfiguredSummary() error {
+	path := e.getConfig().RunSummaryPath
+	if path == "" {
+		return nil
+	}
+	return e.WriteRunSummary(resolvePath(path))
+}
@@ -0,0 +1,96 @@
+package catch
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// summaryCatcher returns an independent catcher writing its run summary
+// to a file under the test's temporary directory
+func summaryCatcher(t *testing.T, config ErrorConfig) (*ErrorCatcher, string) {
+	path := filepath.Join(t.TempDir(), "run.json")
+	config.Handler = &recorder{}
+	config.RunSummaryPath = path
+	return New(config), path
+}
+
+// readSummary decodes the run summary written at path
+func readSummary(t *testing.T, path string) RunSummary {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("summary not written: %v", err)
+	}
+	var summary RunSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("summary is not JSON: %v\n%s", err, data)
+	}
+	return summary
+}
+
+func TestRunSummarySuccess(t *testing.T) {
+	c, path := summaryCatcher(t, testConfig())
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	summary := readSummary(t, path)
+	if summary.Schema != RunSummarySchema || summary.Status != "ok" || summary.ExitReason != ExitNormal {
+		t.Errorf("summary = %+v, want an ok run ending normally", summary)
+	}
+	if summary.ErrorCount != 0 || len(summary.Codes) != 0 || summary.FirstError != nil {
+		t.Errorf("summary of a clean run lists errors: %+v", summary)
+	}
+}
+
+func TestRunSummaryFatal(t *testing.T) {
+	codes := stubExit(t)
+	config := testConfig()
+	config.ExitOnError = true
+	c, path := summaryCatcher(t, config)
+
+	c.Err(errors.New("no such file or directory"))
+	if len(*codes) != 1 {
+		t.Fatalf("exit calls = %v, want one", *codes)
+	}
+
+	summary := readSummary(t, path)
+	if summary.Status != "failed" || summary.ExitReason != ExitFatal {
+		t.Errorf("status, reason = %s, %s; want failed, fatal", summary.Status, summary.ExitReason)
+	}
+	if summary.ErrorCount != 1 || summary.Codes["FS001"] != 1 {
+		t.Errorf("count, codes = %d, %v; want 1 FS001", summary.ErrorCount, summary.Codes)
+	}
+	if summary.FirstError == nil || summary.FirstError.Message != "no such file or directory" ||
+		filepath.Base(summary.FirstError.File) != "summary_test.go" {
+		t.Errorf("first error = %+v", summary.FirstError)
+	}
+}
+
+func TestRunSummaryBudgetExceeded(t *testing.T) {
+	config := testConfig()
+	config.ErrorBudget = 2
+	c, path := summaryCatcher(t, config)
+
+	for _, msg := range []string{"permission denied", "connection refused", "connection refused"} {
+		c.Err(errors.New(msg))
+	}
+	c.Close()
+
+	summary := readSummary(t, path)
+	if summary.Status != "budget_exceeded" || summary.ExitReason != ExitNormal {
+		t.Errorf("status, reason = %s, %s; want budget_exceeded, normal", summary.Status, summary.ExitReason)
+	}
+	if b := summary.Budget; b == nil || *b != (BudgetStatus{Limit: 2, Used: 3, Exceeded: true}) {
+		t.Errorf("budget = %+v", summary.Budget)
+	}
+	if summary.Codes["FS002"] != 1 || summary.Codes["NET001"] != 2 {
+		t.Errorf("codes = %v", summary.Codes)
+	}
+	if summary.LastError == nil || summary.LastError.Message != "connection refused" {
+		t.Errorf("last error = %+v", summary.LastError)
+	}
+}
@@ -0,0 +1,119 @@
+package catch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultDiffThreshold is the relative count change CompareRunSummaries
+// reports: 0.25 means a code must become 25% more or less frequent
+const DefaultDiffThreshold = 0.25
+
+// SummaryDiff compares the error codes of two runs, e.g. the main branch
+// (before) and a pull request (after)
+type SummaryDiff struct {
+	New      []CodeDelta `json:"new"`      // Codes only seen after
+	Resolved []CodeDelta `json:"resolved"` // Codes only seen before
+	Changed  []CodeDelta `json:"changed"`  // Codes whose count moved beyond the threshold
+}
+
+// CodeDelta is the count of one error code in both runs
+type CodeDelta struct {
+	Code   string `json:"code"`
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+}
+
+// ReadRunSummary loads a summary written by WriteRunSummary
+func ReadRunSummary(path string) (RunSummary, error) {
+	var summary RunSummary
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return summary, err
+	}
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return summary, fmt.Errorf("%s: %w", path, err)
+	}
+	if summary.Schema != RunSummarySchema {
+		return summary, fmt.Errorf("%s: unsupported schema %q", path, summary.Schema)
+	}
+	return summary, nil
+}
+
+// CompareRunSummaries diffs the codes of a (before) and b (after) using
+// DefaultDiffThreshold
+func CompareRunSummaries(a, b RunSummary) SummaryDiff {
+	return CompareRunSummariesThreshold(a, b, DefaultDiffThreshold)
+}
+
+// CompareRunSummariesThreshold diffs the codes of a and b, listing codes
+// present in both only when their count changed by more than threshold
+// relative to a
+func CompareRunSummariesThreshold(a, b RunSummary, threshold float64) SummaryDiff {
+	diff := SummaryDiff{New: []CodeDelta{}, Resolved: []CodeDelta{}, Changed: []CodeDelta{}}
+	for code, after := range b.Codes {
+		before, ok := a.Codes[code]
+		delta := CodeDelta{Code: code, Before: before, After: after}
+		switch {
+		case !ok || before == 0:
+			if after > 0 {
+				diff.New = append(diff.New, delta)
+			}
+		case after == 0:
+			diff.Resolved = append(diff.Resolved, delta)
+		case float64(abs(after-before)) > threshold*float64(before):
+			diff.Changed = append(diff.Changed, delta)
+		}
+	}
+	for code, before := range a.Codes {
+		if _, ok := b.Codes[code]; !ok && before > 0 {
+			diff.Resolved = append(diff.Resolved, CodeDelta{Code: code, Before: before})
+		}
+	}
+	for _, list := range [][]CodeDelta{diff.New, diff.Resolved, diff.Changed} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	}
+	return diff
+}
+
+// Empty reports whether the runs had no notable differences
+func (d SummaryDiff) Empty() bool {
+	return len(d.New) == 0 && len(d.Resolved) == 0 && len(d.Changed) == 0
+}
+
+// String renders the diff for humans, one code per line
+func (d SummaryDiff) String() string {
+	if d.Empty() {
+		return "no changes in error codes\n"
+	}
+	var b strings.Builder
+	for _, delta := range d.New {
+		fmt.Fprintf(&b, "+ %s new (%d)\n", delta.Code, delta.After)
+	}
+	for _, delta := range d.Resolved {
+		fmt.Fprintf(&b, "- %s resolved (was %d)\n", delta.Code, delta.Before)
+	}
+	for _, delta := range d.Changed {
+		fmt.Fprintf(&b, "~ %s %d -> %d\n", delta.Code, delta.Before, delta.After)
+	}
+	return b.String()
+}
+
+// JSON encodes the diff as an indented JSON object
+func (d SummaryDiff) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// ExitOnNew prints the diff to stderr and exits with status 1 when the
+// after run introduced new codes, for use as a CI gate
+// Usage: catch.CompareRunSummaries(base, catch.Summary()).ExitOnNew()
+func (d SummaryDiff) ExitOnNew() {
+	if len(d.New) == 0 {
+		return
+	}
+	fmt.Fprint(os.Stderr, d.String())
+	exit(1)
+}
@@ -0,0 +1,130 @@
+package catch
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// runWith returns a summary with the given code counts
+func runWith(codes map[string]int) RunSummary {
+	return RunSummary{Schema: RunSummarySchema, Codes: codes}
+}
+
+var (
+	mainRun = runWith(map[string]int{"FS001": 10, "NET001": 4, "NET002": 3, "DB001": 10, "GEN000": 0})
+	prRun   = runWith(map[string]int{"FS001": 10, "NET001": 9, "DB001": 12, "AUTH001": 2, "GEN000": 3, "SYS001": 0})
+)
+
+func TestCompareRunSummaries(t *testing.T) {
+	diff := CompareRunSummaries(mainRun, prRun)
+	want := SummaryDiff{
+		New:      []CodeDelta{{Code: "AUTH001", After: 2}, {Code: "GEN000", After: 3}},
+		Resolved: []CodeDelta{{Code: "NET002", Before: 3}},
+		Changed:  []CodeDelta{{Code: "NET001", Before: 4, After: 9}},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Errorf("diff = %+v\nwant %+v", diff, want)
+	}
+
+	// DB001 moved 20%, inside the default threshold but not this one
+	diff = CompareRunSummariesThreshold(mainRun, prRun, 0.1)
+	if len(diff.Changed) != 2 || diff.Changed[0].Code != "DB001" {
+		t.Errorf("changed at 10%% = %+v, want DB001 and NET001", diff.Changed)
+	}
+
+	// Counting down to zero resolves a code
+	diff = CompareRunSummaries(mainRun, runWith(map[string]int{"FS001": 10, "NET001": 4, "NET002": 0, "DB001": 10}))
+	if len(diff.New) != 0 || len(diff.Changed) != 0 || !reflect.DeepEqual(diff.Resolved, []CodeDelta{{Code: "NET002", Before: 3}}) {
+		t.Errorf("diff = %+v, want NET002 resolved only", diff)
+	}
+}
+
+func TestSummaryDiffRendering(t *testing.T) {
+	diff := CompareRunSummaries(mainRun, prRun)
+	want := "+ AUTH001 new (2)\n" +
+		"+ GEN000 new (3)\n" +
+		"- NET002 resolved (was 3)\n" +
+		"~ NET001 4 -> 9\n"
+	if got := diff.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+
+	same := CompareRunSummaries(mainRun, mainRun)
+	if !same.Empty() || same.String() != "no changes in error codes\n" {
+		t.Errorf("identical runs: empty %v, %q", same.Empty(), same.String())
+	}
+	data, err := same.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string][]CodeDelta
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"new", "resolved", "changed"} {
+		if list, ok := decoded[key]; !ok || list == nil {
+			t.Errorf("JSON %s is missing or null:\n%s", key, data)
+		}
+	}
+
+	data, err = diff.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back SummaryDiff
+	if err := json.Unmarshal(data, &back); err != nil || !reflect.DeepEqual(back, diff) {
+		t.Errorf("JSON round trip = %+v, %v; want %+v", back, err, diff)
+	}
+}
+
+func TestSummaryDiffExitOnNew(t *testing.T) {
+	codes := stubExit(t)
+	stderr, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stderr.Close()
+	prev := os.Stderr
+	os.Stderr = stderr
+	t.Cleanup(func() { os.Stderr = prev })
+
+	CompareRunSummaries(prRun, prRun).ExitOnNew()
+	CompareRunSummaries(prRun, runWith(map[string]int{"FS001": 10, "NET001": 1})).ExitOnNew() // Only resolutions and changes
+	if len(*codes) != 0 {
+		t.Fatalf("exited with %v without new codes", *codes)
+	}
+	CompareRunSummaries(mainRun, prRun).ExitOnNew()
+	if !reflect.DeepEqual(*codes, []int{1}) {
+		t.Fatalf("exit codes = %v, want [1]", *codes)
+	}
+	printed, _ := os.ReadFile(stderr.Name())
+	if !strings.Contains(string(printed), "+ AUTH001 new (2)\n") {
+		t.Errorf("stderr = %q, want the diff", printed)
+	}
+}
+
+func TestReadRunSummary(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "main.json")
+	data, _ := json.Marshal(mainRun)
+	if err := os.WriteFile(good, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	summary, err := ReadRunSummary(good)
+	if err != nil || !reflect.DeepEqual(summary.Codes, mainRun.Codes) {
+		t.Errorf("ReadRunSummary = %v, %v", summary.Codes, err)
+	}
+
+	other := filepath.Join(dir, "other.json")
+	os.WriteFile(other, []byte(`{"schema":"gocatch-run/99","codes":{}}`), 0o644)
+	if _, err := ReadRunSummary(other); err == nil || !strings.Contains(err.Error(), "unsupported schema") {
+		t.Errorf("other schema: err = %v", err)
+	}
+	if _, err := ReadRunSummary(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("missing file: err = %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package catch
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// TempDir creates a temporary directory, handling a creation failure as
+// fatal. The returned cleanup removes the directory, reporting a failure
+// at warning severity; it is safe to call more than once.
+// Usage: dir, cleanup := catch.TempDir("build-*"); defer cleanup()
+func TempDir(pattern string) (string, func()) {
+	dir, err := os.MkdirTemp("", pattern)
+	if err != nil {
+		info := Catch.buildErrorInfo(err)
+		info.Severity = LevelFatal
+		info.Context["pattern"] = pattern
+		info.Context["tmpdir"] = os.TempDir()
+		Catch.handleError(info)
+		return "", func() {}
+	}
+	return dir, removeOnce(dir, nil)
+}
+
+// TempFile creates a temporary file in dir (os.TempDir when empty),
+// handling a creation failure as fatal. The returned cleanup closes and
+// removes the file, reporting a failure at warning severity; it is safe to
+// call more than once.
+// Usage: f, cleanup := catch.TempFile("", "upload-*.json"); defer cleanup()
+func TempFile(dir, pattern string) (*os.File, func()) {
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		info := Catch.buildErrorInfo(err)
+		info.Severity = LevelFatal
+		info.Context["pattern"] = pattern
+		info.Context["tmpdir"] = os.TempDir()
+		if dir != "" {
+			info.Context["dir"] = dir
+		}
+		Catch.handleError(info)
+		return nil, func() {}
+	}
+	return f, removeOnce(f.Name(), f)
+}
+
+// removeOnce returns an idempotent cleanup removing path after closing f.
+// The first call does the work; sync.Once is avoided so that no foreign
+// frames sit between the cleanup and its caller in the report.
+func removeOnce(path string, f *os.File) func() {
+	var done atomic.Bool
+	return func() {
+		if !done.CompareAndSwap(false, true) {
+			return
+		}
+		if f != nil {
+			f.Close()
+		}
+		err := os.RemoveAll(path)
+		if err == nil {
+			return
+		}
+
+		info := Catch.buildErrorInfo(err)
+		info.Severity = LevelWarn
+		info.Context["path"] = path
+		info.Context["files_left"] = countFiles(path)
+		Catch.handleError(info)
+	}
+}
+
+// countFiles counts the regular files remaining under path
+func countFiles(path string) int {
+	n := 0
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			n++
+		}
+		return nil
+	})
+	return n
+}
@@ -0,0 +1,119 @@
+package catch
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// setTempDir points os.TempDir at dir for one test
+func setTempDir(t *testing.T, dir string) {
+	for _, key := range []string{"TMPDIR", "TMP", "TEMP"} {
+		t.Setenv(key, dir)
+	}
+}
+
+func TestTempDirCreationFailure(t *testing.T) {
+	codes := stubExit(t)
+	rec := recordCatch(t, testConfig())
+	missing := filepath.Join(t.TempDir(), "missing")
+	setTempDir(t, missing)
+
+	dir, cleanup := TempDir("build-*")
+	cleanup()
+	if dir != "" {
+		t.Errorf("dir = %q after a failure", dir)
+	}
+	if len(*codes) != 1 {
+		t.Errorf("exit calls = %v, want one", *codes)
+	}
+	reports := rec.reports()
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reports))
+	}
+	info := reports[0]
+	if info.Severity != LevelFatal || info.ErrorCode != "FS001" {
+		t.Errorf("severity, code = %v, %s; want fatal FS001", info.Severity, info.ErrorCode)
+	}
+	if info.Context["pattern"] != "build-*" || info.Context["tmpdir"] != missing {
+		t.Errorf("context = %v, want pattern and tmpdir", info.Context)
+	}
+}
+
+func TestTempFileCreationFailure(t *testing.T) {
+	stubExit(t)
+	rec := recordCatch(t, testConfig())
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	f, _ := TempFile(missing, "upload-*.json")
+	if f != nil {
+		t.Error("file returned after a failure")
+	}
+	reports := rec.reports()
+	if len(reports) != 1 || reports[0].Context["dir"] != missing || reports[0].Severity != LevelFatal {
+		t.Errorf("reports = %+v, want one fatal report naming the dir", reports)
+	}
+}
+
+func TestTempCleanupRemovesOnce(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	setTempDir(t, t.TempDir())
+
+	dir, cleanupDir := TempDir("work-*")
+	f, cleanupFile := TempFile(dir, "part-*")
+	f.WriteString("data")
+
+	cleanupFile()
+	cleanupFile()
+	if _, err := os.Stat(f.Name()); !os.IsNotExist(err) {
+		t.Errorf("temp file left behind: %v", err)
+	}
+	cleanupDir()
+	cleanupDir()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("temp dir left behind: %v", err)
+	}
+	if n := len(rec.reports()); n != 0 {
+		t.Errorf("%d reports for a clean removal", n)
+	}
+}
+
+func TestTempCleanupBlocked(t *testing.T) {
+	if runtime.GOOS != "windows" && os.Geteuid() == 0 {
+		t.Skip("root removes files from read-only directories")
+	}
+	rec := recordCatch(t, testConfig())
+	setTempDir(t, t.TempDir())
+
+	dir, cleanup := TempDir("work-*")
+	locked := filepath.Join(dir, "locked")
+	if err := os.Mkdir(locked, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(filepath.Join(locked, "held.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS == "windows" {
+		defer f.Close() // An open file cannot be removed
+	} else {
+		f.Close()
+		os.Chmod(locked, 0o500) // Nor can a file in a read-only directory
+		defer os.Chmod(locked, 0o755)
+	}
+
+	cleanup()
+	cleanup()
+	reports := rec.reports()
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want 1", len(reports))
+	}
+	info := reports[0]
+	if info.Severity != LevelWarn || info.Context["path"] != dir || info.Context["files_left"] != 1 {
+		t.Errorf("severity, context = %v, %v; want a warning with the path and 1 file left", info.Severity, info.Context)
+	}
+	if filepath.Base(info.File) != "temp_test.go" {
+		t.Errorf("reported at %s, want the cleanup call", info.File)
+	}
+}
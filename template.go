@@ -0,0 +1,132 @@
+package catch
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// templateErrPattern matches the position prefix of text/template and
+// html/template errors: "template: page:3:14: executing "page" at <.User.Name>: ..."
+var templateErrPattern = regexp.MustCompile(`(?:html/)?template: ?([^:\s]+):(\d+)(?::(\d+))?: (?:executing "[^"]*" at <([^>]*)>: )?`)
+
+// templatePosition is where a template error occurred
+type templatePosition struct {
+	name   string
+	line   int
+	column int    // 1-based, 0 when unknown
+	action string // The failing action, e.g. .User.Name
+}
+
+// parseTemplateError extracts the position from a template error message
+func parseTemplateError(err error) (templatePosition, bool) {
+	m := templateErrPattern.FindStringSubmatch(safeFormat("%v", err))
+	if m == nil {
+		return templatePosition{}, false
+	}
+	pos := templatePosition{name: m[1], action: m[4]}
+	pos.line, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		col, _ := strconv.Atoi(m[3])
+		pos.column = col + 1 // Templates count columns from 0
+	}
+	return pos, true
+}
+
+// Template reports a template parse or execution error with a source
+// block of the template text pointing at the failing action
+// Usage: catch.Template(tmpl.Execute(w, data), "page.html", pageSrc)
+func Template(err error, name, src string) error {
+	if err == nil {
+		return nil
+	}
+	config := Catch.getConfig()
+	info := Catch.buildErrorInfo(err)
+
+	if pos, ok := parseTemplateError(err); ok && pos.line > 0 {
+		info.Context["reported_at"] = strings.TrimSpace(info.location(config))
+		info.File, info.Line, info.Function = name, pos.line, ""
+		info.SourceLines = nil
+		if config.ShowSourceCode {
+			info.SourceLines = sourceLinesOf(src, pos.line, config.ContextLines)
+		}
+		// Mark the whole failing action; the error's column is only where
+		// its last field starts
+		mark := spanOf(info.SourceLines, pos.action)
+		if mark.Column == 0 {
+			mark = exprSpan{Column: pos.column}
+		}
+		info.setSpan(mark)
+	}
+	Catch.handleError(info)
+	return err
+}
+
+// sourceLinesOf returns the lines of src around line
+func sourceLinesOf(src string, line, contextLines int) []SourceLine {
+	var lines []SourceLine
+	contents := strings.Split(src, "\n")
+	if n := len(contents); n > 1 && contents[n-1] == "" && line < n {
+		contents = contents[:n-1] // After the final newline, unless the error is there
+	}
+	for i, content := range contents {
+		n := i + 1
+		if n >= line-contextLines && n <= line+contextLines {
+			lines = append(lines, SourceLine{Number: n, Content: content, IsError: n == line})
+		}
+	}
+	return lines
+}
+
+// templateSuggestion is the generic help for TMPL001
+const templateSuggestion = "check the template against the data passed to Execute"
+
+// classifyTemplate recognizes text/template and html/template errors
+func classifyTemplate(err error) (code, suggestion string, ok bool) {
+	var execErr template.ExecError
+	if _, parsed := parseTemplateError(err); parsed || errors.As(err, &execErr) {
+		return "TMPL001", templateSuggestion, true
+	}
+	return "", "", false
+}
+
+// enrichTemplate records the template position and failing field path of
+// template errors, and points the suggestion at the data behind that path
+func enrichTemplate(info *ErrorInfo) {
+	if info.ErrorCode != "TMPL001" {
+		return
+	}
+	pos, ok := parseTemplateError(info.Error)
+	if !ok {
+		var execErr template.ExecError
+		if errors.As(info.Error, &execErr) {
+			setContext(info, "template", execErr.Name)
+		}
+		return
+	}
+
+	setContext(info, "template", pos.name)
+	setContext(info, "template_line", pos.line)
+	if pos.action == "" {
+		info.Suggestion = "fix the template syntax at " + pos.name + ":" + strconv.Itoa(pos.line)
+		return
+	}
+	setContext(info, "field_path", pos.action)
+	if parent := parentPath(pos.action); parent != "" {
+		info.Suggestion = "check that " + parent + " is set (not nil or missing) in the data passed to the template, or guard " + pos.action + " with {{with " + parent + "}}"
+	} else {
+		info.Suggestion = "check that the data passed to the template provides " + pos.action
+	}
+}
+
+// parentPath returns the field path holding the last field of path:
+// .User.Name -> .User; "" when there is none
+func parentPath(path string) string {
+	i := strings.LastIndex(path, ".")
+	if i <= 0 || strings.ContainsAny(path, " ()|") {
+		return ""
+	}
+	return path[:i]
+}
@@ -0,0 +1,110 @@
+package catch
+
+import (
+	"errors"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+const pageTemplate = "<h1>{{.Title}}</h1>\n<p>Hello {{.User.Name}}</p>\n<footer>{{.Footer}}</footer>\n"
+
+type pageUser struct{ Name string }
+
+type pageData struct {
+	Title  string
+	User   *pageUser
+	Footer string
+}
+
+// templateConfig is testConfig showing the template source alone
+func templateConfig() ErrorConfig {
+	config := testConfig()
+	config.ShowStackTrace = false
+	config.ShowUptime = false
+	return config
+}
+
+func TestTemplateExecError(t *testing.T) {
+	execs := map[string]func() error{
+		"text/template": func() error {
+			return template.Must(template.New("page").Parse(pageTemplate)).Execute(io.Discard, pageData{Title: "Home"})
+		},
+		"html/template": func() error {
+			return htmltemplate.Must(htmltemplate.New("page").Parse(pageTemplate)).Execute(io.Discard, pageData{Title: "Home"})
+		},
+	}
+	for name, execute := range execs {
+		t.Run(name, func(t *testing.T) {
+			out := testCatch(t, templateConfig())
+			Template(execute(), "page", pageTemplate)
+			report := out.String()
+			for _, want := range []string{
+				"error[TMPL001]: template: page:2:",
+				" --> page:2\n",
+				"2 | <p>Hello {{.User.Name}}</p>\n  |            ^^^^^^^^^^\n3 | <footer>",
+				"field_path: .User.Name",
+				"template_line: 2",
+				"reported_at: template_test.go:",
+				"= help: check that .User is set (not nil or missing) in the data passed to the template, or guard .User.Name with {{with .User}}",
+			} {
+				if !strings.Contains(report, want) {
+					t.Errorf("report lacks %q:\n%s", want, report)
+				}
+			}
+			if strings.Contains(report, "4 |") {
+				t.Errorf("line after the final newline shown:\n%s", report)
+			}
+		})
+	}
+}
+
+func TestTemplateParseError(t *testing.T) {
+	out := testCatch(t, templateConfig())
+	src := "<p>\n{{if .Admin}}admin\n"
+	_, err := template.New("page").Parse(src)
+	Template(err, "page", src)
+
+	report := out.String()
+	for _, want := range []string{"error[TMPL001]: template: page:3: unexpected EOF", "3 | \n  | ^\n", "= help: fix the template syntax at page:3"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "field_path") {
+		t.Errorf("field path recorded for a parse error:\n%s", report)
+	}
+}
+
+func TestParseTemplateError(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want templatePosition
+		ok   bool
+	}{
+		{`template: page:2:16: executing "page" at <.User.Name>: nil pointer evaluating *main.User.Name`,
+			templatePosition{name: "page", line: 2, column: 17, action: ".User.Name"}, true},
+		{`html/template:mail.html:7:3: executing "mail.html" at <index .Items 3>: error calling index: out of range`,
+			templatePosition{name: "mail.html", line: 7, column: 4, action: "index .Items 3"}, true},
+		{`template: page:1: bad character U+007D '}'`, templatePosition{name: "page", line: 1}, true},
+		{"connection refused", templatePosition{}, false},
+	}
+	for _, tc := range tests {
+		got, ok := parseTemplateError(errors.New(tc.msg))
+		if ok != tc.ok || got != tc.want {
+			t.Errorf("parseTemplateError(%q) = %+v, %v; want %+v, %v", tc.msg, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestTemplateNilError(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	if err := Template(nil, "page", pageTemplate); err != nil {
+		t.Errorf("Template(nil) = %v", err)
+	}
+	if n := len(rec.reports()); n != 0 {
+		t.Errorf("nil error reported %d times", n)
+	}
+}
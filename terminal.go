@@ -0,0 +1,127 @@
+package catch
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Terminal describes what an output stream can display. The standard
+// streams are assessed once and cached, other writers on every use, so a
+// report can keep colors on the terminal while a pipe to a supervisor
+// receives plain text.
+type Terminal struct {
+	TTY        bool // Connected to a terminal; colors are only used if so
+	Width      int  // Columns, 0 when unknown
+	Hyperlinks bool // The terminal is known to support OSC 8 links
+}
+
+func (t Terminal) String() string {
+	if !t.TTY {
+		return "not a terminal"
+	}
+	return fmt.Sprintf("terminal, width %d, hyperlinks %t", t.Width, t.Hyperlinks)
+}
+
+// terminalDetector assesses a writer; replaceable for tests
+type terminalDetector func(w io.Writer) Terminal
+
+type detectorHolder struct{ detect terminalDetector }
+
+var activeDetector = storedPointer(&detectorHolder{detectTerminal})
+
+// SetTerminalDetectorForTesting replaces terminal detection, clears cached
+// results and returns a function restoring the previous detector
+// Usage: defer catch.SetTerminalDetectorForTesting(func(io.Writer) catch.Terminal { return catch.Terminal{TTY: true} })()
+func SetTerminalDetectorForTesting(detect func(w io.Writer) Terminal) (restore func()) {
+	prev := activeDetector.Swap(&detectorHolder{detect})
+	RedetectTerminals()
+	return func() {
+		activeDetector.Store(prev)
+		RedetectTerminals()
+	}
+}
+
+// terminals caches the assessment of the standard streams. Other writers
+// come and go, e.g. one per request, and would pile up here.
+var terminals sync.Map // *os.File -> Terminal
+
+// RedetectTerminals discards cached terminal assessments, for use after
+// the process's streams change, e.g. after daemonizing
+func RedetectTerminals() {
+	terminals.Range(func(key, _ interface{}) bool {
+		terminals.Delete(key)
+		return true
+	})
+}
+
+// terminalFor returns the assessment of w, cached for the standard streams
+func terminalFor(w io.Writer) Terminal {
+	std, ok := stdStream(w)
+	if !ok {
+		return activeDetector.Load().detect(w)
+	}
+	if t, ok := terminals.Load(std); ok {
+		return t.(Terminal)
+	}
+	t := activeDetector.Load().detect(w)
+	terminals.Store(std, t)
+	return t
+}
+
+// stdStream returns the standard stream w writes to directly, if any
+func stdStream(w io.Writer) (*os.File, bool) {
+	if stdout, ok := w.(*StdoutWriter); ok {
+		w = stdout.w
+	}
+	f, ok := w.(*os.File)
+	if !ok || (f != os.Stdout && f != os.Stderr) {
+		return nil, false
+	}
+	return f, true
+}
+
+// detectTerminal checks whether w is a character device and reads the
+// width and hyperlink support from the environment
+func detectTerminal(w io.Writer) Terminal {
+	if stdout, ok := w.(*StdoutWriter); ok {
+		w = stdout.w
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return Terminal{}
+	}
+	stat, err := f.Stat()
+	if err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		return Terminal{}
+	}
+
+	t := Terminal{TTY: true}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		t.Width = columns
+	}
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode":
+		t.Hyperlinks = true
+	}
+	if os.Getenv("VTE_VERSION") != "" || os.Getenv("WT_SESSION") != "" {
+		t.Hyperlinks = true
+	}
+	return t
+}
+
+// consoleWriter returns the writer the configured console output uses, or
+// nil when a custom Handler replaces it
+func consoleWriter(config ErrorConfig) io.Writer {
+	switch h := config.Handler.(type) {
+	case nil:
+		return config.output()
+	case ConsoleHandler:
+		return h.writer(config)
+	case *ConsoleHandler:
+		return h.writer(config)
+	}
+	return nil
+}
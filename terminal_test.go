@@ -0,0 +1,63 @@
+package catch
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+// countDetections replaces terminal detection for one test, counting its
+// calls per writer
+func countDetections(t *testing.T) map[io.Writer]int {
+	calls := make(map[io.Writer]int)
+	t.Cleanup(SetTerminalDetectorForTesting(func(w io.Writer) Terminal {
+		calls[w]++
+		return Terminal{TTY: true, Width: 80}
+	}))
+	return calls
+}
+
+func TestTerminalForCachesStdStreams(t *testing.T) {
+	calls := countDetections(t)
+	for i := 0; i < 3; i++ {
+		if got := terminalFor(os.Stderr); !got.TTY || got.Width != 80 {
+			t.Fatalf("terminalFor(os.Stderr) = %v", got)
+		}
+	}
+	if calls[os.Stderr] != 1 {
+		t.Errorf("os.Stderr assessed %d times, want 1", calls[os.Stderr])
+	}
+}
+
+func TestTerminalForDoesNotCacheOtherWriters(t *testing.T) {
+	calls := countDetections(t)
+	for i := 0; i < 100; i++ {
+		terminalFor(new(bytes.Buffer))
+	}
+	n := 0
+	terminals.Range(func(key, _ interface{}) bool {
+		if key != os.Stdout && key != os.Stderr {
+			t.Errorf("cached %T", key)
+		}
+		n++
+		return true
+	})
+	if len(calls) != 100 || n > 2 {
+		t.Errorf("%d writers assessed, %d cached", len(calls), n)
+	}
+}
+
+func TestDetectTerminalPlainWriters(t *testing.T) {
+	if got := detectTerminal(new(bytes.Buffer)); got.TTY {
+		t.Errorf("a buffer is %v", got)
+	}
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if got := detectTerminal(f); got.TTY {
+		t.Errorf("a regular file is %v", got)
+	}
+}
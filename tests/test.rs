@@ -1,3 +0,0 @@
-fn main(){
-    println!(test
-}
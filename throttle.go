@@ -0,0 +1,133 @@
+package catch
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// throttleLevel describes how much console output a report gets during a burst
+type throttleLevel int
+
+const (
+	throttleFull    throttleLevel = iota // full multi-line report
+	throttleCompact                      // one line per error
+	throttleRollup                       // one summary line per second
+)
+
+// DefaultThrottleQuietPeriod is the calm time before full reports resume
+// when ThrottleQuietPeriod is zero
+const DefaultThrottleQuietPeriod = 2 * time.Second
+
+// outputThrottle caps console output when errors arrive faster than a
+// terminal can draw them. It is shared by every catcher in the process.
+type outputThrottle struct {
+	mu          sync.Mutex
+	windowStart time.Time
+	count       int
+	level       throttleLevel
+	calmAt      time.Time
+	suppressed  int
+
+	// Where the roll-up for the current window goes if no later report
+	// picks it up, and the timer that writes it when the window ends
+	out    io.Writer
+	logged bool
+	timer  *time.Timer
+}
+
+var consoleThrottle = &outputThrottle{}
+
+// admit decides how the next report to w is written. It returns the level
+// to render at and any pending roll-up line for the previous second.
+func (t *outputThrottle) admit(config ErrorConfig, w io.Writer) (throttleLevel, string) {
+	if config.ThrottleCompactAfter <= 0 {
+		return throttleFull, ""
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := now()
+	var rollup string
+	if current.Sub(t.windowStart) >= time.Second {
+		if t.suppressed > 0 {
+			rollup = t.rollupLine()
+		}
+		t.stopTimer()
+		t.windowStart = current
+		t.count = 0
+		t.suppressed = 0
+	}
+	t.count++
+
+	// Escalate while the burst lasts, stay escalated until things calm down
+	burst := throttleFull
+	switch {
+	case config.ThrottleRollupAfter > 0 && t.count > config.ThrottleRollupAfter:
+		burst = throttleRollup
+	case t.count > config.ThrottleCompactAfter:
+		burst = throttleCompact
+	}
+	if burst > throttleFull {
+		quiet := config.ThrottleQuietPeriod
+		if quiet <= 0 {
+			quiet = DefaultThrottleQuietPeriod
+		}
+		t.calmAt = current.Add(quiet)
+	} else if t.level > throttleFull && !current.Before(t.calmAt) {
+		t.level = throttleFull
+	}
+	if burst > t.level {
+		t.level = burst
+	}
+
+	if t.level == throttleRollup {
+		if t.suppressed == 0 {
+			window := t.windowStart
+			t.timer = time.AfterFunc(window.Add(time.Second).Sub(current), func() {
+				t.flush(window)
+			})
+		}
+		t.out, t.logged = w, config.LogToFile != ""
+		t.suppressed++
+	}
+	return t.level, rollup
+}
+
+// rollupLine describes the reports suppressed in the current window. The
+// log file is only mentioned when the reports went to one.
+func (t *outputThrottle) rollupLine() string {
+	line := fmt.Sprintf("+%d errors in the last second", t.suppressed)
+	if t.logged {
+		line += ", see log file"
+	}
+	return line + "\n"
+}
+
+// flush writes the pending roll-up line and closes the window it belongs
+// to. The timer passes the window it was set for, so a late timer cannot
+// cut the next window short; Close passes the zero time to flush whatever
+// is pending.
+func (t *outputThrottle) flush(window time.Time) {
+	consoleMu.Lock()
+	defer consoleMu.Unlock()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.suppressed == 0 || !window.IsZero() && !window.Equal(t.windowStart) {
+		return
+	}
+	fmt.Fprint(t.out, t.rollupLine())
+	t.stopTimer()
+	t.windowStart, t.count, t.suppressed = time.Time{}, 0, 0
+}
+
+// stopTimer cancels the pending roll-up timer. Callers hold t.mu.
+func (t *outputThrottle) stopTimer() {
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+}
@@ -0,0 +1,147 @@
+package catch
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// resetThrottle clears the process-wide console throttle for one test
+func resetThrottle(t *testing.T) {
+	reset := func() {
+		c := consoleThrottle
+		c.mu.Lock()
+		c.stopTimer()
+		c.windowStart, c.count, c.level, c.calmAt, c.suppressed = time.Time{}, 0, throttleFull, time.Time{}, 0
+		c.out, c.logged = nil, false
+		c.mu.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestDefaultConfigDoesNotThrottle(t *testing.T) {
+	resetThrottle(t)
+	for i := 0; i < 300; i++ {
+		if level, _ := consoleThrottle.admit(DefaultConfig, io.Discard); level != throttleFull {
+			t.Fatalf("report %d throttled to %d", i+1, level)
+		}
+	}
+}
+
+func TestThrottleEscalatesAndRecovers(t *testing.T) {
+	resetThrottle(t)
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer SetClockForTesting(clock)()
+	config := testConfig()
+	config.ThrottleCompactAfter = 2
+	config.ThrottleRollupAfter = 4
+
+	var levels []throttleLevel
+	for i := 0; i < 6; i++ {
+		level, _ := consoleThrottle.admit(config, io.Discard)
+		levels = append(levels, level)
+	}
+	want := []throttleLevel{throttleFull, throttleFull, throttleCompact, throttleCompact, throttleRollup, throttleRollup}
+	for i := range want {
+		if levels[i] != want[i] {
+			t.Fatalf("levels %v, want %v", levels, want)
+		}
+	}
+
+	clock.Advance(time.Second)
+	level, rollup := consoleThrottle.admit(config, io.Discard)
+	if level != throttleRollup || !strings.Contains(rollup, "+2 errors in the last second") {
+		t.Errorf("next second: level %d, rollup %q", level, rollup)
+	}
+	clock.Advance(DefaultThrottleQuietPeriod + time.Second)
+	if level, _ := consoleThrottle.admit(config, io.Discard); level != throttleFull {
+		t.Errorf("still throttled at %d after the quiet period", level)
+	}
+}
+
+func TestThrottledConsoleWritesCompactLines(t *testing.T) {
+	resetThrottle(t)
+	config := testConfig()
+	config.ThrottleCompactAfter = 1
+	buf := testCatch(t, config)
+	for i := 0; i < 3; i++ {
+		Catch.Err(errors.New("disk full"))
+	}
+	if got := countHeadlines(buf.String(), "disk full"); got != 3 {
+		t.Errorf("%d report lines, want 3:\n%s", got, buf)
+	}
+	if got := strings.Count(buf.String(), "-->"); got != 1 {
+		t.Errorf("%d full reports, want 1:\n%s", got, buf)
+	}
+}
+
+func TestThrottleRollupMentionsLogFileOnlyWhenLogging(t *testing.T) {
+	for _, logFile := range []string{"", "errors.log"} {
+		resetThrottle(t)
+		clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		restore := SetClockForTesting(clock)
+		config := testConfig()
+		config.ThrottleCompactAfter = 1
+		config.ThrottleRollupAfter = 1
+		config.LogToFile = logFile
+
+		for i := 0; i < 3; i++ {
+			consoleThrottle.admit(config, io.Discard)
+		}
+		clock.Advance(time.Second)
+		_, rollup := consoleThrottle.admit(config, io.Discard)
+		restore()
+
+		if got := strings.Contains(rollup, "see log file"); got != (logFile != "") {
+			t.Errorf("LogToFile %q: rollup %q", logFile, rollup)
+		}
+	}
+}
+
+func TestThrottleRollupFlushesWithoutLaterReport(t *testing.T) {
+	resetThrottle(t)
+	config := testConfig()
+	config.ThrottleCompactAfter = 1
+	config.ThrottleRollupAfter = 1
+	buf := testCatch(t, config)
+	for i := 0; i < 4; i++ {
+		Catch.Err(errors.New("disk full"))
+	}
+
+	// The timer writes the line once the second is over
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		consoleMu.Lock()
+		out := buf.String()
+		consoleMu.Unlock()
+		if strings.Contains(out, "+3 errors in the last second\n") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("no roll-up line after the window:\n%s", out)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCloseFlushesThrottleRollup(t *testing.T) {
+	resetThrottle(t)
+	var buf strings.Builder
+	config := testConfig()
+	config.Output = &buf
+	config.ThrottleCompactAfter = 1
+	config.ThrottleRollupAfter = 1
+	c := New(config)
+	for i := 0; i < 4; i++ {
+		c.Err(errors.New("disk full"))
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "+3 errors in the last second\n") {
+		t.Errorf("no roll-up line after Close:\n%s", buf.String())
+	}
+}
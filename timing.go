@@ -0,0 +1,182 @@
+package catch
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// timingPhase is a stage of handling an error
+type timingPhase int
+
+const (
+	phaseClassify timingPhase = iota // error code and suggestion
+	phaseAnalysis                    // context, source and enrichment
+	phaseRender                      // report text or JSON
+	phaseLog                         // log file writes
+	phaseHandler                     // the Handler, less its rendering
+	phaseTotal                       // the whole pipeline
+	numPhases
+)
+
+var phaseNames = [numPhases]string{"classify", "analysis", "render", "log", "handler", "total"}
+
+// timingEnv is GOCATCH_TIMING=1 at startup, which enables timing for
+// every catcher whatever its configuration
+var timingEnv = os.Getenv("GOCATCH_TIMING") == "1"
+
+// pipelineTiming accumulates the time spent in each phase for one error.
+// Its methods do nothing on a nil receiver, which is what newTiming
+// returns when timing is off.
+type pipelineTiming struct {
+	start time.Time
+	d     [numPhases]time.Duration
+}
+
+// newTiming starts timing a report, or returns nil when timing is off for
+// config; when off it costs these two checks
+func newTiming(config ErrorConfig) *pipelineTiming {
+	if !timingEnv && !config.Timing {
+		return nil
+	}
+	return &pipelineTiming{start: time.Now()}
+}
+
+// now returns the current time, or the zero time when not timing
+func (t *pipelineTiming) now() time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// add charges the time since start to phase
+func (t *pipelineTiming) add(phase timingPhase, start time.Time) {
+	if t != nil {
+		t.d[phase] += time.Since(start)
+	}
+}
+
+// get returns the time charged to phase so far
+func (t *pipelineTiming) get(phase timingPhase) time.Duration {
+	if t == nil {
+		return 0
+	}
+	return t.d[phase]
+}
+
+// finish records the total and adds the report to TimingStats
+func (t *pipelineTiming) finish() {
+	if t == nil {
+		return
+	}
+	t.d[phaseTotal] = time.Since(t.start)
+	timings.record(t.d)
+}
+
+// microseconds returns the phases measured so far, in microseconds, for
+// the JSON report
+func (t *pipelineTiming) microseconds() map[string]int64 {
+	if t == nil {
+		return nil
+	}
+	us := make(map[string]int64, numPhases)
+	for phase, d := range t.d {
+		if d > 0 {
+			us[phaseNames[phase]] = d.Microseconds()
+		}
+	}
+	return us
+}
+
+// renderTiming renders the dim breakdown line ending a report: the
+// phases completed before the console report was written
+func renderTiming(info ErrorInfo, config ErrorConfig) string {
+	t := info.timing
+	if t == nil {
+		return ""
+	}
+	var parts []string
+	for _, phase := range []timingPhase{phaseClassify, phaseAnalysis, phaseRender, phaseLog} {
+		parts = append(parts, fmt.Sprintf("%s %dµs", phaseNames[phase], t.d[phase].Microseconds()))
+	}
+	line := "timing: " + strings.Join(parts, ", ")
+	if config.UseColors {
+		return "  " + Gray + "= " + line + Reset + "\n\n"
+	}
+	return "  = " + line + "\n\n"
+}
+
+// TimingStat summarizes the time spent in one phase across reports
+type TimingStat struct {
+	Count         int
+	P50, P90, P99 time.Duration
+	Max           time.Duration
+}
+
+// maxTimingSamples bounds the samples kept per phase
+const maxTimingSamples = 1024
+
+// timingSamples holds recent per-phase durations
+type timingSamples struct {
+	mu      sync.Mutex
+	samples [numPhases][]time.Duration
+	next    int
+	count   int
+}
+
+var timings = &timingSamples{}
+
+// record adds one report's phase durations
+func (s *timingSamples) record(d [numPhases]time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	capacity := maxTimingSamples
+	if processLowMemory() {
+		capacity = lowMemoryTimingSamples
+	}
+	if s.next >= capacity {
+		s.next = 0
+	}
+	for phase := range d {
+		if len(s.samples[phase]) > capacity {
+			s.samples[phase] = slices.Clone(s.samples[phase][:capacity]) // Release the larger array
+		}
+		if len(s.samples[phase]) < capacity {
+			s.samples[phase] = append(s.samples[phase], d[phase])
+		} else {
+			s.samples[phase][s.next] = d[phase]
+		}
+	}
+	s.next = (s.next + 1) % capacity
+	s.count++
+}
+
+// TimingStats returns percentiles of the time spent in each phase of
+// handling an error (classify, analysis, render, log, handler and total)
+// over the last 1024 reports. It is empty unless timing is enabled, with
+// ErrorConfig.Timing or GOCATCH_TIMING=1.
+func TimingStats() map[string]TimingStat {
+	timings.mu.Lock()
+	defer timings.mu.Unlock()
+	if timings.count == 0 {
+		return nil
+	}
+	stats := make(map[string]TimingStat, numPhases)
+	for phase, samples := range timings.samples {
+		sorted := slices.Clone(samples)
+		slices.Sort(sorted)
+		at := func(p float64) time.Duration { return sorted[int(p*float64(len(sorted)-1))] }
+		stats[phaseNames[phase]] = TimingStat{
+			Count: timings.count,
+			P50:   at(0.50),
+			P90:   at(0.90),
+			P99:   at(0.99),
+			Max:   sorted[len(sorted)-1],
+		}
+	}
+	return stats
+}
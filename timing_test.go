@@ -0,0 +1,35 @@
+package catch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTimingIsPerCatcher(t *testing.T) {
+	if timingEnv {
+		t.Skip("GOCATCH_TIMING=1 enables timing for every catcher")
+	}
+	config := testConfig()
+	config.ShowSourceCode = false
+	global := testCatch(t, config)
+
+	var own strings.Builder
+	timedConfig := config
+	timedConfig.Output = &own
+	timedConfig.Timing = true
+	timed := New(timedConfig)
+
+	timed.Err(errors.New("timed failure"))
+	Err(errors.New("untimed failure"))
+
+	if !strings.Contains(own.String(), "= timing: classify ") {
+		t.Errorf("catcher with Timing lacks the breakdown:\n%s", own.String())
+	}
+	if strings.Contains(global.String(), "timing:") {
+		t.Errorf("global Catch timed a report after New(Timing: true):\n%s", global)
+	}
+	if stats := TimingStats(); stats["total"].Count == 0 {
+		t.Errorf("TimingStats = %v, want the timed report counted", stats)
+	}
+}
@@ -0,0 +1,96 @@
+package catch
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+)
+
+// trackedCloser is an io.Closer registered with Track
+type trackedCloser struct {
+	io.Closer
+	catcher  *ErrorCatcher
+	name     string
+	file     string // Where Track was called
+	line     int
+	function string
+	seq      uint64 // Registration order
+	closed   atomic.Bool
+}
+
+// Close closes the resource and stops tracking it
+func (t *trackedCloser) Close() error {
+	if t.closed.CompareAndSwap(false, true) {
+		t.catcher.resources.Delete(t)
+	}
+	return t.Closer.Close()
+}
+
+// resourceSeq orders tracked resources for the leak report
+var resourceSeq atomic.Uint64
+
+// Track registers c with the global catcher as an open resource and
+// returns a Closer that unregisters it. Resources still open at Close, or
+// before a fatal exit, are reported as leaks together with where they
+// were tracked.
+// Usage: f := catch.Must(os.Create(path)); defer catch.Track(f, path).Close()
+func Track(c io.Closer, name string) io.Closer {
+	return Catch.Track(c, name)
+}
+
+// Track registers c with this catcher; see the package-level Track
+func (e *ErrorCatcher) Track(c io.Closer, name string) io.Closer {
+	t := &trackedCloser{Closer: c, catcher: e, name: name, seq: resourceSeq.Add(1)}
+	if frames := reportFrames(1); len(frames) > 0 {
+		t.file, t.line, t.function = frames[0].File, frames[0].Line, shortFuncName(frames[0].Function)
+	}
+	e.resources.Store(t, struct{}{})
+	return t
+}
+
+// reportLeaks reports the tracked resources still open, in one report
+// located where the first of them was tracked. Leaks are warnings unless
+// LeaksAsErrors is set, and never exit the process themselves.
+func (e *ErrorCatcher) reportLeaks() {
+	var leaks []*trackedCloser
+	e.resources.Range(func(key, _ interface{}) bool {
+		if _, loaded := e.resources.LoadAndDelete(key); loaded {
+			leaks = append(leaks, key.(*trackedCloser))
+		}
+		return true
+	})
+	if len(leaks) == 0 {
+		return
+	}
+	sort.Slice(leaks, func(i, j int) bool { return leaks[i].seq < leaks[j].seq })
+
+	config := e.getConfig()
+	err := fmt.Errorf("%d tracked resource(s) not closed", len(leaks))
+	if len(leaks) == 1 {
+		err = fmt.Errorf("tracked resource %q not closed", leaks[0].name)
+	}
+	info := ErrorInfo{
+		Error:      err,
+		ErrorCode:  "RES001",
+		Suggestion: "close each resource when done with it, e.g. defer f.Close() right after opening",
+		Context:    make(map[string]interface{}),
+		Uptime:     now().Sub(processStart),
+		File:       leaks[0].file,
+		Line:       leaks[0].line,
+		Function:   leaks[0].function,
+		Severity:   LevelWarn,
+	}
+	if config.LeaksAsErrors {
+		info.Severity = LevelError
+	}
+	open := make([]string, len(leaks))
+	for i, leak := range leaks {
+		open[i] = fmt.Sprintf("%s (tracked at %s:%d in %s)", leak.name, filepath.Base(leak.file), leak.line, leak.function)
+	}
+	info.Context["open_resources"] = open
+	e.loadSources(&info, config)
+	info.opts.noExit = true
+	e.handleError(info)
+}
@@ -0,0 +1,84 @@
+package catch
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// nopCloser is a resource whose Close always succeeds
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// trackCatcher is a catcher handing reports to the returned recorder
+func trackCatcher(config ErrorConfig) (*ErrorCatcher, *recorder) {
+	r := &recorder{}
+	config.Handler = r
+	return New(config), r
+}
+
+func TestUnclosedTrackedResourceIsReportedAtClose(t *testing.T) {
+	c, rec := trackCatcher(testConfig())
+	a := c.Track(nopCloser{}, "a.db")
+	c.Track(nopCloser{}, "b.db") // Never closed
+	_, _, line, _ := runtime.Caller(0)
+	d := c.Track(nopCloser{}, "c.db")
+	a.Close()
+	d.Close()
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reports := rec.reports()
+	if len(reports) != 1 {
+		t.Fatalf("%d reports, want 1", len(reports))
+	}
+	info := reports[0]
+	if info.ErrorCode != "RES001" || info.Severity != LevelWarn {
+		t.Errorf("code %s, severity %v; want RES001 and a warning", info.ErrorCode, info.Severity)
+	}
+	if filepath.Base(info.File) != "track_test.go" || info.Line != line-1 {
+		t.Errorf("located at %s:%d, want track_test.go:%d", filepath.Base(info.File), info.Line, line-1)
+	}
+	open, _ := info.Context["open_resources"].([]string)
+	if len(open) != 1 || !strings.HasPrefix(open[0], "b.db (tracked at track_test.go:") {
+		t.Errorf("open resources %v, want only b.db", open)
+	}
+}
+
+func TestLeaksAsErrorsNeverExit(t *testing.T) {
+	exits := stubExit(t)
+	config := testConfig()
+	config.LeaksAsErrors = true
+	config.ExitOnError = true
+	c, rec := trackCatcher(config)
+	c.Track(nopCloser{}, "conn")
+
+	c.Close()
+
+	if reports := rec.reports(); len(reports) != 1 || reports[0].Severity != LevelError {
+		t.Fatalf("reports %+v, want one error", reports)
+	}
+	if len(*exits) != 0 {
+		t.Errorf("leak report exited with %v", *exits)
+	}
+}
+
+func TestLeaksAreReportedBeforeFatalExit(t *testing.T) {
+	exits := stubExit(t)
+	c, rec := trackCatcher(testConfig())
+	c.Track(nopCloser{}, "journal")
+
+	c.Fatal(errors.New("corrupt index"))
+
+	reports := rec.reports()
+	if len(reports) != 2 || reports[1].ErrorCode != "RES001" {
+		t.Fatalf("reports %+v, want the fatal error then the leak", reports)
+	}
+	if len(*exits) != 1 {
+		t.Errorf("exits %v, want one", *exits)
+	}
+}
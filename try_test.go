@@ -0,0 +1,90 @@
+package catch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// tryPanic returns what Try stores when the deferring function panics
+// with v
+func tryPanic(v any) (err error) {
+	defer Try()(&err)
+	panic(v)
+}
+
+func TestTryRecoversPanic(t *testing.T) {
+	config := testConfig()
+	config.ShowSourceCode = false
+	rec := recordCatch(t, config)
+
+	err := tryPanic("index out of range")
+	if err == nil || err.Error() != "panic: index out of range" {
+		t.Fatalf("Try stored %v, want the converted panic", err)
+	}
+	reports := rec.reports()
+	if len(reports) != 1 || reports[0].Error != err {
+		t.Fatalf("reports %v, want one for the stored error", reports)
+	}
+
+	sentinel := errors.New("quota exceeded")
+	if err := tryPanic(sentinel); err != sentinel {
+		t.Errorf("Try stored %v for an error panic, want the error itself", err)
+	}
+}
+
+func TestTryWrapsEarlierError(t *testing.T) {
+	testCatch(t, testConfig())
+	prior, later := errors.New("write failed"), errors.New("flush panicked")
+
+	err := tried(prior, later)
+	if !errors.Is(err, prior) || !errors.Is(err, later) {
+		t.Errorf("Try stored %v, want both the earlier error and the panic", err)
+	}
+}
+
+func TestTryWithoutPointerStillReports(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	func() {
+		defer Try()(nil)
+		panic("nil map write")
+	}()
+
+	if reports := rec.reports(); len(reports) != 1 || !strings.Contains(reports[0].Error.Error(), "nil map write") {
+		t.Errorf("reports %v, want the panic", reports)
+	}
+}
+
+func TestTry1(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+
+	if got := Try1(func() (int, error) { return 42, nil }); got != 42 {
+		t.Errorf("Try1 = %d, want 42", got)
+	}
+	if len(rec.reports()) != 0 {
+		t.Errorf("success reported %v", rec.reports())
+	}
+	if got := Try1(func() (int, error) { return 7, errors.New("parse failed") }); got != 7 {
+		t.Errorf("Try1 after an error = %d, want what fn returned", got)
+	}
+	if got := Try1(func() (int, error) { panic("boom") }); got != 0 {
+		t.Errorf("Try1 after a panic = %d, want the zero value", got)
+	}
+	reports := rec.reports()
+	if len(reports) != 2 || reports[0].Error.Error() != "parse failed" || reports[1].Error.Error() != "panic: boom" {
+		t.Errorf("reports %v, want the error then the panic", reports)
+	}
+}
+
+func TestTry2(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+
+	n, rest := Try2(func() (int, string, error) { return 3, "abc", nil })
+	if n != 3 || rest != "abc" {
+		t.Errorf("Try2 = %d, %q; want 3, abc", n, rest)
+	}
+	n, rest = Try2(func() (int, string, error) { panic("boom") })
+	if n != 0 || rest != "" || len(rec.reports()) != 1 {
+		t.Errorf("Try2 after a panic = %d, %q with %d reports; want zero values and one", n, rest, len(rec.reports()))
+	}
+}
@@ -0,0 +1,164 @@
+package catch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CheckRendering renders a sample report through each built-in sink of
+// config and returns a problem for every sink whose output would not
+// parse: JSON that is invalid or lacks the schema key, text that is not
+// valid UTF-8 or leaves an escape sequence unterminated or a color on at
+// the end of a line, and field selectors naming fields that don't exist.
+// Each problem names its sink. Custom Handlers and Loggers are not run,
+// since their output goes where gocatch can't read it back.
+func CheckRendering(config ErrorConfig) []error {
+	var problems []error
+	add := func(sink string, err error) {
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", sink, err))
+		}
+	}
+
+	add("fields", checkFields(config.Fields))
+	info := sampleInfo()
+
+	_, live := config.Handler.(*LiveRegion)
+	if consoleWriter(config) != nil || live {
+		text := config
+		text.UseColors = config.colorMode() != ColorNever && !config.accessible()
+		switch config.Format {
+		case "", FormatPretty:
+			add("console (pretty)", checkText(RenderReport(info, text)))
+			if config.ShowHints && config.HintText != "" {
+				add("console (hint)", checkText(config.HintText))
+			}
+		case FormatJSON:
+			add("console (json)", checkJSONFields(info, config.Fields))
+		default:
+			add("console", fmt.Errorf("unknown Format %q", config.Format))
+		}
+		if config.Format != FormatJSON && config.ThrottleCompactAfter > 0 {
+			add("console (compact)", checkText(renderCompact(info, text)))
+		}
+	}
+
+	if config.LogToFile != "" {
+		switch config.LogFormat {
+		case "", LogText, LogJSONL:
+			sink := fmt.Sprintf("log file (%s)", config.LogFormat)
+			if config.LogFormat == "" {
+				sink = "log file (text)"
+			}
+			entry, err := renderLogEntry(info, config)
+			if err != nil {
+				add(sink, err)
+				break
+			}
+			entry = Catch.stripANSI(entry)
+			if config.LogFormat == LogJSONL {
+				add(sink, checkJSON([]byte(entry)))
+			} else {
+				add(sink, checkText(entry))
+			}
+		default:
+			add("log file", fmt.Errorf("unknown LogFormat %q", config.LogFormat))
+		}
+	}
+	return problems
+}
+
+// sampleInfo is the report CheckRendering renders, with every section
+// that a real report can have filled in
+func sampleInfo() ErrorInfo {
+	return ErrorInfo{
+		Error:       errors.New("open config.yaml: no such file or directory"),
+		File:        "main.go",
+		Line:        12,
+		Function:    "main.run",
+		Context:     map[string]interface{}{"path": "config.yaml", "attempt": 2},
+		Stack:       []StackFrame{{File: "main.go", Line: 12, Function: "main.run"}, {File: "main.go", Line: 5, Function: "main.main"}},
+		SourceLines: []SourceLine{{Number: 11, Content: "func run() {"}, {Number: 12, Content: "\tcatch.Err(err)", IsError: true}, {Number: 13, Content: "}"}},
+		ErrorCode:   "FS001",
+		Suggestion:  "check that the file exists",
+		Severity:    LevelError,
+		GroupKey:    "0123456789abcdef",
+		ID:          "0123456789abcdef",
+		Time:        time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		Causes:      []string{"no such file or directory"},
+	}
+}
+
+// checkFields reports fields named in sel that FieldSelector doesn't know,
+// and fields both included and excluded
+func checkFields(sel FieldSelector) error {
+	var problems []string
+	for _, f := range append(append([]Field{}, sel.Include...), sel.Exclude...) {
+		if _, ok := jsonKeys[f]; !ok {
+			problems = append(problems, fmt.Sprintf("unknown field %q", f))
+		}
+	}
+	for _, f := range sel.Include {
+		if containsField(sel.Exclude, f) {
+			problems = append(problems, fmt.Sprintf("field %q is both included and excluded", f))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(problems, "; "))
+}
+
+// checkJSONFields checks the JSON object written for info under sel
+func checkJSONFields(info ErrorInfo, sel FieldSelector) error {
+	data, err := MarshalReportFields(info, sel)
+	if err != nil {
+		return err
+	}
+	return checkJSON(data)
+}
+
+// checkJSON checks that every line of data is a JSON object with a
+// string schema key
+func checkJSON(data []byte) error {
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		var doc map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(line), &doc); err != nil {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+		var schema string
+		if err := json.Unmarshal(doc["schema"], &schema); err != nil || schema == "" {
+			return errors.New("JSON object without a schema key")
+		}
+	}
+	return nil
+}
+
+// checkText checks that s is valid UTF-8, that every escape sequence in
+// it is complete, and that no color is left on at the end of a line
+func checkText(s string) error {
+	if !utf8.ValidString(s) {
+		return errors.New("invalid UTF-8")
+	}
+	for n, line := range strings.Split(s, "\n") {
+		colored := false
+		for i := strings.IndexByte(line, '\033'); i >= 0; i = strings.IndexByte(line, '\033') {
+			seq := escapePattern.FindString(line[i:])
+			if seq == "" || !strings.HasPrefix(line[i:], seq) {
+				return fmt.Errorf("unterminated escape sequence on line %d", n+1)
+			}
+			if strings.HasSuffix(seq, "m") {
+				colored = seq != Reset && seq != "\033[m"
+			}
+			line = line[i+len(seq):]
+		}
+		if colored {
+			return fmt.Errorf("color not reset at the end of line %d", n+1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package catch
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// renderingProblems returns what CheckRendering finds in config, joined
+func renderingProblems(config ErrorConfig) string {
+	var msgs []string
+	for _, problem := range CheckRendering(config) {
+		msgs = append(msgs, problem.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func TestCheckRenderingAcceptsEverySink(t *testing.T) {
+	for _, format := range []OutputFormat{FormatPretty, FormatJSON} {
+		for _, logFormat := range []LogFormat{LogText, LogJSONL} {
+			config := testConfig()
+			config.Output = io.Discard
+			config.Colors = ColorAlways
+			config.Format = format
+			config.LogToFile = filepath.Join(t.TempDir(), "app.log")
+			config.LogFormat = logFormat
+			config.ThrottleCompactAfter = 5
+			if problems := renderingProblems(config); problems != "" {
+				t.Errorf("%s console with %s log: %s", format, logFormat, problems)
+			}
+		}
+	}
+}
+
+func TestCheckRenderingBrokenHint(t *testing.T) {
+	for _, tc := range []struct {
+		hint, want string
+	}{
+		{"press \x1b[31 for help", "console (hint): unterminated escape sequence on line 1"},
+		{"\x1b[31mred all the way", "console (hint): color not reset at the end of line 1"},
+		{"caf\xe9", "console (hint): invalid UTF-8"},
+	} {
+		config := testConfig()
+		config.Output = io.Discard
+		config.ShowHints = true
+		config.HintText = tc.hint
+		if problems := renderingProblems(config); problems != tc.want {
+			t.Errorf("hint %q: problems %q, want %q", tc.hint, problems, tc.want)
+		}
+	}
+}
+
+func TestCheckRenderingBadFieldSelector(t *testing.T) {
+	config := testConfig()
+	config.Output = io.Discard
+	config.Format = FormatJSON
+	config.Fields = FieldSelector{Include: []Field{FieldMessage, FieldStack, "stacktrace"}, Exclude: []Field{FieldStack}}
+
+	problems := renderingProblems(config)
+	for _, want := range []string{`fields: unknown field "stacktrace"`, `field "stack" is both included and excluded`} {
+		if !strings.Contains(problems, want) {
+			t.Errorf("problems lack %q:\n%s", want, problems)
+		}
+	}
+}
+
+func TestCheckRenderingUnknownFormats(t *testing.T) {
+	config := testConfig()
+	config.Output = io.Discard
+	config.Format = "yaml"
+	config.LogToFile = filepath.Join(t.TempDir(), "app.log")
+	config.LogFormat = "xml"
+
+	want := "console: unknown Format \"yaml\"\nlog file: unknown LogFormat \"xml\""
+	if problems := renderingProblems(config); problems != want {
+		t.Errorf("problems %q, want %q", problems, want)
+	}
+}
+
+func TestConfigureValidatesRendering(t *testing.T) {
+	var reports []ErrorInfo
+	config := testConfig()
+	config.Output = io.Discard
+	config.ShowHints = true
+	config.HintText = "\x1b[31mred all the way"
+	c := New(config)
+	c.RegisterHook(func(info *ErrorInfo) HookAction {
+		reports = append(reports, *info)
+		return Continue
+	})
+
+	config.ValidateRendering = true
+	line := lineOf() + 1
+	c.Configure(config)
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want one warning", len(reports))
+	}
+	if w := reports[0]; w.ErrorCode != "CFG001" || w.Severity != LevelWarn || w.Line != line || !strings.Contains(w.Error.Error(), "console (hint)") {
+		t.Errorf("warning %s at line %d: %v", w.ErrorCode, w.Line, w.Error)
+	}
+
+	var dump strings.Builder
+	c.DebugConfig(&dump)
+	if !strings.Contains(dump.String(), "  rendering:\n    console (hint): color not reset") {
+		t.Errorf("DebugConfig lacks the problem:\n%s", dump.String())
+	}
+}
@@ -0,0 +1,73 @@
+package catch
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	maxDetailLines = 40   // Lines of %+v details shown at most
+	maxDetailBytes = 4096 // Bytes of %+v details shown at most
+)
+
+// safeFormat formats v with the given verb, recovering from panics in
+// custom Error, String or Format methods
+func safeFormat(verb string, v interface{}) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			s = fmt.Sprintf("(%T: formatting panicked: %v)", v, r)
+		}
+	}()
+	return fmt.Sprintf(verb, v)
+}
+
+// verboseDetails returns what %+v shows beyond Error(), or "" when it
+// adds nothing
+func verboseDetails(err error) string {
+	msg := safeFormat("%v", err)
+	verbose := safeFormat("%+v", err)
+	if strings.TrimSpace(verbose) == strings.TrimSpace(msg) {
+		return ""
+	}
+
+	details := strings.TrimPrefix(verbose, msg)
+	details = strings.Trim(details, "\n")
+	if strings.TrimSpace(details) == "" {
+		return ""
+	}
+
+	lines := strings.Split(details, "\n")
+	truncated := false
+	if len(lines) > maxDetailLines {
+		lines = lines[:maxDetailLines]
+		truncated = true
+	}
+	details = strings.Join(lines, "\n")
+	if len(details) > maxDetailBytes {
+		details = strings.ToValidUTF8(details[:maxDetailBytes], "")
+		truncated = true
+	}
+	if truncated {
+		details += "\n…"
+	}
+	return details
+}
+
+// RenderDetails renders the "= details:" block with the extra %+v output
+func RenderDetails(info ErrorInfo, config ErrorConfig) string {
+	if info.Details == "" {
+		return ""
+	}
+
+	var output strings.Builder
+	if config.UseColors {
+		output.WriteString(fmt.Sprintf("  %s=%s %sdetails:%s\n", Blue+Bold, Reset, Yellow+Bold, Reset))
+	} else {
+		output.WriteString("  = details:\n")
+	}
+	for _, line := range strings.Split(info.Details, "\n") {
+		output.WriteString("    " + line + "\n")
+	}
+	output.WriteString("\n")
+	return output.String()
+}
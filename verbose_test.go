@@ -0,0 +1,93 @@
+package catch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// apiError prints its request ID and response body under %+v only
+type apiError struct {
+	status    int
+	requestID string
+	body      string
+}
+
+func (e *apiError) Error() string { return fmt.Sprintf("api: status %d", e.status) }
+
+func (e *apiError) Format(s fmt.State, verb rune) {
+	fmt.Fprint(s, e.Error())
+	if verb == 'v' && s.Flag('+') {
+		fmt.Fprintf(s, "\nrequest id: %s\nresponse body:\n%s", e.requestID, e.body)
+	}
+}
+
+// panickyFormatter panics when formatted with %+v
+type panickyFormatter struct{}
+
+func (panickyFormatter) Error() string { return "panicky" }
+
+func (panickyFormatter) Format(s fmt.State, verb rune) {
+	if s.Flag('+') {
+		panic("no verbose form")
+	}
+	fmt.Fprint(s, "panicky")
+}
+
+func TestVerboseDetailsRendered(t *testing.T) {
+	config := testConfig()
+	config.ShowVerboseError = true
+	config.ShowSourceCode = false
+	out := testCatch(t, config)
+
+	Err(&apiError{status: 502, requestID: "req-9f2", body: `{"error":"upstream"}`})
+	want := "  = details:\n    request id: req-9f2\n    response body:\n    {\"error\":\"upstream\"}\n\n"
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("report lacks %q:\n%s", want, out)
+	}
+	if countHeadlines(out.String(), "api: status 502") != 1 {
+		t.Errorf("headline changed:\n%s", out)
+	}
+}
+
+func TestVerboseDetailsInJSON(t *testing.T) {
+	config := testConfig()
+	config.ShowVerboseError = true
+	config.Format = FormatJSON
+	out := testCatch(t, config)
+
+	Err(&apiError{status: 502, requestID: "req-9f2", body: "bad gateway"})
+	if !strings.Contains(out.String(), `"details":"request id: req-9f2\nresponse body:\nbad gateway"`) {
+		t.Errorf("JSON report lacks details:\n%s", out)
+	}
+}
+
+func TestVerboseDetailsOffByDefault(t *testing.T) {
+	rec := recordCatch(t, testConfig())
+	Err(&apiError{status: 502, requestID: "req-9f2", body: "bad gateway"})
+	if d := rec.reports()[0].Details; d != "" {
+		t.Errorf("details = %q without ShowVerboseError", d)
+	}
+}
+
+func TestVerboseDetailsAddNothing(t *testing.T) {
+	for _, err := range []error{errors.New("plain"), fmt.Errorf("outer: %w", errors.New("inner"))} {
+		if got := verboseDetails(err); got != "" {
+			t.Errorf("details of %q = %q, want none", err, got)
+		}
+	}
+}
+
+func TestVerboseDetailsSurviveFormatPanics(t *testing.T) {
+	if got := verboseDetails(panickyFormatter{}); !strings.Contains(got, "no verbose form") {
+		t.Errorf("details = %q, want the panic noted", got)
+	}
+}
+
+func TestVerboseDetailsCapped(t *testing.T) {
+	got := verboseDetails(&apiError{status: 500, requestID: "r", body: strings.Repeat("line\n", maxDetailLines+10)})
+	if n := strings.Count(got, "\n"); n != maxDetailLines || !strings.HasSuffix(got, "\n…") {
+		t.Errorf("details have %d lines, want %d and a trailing …:\n%s", n, maxDetailLines, got)
+	}
+}